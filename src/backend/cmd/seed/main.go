@@ -13,6 +13,7 @@ import (
 	"evening-gown/internal/config"
 	"evening-gown/internal/database"
 	"evening-gown/internal/logging"
+	"evening-gown/internal/media"
 	"evening-gown/internal/model"
 
 	"gorm.io/gorm"
@@ -72,6 +73,8 @@ func main() {
 func seedAll(db *gorm.DB) error {
 	now := time.Now().UTC()
 
+	// The demo products' seasons and categories are default taxonomy terms (seeded by AutoMigrate).
+
 		detail1 := mustJSON(map[string]any{
 		"title_i18n": map[string]any{"zh": "白色幻影礼服", "en": "FLEURLIS Gown"},
 		"specs": []any{
@@ -195,7 +198,11 @@ func upsertProduct(db *gorm.DB, p model.Product) error {
 	var existing model.Product
 	err := db.Where("style_no = ?", p.StyleNo).First(&existing).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
-		return db.Create(&p).Error
+		if err := db.Create(&p).Error; err != nil {
+			return err
+		}
+		_, err := media.SyncProductAssets(context.Background(), db, p.ID)
+		return err
 	}
 	if err != nil {
 		return err
@@ -216,7 +223,11 @@ func upsertProduct(db *gorm.DB, p model.Product) error {
 		"deleted_at":      nil,
 	}
 
-	return db.Model(&model.Product{}).Where("id = ?", existing.ID).Updates(updates).Error
+	if err := db.Model(&model.Product{}).Where("id = ?", existing.ID).Updates(updates).Error; err != nil {
+		return err
+	}
+	_, err = media.SyncProductAssets(context.Background(), db, existing.ID)
+	return err
 }
 
 func upsertUpdate(db *gorm.DB, u model.UpdatePost) error {
@@ -19,9 +19,15 @@ import (
 	"evening-gown/internal/handler/health"
 	publicHandlers "evening-gown/internal/handler/public"
 	"evening-gown/internal/logging"
+	"evening-gown/internal/mail"
+	"evening-gown/internal/media"
 	"evening-gown/internal/middleware"
+	"evening-gown/internal/ratelimit"
 	"evening-gown/internal/router"
+	"evening-gown/internal/scheduler"
+	"evening-gown/internal/security"
 	"evening-gown/internal/storage"
+	"evening-gown/internal/trash"
 
 	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
@@ -67,13 +73,13 @@ func Run() error {
 
 	// JWT service (shared by admin auth middleware).
 	var jwtSvc *jwtauth.Service
-	if cfg.JWT.Secret != "" {
+	if cfg.JWT.Enabled() {
 		jwtSvc, err = jwtauth.New(cfg.JWT)
 		if err != nil {
 			return err
 		}
 	} else {
-		logger.Info("jwt disabled: JWT_SECRET not set")
+		logger.Info("jwt disabled: JWT_SECRET (or JWT_PRIVATE_KEY_FILE) not set")
 	}
 
 	var redisClient *redis.Client
@@ -101,20 +107,37 @@ func Run() error {
 
 	// Legacy auth handler (dev-only token issuer / verify helper).
 	var authHandler *authHandlerPkg.Handler
-	if cfg.JWT.Secret != "" {
-		authHandler = authHandlerPkg.New(cfg.JWT)
+	if jwtSvc != nil {
+		authHandler = authHandlerPkg.NewWithService(jwtSvc)
 	}
 
 	healthHandler := health.New(db, redisClient, minioClient)
 	publicCache := cache.NewPublicCache(redisClient)
 
+	previews := security.NewPreviewSigner(cfg.Preview.Secret)
+	if previews == nil {
+		logger.Info("preview links disabled: PREVIEW_SECRET not set")
+	}
+
 	deps := router.Dependencies{Health: healthHandler, Auth: authHandler, EnableDevTokenIssuer: cfg.Dev.EnableDevTokenIssuer}
+	// Width variants of product images (?w=), advertised as srcsets in product payloads.
+	imageVariants := publicHandlers.NewImageVariants(db, minioClient, cfg.Minio, cfg.Upload)
+	if minioClient != nil {
+		deps.Public.Assets = publicHandlers.NewAssetsHandlerWithPreview(db, minioClient, cfg.Minio, publicCache, previews).WithVariants(imageVariants)
+	}
+
+	// Purged products take their images along when MinIO is configured.
+	var objects trash.ObjectRemover
 	if minioClient != nil {
-		deps.Public.Assets = publicHandlers.NewAssetsHandler(db, minioClient, cfg.Minio, publicCache)
+		objects = trash.RemoverFunc(func(ctx context.Context, key string) error {
+			return storage.RemoveImage(ctx, minioClient, cfg.Minio, key)
+		})
 	}
+	var purger *trash.Purger
 
 	// Business APIs require Postgres.
 	if db != nil {
+		purger = trash.NewPurger(db, objects, cfg.Jobs.TrashRetention, logger)
 		if err := bootstrap.AutoMigrate(db); err != nil {
 			return err
 		}
@@ -127,28 +150,105 @@ func Run() error {
 			}
 		}
 
-		deps.Public.Products = publicHandlers.NewProductsHandler(db, publicCache)
-		deps.Public.Updates = publicHandlers.NewUpdatesHandler(db, publicCache)
+		deps.Public.Products = publicHandlers.NewProductsHandlerWithPreview(db, publicCache, previews).WithImageWidths(imageVariants.Widths())
+		deps.Public.Updates = publicHandlers.NewUpdatesHandlerWithPreview(db, publicCache, previews)
 		deps.Public.Contacts = publicHandlers.NewContactsHandlerWithRedis(db, redisClient)
 		deps.Public.Events = publicHandlers.NewEventsHandler(db)
+		deps.Public.Taxonomy = publicHandlers.NewTaxonomyHandler(db, publicCache)
 
-		deps.Admin.Auth = adminHandlers.NewAuthHandler(db, jwtSvc)
+		passwordPolicy := security.PasswordPolicy{
+			MinLength:   cfg.Auth.PasswordMinLength,
+			MinClasses:  cfg.Auth.PasswordMinClasses,
+			DenyCommon:  cfg.Auth.PasswordDenyCommon,
+			HistorySize: cfg.Auth.PasswordHistory,
+			BcryptCost:  cfg.Auth.BcryptCost,
+		}
+		lockout := security.LockoutPolicy{
+			Threshold:   cfg.Auth.LockoutThreshold,
+			Duration:    cfg.Auth.LockoutDuration,
+			MaxDuration: cfg.Auth.LockoutMaxDuration,
+		}
+		var unknownLogins ratelimit.Limiter
+		if redisClient != nil && lockout.Enabled() {
+			unknownLogins = ratelimit.NewRedis(redisClient, "eg:login_unknown", lockout.Threshold-1, lockout.Duration)
+		}
+		deps.Admin.Auth = adminHandlers.NewAuthHandlerWithOptions(db, jwtSvc, adminHandlers.AuthHandlerOptions{
+			Lockout:             lockout,
+			UnknownLoginLimiter: unknownLogins,
+			TOTPIssuer:          cfg.Auth.TOTPIssuer,
+			MFAPendingTTL:       cfg.Auth.MFAPendingTTL,
+			TOTPKey:             cfg.Auth.TOTPKey,
+			PasswordPolicy:      passwordPolicy,
+		})
 		if minioClient != nil {
 			deps.Admin.Assets = adminHandlers.NewAssetsHandler(db, minioClient, cfg.Minio)
 		}
-		deps.Admin.Uploads = adminHandlers.NewUploadsHandler(minioClient, cfg.Minio, cfg.Upload)
+		deps.Admin.Uploads = adminHandlers.NewUploadsHandler(db, minioClient, cfg.Minio, cfg.Upload)
+		deps.Admin.Media = adminHandlers.NewMediaHandler(db)
 		deps.Admin.Products = adminHandlers.NewProductsHandler(db, publicCache)
 		deps.Admin.Updates = adminHandlers.NewUpdatesHandler(db, publicCache)
+		deps.Admin.Taxonomy = adminHandlers.NewTaxonomyHandler(db, publicCache)
 		deps.Admin.Contacts = adminHandlers.NewContactsHandlerWithRedis(db, redisClient)
 		deps.Admin.Events = adminHandlers.NewEventsHandlerWithRedis(db, redisClient)
 		deps.Admin.Settings = adminHandlers.NewSettingsHandler(db)
+		deps.Admin.Users = adminHandlers.NewUsersHandlerWithPasswordPolicy(db, passwordPolicy)
+		mailer, err := mail.New(cfg.Mail, logger)
+		if err != nil {
+			return err
+		}
+		if mailer == nil {
+			logger.Info("password reset emails disabled: MAIL_DRIVER not configured")
+		}
+		deps.Admin.PasswordReset = adminHandlers.NewPasswordResetHandler(db, mailer, adminHandlers.PasswordResetOptions{
+			ResetURL:       cfg.Auth.PasswordResetURL,
+			TTL:            cfg.Auth.PasswordResetTTL,
+			PasswordPolicy: passwordPolicy,
+		})
 		deps.Admin.AuthMiddleware = middleware.AdminAuth(db, jwtSvc)
+		deps.Admin.Audit = adminHandlers.NewAuditHandler(db)
+		deps.Admin.Previews = adminHandlers.NewPreviewsHandler(db, previews, adminHandlers.PreviewOptions{
+			TTL:    cfg.Preview.TTL,
+			MaxTTL: cfg.Preview.MaxTTL,
+		})
+		deps.Admin.Trash = adminHandlers.NewTrashHandler(db, publicCache, redisClient, purger)
+		deps.Admin.AuditMiddleware = middleware.Audit(db)
+		if limiter := ratelimit.New(redisClient, "eg:ratelimit", cfg.Auth.LoginRateLimit, cfg.Auth.LoginRateLimitWindow); limiter != nil {
+			deps.Admin.LoginRateLimit = middleware.RateLimit(limiter, "admin_login")
+		}
 	} else {
 		logger.Info("business APIs disabled: postgres not configured")
 	}
 
 	r := router.New(deps)
 
+	// Background jobs stop before the deferred DB/Redis closes run.
+	jobs := scheduler.NewRunner(logger)
+	if db != nil {
+		jobs.Add(scheduler.Job{
+			Name:     "publish_scheduler",
+			Interval: cfg.Jobs.PublishSchedulerInterval,
+			Run:      scheduler.NewPublisher(db, publicCache).Run,
+		})
+		jobs.Add(scheduler.Job{
+			Name:     "trash_purge",
+			Interval: cfg.Jobs.TrashPurgeInterval,
+			Run:      purger.Run,
+		})
+		if store := media.NewMinioStore(minioClient, cfg.Minio); store != nil {
+			jobs.Add(scheduler.Job{
+				Name:     "asset_gc",
+				Interval: cfg.Jobs.AssetGCInterval,
+				Run:      media.NewCollector(db, store, cfg.Jobs.AssetGCGrace, logger).Run,
+			})
+		}
+	}
+	jobsCtx, stopJobs := context.WithCancel(ctx)
+	jobs.Start(jobsCtx)
+	defer func() {
+		stopJobs()
+		jobs.Wait()
+	}()
+
 	srv := &http.Server{
 		Addr:    cfg.App.Addr(),
 		Handler: r,
@@ -0,0 +1,187 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"evening-gown/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// Actor identifies who performed a backoffice request.
+//
+// It is attached to the request context by middleware.Audit, so callers below the HTTP layer
+// never need *gin.Context to record an entry.
+type Actor struct {
+	UserID    uint
+	Email     string
+	Role      string
+	RequestID string
+	IP        string
+	UserAgent string
+}
+
+// Entry describes one mutation. Before/After are any JSON-serializable values
+// (usually the model before and after the change); nil means "did not exist".
+type Entry struct {
+	Action     string
+	EntityType string
+	EntityID   string
+	Before     any
+	After      any
+}
+
+type ctxKey struct{}
+
+// scope is the per-request audit state stored in the context.
+type scope struct {
+	actor    Actor
+	recorded bool
+}
+
+// WithActor returns a context carrying actor. Entries recorded with it are attributed to actor.
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, ctxKey{}, &scope{actor: actor})
+}
+
+// ActorFrom returns the actor attached by WithActor.
+func ActorFrom(ctx context.Context) (Actor, bool) {
+	s, ok := ctx.Value(ctxKey{}).(*scope)
+	if !ok || s == nil {
+		return Actor{}, false
+	}
+	return s.actor, true
+}
+
+// Recorded reports whether an entry was recorded with ctx (used by the middleware fallback).
+func Recorded(ctx context.Context) bool {
+	s, ok := ctx.Value(ctxKey{}).(*scope)
+	return ok && s != nil && s.recorded
+}
+
+// Record stores e attributed to the actor in ctx. db may be a transaction.
+func Record(ctx context.Context, db *gorm.DB, e Entry) error {
+	changes, err := Diff(e.Before, e.After)
+	if err != nil {
+		return err
+	}
+
+	row := model.AuditLog{
+		Action:     strings.TrimSpace(e.Action),
+		EntityType: strings.TrimSpace(e.EntityType),
+		EntityID:   strings.TrimSpace(e.EntityID),
+		Changes:    changes,
+	}
+	s, _ := ctx.Value(ctxKey{}).(*scope)
+	if s != nil {
+		if s.actor.UserID != 0 {
+			id := s.actor.UserID
+			row.ActorID = &id
+		}
+		row.ActorEmail = s.actor.Email
+		row.ActorRole = s.actor.Role
+		row.RequestID = s.actor.RequestID
+		row.IP = s.actor.IP
+		row.UserAgent = s.actor.UserAgent
+	}
+
+	if err := db.WithContext(ctx).Create(&row).Error; err != nil {
+		return err
+	}
+	if s != nil {
+		s.recorded = true
+	}
+	return nil
+}
+
+// Change is one field of a diff.
+type Change struct {
+	From json.RawMessage `json:"from"`
+	To   json.RawMessage `json:"to"`
+}
+
+// Diff compares the JSON objects of before and after and returns the changed top-level fields
+// as {"field": {"from": ..., "to": ...}}. It returns nil when nothing changed.
+//
+// A nil before (create) or after (delete) diffs against an empty object, so every field is listed.
+// Timestamps maintained by the database (updatedAt) are ignored to keep diffs readable.
+func Diff(before, after any) (json.RawMessage, error) {
+	b, err := toFields(before)
+	if err != nil {
+		return nil, err
+	}
+	a, err := toFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(a)+len(b))
+	seen := map[string]bool{}
+	for _, m := range []map[string]json.RawMessage{b, a} {
+		for k := range m {
+			if !seen[k] && !ignoredField(k) {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+	sort.Strings(keys)
+
+	out := map[string]Change{}
+	for _, k := range keys {
+		from, to := normalizeJSON(b[k]), normalizeJSON(a[k])
+		if bytes.Equal(from, to) {
+			continue
+		}
+		out[k] = Change{From: from, To: to}
+	}
+	if len(out) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(out)
+}
+
+func toFields(v any) (map[string]json.RawMessage, error) {
+	if v == nil {
+		return map[string]json.RawMessage{}, nil
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.Equal(raw, []byte("null")) {
+		return map[string]json.RawMessage{}, nil
+	}
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &m); err != nil {
+		// Not an object: diff it as a single value.
+		return map[string]json.RawMessage{"value": raw}, nil
+	}
+	return m, nil
+}
+
+// normalizeJSON compacts a value so formatting differences do not show up as changes.
+// Missing values become JSON null.
+func normalizeJSON(raw json.RawMessage) json.RawMessage {
+	if len(raw) == 0 {
+		return json.RawMessage("null")
+	}
+	var v any
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return raw
+	}
+	// Re-marshal: sorts object keys and drops insignificant whitespace.
+	out, err := json.Marshal(v)
+	if err != nil {
+		return raw
+	}
+	return out
+}
+
+func ignoredField(k string) bool {
+	return k == "updatedAt"
+}
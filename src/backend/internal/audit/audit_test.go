@@ -0,0 +1,62 @@
+package audit
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+type dress struct {
+	ID        uint            `json:"id"`
+	StyleNo   string          `json:"styleNo"`
+	Secret    string          `json:"-"`
+	Detail    json.RawMessage `json:"detail"`
+	UpdatedAt string          `json:"updatedAt"`
+}
+
+func TestDiff_ChangedFieldsOnly(t *testing.T) {
+	before := dress{ID: 1, StyleNo: "EG-001", Secret: "a", Detail: json.RawMessage(`{"b":1, "a":2}`), UpdatedAt: "t1"}
+	after := dress{ID: 1, StyleNo: "EG-002", Secret: "b", Detail: json.RawMessage(`{"a":2,"b":1}`), UpdatedAt: "t2"}
+
+	raw, err := Diff(before, after)
+	if err != nil {
+		t.Fatalf("diff: %v", err)
+	}
+	var got map[string]Change
+	if err := json.Unmarshal(raw, &got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	want := map[string]Change{"styleNo": {From: json.RawMessage(`"EG-001"`), To: json.RawMessage(`"EG-002"`)}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected diff: %s", raw)
+	}
+}
+
+func TestDiff_CreateDeleteAndNoop(t *testing.T) {
+	d := &dress{ID: 7, StyleNo: "EG-007"}
+
+	raw, err := Diff(nil, d)
+	if err != nil {
+		t.Fatalf("diff: %v", err)
+	}
+	var created map[string]Change
+	_ = json.Unmarshal(raw, &created)
+	if string(created["styleNo"].From) != "null" || string(created["styleNo"].To) != `"EG-007"` {
+		t.Fatalf("unexpected create diff: %s", raw)
+	}
+
+	var missing *dress
+	raw, err = Diff(d, missing)
+	if err != nil {
+		t.Fatalf("diff: %v", err)
+	}
+	var deleted map[string]Change
+	_ = json.Unmarshal(raw, &deleted)
+	if string(deleted["id"].From) != "7" || string(deleted["id"].To) != "null" {
+		t.Fatalf("unexpected delete diff: %s", raw)
+	}
+
+	if raw, _ := Diff(d, d); raw != nil {
+		t.Fatalf("expected no diff, got %s", raw)
+	}
+}
@@ -1,6 +1,8 @@
 package auth
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"strings"
@@ -18,9 +20,20 @@ var (
 	ErrJWTMissingSecret = errors.New("missing jwt secret")
 )
 
+// Service issues and validates JWTs.
+//
+// Tokens are signed with one active key (HS256 secret, RSA or Ed25519 private key) and carry
+// its "kid" header. Verification accepts any configured key, so a previous key can stay
+// trusted during a rotation window (see keys.go).
 type Service struct {
-	cfg config.JWTConfig
-	key []byte
+	cfg       config.JWTConfig
+	signer    signingKey
+	verifiers map[string]verificationKey
+	// legacyHMAC verifies tokens without a kid header (issued before kid was introduced).
+	legacyHMAC []byte
+	// legacyUntil ends legacyHMAC acceptance (zero: no cutoff, i.e. HS256 is the active key).
+	legacyUntil time.Time
+	methods     []string
 }
 
 // AdminClaims extends the standard registered claims with a password update marker.
@@ -31,15 +44,82 @@ type AdminClaims struct {
 	jwt.RegisteredClaims
 	PasswordUpdatedAt int64 `json:"pwd_at,omitempty"`
 	// TokenType distinguishes access vs refresh tokens.
-	// Values: "access" | "refresh". Empty means legacy access token.
+	// Values: "access" | "refresh" | "mfa_pending". Empty means legacy access token.
 	TokenType string `json:"token_type,omitempty"`
+	// SessionID links the token to a server-side session (model.Session).
+	// Empty means a legacy token issued before sessions existed.
+	// Refresh tokens also carry a unique jti (RegisteredClaims.ID) used for rotation.
+	SessionID string `json:"sid,omitempty"`
+}
+
+// NewTokenID returns a random 128-bit identifier (hex) for session ids and jti claims.
+func NewTokenID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("generate token id: %w", err)
+	}
+	return hex.EncodeToString(b[:]), nil
 }
 
 func New(cfg config.JWTConfig) (*Service, error) {
-	if strings.TrimSpace(cfg.Secret) == "" {
-		return nil, ErrJWTMissingSecret
+	s := &Service{cfg: cfg, verifiers: map[string]verificationKey{}}
+	if err := s.loadKeys(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// sign signs claims with the active key and sets the kid header.
+func (s *Service) sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(s.signer.method, claims)
+	if s.signer.kid != "" {
+		token.Header["kid"] = s.signer.kid
+	}
+	ss, err := token.SignedString(s.signer.key)
+	if err != nil {
+		return "", fmt.Errorf("sign token: %w", err)
+	}
+	return ss, nil
+}
+
+// parserOptions pins accepted algorithms (no alg=none) plus issuer/audience checks.
+func (s *Service) parserOptions() []jwt.ParserOption {
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods(s.methods),
+	}
+	if strings.TrimSpace(s.cfg.Issuer) != "" {
+		opts = append(opts, jwt.WithIssuer(s.cfg.Issuer))
+	}
+	if strings.TrimSpace(s.cfg.Audience) != "" {
+		opts = append(opts, jwt.WithAudience(s.cfg.Audience))
+	}
+	return opts
+}
+
+// keyFunc resolves the verification key by kid and rejects algorithm mismatches
+// (e.g. an HS256 token "signed" with an RSA public key).
+func (s *Service) keyFunc(t *jwt.Token) (any, error) {
+	if t.Method == nil {
+		return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
+	}
+	kid, _ := t.Header["kid"].(string)
+	if kid == "" {
+		if s.legacyHMAC == nil || t.Method.Alg() != jwt.SigningMethodHS256.Alg() {
+			return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
+		}
+		if !s.legacyUntil.IsZero() && time.Now().After(s.legacyUntil) {
+			return nil, fmt.Errorf("legacy hs256 tokens no longer accepted")
+		}
+		return s.legacyHMAC, nil
+	}
+	v, ok := s.verifiers[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id: %s", kid)
+	}
+	if t.Method.Alg() != v.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
 	}
-	return &Service{cfg: cfg, key: []byte(cfg.Secret)}, nil
+	return v.key, nil
 }
 
 func (s *Service) IssueToken(subject string) (tokenString string, expiresAt time.Time, err error) {
@@ -65,16 +145,16 @@ func (s *Service) IssueToken(subject string) (tokenString string, expiresAt time
 		claims.Audience = jwt.ClaimStrings{s.cfg.Audience}
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	ss, err := token.SignedString(s.key)
+	ss, err := s.sign(claims)
 	if err != nil {
-		return "", time.Time{}, fmt.Errorf("sign token: %w", err)
+		return "", time.Time{}, err
 	}
 	return ss, expiresAt, nil
 }
 
-// IssueAdminToken issues a HS256 JWT for admin usage with an additional password marker.
-func (s *Service) IssueAdminToken(subject string, passwordUpdatedAtUnix int64) (tokenString string, expiresAt time.Time, err error) {
+// IssueAdminToken issues a JWT for admin usage with an additional password marker.
+// sessionID binds the token to a server-side session so it dies when the session is revoked.
+func (s *Service) IssueAdminToken(subject string, passwordUpdatedAtUnix int64, sessionID string) (tokenString string, expiresAt time.Time, err error) {
 	if s == nil {
 		return "", time.Time{}, ErrJWTDisabled
 	}
@@ -95,23 +175,24 @@ func (s *Service) IssueAdminToken(subject string, passwordUpdatedAtUnix int64) (
 			NotBefore: jwt.NewNumericDate(now.Add(-30 * time.Second)),
 		},
 		PasswordUpdatedAt: passwordUpdatedAtUnix,
-		TokenType:        "access",
+		TokenType:        TokenTypeAccess,
+		SessionID:        sessionID,
 	}
 	if strings.TrimSpace(s.cfg.Audience) != "" {
 		claims.Audience = jwt.ClaimStrings{s.cfg.Audience}
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	ss, err := token.SignedString(s.key)
+	ss, err := s.sign(claims)
 	if err != nil {
-		return "", time.Time{}, fmt.Errorf("sign token: %w", err)
+		return "", time.Time{}, err
 	}
 	return ss, expiresAt, nil
 }
 
-// IssueAdminRefreshToken issues a HS256 refresh token for admin usage.
+// IssueAdminRefreshToken issues a refresh token for admin usage.
 // It is meant to be exchanged for short-lived access tokens via a refresh endpoint.
-func (s *Service) IssueAdminRefreshToken(subject string, passwordUpdatedAtUnix int64) (tokenString string, expiresAt time.Time, err error) {
+// jti must match the session's current refresh token id; it changes on every rotation.
+func (s *Service) IssueAdminRefreshToken(subject string, passwordUpdatedAtUnix int64, sessionID, jti string) (tokenString string, expiresAt time.Time, err error) {
 	if s == nil {
 		return "", time.Time{}, ErrJWTDisabled
 	}
@@ -135,18 +216,19 @@ func (s *Service) IssueAdminRefreshToken(subject string, passwordUpdatedAtUnix i
 			ExpiresAt: jwt.NewNumericDate(expiresAt),
 			IssuedAt:  jwt.NewNumericDate(now),
 			NotBefore: jwt.NewNumericDate(now.Add(-30 * time.Second)),
+			ID:        jti,
 		},
 		PasswordUpdatedAt: passwordUpdatedAtUnix,
-		TokenType:        "refresh",
+		TokenType:        TokenTypeRefresh,
+		SessionID:        sessionID,
 	}
 	if strings.TrimSpace(s.cfg.Audience) != "" {
 		claims.Audience = jwt.ClaimStrings{s.cfg.Audience}
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	ss, err := token.SignedString(s.key)
+	ss, err := s.sign(claims)
 	if err != nil {
-		return "", time.Time{}, fmt.Errorf("sign token: %w", err)
+		return "", time.Time{}, err
 	}
 	return ss, expiresAt, nil
 }
@@ -160,23 +242,7 @@ func (s *Service) ParseToken(tokenString string) (*jwt.RegisteredClaims, error)
 		return nil, ErrJWTMissingToken
 	}
 
-	opts := []jwt.ParserOption{
-		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
-	}
-	if strings.TrimSpace(s.cfg.Issuer) != "" {
-		opts = append(opts, jwt.WithIssuer(s.cfg.Issuer))
-	}
-	if strings.TrimSpace(s.cfg.Audience) != "" {
-		opts = append(opts, jwt.WithAudience(s.cfg.Audience))
-	}
-
-	parsed, err := jwt.ParseWithClaims(tokenString, &jwt.RegisteredClaims{}, func(t *jwt.Token) (any, error) {
-		// Prevent alg=none and other unexpected algorithms.
-		if t.Method == nil || t.Method.Alg() != jwt.SigningMethodHS256.Alg() {
-			return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
-		}
-		return s.key, nil
-	}, opts...)
+	parsed, err := jwt.ParseWithClaims(tokenString, &jwt.RegisteredClaims{}, s.keyFunc, s.parserOptions()...)
 	if err != nil {
 		return nil, fmt.Errorf("parse token: %w", err)
 	}
@@ -192,46 +258,63 @@ func (s *Service) ParseToken(tokenString string) (*jwt.RegisteredClaims, error)
 	return claims, nil
 }
 
-// ParseAdminToken validates a JWT and returns admin claims (registered claims + pwd_at).
-func (s *Service) ParseAdminToken(tokenString string) (*AdminClaims, error) {
+// Admin token types (AdminClaims.TokenType).
+const (
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
+	// TokenTypeMFAPending proves the password step of a two-step login; it only unlocks
+	// the second (TOTP) step and is never accepted as an access token.
+	TokenTypeMFAPending = "mfa_pending"
+)
+
+// IssueAdminMFAPendingToken issues a short-lived token for the second login step.
+func (s *Service) IssueAdminMFAPendingToken(subject string, passwordUpdatedAtUnix int64, ttl time.Duration) (tokenString string, expiresAt time.Time, err error) {
 	if s == nil {
-		return nil, ErrJWTDisabled
+		return "", time.Time{}, ErrJWTDisabled
 	}
-	tokenString = strings.TrimSpace(tokenString)
-	if tokenString == "" {
-		return nil, ErrJWTMissingToken
+	if strings.TrimSpace(subject) == "" {
+		return "", time.Time{}, fmt.Errorf("subject is empty")
 	}
-
-	opts := []jwt.ParserOption{
-		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+	if ttl <= 0 {
+		ttl = 5 * time.Minute
 	}
-	if strings.TrimSpace(s.cfg.Issuer) != "" {
-		opts = append(opts, jwt.WithIssuer(s.cfg.Issuer))
+
+	now := time.Now()
+	expiresAt = now.Add(ttl)
+
+	claims := AdminClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    s.cfg.Issuer,
+			Subject:   subject,
+			Audience:  jwt.ClaimStrings{},
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now.Add(-30 * time.Second)),
+		},
+		PasswordUpdatedAt: passwordUpdatedAtUnix,
+		TokenType:         TokenTypeMFAPending,
 	}
 	if strings.TrimSpace(s.cfg.Audience) != "" {
-		opts = append(opts, jwt.WithAudience(s.cfg.Audience))
+		claims.Audience = jwt.ClaimStrings{s.cfg.Audience}
 	}
 
-	parsed, err := jwt.ParseWithClaims(tokenString, &AdminClaims{}, func(t *jwt.Token) (any, error) {
-		if t.Method == nil || t.Method.Alg() != jwt.SigningMethodHS256.Alg() {
-			return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
-		}
-		return s.key, nil
-	}, opts...)
+	ss, err := s.sign(claims)
 	if err != nil {
-		return nil, fmt.Errorf("parse token: %w", err)
-	}
-	if parsed == nil || !parsed.Valid {
-		return nil, ErrJWTInvalidToken
+		return "", time.Time{}, err
 	}
+	return ss, expiresAt, nil
+}
 
-	claims, ok := parsed.Claims.(*AdminClaims)
-	if !ok || claims == nil {
-		return nil, ErrJWTInvalidToken
+// ParseAdminToken validates a JWT and returns admin claims (registered claims + pwd_at).
+func (s *Service) ParseAdminToken(tokenString string) (*AdminClaims, error) {
+	claims, err := s.parseAdminClaims(tokenString)
+	if err != nil {
+		return nil, err
 	}
 
-	// Do not allow refresh tokens to pass as access tokens.
-	if strings.EqualFold(strings.TrimSpace(claims.TokenType), "refresh") {
+	// Do not allow refresh or pending-2FA tokens to pass as access tokens.
+	switch strings.ToLower(strings.TrimSpace(claims.TokenType)) {
+	case TokenTypeRefresh, TokenTypeMFAPending:
 		return nil, ErrJWTInvalidToken
 	}
 
@@ -240,6 +323,26 @@ func (s *Service) ParseAdminToken(tokenString string) (*AdminClaims, error) {
 
 // ParseAdminRefreshToken validates a refresh token and returns its claims.
 func (s *Service) ParseAdminRefreshToken(tokenString string) (*AdminClaims, error) {
+	return s.parseAdminTokenOfType(tokenString, TokenTypeRefresh)
+}
+
+// ParseAdminMFAPendingToken validates a token issued by IssueAdminMFAPendingToken.
+func (s *Service) ParseAdminMFAPendingToken(tokenString string) (*AdminClaims, error) {
+	return s.parseAdminTokenOfType(tokenString, TokenTypeMFAPending)
+}
+
+func (s *Service) parseAdminTokenOfType(tokenString, tokenType string) (*AdminClaims, error) {
+	claims, err := s.parseAdminClaims(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(strings.TrimSpace(claims.TokenType), tokenType) {
+		return nil, ErrJWTInvalidToken
+	}
+	return claims, nil
+}
+
+func (s *Service) parseAdminClaims(tokenString string) (*AdminClaims, error) {
 	if s == nil {
 		return nil, ErrJWTDisabled
 	}
@@ -248,22 +351,7 @@ func (s *Service) ParseAdminRefreshToken(tokenString string) (*AdminClaims, erro
 		return nil, ErrJWTMissingToken
 	}
 
-	opts := []jwt.ParserOption{
-		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
-	}
-	if strings.TrimSpace(s.cfg.Issuer) != "" {
-		opts = append(opts, jwt.WithIssuer(s.cfg.Issuer))
-	}
-	if strings.TrimSpace(s.cfg.Audience) != "" {
-		opts = append(opts, jwt.WithAudience(s.cfg.Audience))
-	}
-
-	parsed, err := jwt.ParseWithClaims(tokenString, &AdminClaims{}, func(t *jwt.Token) (any, error) {
-		if t.Method == nil || t.Method.Alg() != jwt.SigningMethodHS256.Alg() {
-			return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
-		}
-		return s.key, nil
-	}, opts...)
+	parsed, err := jwt.ParseWithClaims(tokenString, &AdminClaims{}, s.keyFunc, s.parserOptions()...)
 	if err != nil {
 		return nil, fmt.Errorf("parse token: %w", err)
 	}
@@ -275,9 +363,5 @@ func (s *Service) ParseAdminRefreshToken(tokenString string) (*AdminClaims, erro
 	if !ok || claims == nil {
 		return nil, ErrJWTInvalidToken
 	}
-	if !strings.EqualFold(strings.TrimSpace(claims.TokenType), "refresh") {
-		return nil, ErrJWTInvalidToken
-	}
-
 	return claims, nil
 }
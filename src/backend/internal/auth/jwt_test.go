@@ -0,0 +1,208 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"evening-gown/internal/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestService_HS256_RoundTrip(t *testing.T) {
+	svc, err := New(config.JWTConfig{Secret: "test-secret", Issuer: "evening-gown", ExpiresIn: time.Hour})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+
+	tok, _, err := svc.IssueAdminToken("1", 0, "sid")
+	if err != nil {
+		t.Fatalf("issue: %v", err)
+	}
+	claims, err := svc.ParseAdminToken(tok)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if claims.Subject != "1" || claims.SessionID != "sid" {
+		t.Fatalf("unexpected claims: %+v", claims)
+	}
+	if keys := svc.JWKS().Keys; len(keys) != 0 {
+		t.Fatalf("hmac secrets must not be published, got %+v", keys)
+	}
+}
+
+func TestService_Asymmetric_RotationAndJWKS(t *testing.T) {
+	dir := t.TempDir()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("rsa key: %v", err)
+	}
+	oldPriv := writePrivateKey(t, dir, "old.pem", rsaKey)
+	oldPub := writePublicKey(t, dir, "old.pub.pem", &rsaKey.PublicKey)
+
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("ed25519 key: %v", err)
+	}
+	newPriv := writePrivateKey(t, dir, "new.pem", edKey)
+
+	base := config.JWTConfig{Issuer: "evening-gown", ExpiresIn: time.Hour, Secret: "legacy-secret"}
+
+	oldCfg := base
+	oldCfg.Algorithm = "RS256"
+	oldCfg.PrivateKeyFile = oldPriv
+	oldCfg.KeyID = "2025-07"
+	oldSvc, err := New(oldCfg)
+	if err != nil {
+		t.Fatalf("new old: %v", err)
+	}
+
+	newCfg := base
+	newCfg.Algorithm = "EdDSA"
+	newCfg.PrivateKeyFile = newPriv
+	newCfg.KeyID = "2026-01"
+	newCfg.VerifyKeys = map[string]string{"2025-07": oldPub}
+	newSvc, err := New(newCfg)
+	if err != nil {
+		t.Fatalf("new new: %v", err)
+	}
+
+	// New tokens carry the active kid and verify.
+	tok, _, err := newSvc.IssueAdminToken("7", 0, "")
+	if err != nil {
+		t.Fatalf("issue: %v", err)
+	}
+	parsed, _, err := jwt.NewParser().ParseUnverified(tok, &AdminClaims{})
+	if err != nil {
+		t.Fatalf("parse unverified: %v", err)
+	}
+	if parsed.Header["kid"] != "2026-01" || parsed.Header["alg"] != "EdDSA" {
+		t.Fatalf("unexpected header: %v", parsed.Header)
+	}
+	if _, err := newSvc.ParseAdminToken(tok); err != nil {
+		t.Fatalf("parse new token: %v", err)
+	}
+
+	// Tokens signed by the previous key stay valid during the rotation window.
+	oldTok, _, err := oldSvc.IssueAdminToken("7", 0, "")
+	if err != nil {
+		t.Fatalf("issue old: %v", err)
+	}
+	if _, err := newSvc.ParseAdminToken(oldTok); err != nil {
+		t.Fatalf("parse rotated-out token: %v", err)
+	}
+	// ...but the old service does not know the new key.
+	if _, err := oldSvc.ParseAdminToken(tok); err == nil {
+		t.Fatalf("expected unknown kid to be rejected")
+	}
+
+	// Legacy HS256 tokens without kid are rejected unless an explicit cutoff is configured.
+	legacy := jwt.NewWithClaims(jwt.SigningMethodHS256, AdminClaims{RegisteredClaims: jwt.RegisteredClaims{
+		Issuer:    "evening-gown",
+		Subject:   "7",
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	}})
+	legacyTok, err := legacy.SignedString([]byte("legacy-secret"))
+	if err != nil {
+		t.Fatalf("sign legacy: %v", err)
+	}
+	if _, err := newSvc.ParseAdminToken(legacyTok); err == nil {
+		t.Fatalf("expected legacy token to be rejected without a cutoff")
+	}
+	for _, tc := range []struct {
+		until time.Time
+		ok    bool
+	}{
+		{time.Now().Add(24 * time.Hour), true},
+		{time.Now().Add(-time.Minute), false},
+	} {
+		cfg := newCfg
+		cfg.LegacyHS256Until = tc.until
+		svc, err := New(cfg)
+		if err != nil {
+			t.Fatalf("new with legacy cutoff: %v", err)
+		}
+		if _, err := svc.ParseAdminToken(legacyTok); (err == nil) != tc.ok {
+			t.Fatalf("legacy token until %s: ok=%t, err=%v", tc.until, tc.ok, err)
+		}
+	}
+
+	// Algorithm confusion: HS256 with a published kid must not verify.
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, AdminClaims{RegisteredClaims: jwt.RegisteredClaims{
+		Issuer:    "evening-gown",
+		Subject:   "1",
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	}})
+	forged.Header["kid"] = "2025-07"
+	pubDER, _ := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	forgedTok, _ := forged.SignedString(pubDER)
+	if _, err := newSvc.ParseAdminToken(forgedTok); err == nil {
+		t.Fatalf("expected algorithm mismatch to be rejected")
+	}
+
+	set := newSvc.JWKS()
+	if len(set.Keys) != 2 {
+		t.Fatalf("expected 2 published keys, got %+v", set.Keys)
+	}
+	byKid := map[string]JWK{}
+	for _, k := range set.Keys {
+		byKid[k.Kid] = k
+	}
+	if k := byKid["2026-01"]; k.Kty != "OKP" || k.Crv != "Ed25519" || k.X == "" || k.Alg != "EdDSA" {
+		t.Fatalf("unexpected ed25519 jwk: %+v", k)
+	}
+	if k := byKid["2025-07"]; k.Kty != "RSA" || k.N == "" || k.E != "AQAB" || k.Alg != "RS256" {
+		t.Fatalf("unexpected rsa jwk: %+v", k)
+	}
+}
+
+func TestNew_RejectsMismatchedAlgorithm(t *testing.T) {
+	dir := t.TempDir()
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	path := writePrivateKey(t, dir, "ed.pem", edKey)
+
+	if _, err := New(config.JWTConfig{Algorithm: "RS256", PrivateKeyFile: path}); err == nil {
+		t.Fatalf("expected error for ed25519 key with RS256")
+	}
+	if _, err := New(config.JWTConfig{Algorithm: "RS256"}); err == nil {
+		t.Fatalf("expected error without private key file")
+	}
+	if _, err := New(config.JWTConfig{Algorithm: "none", Secret: "x"}); err == nil {
+		t.Fatalf("expected error for unsupported algorithm")
+	}
+}
+
+func writePrivateKey(t *testing.T, dir, name string, key any) string {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal private key: %v", err)
+	}
+	return writePEM(t, dir, name, "PRIVATE KEY", der)
+}
+
+func writePublicKey(t *testing.T, dir, name string, key any) string {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		t.Fatalf("marshal public key: %v", err)
+	}
+	return writePEM(t, dir, name, "PUBLIC KEY", der)
+}
+
+func writePEM(t *testing.T, dir, name, typ string, der []byte) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der}), 0o600); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+	return path
+}
@@ -0,0 +1,267 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var ErrJWTMissingPrivateKey = errors.New("missing jwt private key file")
+
+type signingKey struct {
+	kid    string
+	method jwt.SigningMethod
+	// key is []byte (HS256), *rsa.PrivateKey (RS256) or ed25519.PrivateKey (EdDSA).
+	key any
+}
+
+type verificationKey struct {
+	method jwt.SigningMethod
+	// key is []byte (HS256), *rsa.PublicKey (RS256) or ed25519.PublicKey (EdDSA).
+	key any
+}
+
+// loadKeys builds the signer and the verification key set from cfg.
+func (s *Service) loadKeys() error {
+	alg := strings.TrimSpace(s.cfg.Algorithm)
+	secret := strings.TrimSpace(s.cfg.Secret)
+	kid := strings.TrimSpace(s.cfg.KeyID)
+
+	switch {
+	case alg == "" || strings.EqualFold(alg, "HS256"):
+		if secret == "" {
+			return ErrJWTMissingSecret
+		}
+		s.signer = signingKey{kid: kid, method: jwt.SigningMethodHS256, key: []byte(secret)}
+		// Tokens issued before kid headers existed (or while no kid is configured).
+		s.legacyHMAC = []byte(secret)
+		if kid != "" {
+			s.verifiers[kid] = verificationKey{method: jwt.SigningMethodHS256, key: []byte(secret)}
+		}
+	case strings.EqualFold(alg, "RS256"), strings.EqualFold(alg, "EdDSA"):
+		path := strings.TrimSpace(s.cfg.PrivateKeyFile)
+		if path == "" {
+			return ErrJWTMissingPrivateKey
+		}
+		priv, err := readPrivateKey(path)
+		if err != nil {
+			return err
+		}
+		method, pub, err := methodForPrivateKey(priv)
+		if err != nil {
+			return err
+		}
+		if !strings.EqualFold(method.Alg(), alg) {
+			return fmt.Errorf("jwt private key %s does not match JWT_ALGORITHM=%s", path, alg)
+		}
+		if kid == "" {
+			kid, err = keyThumbprint(pub)
+			if err != nil {
+				return err
+			}
+		}
+		s.signer = signingKey{kid: kid, method: method, key: priv}
+		s.verifiers[kid] = verificationKey{method: method, key: pub}
+	default:
+		return fmt.Errorf("unsupported JWT_ALGORITHM %q (want HS256, RS256 or EdDSA)", alg)
+	}
+
+	// After moving to RS256/EdDSA, kid-less HS256 tokens signed with the old shared secret are
+	// only trusted until the explicit JWT_LEGACY_HS256_UNTIL cutoff.
+	if s.signer.method != jwt.SigningMethodHS256 && secret != "" && s.cfg.LegacyHS256Until.After(time.Now()) {
+		s.legacyHMAC = []byte(secret)
+		s.legacyUntil = s.cfg.LegacyHS256Until
+	}
+
+	for vkid, path := range s.cfg.VerifyKeys {
+		vkid = strings.TrimSpace(vkid)
+		if vkid == "" || vkid == s.signer.kid {
+			continue
+		}
+		pub, err := readPublicKey(strings.TrimSpace(path))
+		if err != nil {
+			return err
+		}
+		method, err := methodForPublicKey(pub)
+		if err != nil {
+			return err
+		}
+		s.verifiers[vkid] = verificationKey{method: method, key: pub}
+	}
+
+	seen := map[string]bool{}
+	if s.legacyHMAC != nil {
+		seen[jwt.SigningMethodHS256.Alg()] = true
+	}
+	for _, v := range s.verifiers {
+		seen[v.method.Alg()] = true
+	}
+	seen[s.signer.method.Alg()] = true
+	for m := range seen {
+		s.methods = append(s.methods, m)
+	}
+	sort.Strings(s.methods)
+	return nil
+}
+
+// JWK is a public JSON Web Key (RFC 7517).
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// OKP (Ed25519)
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKSet is the /.well-known/jwks.json document.
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns all public verification keys (active and rotating-out).
+// Shared HMAC secrets are never published, so HS256-only setups return an empty set.
+func (s *Service) JWKS() JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+	if s == nil {
+		return set
+	}
+
+	kids := make([]string, 0, len(s.verifiers))
+	for kid := range s.verifiers {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+
+	for _, kid := range kids {
+		v := s.verifiers[kid]
+		switch pub := v.key.(type) {
+		case *rsa.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				Kty: "RSA",
+				Kid: kid,
+				Use: "sig",
+				Alg: v.method.Alg(),
+				N:   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				Kty: "OKP",
+				Kid: kid,
+				Use: "sig",
+				Alg: v.method.Alg(),
+				Crv: "Ed25519",
+				X:   base64.RawURLEncoding.EncodeToString(pub),
+			})
+		}
+	}
+	return set
+}
+
+func readPEMBlock(path string) (*pem.Block, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read jwt key %s: %w", path, err)
+	}
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, fmt.Errorf("jwt key %s: no PEM block found", path)
+	}
+	return block, nil
+}
+
+func readPrivateKey(path string) (crypto.Signer, error) {
+	block, err := readPEMBlock(path)
+	if err != nil {
+		return nil, err
+	}
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("jwt key %s: unsupported private key type %T", path, key)
+		}
+		return signer, nil
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("jwt key %s: unsupported private key format %q", path, block.Type)
+}
+
+func readPublicKey(path string) (crypto.PublicKey, error) {
+	block, err := readPEMBlock(path)
+	if err != nil {
+		return nil, err
+	}
+	switch block.Type {
+	case "CERTIFICATE":
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("jwt key %s: %w", path, err)
+		}
+		return cert.PublicKey, nil
+	case "RSA PUBLIC KEY":
+		key, err := x509.ParsePKCS1PublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("jwt key %s: %w", path, err)
+		}
+		return key, nil
+	default:
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("jwt key %s: %w", path, err)
+		}
+		return key, nil
+	}
+}
+
+func methodForPrivateKey(key crypto.Signer) (jwt.SigningMethod, crypto.PublicKey, error) {
+	pub := key.Public()
+	method, err := methodForPublicKey(pub)
+	if err != nil {
+		return nil, nil, err
+	}
+	return method, pub, nil
+}
+
+func methodForPublicKey(pub crypto.PublicKey) (jwt.SigningMethod, error) {
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		if k.N.BitLen() < 2048 {
+			return nil, fmt.Errorf("rsa key too small (%d bits, want >= 2048)", k.N.BitLen())
+		}
+		return jwt.SigningMethodRS256, nil
+	case ed25519.PublicKey:
+		return jwt.SigningMethodEdDSA, nil
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", pub)
+	}
+}
+
+// keyThumbprint derives a stable kid from the public key (base64url SHA-256 of its DER form).
+func keyThumbprint(pub crypto.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return "", fmt.Errorf("marshal jwt public key: %w", err)
+	}
+	sum := sha256.Sum256(der)
+	return base64.RawURLEncoding.EncodeToString(sum[:12]), nil
+}
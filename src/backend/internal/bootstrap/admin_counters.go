@@ -20,7 +20,7 @@ func InitAdminCounters(ctx context.Context, db *gorm.DB, rdb *redis.Client) erro
 	}
 
 	var newLeads int64
-	if err := db.WithContext(ctx).Model(&model.ContactLead{}).Where("status = ?", "new").Count(&newLeads).Error; err != nil {
+	if err := db.WithContext(ctx).Model(&model.ContactLead{}).Where("status = ? AND deleted_at IS NULL", "new").Count(&newLeads).Error; err != nil {
 		return err
 	}
 
@@ -27,13 +27,29 @@ func AutoMigrate(db *gorm.DB) error {
 		return err
 	}
 
+	// users.email used to be unique across soft-deleted rows too; it is now a partial index.
+	if err := dropLegacyUsersEmailIndex(db); err != nil {
+		return err
+	}
+
 	if err := db.AutoMigrate(
 		&model.User{},
+		&model.Session{},
+		&model.RecoveryCode{},
+		&model.PasswordResetToken{},
+		&model.PasswordHistory{},
+		&model.AuditLog{},
 		&model.Product{},
+		&model.ProductRevision{},
+		&model.ProductDraft{},
+		&model.ProductVariant{},
+		&model.ProductAsset{},
 		&model.AppSetting{},
 		&model.UpdatePost{},
 		&model.ContactLead{},
 		&model.Event{},
+		&model.TaxonomyTerm{},
+		&model.Asset{},
 	); err != nil {
 		return err
 	}
@@ -43,6 +59,29 @@ func AutoMigrate(db *gorm.DB) error {
 		return err
 	}
 
+	if err := ensureTaxonomyTerms(db); err != nil {
+		return err
+	}
+
+	if err := ensureProductSearch(db); err != nil {
+		return err
+	}
+
+	if err := ensureProductAssets(db); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func dropLegacyUsersEmailIndex(db *gorm.DB) error {
+	m := db.Migrator()
+	if !m.HasTable(&model.User{}) || !m.HasIndex(&model.User{}, "idx_users_email") {
+		return nil
+	}
+	if err := m.DropIndex(&model.User{}, "idx_users_email"); err != nil {
+		return fmt.Errorf("drop users email index: %w", err)
+	}
 	return nil
 }
 
@@ -90,15 +129,15 @@ func ensureProductDetailTemplateSetting(db *gorm.DB) error {
 
 // EnsureSingleAdmin creates the first super admin user if no admin exists.
 //
-// It enforces a "single super admin" policy on creation, but does not delete
-// extra rows if they already exist.
+// It only bootstraps the initial super admin from env. Further accounts (including
+// additional admins) are invited through the /api/v1/admin/users endpoints.
 func EnsureSingleAdmin(db *gorm.DB, email, password string) error {
 	if db == nil {
 		return ErrPostgresRequired
 	}
 
 	var adminCount int64
-	if err := db.Model(&model.User{}).Where("role = ? AND deleted_at IS NULL", "admin").Count(&adminCount).Error; err != nil {
+	if err := db.Model(&model.User{}).Where("role = ? AND deleted_at IS NULL", model.RoleAdmin).Count(&adminCount).Error; err != nil {
 		return fmt.Errorf("count admin users: %w", err)
 	}
 	if adminCount > 0 {
@@ -121,7 +160,7 @@ func EnsureSingleAdmin(db *gorm.DB, email, password string) error {
 	user := model.User{
 		Email:          email,
 		PasswordHash:   hash,
-		Role:           "admin",
+		Role:           model.RoleAdmin,
 		Status:         "active",
 		PasswordUpdatedAt: &now,
 	}
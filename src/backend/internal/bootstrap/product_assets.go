@@ -0,0 +1,52 @@
+package bootstrap
+
+import (
+	"fmt"
+
+	"evening-gown/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// productAssetsBackfillBatch bounds the products loaded at once while backfilling.
+const productAssetsBackfillBatch = 200
+
+// ensureProductAssets fills product_assets for catalogs created before the index existed.
+// Product writes keep it up to date afterwards, so it only runs while the table is empty.
+func ensureProductAssets(db *gorm.DB) error {
+	if db == nil {
+		return ErrPostgresRequired
+	}
+
+	var n int64
+	if err := db.Model(&model.ProductAsset{}).Count(&n).Error; err != nil {
+		return fmt.Errorf("count product assets: %w", err)
+	}
+	if n > 0 {
+		return nil
+	}
+
+	var lastID uint
+	for {
+		var products []model.Product
+		if err := db.Where("id > ?", lastID).Order("id asc").Limit(productAssetsBackfillBatch).Find(&products).Error; err != nil {
+			return fmt.Errorf("list products: %w", err)
+		}
+		if len(products) == 0 {
+			return nil
+		}
+		lastID = products[len(products)-1].ID
+
+		var rows []model.ProductAsset
+		for _, p := range products {
+			rows = append(rows, model.PlanProductAssets(p)...)
+		}
+		if len(rows) == 0 {
+			continue
+		}
+		if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&rows).Error; err != nil {
+			return fmt.Errorf("backfill product assets: %w", err)
+		}
+	}
+}
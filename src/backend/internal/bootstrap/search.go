@@ -0,0 +1,41 @@
+package bootstrap
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// productSearchDDL adds the generated search columns and their indexes (see package search).
+// Every statement is idempotent.
+var productSearchDDL = []string{
+	`CREATE EXTENSION IF NOT EXISTS pg_trgm`,
+	`ALTER TABLE products ADD COLUMN IF NOT EXISTS search_tsv tsvector
+  GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', coalesce(style_no, '') || ' ' || coalesce(slug, '')), 'A') ||
+    setweight(jsonb_to_tsvector('simple', coalesce(detail_json, '{}'::jsonb), '["string"]'), 'B')
+  ) STORED`,
+	`ALTER TABLE products ADD COLUMN IF NOT EXISTS search_text text
+  GENERATED ALWAYS AS (
+    lower(coalesce(style_no, '') || ' ' || coalesce(slug, '') || ' ' ||
+      coalesce(jsonb_path_query_array(detail_json, 'strict $.** ? (@.type() == "string")')::text, ''))
+  ) STORED`,
+	`CREATE INDEX IF NOT EXISTS idx_products_search_tsv ON products USING gin (search_tsv)`,
+	`CREATE INDEX IF NOT EXISTS idx_products_search_text_trgm ON products USING gin (search_text gin_trgm_ops)`,
+}
+
+func ensureProductSearch(db *gorm.DB) error {
+	if db == nil {
+		return nil
+	}
+	// Tests use SQLite, where search falls back to LIKE.
+	if db.Dialector == nil || db.Dialector.Name() != "postgres" {
+		return nil
+	}
+	for _, stmt := range productSearchDDL {
+		if err := db.Exec(stmt).Error; err != nil {
+			return fmt.Errorf("product search migration: %w", err)
+		}
+	}
+	return nil
+}
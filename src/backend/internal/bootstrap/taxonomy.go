@@ -0,0 +1,69 @@
+package bootstrap
+
+import (
+	"fmt"
+	"sort"
+
+	"evening-gown/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ensureTaxonomyTerms seeds every taxonomy kind that has no terms yet with the defaults plus
+// the values existing products already use, so that validating product writes against the
+// taxonomy does not lock out the current catalog.
+func ensureTaxonomyTerms(db *gorm.DB) error {
+	if db == nil {
+		return ErrPostgresRequired
+	}
+
+	defaults := model.DefaultTaxonomyTerms()
+	for _, kind := range model.TaxonomyKinds {
+		var n int64
+		if err := db.Model(&model.TaxonomyTerm{}).Where("kind = ?", kind).Count(&n).Error; err != nil {
+			return fmt.Errorf("count taxonomy terms: %w", err)
+		}
+		if n > 0 {
+			continue
+		}
+
+		var terms []model.TaxonomyTerm
+		seen := map[string]bool{}
+		for _, t := range defaults {
+			if t.Kind == kind {
+				terms = append(terms, t)
+				seen[t.Code] = true
+			}
+		}
+
+		column, ok := model.TaxonomyColumn(kind)
+		if !ok {
+			continue
+		}
+		var used []string
+		if err := db.Model(&model.Product{}).Distinct(column).Pluck(column, &used).Error; err != nil {
+			return fmt.Errorf("list product %s values: %w", kind, err)
+		}
+		var extra []string
+		for _, raw := range used {
+			code, ok := model.NormalizeTaxonomyCode(kind, raw)
+			if ok && !seen[code] {
+				seen[code] = true
+				extra = append(extra, code)
+			}
+		}
+		sort.Strings(extra)
+		for _, code := range extra {
+			terms = append(terms, model.TaxonomyTerm{Kind: kind, Code: code, LabelI18n: []byte(`{}`), Active: true})
+		}
+
+		if len(terms) == 0 {
+			continue
+		}
+		if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&terms).Error; err != nil {
+			return fmt.Errorf("seed taxonomy terms: %w", err)
+		}
+	}
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"hash/fnv"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
@@ -32,6 +33,10 @@ type PublicCache struct {
 const (
 	publicProductsVerKey = "eg:public:ver:products"
 	publicUpdatesVerKey  = "eg:public:ver:updates"
+	publicTaxonomyVerKey = "eg:public:ver:taxonomy"
+	// Bumped only when the images referenced by published products change (see
+	// media.BumpAssetsCache), so ordinary product edits keep the asset allow-cache.
+	publicAssetsVerKey = "eg:public:ver:assets"
 
 	notFoundMarker = "__NOT_FOUND__"
 )
@@ -52,6 +57,14 @@ func (c *PublicCache) UpdatesVersion(ctx context.Context) int64 {
 	return c.getVersion(ctx, publicUpdatesVerKey)
 }
 
+func (c *PublicCache) TaxonomyVersion(ctx context.Context) int64 {
+	return c.getVersion(ctx, publicTaxonomyVerKey)
+}
+
+func (c *PublicCache) AssetsVersion(ctx context.Context) int64 {
+	return c.getVersion(ctx, publicAssetsVerKey)
+}
+
 func (c *PublicCache) BumpProductsVersion(ctx context.Context) (int64, error) {
 	if !c.enabled() {
 		return 0, nil
@@ -59,6 +72,13 @@ func (c *PublicCache) BumpProductsVersion(ctx context.Context) (int64, error) {
 	return c.rdb.Incr(ctx, publicProductsVerKey).Result()
 }
 
+func (c *PublicCache) BumpAssetsVersion(ctx context.Context) (int64, error) {
+	if !c.enabled() {
+		return 0, nil
+	}
+	return c.rdb.Incr(ctx, publicAssetsVerKey).Result()
+}
+
 func (c *PublicCache) BumpUpdatesVersion(ctx context.Context) (int64, error) {
 	if !c.enabled() {
 		return 0, nil
@@ -66,6 +86,13 @@ func (c *PublicCache) BumpUpdatesVersion(ctx context.Context) (int64, error) {
 	return c.rdb.Incr(ctx, publicUpdatesVerKey).Result()
 }
 
+func (c *PublicCache) BumpTaxonomyVersion(ctx context.Context) (int64, error) {
+	if !c.enabled() {
+		return 0, nil
+	}
+	return c.rdb.Incr(ctx, publicTaxonomyVerKey).Result()
+}
+
 func (c *PublicCache) GetJSONBytes(ctx context.Context, key string) ([]byte, bool, bool) {
 	// returns (bytes, hit, isNotFoundMarker)
 	if !c.enabled() {
@@ -112,7 +139,10 @@ func (c *PublicCache) SetJSON(ctx context.Context, key string, v any, ttl time.D
 	c.SetJSONBytes(ctx, key, b, ttl)
 }
 
-func (c *PublicCache) ProductsListKey(ver int64, season, category, availability, isNew string, limit, offset int) string {
+// ProductsListKey includes the normalized search query q (see search.Normalize); "" means no search.
+// q is URL-escaped rather than passed through escapeKeyPart so that distinct queries never share a key.
+// cursor is nil for offset paging; in cursor mode it points at the (possibly empty) cursor.
+func (c *PublicCache) ProductsListKey(ver int64, season, category, availability, isNew, q, sort string, cursor *string, limit, offset int) string {
 	// Keep key stable by normalizing optional params.
 	season = strings.TrimSpace(season)
 	category = strings.TrimSpace(category)
@@ -123,24 +153,46 @@ func (c *PublicCache) ProductsListKey(ver int64, season, category, availability,
 	}
 
 	// Use a simple query-like format to keep it debuggable.
-	return fmt.Sprintf("eg:public:products:list:v%d:season=%s:category=%s:availability=%s:is_new=%s:limit=%d:offset=%d", ver, escapeKeyPart(season), escapeKeyPart(category), escapeKeyPart(availability), isNew, limit, offset)
+	return fmt.Sprintf("eg:public:products:list:v%d:season=%s:category=%s:availability=%s:is_new=%s:q=%s:sort=%s:limit=%d:%s", ver, escapeKeyPart(season), escapeKeyPart(category), escapeKeyPart(availability), isNew, url.QueryEscape(q), escapeKeyPart(sort), limit, pageKeyPart(cursor, offset))
+}
+
+// ProductFacetsKey takes the same filters as ProductsListKey; facets do not depend on paging or sort.
+func (c *PublicCache) ProductFacetsKey(ver int64, season, category, availability, isNew, q string) string {
+	isNew = strings.TrimSpace(isNew)
+	if isNew != "true" && isNew != "false" {
+		isNew = ""
+	}
+	return fmt.Sprintf("eg:public:products:facets:v%d:season=%s:category=%s:availability=%s:is_new=%s:q=%s", ver, escapeKeyPart(strings.TrimSpace(season)), escapeKeyPart(strings.TrimSpace(category)), escapeKeyPart(strings.TrimSpace(availability)), isNew, url.QueryEscape(q))
 }
 
 func (c *PublicCache) ProductDetailKey(ver int64, id uint) string {
 	return fmt.Sprintf("eg:public:products:get:v%d:id=%d", ver, id)
 }
 
-func (c *PublicCache) UpdatesListKey(ver int64, limit, offset int) string {
-	return fmt.Sprintf("eg:public:updates:list:v%d:limit=%d:offset=%d", ver, limit, offset)
+// UpdatesListKey takes cursor like ProductsListKey.
+func (c *PublicCache) UpdatesListKey(ver int64, sort string, cursor *string, limit, offset int) string {
+	return fmt.Sprintf("eg:public:updates:list:v%d:sort=%s:limit=%d:%s", ver, escapeKeyPart(sort), limit, pageKeyPart(cursor, offset))
+}
+
+// pageKeyPart tells offset pages from cursor pages, whose responses differ (no total).
+func pageKeyPart(cursor *string, offset int) string {
+	if cursor == nil {
+		return fmt.Sprintf("offset=%d", offset)
+	}
+	return "cursor=" + url.QueryEscape(*cursor)
 }
 
 func (c *PublicCache) UpdateDetailKey(ver int64, id uint) string {
 	return fmt.Sprintf("eg:public:updates:get:v%d:id=%d", ver, id)
 }
 
-func (c *PublicCache) AssetAllowKey(productsVer int64, objectKey string) string {
+func (c *PublicCache) TaxonomyKey(ver int64, kind string) string {
+	return fmt.Sprintf("eg:public:taxonomy:v%d:kind=%s", ver, escapeKeyPart(strings.TrimSpace(kind)))
+}
+
+func (c *PublicCache) AssetAllowKey(assetsVer int64, objectKey string) string {
 	objectKey = strings.TrimSpace(strings.TrimPrefix(objectKey, "/"))
-	return fmt.Sprintf("eg:public:assets:allow:v%d:key=%s", productsVer, escapeKeyPart(objectKey))
+	return fmt.Sprintf("eg:public:assets:allow:v%d:key=%s", assetsVer, escapeKeyPart(objectKey))
 }
 
 func (c *PublicCache) BoolFromCache(ctx context.Context, key string) (val bool, hit bool) {
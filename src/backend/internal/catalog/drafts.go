@@ -0,0 +1,70 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+
+	"evening-gown/internal/media"
+	"evening-gown/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// ErrNoDraft means the product has no pending draft, or it changed while being promoted.
+var ErrNoDraft = errors.New("no pending draft")
+
+// PromoteDraft copies d, the pending draft of live, onto the live row and deletes it. live is
+// snapshotted into product_revisions first; variants and product_assets follow the promoted
+// detail. db should be a transaction. It reports whether the product's image references changed.
+func PromoteDraft(ctx context.Context, db *gorm.DB, live model.Product, d model.ProductDraft) (bool, error) {
+	tx := db.WithContext(ctx)
+	working, err := d.WorkingCopy(live)
+	if err != nil {
+		return false, err
+	}
+	if err := SaveRevision(ctx, tx, live, "publish_changes"); err != nil {
+		return false, err
+	}
+	if err := tx.Model(&model.Product{}).
+		Where("id = ?", live.ID).
+		Where("deleted_at IS NULL").
+		Updates(model.ProductContentOf(working).Columns()).Error; err != nil {
+		return false, err
+	}
+	if _, err := SyncVariants(ctx, tx, live.ID, working.DetailJSON); err != nil {
+		return false, err
+	}
+	assetsChanged, err := media.SyncProductAssets(ctx, tx, live.ID)
+	if err != nil {
+		return false, err
+	}
+	// Conditional on the draft we read, so a concurrent edit is not silently dropped.
+	res := tx.Where("id = ? AND updated_at = ?", d.ID, d.UpdatedAt).Delete(&model.ProductDraft{})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return false, ErrNoDraft
+	}
+	return assetsChanged, nil
+}
+
+// PromotePendingDraft promotes the draft of product id, if it has one, e.g. while publishing
+// it: edits saved after an unpublish would otherwise stay hidden behind the old content.
+// db should be the transaction that publishes the product.
+func PromotePendingDraft(ctx context.Context, db *gorm.DB, id uint) (promoted, assetsChanged bool, err error) {
+	tx := db.WithContext(ctx)
+	var d model.ProductDraft
+	if err := tx.Where("product_id = ?", id).First(&d).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, false, nil
+		}
+		return false, false, err
+	}
+	var live model.Product
+	if err := tx.Where("deleted_at IS NULL").First(&live, id).Error; err != nil {
+		return false, false, err
+	}
+	assetsChanged, err = PromoteDraft(ctx, tx, live, d)
+	return err == nil, assetsChanged, err
+}
@@ -0,0 +1,30 @@
+// Package catalog holds the product writes shared by the admin API and background jobs:
+// revisions, variants and promoting pending drafts.
+package catalog
+
+import (
+	"context"
+	"encoding/json"
+
+	"evening-gown/internal/audit"
+	"evening-gown/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// SaveRevision snapshots p (the row as it is before a change). db is usually a transaction.
+func SaveRevision(ctx context.Context, db *gorm.DB, p model.Product, reason string) error {
+	snapshot, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	rev := model.ProductRevision{ProductID: p.ID, Reason: reason, Snapshot: snapshot}
+	if actor, ok := audit.ActorFrom(ctx); ok {
+		if actor.UserID != 0 {
+			id := actor.UserID
+			rev.ActorID = &id
+		}
+		rev.ActorEmail = actor.Email
+	}
+	return db.WithContext(ctx).Create(&rev).Error
+}
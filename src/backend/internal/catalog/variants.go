@@ -0,0 +1,70 @@
+package catalog
+
+import (
+	"context"
+	"encoding/json"
+
+	"evening-gown/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// SyncVariants reconciles product_variants with the option groups in detail:
+// missing combinations are created, obsolete ones removed, and existing rows keep their
+// backoffice fields (suffix, availability, MOQ, lead time). db is usually a transaction.
+// It reports whether anything changed.
+func SyncVariants(ctx context.Context, db *gorm.DB, productID uint, detail json.RawMessage) (bool, error) {
+	plan, err := model.PlanProductVariants(detail)
+	if err != nil {
+		return false, err
+	}
+
+	var existing []model.ProductVariant
+	if err := db.WithContext(ctx).Where("product_id = ?", productID).Find(&existing).Error; err != nil {
+		return false, err
+	}
+	type combo struct{ color, size string }
+	byCombo := make(map[combo]model.ProductVariant, len(existing))
+	for _, v := range existing {
+		byCombo[combo{v.Color, v.Size}] = v
+	}
+
+	changed := false
+	for _, p := range plan {
+		k := combo{p.Color, p.Size}
+		v, ok := byCombo[k]
+		delete(byCombo, k)
+		if !ok {
+			if err := db.WithContext(ctx).Create(&model.ProductVariant{
+				ProductID: productID,
+				Color:     p.Color,
+				Size:      p.Size,
+				Position:  p.Position,
+			}).Error; err != nil {
+				return false, err
+			}
+			changed = true
+			continue
+		}
+		if v.Position != p.Position {
+			if err := db.WithContext(ctx).Model(&model.ProductVariant{}).
+				Where("id = ?", v.ID).
+				Update("position", p.Position).Error; err != nil {
+				return false, err
+			}
+			changed = true
+		}
+	}
+
+	if len(byCombo) > 0 {
+		ids := make([]uint, 0, len(byCombo))
+		for _, v := range byCombo {
+			ids = append(ids, v.ID)
+		}
+		if err := db.WithContext(ctx).Where("id IN ?", ids).Delete(&model.ProductVariant{}).Error; err != nil {
+			return false, err
+		}
+		changed = true
+	}
+	return changed, nil
+}
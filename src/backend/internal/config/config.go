@@ -21,6 +21,10 @@ type Config struct {
 	Minio    MinioConfig
 	Upload   UploadConfig
 	JWT      JWTConfig
+	Auth     AuthConfig
+	Mail     MailConfig
+	Preview  PreviewConfig
+	Jobs     JobsConfig
 	Admin    AdminConfig
 	Dev      DevConfig
 	Log      LogConfig
@@ -61,6 +65,101 @@ type AdminConfig struct {
 	Password string
 }
 
+// AuthConfig controls admin login hardening (account lockout, per-IP rate limiting, 2FA).
+//
+// Env:
+// - LOGIN_LOCKOUT_THRESHOLD: consecutive failures before the account is locked (default: 5, 0 disables)
+// - LOGIN_LOCKOUT_DURATION: first lock window (default: 15m); doubles on every further failure
+// - LOGIN_LOCKOUT_MAX_DURATION: cap for the exponential backoff (default: 24h)
+// - LOGIN_RATE_LIMIT: max login attempts per client IP per window (default: 20, 0 disables)
+// - LOGIN_RATE_LIMIT_WINDOW: rate limit window (default: 1m)
+// - TOTP_ISSUER: issuer shown in authenticator apps (default: Evening Gown)
+// - MFA_PENDING_TTL: how long the password step of a 2FA login stays valid (default: 5m)
+// - AUTH_TOTP_KEY: encrypts TOTP secrets at rest (AES-GCM); empty stores them in plaintext.
+//   Changing it makes enrolled authenticators unusable, so keep it like a database password.
+// - PASSWORD_RESET_URL: frontend page receiving ?token=... from reset emails
+// - PASSWORD_RESET_TTL: reset link validity (default: 30m)
+// - PASSWORD_MIN_LENGTH: minimum length of new passwords (default: 10)
+// - PASSWORD_MIN_CLASSES: required character classes out of lower/upper/digit/symbol (default: 2)
+// - PASSWORD_DENY_COMMON: reject passwords from the bundled common-password list (default: true)
+// - PASSWORD_HISTORY: how many recent passwords cannot be reused (default: 5, 0 disables)
+// - BCRYPT_COST: bcrypt work factor; existing hashes are upgraded on next login (default: 10)
+type AuthConfig struct {
+	LockoutThreshold   int
+	LockoutDuration    time.Duration
+	LockoutMaxDuration time.Duration
+
+	LoginRateLimit       int
+	LoginRateLimitWindow time.Duration
+
+	TOTPIssuer    string
+	MFAPendingTTL time.Duration
+	TOTPKey       string
+
+	PasswordResetURL string
+	PasswordResetTTL time.Duration
+
+	PasswordMinLength  int
+	PasswordMinClasses int
+	PasswordDenyCommon bool
+	PasswordHistory    int
+	BcryptCost         int
+}
+
+// MailConfig selects how transactional emails are delivered.
+//
+// Env:
+// - MAIL_DRIVER: none (default, mail disabled) | log (dev only: reset links land in the app log) | file | smtp
+// - MAIL_FROM: sender address, e.g. "Evening Gown <no-reply@example.com>"
+// - MAIL_FILE_DIR: output directory for the file driver (default: mail)
+// - SMTP_HOST / SMTP_PORT (default: 587) / SMTP_USERNAME / SMTP_PASSWORD
+// - SMTP_IMPLICIT_TLS: true for port-465 style TLS; otherwise STARTTLS is used when offered
+type MailConfig struct {
+	Driver  string
+	From    string
+	FileDir string
+	SMTP    SMTPConfig
+}
+
+// SMTPConfig holds SMTP relay settings (used by MAIL_DRIVER=smtp).
+type SMTPConfig struct {
+	Host        string
+	Port        int
+	Username    string
+	Password    string
+	ImplicitTLS bool
+}
+
+// PreviewConfig controls signed preview links for unpublished products and updates.
+//
+// Env:
+// - PREVIEW_SECRET: HMAC key for preview tokens (empty disables preview links); rotating it revokes all links
+// - PREVIEW_TTL: default link validity (default: 72h)
+// - PREVIEW_MAX_TTL: longest validity an admin may request (default: 720h)
+type PreviewConfig struct {
+	Secret string
+	TTL    time.Duration
+	MaxTTL time.Duration
+}
+
+// JobsConfig controls background jobs started by the server.
+//
+// Jobs are safe to run on several instances at once.
+//
+// Env:
+// - PUBLISH_SCHEDULER_INTERVAL: how often scheduled publish/unpublish times are applied (default: 30s, 0 disables)
+// - TRASH_RETENTION: how long deleted records stay restorable before they are purged (default: 720h, 0 keeps them)
+// - TRASH_PURGE_INTERVAL: how often expired records are purged (default: 1h, 0 disables)
+// - ASSET_GC_GRACE: how old an unreferenced upload must be before it is deleted (default: 168h, 0 keeps them)
+// - ASSET_GC_INTERVAL: how often unreferenced uploads are collected (default: 6h, 0 disables)
+type JobsConfig struct {
+	PublishSchedulerInterval time.Duration
+	TrashRetention           time.Duration
+	TrashPurgeInterval       time.Duration
+	AssetGCGrace             time.Duration
+	AssetGCInterval          time.Duration
+}
+
 // DevConfig contains development-only toggles.
 type DevConfig struct {
 	// EnableDevTokenIssuer keeps legacy /auth/token endpoint enabled.
@@ -112,24 +211,76 @@ type MinioConfig struct {
 	PublicBaseURL string
 }
 
-// UploadConfig defines request limits for file uploads.
+// UploadConfig defines request limits for file uploads and the image renditions derived from them.
 type UploadConfig struct {
-	// MaxImageUploadBytes limits the uploaded image file size.
-	// Default: 1MB.
+	// MaxImageUploadBytes limits the uploaded image file size. Camera JPEGs are often 5-15MB;
+	// larger originals go through direct uploads.
+	// Env: MAX_IMAGE_UPLOAD_BYTES. Default: 20MB.
 	MaxImageUploadBytes int64
+	// ImageDecodeWorkers bounds how many uploaded images are decoded and resized at once; a
+	// decoded original holds up to 4 bytes per pixel in memory.
+	// Env: IMAGE_DECODE_WORKERS. Default: 2.
+	ImageDecodeWorkers int
+
+	// ImageRenditionWidths are the widths (px) each uploaded image is resized to.
+	// Env: IMAGE_RENDITION_WIDTHS, comma-separated. Default: 320,768,1600.
+	ImageRenditionWidths []int
+	// ImageQuality is the JPEG quality (1-100) of renditions.
+	// Env: IMAGE_QUALITY. Default: 82.
+	ImageQuality int
+	// ImageVariantWorkers bounds how many missing variants requested through
+	// /api/v1/assets/*key?w= are generated at once.
+	// Env: IMAGE_VARIANT_WORKERS. Default: 2.
+	ImageVariantWorkers int
+
+	// MaxDirectUploadBytes limits files uploaded straight to MinIO through a presigned
+	// upload slot (videos, high-resolution originals); they never pass through the server.
+	// Env: MAX_DIRECT_UPLOAD_BYTES. Default: 500MB.
+	MaxDirectUploadBytes int64
+	// DirectUploadTTL is how long a presigned upload slot stays valid.
+	// Env: DIRECT_UPLOAD_TTL. Default: 15m.
+	DirectUploadTTL time.Duration
 }
 
 // JWTConfig defines JSON Web Token signing and validation settings.
+//
+// Env:
+// - JWT_ALGORITHM: HS256 (default, uses JWT_SECRET) | RS256 | EdDSA (use JWT_PRIVATE_KEY_FILE)
+// - JWT_PRIVATE_KEY_FILE: PEM private key (PKCS#8, or PKCS#1 for RSA) of the active signing key
+// - JWT_KEY_ID: kid header of the active key (default: derived from the public key)
+// - JWT_VERIFY_KEYS: extra trusted public keys during rotation, comma-separated "kid=/path/key.pem"
+//
+// - JWT_LEGACY_HS256_UNTIL: RFC 3339 cutoff for kid-less HS256 tokens after switching to RS256/EdDSA
+//
+// When an asymmetric algorithm is used, older HS256 tokens (without kid) signed with JWT_SECRET
+// only validate while JWT_LEGACY_HS256_UNTIL is set and in the future. Leave it unset (or in
+// the past) to reject them.
 type JWTConfig struct {
 	Secret    string
 	Issuer    string
 	Audience  string
+	Algorithm string
+	// PrivateKeyFile is required for RS256/EdDSA.
+	PrivateKeyFile string
+	KeyID          string
+	// VerifyKeys maps kid => public key PEM file path.
+	VerifyKeys map[string]string
+	// LegacyHS256Until bounds acceptance of kid-less HS256 tokens under RS256/EdDSA (zero: rejected).
+	LegacyHS256Until time.Time
 	// ExpiresIn is the access token lifetime.
 	ExpiresIn time.Duration
 	// RefreshExpiresIn is the refresh token lifetime.
 	RefreshExpiresIn time.Duration
 }
 
+// Enabled reports whether enough key material is configured to issue tokens.
+func (j JWTConfig) Enabled() bool {
+	if strings.EqualFold(strings.TrimSpace(j.Algorithm), "HS256") || strings.TrimSpace(j.Algorithm) == "" {
+		return strings.TrimSpace(j.Secret) != ""
+	}
+	return strings.TrimSpace(j.PrivateKeyFile) != ""
+}
+
 // Load reads environment variables (optionally from .env) and returns a Config.
 func Load() (Config, error) {
 	// Attempt to load a local .env file for development. Missing file is ignored.
@@ -165,16 +316,74 @@ func Load() (Config, error) {
 			PublicBaseURL: getEnv("MINIO_PUBLIC_BASE_URL", ""),
 		},
 		Upload: UploadConfig{
-			MaxImageUploadBytes: getInt64Env("MAX_IMAGE_UPLOAD_BYTES", 1048576),
+			MaxImageUploadBytes:  getInt64Env("MAX_IMAGE_UPLOAD_BYTES", 20971520),
+			ImageDecodeWorkers:   getIntEnv("IMAGE_DECODE_WORKERS", 2),
+			ImageRenditionWidths: getIntListEnv("IMAGE_RENDITION_WIDTHS", []int{320, 768, 1600}),
+			ImageQuality:         getIntEnv("IMAGE_QUALITY", 82),
+			ImageVariantWorkers:  getIntEnv("IMAGE_VARIANT_WORKERS", 2),
+			MaxDirectUploadBytes: getInt64Env("MAX_DIRECT_UPLOAD_BYTES", 524288000),
+			DirectUploadTTL:      getDurationEnv("DIRECT_UPLOAD_TTL", 15*time.Minute),
 		},
 		JWT: JWTConfig{
 			Secret:    getEnv("JWT_SECRET", ""),
 			Issuer:    getEnv("JWT_ISSUER", "evening-gown"),
 			Audience:  getEnv("JWT_AUDIENCE", ""),
+			Algorithm: getEnv("JWT_ALGORITHM", "HS256"),
+
+			PrivateKeyFile: getEnv("JWT_PRIVATE_KEY_FILE", ""),
+			KeyID:          getEnv("JWT_KEY_ID", ""),
+			VerifyKeys:     getKeyValueListEnv("JWT_VERIFY_KEYS"),
+
+			LegacyHS256Until: getTimeEnv("JWT_LEGACY_HS256_UNTIL"),
 			// Default to a short-lived access token; use refresh tokens for long sessions.
 			ExpiresIn:        getDurationEnv("JWT_EXPIRES_IN", 15*time.Minute),
 			RefreshExpiresIn: getDurationEnv("JWT_REFRESH_EXPIRES_IN", 30*24*time.Hour),
 		},
+		Auth: AuthConfig{
+			LockoutThreshold:   getIntEnv("LOGIN_LOCKOUT_THRESHOLD", 5),
+			LockoutDuration:    getDurationEnv("LOGIN_LOCKOUT_DURATION", 15*time.Minute),
+			LockoutMaxDuration: getDurationEnv("LOGIN_LOCKOUT_MAX_DURATION", 24*time.Hour),
+
+			LoginRateLimit:       getIntEnv("LOGIN_RATE_LIMIT", 20),
+			LoginRateLimitWindow: getDurationEnv("LOGIN_RATE_LIMIT_WINDOW", time.Minute),
+
+			TOTPIssuer:    getEnv("TOTP_ISSUER", "Evening Gown"),
+			MFAPendingTTL: getDurationEnv("MFA_PENDING_TTL", 5*time.Minute),
+			TOTPKey:       getEnv("AUTH_TOTP_KEY", ""),
+
+			PasswordResetURL: getEnv("PASSWORD_RESET_URL", "http://localhost:5173/admin/reset-password"),
+			PasswordResetTTL: getDurationEnv("PASSWORD_RESET_TTL", 30*time.Minute),
+
+			PasswordMinLength:  getIntEnv("PASSWORD_MIN_LENGTH", 10),
+			PasswordMinClasses: getIntEnv("PASSWORD_MIN_CLASSES", 2),
+			PasswordDenyCommon: getBoolEnv("PASSWORD_DENY_COMMON", true),
+			PasswordHistory:    getIntEnv("PASSWORD_HISTORY", 5),
+			BcryptCost:         getIntEnv("BCRYPT_COST", 10),
+		},
+		Mail: MailConfig{
+			Driver:  strings.ToLower(strings.TrimSpace(getEnv("MAIL_DRIVER", "none"))),
+			From:    getEnv("MAIL_FROM", ""),
+			FileDir: strings.TrimSpace(getEnv("MAIL_FILE_DIR", "mail")),
+			SMTP: SMTPConfig{
+				Host:        getEnv("SMTP_HOST", ""),
+				Port:        getIntEnv("SMTP_PORT", 587),
+				Username:    getEnv("SMTP_USERNAME", ""),
+				Password:    getEnv("SMTP_PASSWORD", ""),
+				ImplicitTLS: getBoolEnv("SMTP_IMPLICIT_TLS", false),
+			},
+		},
+		Preview: PreviewConfig{
+			Secret: getEnv("PREVIEW_SECRET", ""),
+			TTL:    getDurationEnv("PREVIEW_TTL", 72*time.Hour),
+			MaxTTL: getDurationEnv("PREVIEW_MAX_TTL", 720*time.Hour),
+		},
+		Jobs: JobsConfig{
+			PublishSchedulerInterval: getDurationEnv("PUBLISH_SCHEDULER_INTERVAL", 30*time.Second),
+			TrashRetention:           getDurationEnv("TRASH_RETENTION", 720*time.Hour),
+			TrashPurgeInterval:       getDurationEnv("TRASH_PURGE_INTERVAL", time.Hour),
+			AssetGCGrace:             getDurationEnv("ASSET_GC_GRACE", 168*time.Hour),
+			AssetGCInterval:          getDurationEnv("ASSET_GC_INTERVAL", 6*time.Hour),
+		},
 		Admin: AdminConfig{
 			Email:    getEnv("ADMIN_EMAIL", ""),
 			Password: getEnv("ADMIN_PASSWORD", ""),
@@ -206,6 +415,24 @@ func getEnv(key, fallback string) string {
 	return fallback
 }
 
+// getKeyValueListEnv parses "k1=v1,k2=v2". Entries without "=" are ignored.
+func getKeyValueListEnv(key string) map[string]string {
+	raw := strings.TrimSpace(os.Getenv(key))
+	if raw == "" {
+		return nil
+	}
+	out := map[string]string{}
+	for _, part := range strings.Split(raw, ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(part), "=")
+		k, v = strings.TrimSpace(k), strings.TrimSpace(v)
+		if !ok || k == "" || v == "" {
+			continue
+		}
+		out[k] = v
+	}
+	return out
+}
+
 func getIntEnv(key string, fallback int) int {
 	raw, ok := os.LookupEnv(key)
 	if !ok || raw == "" {
@@ -219,6 +446,31 @@ func getIntEnv(key string, fallback int) int {
 	return value
 }
 
+// getIntListEnv parses "1,2,3". An unparsable list falls back as a whole.
+func getIntListEnv(key string, fallback []int) []int {
+	raw := strings.TrimSpace(os.Getenv(key))
+	if raw == "" {
+		return fallback
+	}
+	var out []int
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		value, err := strconv.Atoi(part)
+		if err != nil {
+			log.Printf("config: %s expects comma-separated integers, got %q: %v (using %v)", key, raw, err, fallback)
+			return fallback
+		}
+		out = append(out, value)
+	}
+	if len(out) == 0 {
+		return fallback
+	}
+	return out
+}
+
 func getInt32Env(key string, fallback int32) int32 {
 	raw, ok := os.LookupEnv(key)
 	if !ok || raw == "" {
@@ -258,6 +510,20 @@ func getDurationEnv(key string, fallback time.Duration) time.Duration {
 	return value
 }
 
+// getTimeEnv parses an RFC 3339 timestamp; empty or invalid values yield the zero time.
+func getTimeEnv(key string) time.Time {
+	raw := strings.TrimSpace(os.Getenv(key))
+	if raw == "" {
+		return time.Time{}
+	}
+	value, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		log.Printf("config: %s expects RFC 3339 time, got %q: %v (ignored)", key, raw, err)
+		return time.Time{}
+	}
+	return value
+}
+
 func getBoolEnv(key string, fallback bool) bool {
 	raw, ok := os.LookupEnv(key)
 	if !ok || raw == "" {
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"evening-gown/internal/config"
+	"evening-gown/internal/imaging"
 	"evening-gown/internal/model"
 
 	"github.com/gin-gonic/gin"
@@ -141,11 +142,17 @@ func (h *AssetsHandler) isKnownProductAsset(c *gin.Context, objectKey string) (b
 	}
 
 	var cnt int64
-	like := "%" + objectKey + "%"
+	exact, like := objectKey, "%"+objectKey+"%"
+	where := "(cover_image_key = ? OR hover_image_key = ? OR CAST(detail_json AS TEXT) LIKE ?)"
+	// Products store one width of an upload; the other renditions are known with it.
+	if base, _, ok := imaging.ParseRenditionKey(objectKey); ok {
+		exact, like = base+"/%", "%"+base+"/%"
+		where = "(cover_image_key LIKE ? OR hover_image_key LIKE ? OR CAST(detail_json AS TEXT) LIKE ?)"
+	}
 	err = h.db.WithContext(c.Request.Context()).Model(&model.Product{}).
 		Where("style_no = ?", styleNo).
 		Where("deleted_at IS NULL").
-		Where("(cover_image_key = ? OR hover_image_key = ? OR CAST(detail_json AS TEXT) LIKE ?)", objectKey, objectKey, like).
+		Where(where, exact, exact, like).
 		Limit(1).
 		Count(&cnt).Error
 	if err != nil {
@@ -0,0 +1,184 @@
+package admin
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"evening-gown/internal/audit"
+	"evening-gown/internal/logging"
+	"evening-gown/internal/model"
+	"evening-gown/internal/pagination"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// auditExportMaxRows bounds a single CSV export; narrow the filters for more.
+const auditExportMaxRows = 50000
+
+// AuditHandler exposes the backoffice audit trail (read-only).
+//
+// Entries are written by the handlers themselves (see recordAudit) and by middleware.Audit.
+type AuditHandler struct {
+	db *gorm.DB
+}
+
+func NewAuditHandler(db *gorm.DB) *AuditHandler {
+	return &AuditHandler{db: db}
+}
+
+// List returns audit entries, newest first.
+//
+// Filters: actor_id, actor (email), action, entity_type, entity_id, request_id, from/to (RFC3339).
+// Route: GET /api/v1/admin/audit
+func (h *AuditHandler) List(c *gin.Context) {
+	if h == nil || h.db == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "service unavailable"})
+		return
+	}
+
+	q := h.filtered(c)
+
+	page := pagination.ParseParams(c.Request.URL.Query(), 50, 200)
+
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		logging.ErrorWithStack(logging.FromGin(c), "admin audit query count failed", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return
+	}
+
+	var items []model.AuditLog
+	if err := q.Order("id desc").Limit(page.Limit).Offset(page.Offset).Find(&items).Error; err != nil {
+		logging.ErrorWithStack(logging.FromGin(c), "admin audit query list failed", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"total": total, "items": items})
+}
+
+// Export streams the filtered entries as CSV (same filters as List).
+// Route: GET /api/v1/admin/audit/export
+func (h *AuditHandler) Export(c *gin.Context) {
+	if h == nil || h.db == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "service unavailable"})
+		return
+	}
+
+	rows, err := h.filtered(c).Order("id desc").Limit(auditExportMaxRows).Rows()
+	if err != nil {
+		logging.ErrorWithStack(logging.FromGin(c), "admin audit query export failed", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return
+	}
+	defer rows.Close()
+
+	filename := fmt.Sprintf("audit-%s.csv", time.Now().UTC().Format("20060102-150405"))
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	_ = w.Write([]string{"id", "created_at", "actor_id", "actor_email", "actor_role", "action", "entity_type", "entity_id", "changes", "request_id", "ip", "user_agent"})
+	for rows.Next() {
+		var row model.AuditLog
+		if err := h.db.ScanRows(rows, &row); err != nil {
+			// Headers are already sent; log and truncate the file.
+			logging.ErrorWithStack(logging.FromGin(c), "admin audit export scan failed", err)
+			break
+		}
+		actorID := ""
+		if row.ActorID != nil {
+			actorID = strconv.FormatUint(uint64(*row.ActorID), 10)
+		}
+		_ = w.Write([]string{
+			strconv.FormatUint(uint64(row.ID), 10),
+			row.CreatedAt.UTC().Format(time.RFC3339),
+			actorID,
+			csvSafe(row.ActorEmail),
+			row.ActorRole,
+			row.Action,
+			row.EntityType,
+			csvSafe(row.EntityID),
+			string(row.Changes),
+			row.RequestID,
+			row.IP,
+			csvSafe(row.UserAgent),
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		logging.FromGin(c).Warn("admin audit export write failed", "err", err)
+	}
+}
+
+func (h *AuditHandler) filtered(c *gin.Context) *gorm.DB {
+	q := h.db.WithContext(c.Request.Context()).Model(&model.AuditLog{})
+
+	if v := strings.TrimSpace(c.Query("actor_id")); v != "" {
+		if id, err := strconv.ParseUint(v, 10, 64); err == nil && id > 0 {
+			q = q.Where("actor_id = ?", uint(id))
+		}
+	}
+	if v := strings.ToLower(strings.TrimSpace(c.Query("actor"))); v != "" {
+		q = q.Where("actor_email = ?", v)
+	}
+	if v := strings.TrimSpace(c.Query("action")); v != "" {
+		q = q.Where("action = ?", v)
+	}
+	if v := strings.TrimSpace(c.Query("entity_type")); v != "" {
+		q = q.Where("entity_type = ?", v)
+	}
+	if v := strings.TrimSpace(c.Query("entity_id")); v != "" {
+		q = q.Where("entity_id = ?", v)
+	}
+	if v := strings.TrimSpace(c.Query("request_id")); v != "" {
+		q = q.Where("request_id = ?", v)
+	}
+	if from := strings.TrimSpace(c.Query("from")); from != "" {
+		if t, err := time.Parse(time.RFC3339, from); err == nil {
+			q = q.Where("created_at >= ?", t)
+		}
+	}
+	if to := strings.TrimSpace(c.Query("to")); to != "" {
+		if t, err := time.Parse(time.RFC3339, to); err == nil {
+			q = q.Where("created_at <= ?", t)
+		}
+	}
+	return q
+}
+
+// csvSafe neutralizes spreadsheet formulas in user-controlled cells.
+func csvSafe(s string) string {
+	if s != "" && strings.ContainsRune("=+-@", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// recordAudit writes an audit entry for a mutation that already succeeded.
+// Failures are logged only: the change is committed and must not be reported as failed.
+func recordAudit(c *gin.Context, db *gorm.DB, e audit.Entry) {
+	if err := audit.Record(c.Request.Context(), db, e); err != nil {
+		logging.ErrorWithStack(logging.FromGin(c), "admin audit record failed", err, "action", e.Action, "entity_id", e.EntityID)
+	}
+}
+
+// reloadForAudit loads the current row (soft-deleted ones included) as the "after" side of an entry.
+func reloadForAudit[T any](ctx context.Context, db *gorm.DB, id uint) *T {
+	var v T
+	if err := db.WithContext(ctx).First(&v, id).Error; err != nil {
+		return nil
+	}
+	return &v
+}
+
+func auditID(id uint) string {
+	return strconv.FormatUint(uint64(id), 10)
+}
@@ -1,6 +1,7 @@
 package admin
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
@@ -10,6 +11,7 @@ import (
 	"evening-gown/internal/logging"
 	"evening-gown/internal/middleware"
 	"evening-gown/internal/model"
+	"evening-gown/internal/ratelimit"
 	"evening-gown/internal/security"
 
 	"github.com/gin-gonic/gin"
@@ -17,12 +19,63 @@ import (
 )
 
 type AuthHandler struct {
-	db     *gorm.DB
-	jwtSvc *auth.Service
+	db            *gorm.DB
+	jwtSvc        *auth.Service
+	lockout       security.LockoutPolicy
+	unknownFails  ratelimit.Limiter
+	totpIssuer    string
+	mfaPendingTTL time.Duration
+	totpKeys      *security.SecretBox
+	passwords     security.PasswordPolicy
+}
+
+// AuthHandlerOptions tunes login hardening. Zero values disable the feature
+// (or fall back to defaults for the 2FA settings).
+type AuthHandlerOptions struct {
+	Lockout security.LockoutPolicy
+	// UnknownLoginLimiter counts failed logins for emails without a usable account, so they
+	// report account_locked at the same threshold as real accounts (no enumeration via 423).
+	// It should allow Lockout.Threshold-1 attempts per Lockout.Duration; nil uses an
+	// in-memory limiter with those settings.
+	UnknownLoginLimiter ratelimit.Limiter
+	// TOTPIssuer is the issuer label in otpauth:// provisioning URIs.
+	TOTPIssuer string
+	// MFAPendingTTL bounds the time between the password step and the TOTP step.
+	MFAPendingTTL time.Duration
+	// TOTPKey encrypts TOTP secrets in the users table (see security.SecretBox); empty
+	// stores them in plaintext.
+	TOTPKey string
+	// PasswordPolicy applies to password changes and bcrypt rehash-on-login (zero value: defaults).
+	PasswordPolicy security.PasswordPolicy
 }
 
 func NewAuthHandler(db *gorm.DB, jwtSvc *auth.Service) *AuthHandler {
-	return &AuthHandler{db: db, jwtSvc: jwtSvc}
+	return NewAuthHandlerWithOptions(db, jwtSvc, AuthHandlerOptions{Lockout: security.DefaultLockoutPolicy()})
+}
+
+func NewAuthHandlerWithOptions(db *gorm.DB, jwtSvc *auth.Service, opts AuthHandlerOptions) *AuthHandler {
+	issuer := strings.TrimSpace(opts.TOTPIssuer)
+	if issuer == "" {
+		issuer = defaultTOTPIssuer
+	}
+	ttl := opts.MFAPendingTTL
+	if ttl <= 0 {
+		ttl = defaultMFAPendingTTL
+	}
+	unknownFails := opts.UnknownLoginLimiter
+	if unknownFails == nil && opts.Lockout.Enabled() {
+		unknownFails = ratelimit.NewMemory(opts.Lockout.Threshold-1, opts.Lockout.Duration)
+	}
+	return &AuthHandler{
+		db:            db,
+		jwtSvc:        jwtSvc,
+		lockout:       opts.Lockout,
+		unknownFails:  unknownFails,
+		totpIssuer:    issuer,
+		mfaPendingTTL: ttl,
+		totpKeys:      security.NewSecretBox(opts.TOTPKey),
+		passwords:     passwordPolicyOrDefault(opts.PasswordPolicy),
+	}
 }
 
 type loginRequest struct {
@@ -65,22 +118,22 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	var user model.User
 	if err := h.db.WithContext(c.Request.Context()).Where("email = ? AND deleted_at IS NULL", email).First(&user).Error; err != nil {
 		// Avoid leaking which part failed.
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"code":    "invalid_credentials",
-			"message": "invalid credentials",
-			"error":   "invalid credentials",
-		})
+		h.rejectUnknownLogin(c, email)
 		return
 	}
-	if user.Role != "admin" || user.Status != "active" {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"code":    "invalid_credentials",
-			"message": "invalid credentials",
-			"error":   "invalid credentials",
-		})
+	if !model.IsValidRole(user.Role) || user.Status != "active" {
+		h.rejectUnknownLogin(c, email)
+		return
+	}
+	if user.LockedUntil != nil && user.LockedUntil.After(time.Now()) {
+		respondAccountLocked(c, *user.LockedUntil)
 		return
 	}
 	if !security.CheckPassword(user.PasswordHash, password) {
+		if lockedUntil, locked := h.recordFailedLogin(c, user.ID); locked {
+			respondAccountLocked(c, lockedUntil)
+			return
+		}
 		c.JSON(http.StatusUnauthorized, gin.H{
 			"code":    "invalid_credentials",
 			"message": "invalid credentials",
@@ -88,48 +141,96 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		})
 		return
 	}
+	h.rehashPassword(c, user, password)
 
+	if user.TwoFactorEnabled() {
+		h.requireSecondFactor(c, user)
+		return
+	}
+
+	h.completeLogin(c, user)
+}
+
+// completeLogin resets the failure counters and starts a new session.
+// Every login gets its own session, so other devices stay signed in.
+func (h *AuthHandler) completeLogin(c *gin.Context, user model.User) {
 	now := time.Now().UTC()
 	_ = h.db.WithContext(c.Request.Context()).Model(&model.User{}).Where("id = ?", user.ID).Updates(map[string]any{
-		"last_login_at":       now,
-		"failed_login_count":  0,
-		"locked_until":        nil,
-		"refresh_token_issued_at": now,
-		"updated_at":          now,
+		"last_login_at":      now,
+		"failed_login_count": 0,
+		"locked_until":       nil,
+		"updated_at":         now,
 	}).Error
 
-	pwdAt := int64(0)
-	if user.PasswordUpdatedAt != nil {
-		pwdAt = user.PasswordUpdatedAt.UTC().Unix()
-	}
+	h.startSession(c, user)
+}
+
+// recordFailedLogin bumps the failure counter atomically and applies the lockout policy.
+// It reports the lock expiry when this failure (re)locks the account.
+func (h *AuthHandler) recordFailedLogin(c *gin.Context, userID uint) (time.Time, bool) {
+	var lockedUntil time.Time
+	locked := false
+
+	err := h.db.WithContext(c.Request.Context()).Transaction(func(tx *gorm.DB) error {
+		now := time.Now().UTC()
+		if err := tx.Model(&model.User{}).Where("id = ?", userID).Updates(map[string]any{
+			"failed_login_count": gorm.Expr("failed_login_count + 1"),
+			"updated_at":         now,
+		}).Error; err != nil {
+			return err
+		}
+
+		var count int
+		if err := tx.Model(&model.User{}).Where("id = ?", userID).Select("failed_login_count").Scan(&count).Error; err != nil {
+			return err
+		}
 
-	accessToken, accessExp, err := h.jwtSvc.IssueAdminToken(strconv.FormatUint(uint64(user.ID), 10), pwdAt)
+		d := h.lockout.LockDuration(count)
+		if d <= 0 {
+			return nil
+		}
+		lockedUntil = now.Add(d)
+		locked = true
+		return tx.Model(&model.User{}).Where("id = ?", userID).Update("locked_until", lockedUntil).Error
+	})
 	if err != nil {
-		logging.ErrorWithStack(logging.FromGin(c), "admin issue token failed", err, "user_id", user.ID)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code":    "issue_token_failed",
-			"message": "issue token failed",
-			"error":   "issue token failed",
-		})
-		return
+		logging.ErrorWithStack(logging.FromGin(c), "admin record failed login failed", err, "user_id", userID)
+		return time.Time{}, false
 	}
+	return lockedUntil, locked
+}
 
-	refreshToken, refreshExp, err := h.jwtSvc.IssueAdminRefreshToken(strconv.FormatUint(uint64(user.ID), 10), pwdAt)
-	if err != nil {
-		logging.ErrorWithStack(logging.FromGin(c), "admin issue refresh token failed", err, "user_id", user.ID)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code":    "issue_token_failed",
-			"message": "issue token failed",
-			"error":   "issue token failed",
-		})
-		return
+// rejectUnknownLogin answers a login for an email without a usable account. Failures are
+// counted per email so the response sequence (401 … 423) matches a real account's lockout.
+func (h *AuthHandler) rejectUnknownLogin(c *gin.Context, email string) {
+	if h.unknownFails != nil {
+		res, err := h.unknownFails.Allow(c.Request.Context(), "admin_login_unknown:"+email)
+		if err != nil {
+			logging.FromGin(c).Warn("unknown login counter failed", "err", err)
+		} else if !res.Allowed {
+			respondAccountLocked(c, time.Now().Add(res.RetryAfter))
+			return
+		}
 	}
+	c.JSON(http.StatusUnauthorized, gin.H{
+		"code":    "invalid_credentials",
+		"message": "invalid credentials",
+		"error":   "invalid credentials",
+	})
+}
 
-	c.JSON(http.StatusOK, gin.H{
-		"token":              accessToken,
-		"expires_at":         accessExp.UTC().Format(time.RFC3339),
-		"refresh_token":      refreshToken,
-		"refresh_expires_at": refreshExp.UTC().Format(time.RFC3339),
+func respondAccountLocked(c *gin.Context, until time.Time) {
+	retryAfter := int64(time.Until(until).Seconds()) + 1
+	if retryAfter < 1 {
+		retryAfter = 1
+	}
+	c.Header("Retry-After", strconv.FormatInt(retryAfter, 10))
+	c.JSON(http.StatusLocked, gin.H{
+		"code":         "account_locked",
+		"message":      "account temporarily locked",
+		"error":        "account temporarily locked",
+		"locked_until": until.UTC().Format(time.RFC3339),
+		"retry_after":  retryAfter,
 	})
 }
 
@@ -190,7 +291,7 @@ func (h *AuthHandler) Refresh(c *gin.Context) {
 		})
 		return
 	}
-	if user.Role != "admin" || user.Status != "active" {
+	if !model.IsValidRole(user.Role) || user.Status != "active" {
 		c.JSON(http.StatusForbidden, gin.H{
 			"code":    "forbidden",
 			"message": "forbidden",
@@ -230,65 +331,39 @@ func (h *AuthHandler) Refresh(c *gin.Context) {
 		}
 	}
 
-	// Refresh token rotation guard: reject refresh tokens older than the latest issued marker.
-	if user.RefreshTokenIssuedAt != nil {
-		if claims.IssuedAt == nil {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"code":    "unauthorized",
-				"message": "unauthorized",
-				"error":   "unauthorized",
-			})
-			return
-		}
-		if claims.IssuedAt.Time.UTC().Unix() < user.RefreshTokenIssuedAt.UTC().Unix() {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"code":    "unauthorized",
-				"message": "unauthorized",
-				"error":   "unauthorized",
-			})
-			return
+	if strings.TrimSpace(claims.SessionID) == "" {
+		// Legacy refresh token issued before server-side sessions: keep the single-marker
+		// rotation guard, then move the client onto a regular session.
+		if user.RefreshTokenIssuedAt != nil {
+			if claims.IssuedAt == nil {
+				c.JSON(http.StatusUnauthorized, gin.H{
+					"code":    "unauthorized",
+					"message": "unauthorized",
+					"error":   "unauthorized",
+				})
+				return
+			}
+			if claims.IssuedAt.Time.UTC().Unix() < user.RefreshTokenIssuedAt.UTC().Unix() {
+				c.JSON(http.StatusUnauthorized, gin.H{
+					"code":    "unauthorized",
+					"message": "unauthorized",
+					"error":   "unauthorized",
+				})
+				return
+			}
 		}
-	}
 
-	now := time.Now().UTC()
-	_ = h.db.WithContext(c.Request.Context()).Model(&model.User{}).Where("id = ?", user.ID).Updates(map[string]any{
-		"refresh_token_issued_at": now,
-		"updated_at":             now,
-	}).Error
-
-	pwdAt := int64(0)
-	if user.PasswordUpdatedAt != nil {
-		pwdAt = user.PasswordUpdatedAt.UTC().Unix()
-	}
+		now := time.Now().UTC()
+		_ = h.db.WithContext(c.Request.Context()).Model(&model.User{}).Where("id = ?", user.ID).Updates(map[string]any{
+			"refresh_token_issued_at": now,
+			"updated_at":              now,
+		}).Error
 
-	accessToken, accessExp, err := h.jwtSvc.IssueAdminToken(strconv.FormatUint(uint64(user.ID), 10), pwdAt)
-	if err != nil {
-		logging.ErrorWithStack(logging.FromGin(c), "admin issue token failed", err, "user_id", user.ID)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code":    "issue_token_failed",
-			"message": "issue token failed",
-			"error":   "issue token failed",
-		})
+		h.startSession(c, user)
 		return
 	}
 
-	refreshToken, refreshExp, err := h.jwtSvc.IssueAdminRefreshToken(strconv.FormatUint(uint64(user.ID), 10), pwdAt)
-	if err != nil {
-		logging.ErrorWithStack(logging.FromGin(c), "admin issue refresh token failed", err, "user_id", user.ID)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code":    "issue_token_failed",
-			"message": "issue token failed",
-			"error":   "issue token failed",
-		})
-		return
-	}
-
-	c.JSON(http.StatusOK, gin.H{
-		"token":              accessToken,
-		"expires_at":         accessExp.UTC().Format(time.RFC3339),
-		"refresh_token":      refreshToken,
-		"refresh_expires_at": refreshExp.UTC().Format(time.RFC3339),
-	})
+	h.rotateSession(c, user, claims)
 }
 
 func (h *AuthHandler) Me(c *gin.Context) {
@@ -312,9 +387,11 @@ func (h *AuthHandler) Me(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"id":    user.ID,
-		"email": user.Email,
-		"role":  user.Role,
+		"id":          user.ID,
+		"email":       user.Email,
+		"role":        user.Role,
+		"permissions": model.RolePermissions(user.Role),
+		"twoFactor":   user.TwoFactorEnabled(),
 	})
 }
 
@@ -372,27 +449,41 @@ func (h *AuthHandler) ChangePassword(c *gin.Context) {
 		return
 	}
 
-	hash, err := security.HashPassword(newPassword)
+	if err := h.passwords.Validate(newPassword, user.Email); err != nil {
+		respondPasswordRejected(c, err)
+		return
+	}
+	if err := checkPasswordHistory(c.Request.Context(), h.db, h.passwords, user, newPassword); err != nil {
+		if errors.Is(err, security.ErrPasswordReused) {
+			respondPasswordRejected(c, err)
+			return
+		}
+		logging.ErrorWithStack(logging.FromGin(c), "admin change password query history failed", err, "user_id", user.ID)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    "change_password_failed",
+			"message": "change password failed",
+			"error":   "change password failed",
+		})
+		return
+	}
+
+	hash, err := h.passwords.Hash(newPassword)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// JWT iat is second-precision. Truncate to seconds so AdminAuth comparison is stable.
-	now := time.Now().UTC().Truncate(time.Second)
-	// Ensure the marker actually changes even if the request happens within the same second
-	// (common in tests / fast machines). AdminAuth compares pwd_at by equality.
-	if user.PasswordUpdatedAt != nil {
-		prev := user.PasswordUpdatedAt.UTC().Truncate(time.Second)
-		if !now.After(prev) {
-			now = prev.Add(time.Second)
+	now := nextPasswordUpdatedAt(user.PasswordUpdatedAt)
+	if err := h.db.WithContext(c.Request.Context()).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.User{}).Where("id = ? AND deleted_at IS NULL", user.ID).Updates(map[string]any{
+			"password_hash":       hash,
+			"password_updated_at": &now,
+			"updated_at":          now,
+		}).Error; err != nil {
+			return err
 		}
-	}
-	if err := h.db.WithContext(c.Request.Context()).Model(&model.User{}).Where("id = ? AND deleted_at IS NULL", user.ID).Updates(map[string]any{
-		"password_hash":       hash,
-		"password_updated_at": &now,
-		"updated_at":          now,
-	}).Error; err != nil {
+		return recordPasswordHistory(tx, h.passwords, user.ID, user.PasswordHash)
+	}); err != nil {
 		logging.ErrorWithStack(logging.FromGin(c), "admin change password failed", err, "user_id", user.ID)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code":    "change_password_failed",
@@ -402,7 +493,48 @@ func (h *AuthHandler) ChangePassword(c *gin.Context) {
 		return
 	}
 
+	// Tokens are already rejected via pwd_at; mark the sessions so the device list reflects it.
+	if _, err := h.revokeSessions(c.Request.Context(), model.SessionRevokedPasswordChange, "user_id = ?", user.ID); err != nil {
+		logging.FromGin(c).Warn("admin revoke sessions after password change failed", "err", err, "user_id", user.ID)
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"ok": true,
 	})
 }
+
+// rehashPassword upgrades the stored hash after a successful password check when the
+// configured bcrypt cost changed. PasswordUpdatedAt is untouched, so issued tokens stay valid.
+// Failures are only logged: the login itself already succeeded.
+func (h *AuthHandler) rehashPassword(c *gin.Context, user model.User, password string) {
+	if !h.passwords.NeedsRehash(user.PasswordHash) {
+		return
+	}
+	hash, err := h.passwords.Hash(password)
+	if err != nil {
+		logging.FromGin(c).Warn("admin rehash password failed", "err", err, "user_id", user.ID)
+		return
+	}
+	// Conditional on the old hash so a concurrent password change is never overwritten.
+	if err := h.db.WithContext(c.Request.Context()).Model(&model.User{}).
+		Where("id = ? AND password_hash = ?", user.ID, user.PasswordHash).
+		Update("password_hash", hash).Error; err != nil {
+		logging.FromGin(c).Warn("admin rehash password failed", "err", err, "user_id", user.ID)
+	}
+}
+
+// nextPasswordUpdatedAt returns the new password marker for a password change.
+//
+// JWT iat is second-precision, so the marker is truncated to seconds. It is also forced to move
+// forward even within the same second (common in tests / fast machines), because AdminAuth
+// compares pwd_at by equality.
+func nextPasswordUpdatedAt(prev *time.Time) time.Time {
+	now := time.Now().UTC().Truncate(time.Second)
+	if prev != nil {
+		p := prev.UTC().Truncate(time.Second)
+		if !now.After(p) {
+			now = p.Add(time.Second)
+		}
+	}
+	return now
+}
@@ -7,9 +7,11 @@ import (
 	"strings"
 	"time"
 
+	"evening-gown/internal/audit"
 	"evening-gown/internal/cache"
 	"evening-gown/internal/logging"
 	"evening-gown/internal/model"
+	"evening-gown/internal/pagination"
 
 	"github.com/gin-gonic/gin"
 	"github.com/redis/go-redis/v9"
@@ -83,38 +85,13 @@ func (h *ContactsHandler) List(c *gin.Context) {
 		return
 	}
 
-	q := h.db.WithContext(c.Request.Context()).Model(&model.ContactLead{})
+	q := h.db.WithContext(c.Request.Context()).Model(&model.ContactLead{}).
+		Where("deleted_at IS NULL")
 	if st := strings.TrimSpace(c.Query("status")); st != "" {
 		q = q.Where("status = ?", st)
 	}
 
-	limit := parseIntQuery(c, "limit", 50)
-	offset := parseIntQuery(c, "offset", 0)
-	if limit <= 0 {
-		limit = 50
-	}
-	if limit > 200 {
-		limit = 200
-	}
-	if offset < 0 {
-		offset = 0
-	}
-
-	var total int64
-	if err := q.Count(&total).Error; err != nil {
-		logging.ErrorWithStack(logging.FromGin(c), "admin contacts query count failed", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
-		return
-	}
-
-	var items []model.ContactLead
-	if err := q.Order("id desc").Limit(limit).Offset(offset).Find(&items).Error; err != nil {
-		logging.ErrorWithStack(logging.FromGin(c), "admin contacts query list failed", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
-		return
-	}
-
-	c.JSON(http.StatusOK, gin.H{"total": total, "items": items})
+	listSorted(c, q, adminContactSorts, pagination.ParseParams(c.Request.URL.Query(), 50, 200), "contacts")
 }
 
 func (h *ContactsHandler) Get(c *gin.Context) {
@@ -130,7 +107,9 @@ func (h *ContactsHandler) Get(c *gin.Context) {
 	}
 
 	var lead model.ContactLead
-	if err := h.db.WithContext(c.Request.Context()).First(&lead, uint(id)).Error; err != nil {
+	if err := h.db.WithContext(c.Request.Context()).
+		Where("deleted_at IS NULL").
+		First(&lead, uint(id)).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
 		return
 	}
@@ -168,7 +147,9 @@ func (h *ContactsHandler) Update(c *gin.Context) {
 	ctx := c.Request.Context()
 
 	var before model.ContactLead
-	if err := h.db.WithContext(ctx).First(&before, uint(id)).Error; err != nil {
+	if err := h.db.WithContext(ctx).
+		Where("deleted_at IS NULL").
+		First(&before, uint(id)).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
 		return
 	}
@@ -179,7 +160,7 @@ func (h *ContactsHandler) Update(c *gin.Context) {
 		return
 	}
 
-	if err := h.db.WithContext(ctx).Model(&model.ContactLead{}).Where("id = ?", uint(id)).Update("status", st).Error; err != nil {
+	if err := h.db.WithContext(ctx).Model(&model.ContactLead{}).Where("id = ? AND deleted_at IS NULL", uint(id)).Update("status", st).Error; err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
@@ -205,6 +186,7 @@ func (h *ContactsHandler) Update(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
 		return
 	}
+	recordAudit(c, h.db, audit.Entry{Action: "contact.update_status", EntityType: "contacts", EntityID: auditID(lead.ID), Before: before, After: lead})
 	c.JSON(http.StatusOK, lead)
 }
 
@@ -222,12 +204,18 @@ func (h *ContactsHandler) Delete(c *gin.Context) {
 
 	ctx := c.Request.Context()
 	var before model.ContactLead
-	if err := h.db.WithContext(ctx).First(&before, uint(id)).Error; err != nil {
+	if err := h.db.WithContext(ctx).
+		Where("deleted_at IS NULL").
+		First(&before, uint(id)).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
 		return
 	}
 
-	res := h.db.WithContext(ctx).Delete(&model.ContactLead{}, uint(id))
+	// Soft delete: the lead stays in the trash until restored or purged.
+	now := time.Now().UTC()
+	res := h.db.WithContext(ctx).Model(&model.ContactLead{}).
+		Where("id = ? AND deleted_at IS NULL", uint(id)).
+		Update("deleted_at", &now)
 	if res.Error != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": res.Error.Error()})
 		return
@@ -236,6 +224,7 @@ func (h *ContactsHandler) Delete(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
 		return
 	}
+	recordAudit(c, h.db, audit.Entry{Action: "contact.delete", EntityType: "contacts", EntityID: auditID(before.ID), Before: before})
 
 	if h.rdb != nil && strings.TrimSpace(before.Status) == "new" {
 		if err := h.applyNewLeadsDelta(ctx, -1); err != nil {
@@ -248,7 +237,7 @@ func (h *ContactsHandler) Delete(c *gin.Context) {
 
 func (h *ContactsHandler) countNewLeads(ctx context.Context) (int64, error) {
 	var count int64
-	err := h.db.WithContext(ctx).Model(&model.ContactLead{}).Where("status = ?", "new").Count(&count).Error
+	err := h.db.WithContext(ctx).Model(&model.ContactLead{}).Where("status = ? AND deleted_at IS NULL", "new").Count(&count).Error
 	return count, err
 }
 
@@ -0,0 +1,328 @@
+package admin
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"evening-gown/internal/imaging"
+	"evening-gown/internal/logging"
+	"evening-gown/internal/middleware"
+	"evening-gown/internal/model"
+	"evening-gown/internal/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/minio/minio-go/v7"
+	"gorm.io/gorm"
+)
+
+// Direct uploads let the backoffice send large files (lookbook videos, high-resolution
+// originals) straight to MinIO: the server hands out a presigned slot, the browser uploads,
+// and finalize verifies the stored object before registering it in the media library.
+// Images are stored as uploaded; width variants are generated on demand (?w=).
+
+// directUploadTypes maps the content types accepted for direct uploads to the key extension.
+var directUploadTypes = map[string]string{
+	"image/jpeg":      "jpg",
+	"image/png":       "png",
+	"image/webp":      "webp",
+	"video/mp4":       "mp4",
+	"video/webm":      "webm",
+	"video/quicktime": "mov",
+}
+
+// products/{styleNo}/{kind}/{yyyy}/{mm}/{dd}/{uuid}.{ext}, the layout UploadImage used for
+// single-size images.
+var directUploadKeyRe = regexp.MustCompile(`^products/([^/]+)/(cover|hover|gallery|video)/\d{4}/\d{2}/\d{2}/[0-9a-f-]{36}\.([a-z0-9]+)$`)
+
+// directUploadKindAccepts reports whether kind may hold contentType: videos go to the
+// "video" kind, images to cover|hover|gallery.
+func directUploadKindAccepts(kind, contentType string) bool {
+	switch kind {
+	case "cover", "hover", "gallery":
+		return strings.HasPrefix(contentType, "image/")
+	case "video":
+		return strings.HasPrefix(contentType, "video/")
+	}
+	return false
+}
+
+// directUploadKey builds the object key of a new slot.
+func directUploadKey(styleNo, kind, contentType string, now time.Time) string {
+	return fmt.Sprintf(
+		"products/%s/%s/%04d/%02d/%02d/%s.%s",
+		styleNo,
+		kind,
+		now.Year(),
+		now.Month(),
+		now.Day(),
+		uuid.NewString(),
+		directUploadTypes[contentType],
+	)
+}
+
+// parseDirectUploadKey validates a key made by directUploadKey and returns its parts and the
+// content type its extension stands for.
+func parseDirectUploadKey(key string) (styleNo, kind, contentType string, ok bool) {
+	m := directUploadKeyRe.FindStringSubmatch(key)
+	if m == nil {
+		return "", "", "", false
+	}
+	normalized, err := model.NormalizeStyleNo(m[1])
+	if err != nil || normalized != m[1] {
+		return "", "", "", false
+	}
+	for ct, ext := range directUploadTypes {
+		if ext == m[3] && directUploadKindAccepts(m[2], ct) {
+			return m[1], m[2], ct, true
+		}
+	}
+	return "", "", "", false
+}
+
+type directUploadRequest struct {
+	Kind        string `json:"kind" binding:"required"`
+	StyleNo     string `json:"styleNo" binding:"required"`
+	ContentType string `json:"contentType" binding:"required"`
+	Size        int64  `json:"size"`
+}
+
+type directUploadFinalizeRequest struct {
+	ObjectKey string `json:"objectKey" binding:"required"`
+}
+
+func (h *UploadsHandler) storageReady(c *gin.Context) bool {
+	if h == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "service unavailable"})
+		return false
+	}
+	if h.minioClient == nil || strings.TrimSpace(h.minioCfg.Endpoint) == "" {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "minio disabled"})
+		return false
+	}
+	if strings.TrimSpace(h.minioCfg.Bucket) == "" {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "minio bucket not configured"})
+		return false
+	}
+	return true
+}
+
+// CreateDirectUpload issues a presigned upload slot.
+//
+// Body: {"kind":"cover|hover|gallery|video","styleNo":"...","contentType":"video/mp4","size":123}
+// The response offers the same object key two ways: "put" (PUT the file with the given
+// headers) and "post" (multipart form to url: fields first, the file last as "file"; MinIO
+// enforces the size limit). Call FinalizeDirectUpload afterwards.
+// Route: POST /api/v1/admin/uploads/direct
+func (h *UploadsHandler) CreateDirectUpload(c *gin.Context) {
+	if !h.storageReady(c) {
+		return
+	}
+
+	var req directUploadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	kind := strings.TrimSpace(req.Kind)
+	if kind != "cover" && kind != "hover" && kind != "gallery" && kind != "video" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid kind"})
+		return
+	}
+	styleNo, err := model.NormalizeStyleNo(strings.TrimSpace(req.StyleNo))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid styleNo"})
+		return
+	}
+	contentType := strings.ToLower(strings.TrimSpace(req.ContentType))
+	if _, ok := directUploadTypes[contentType]; !ok || !directUploadKindAccepts(kind, contentType) {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "unsupported content type for kind", "contentType": contentType})
+		return
+	}
+	if req.Size < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid size"})
+		return
+	}
+	if req.Size > h.maxDirectBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "file too large", "maxBytes": h.maxDirectBytes})
+		return
+	}
+
+	ctx := c.Request.Context()
+	now := time.Now().UTC()
+	objectKey := directUploadKey(styleNo, kind, contentType, now)
+
+	putURL, err := storage.PresignedPut(ctx, h.minioClient, h.minioCfg, objectKey, h.directTTL)
+	if err != nil {
+		logging.ErrorWithStack(logging.FromGin(c), "admin direct upload presign failed", err, "objectKey", objectKey)
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "storage unavailable"})
+		return
+	}
+	postURL, fields, err := storage.PresignedPost(ctx, h.minioClient, h.minioCfg, objectKey, contentType, h.maxDirectBytes, h.directTTL)
+	if err != nil {
+		logging.ErrorWithStack(logging.FromGin(c), "admin direct upload presign failed", err, "objectKey", objectKey)
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "storage unavailable"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"objectKey":   objectKey,
+		"contentType": contentType,
+		"maxBytes":    h.maxDirectBytes,
+		"expiresAt":   now.Add(h.directTTL),
+		"put": gin.H{
+			"url":     putURL,
+			"headers": gin.H{"Content-Type": contentType},
+		},
+		"post": gin.H{
+			"url":    postURL,
+			"fields": fields,
+		},
+	})
+}
+
+// FinalizeDirectUpload verifies an object uploaded through a slot and registers it in the
+// media library. Objects with a wrong size or content type are deleted and answer 422; images
+// whose header cannot be read answer 422 too but are kept: the media collector picks them up
+// like any unfinalized object and removes them after ASSET_GC_GRACE. Finalizing a registered object returns its asset again.
+//
+// Body: {"objectKey":"products/..."}
+// Route: POST /api/v1/admin/uploads/direct/finalize
+func (h *UploadsHandler) FinalizeDirectUpload(c *gin.Context) {
+	if !h.storageReady(c) {
+		return
+	}
+	if h.db == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "service unavailable"})
+		return
+	}
+
+	var req directUploadFinalizeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	objectKey := strings.TrimSpace(req.ObjectKey)
+	styleNo, kind, contentType, ok := parseDirectUploadKey(objectKey)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid objectKey"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	var existing model.Asset
+	err := h.db.WithContext(ctx).Where("object_key = ?", objectKey).First(&existing).Error
+	if err == nil {
+		c.JSON(http.StatusOK, directUploadResponse(existing))
+		return
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		logging.ErrorWithStack(logging.FromGin(c), "admin direct upload asset lookup failed", err, "objectKey", objectKey)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return
+	}
+
+	stat, err := h.minioClient.StatObject(ctx, h.minioCfg.Bucket, objectKey, minio.StatObjectOptions{})
+	if err != nil {
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "upload not found"})
+			return
+		}
+		logging.ErrorWithStack(logging.FromGin(c), "admin direct upload stat failed", err, "objectKey", objectKey)
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "storage unavailable"})
+		return
+	}
+
+	// Rejected objects are removed: nothing can reference them yet.
+	reject := func(body gin.H) {
+		if err := storage.RemoveObject(ctx, h.minioClient, h.minioCfg, objectKey); err != nil {
+			logging.FromGin(c).Warn("admin direct upload remove rejected object failed", "objectKey", objectKey, "err", err)
+		}
+		c.JSON(http.StatusUnprocessableEntity, body)
+	}
+
+	if stat.Size <= 0 || stat.Size > h.maxDirectBytes {
+		reject(gin.H{"error": "invalid size", "size": stat.Size, "maxBytes": h.maxDirectBytes})
+		return
+	}
+	storedType, _, _ := mime.ParseMediaType(stat.ContentType)
+	if storedType != contentType {
+		reject(gin.H{"error": "content type mismatch", "contentType": stat.ContentType, "expected": contentType})
+		return
+	}
+
+	asset := model.Asset{
+		ObjectKey:   objectKey,
+		Base:        imaging.VariantBase(objectKey),
+		Kind:        kind,
+		StyleNo:     styleNo,
+		ContentType: contentType,
+		Size:        stat.Size,
+	}
+	if strings.HasPrefix(contentType, "image/") {
+		width, height, err := h.directUploadImageSize(c, objectKey, contentType, stat.Size)
+		if errors.Is(err, imaging.ErrUnsupportedFormat) || errors.Is(err, imaging.ErrInvalidHeader) {
+			// Not deleted: a parser gap must not destroy a valid upload.
+			logging.FromGin(c).Warn("admin direct upload image check failed", "objectKey", objectKey, "err", err)
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "not a valid image", "contentType": contentType})
+			return
+		}
+		if err != nil {
+			logging.ErrorWithStack(logging.FromGin(c), "admin direct upload read failed", err, "objectKey", objectKey)
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "storage unavailable"})
+			return
+		}
+		asset.Width, asset.Height = width, height
+	}
+	if user, ok := middleware.CurrentUser(c); ok {
+		asset.UploadedBy = &user.ID
+	}
+
+	if err := h.db.WithContext(ctx).Create(&asset).Error; err != nil {
+		// A concurrent finalize of the same key may have won.
+		if h.db.WithContext(ctx).Where("object_key = ?", objectKey).First(&existing).Error == nil {
+			c.JSON(http.StatusOK, directUploadResponse(existing))
+			return
+		}
+		logging.ErrorWithStack(logging.FromGin(c), "admin direct upload register asset failed", err, "objectKey", objectKey)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "upload failed"})
+		return
+	}
+	c.JSON(http.StatusCreated, directUploadResponse(asset))
+}
+
+// directUploadImageSize checks that the stored object really is an image of contentType and
+// returns its dimensions. The header is read with ranged requests (ReadAt), up to the JPEG
+// frame header however much metadata precedes it.
+func (h *UploadsHandler) directUploadImageSize(c *gin.Context, objectKey, contentType string, size int64) (int, int, error) {
+	obj, err := h.minioClient.GetObject(c.Request.Context(), h.minioCfg.Bucket, objectKey, minio.GetObjectOptions{})
+	if err != nil {
+		return 0, 0, err
+	}
+	defer obj.Close()
+	width, height, format, err := imaging.DecodeConfigAt(obj, size)
+	if err != nil {
+		return 0, 0, err
+	}
+	if "image/"+format != contentType {
+		return 0, 0, imaging.ErrUnsupportedFormat
+	}
+	return width, height, nil
+}
+
+func directUploadResponse(a model.Asset) gin.H {
+	return gin.H{
+		"assetId":     a.ID,
+		"url":         "/api/v1/assets/" + a.ObjectKey,
+		"objectKey":   a.ObjectKey,
+		"contentType": a.ContentType,
+		"size":        a.Size,
+		"width":       a.Width,
+		"height":      a.Height,
+	}
+}
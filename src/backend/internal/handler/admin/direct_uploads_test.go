@@ -0,0 +1,41 @@
+package admin
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDirectUploadKey_RoundTrip(t *testing.T) {
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	for _, tc := range []struct{ kind, contentType string }{
+		{"video", "video/mp4"},
+		{"video", "video/quicktime"},
+		{"gallery", "image/webp"},
+		{"cover", "image/jpeg"},
+	} {
+		key := directUploadKey("AB-001", tc.kind, tc.contentType, now)
+		styleNo, kind, contentType, ok := parseDirectUploadKey(key)
+		if !ok || styleNo != "AB-001" || kind != tc.kind || contentType != tc.contentType {
+			t.Fatalf("%s: got %q %q %q ok=%v", key, styleNo, kind, contentType, ok)
+		}
+	}
+}
+
+func TestParseDirectUploadKey_Rejects(t *testing.T) {
+	for _, key := range []string{
+		// Renditions belong to UploadImage.
+		"products/AB-001/cover/2026/03/01/4b0c1c3e-8f5e-4d8c-9f59-3f1c2d9b7a10/w1600.jpg",
+		// A video extension under an image kind and the reverse.
+		"products/AB-001/cover/2026/03/01/4b0c1c3e-8f5e-4d8c-9f59-3f1c2d9b7a10.mp4",
+		"products/AB-001/video/2026/03/01/4b0c1c3e-8f5e-4d8c-9f59-3f1c2d9b7a10.jpg",
+		// Unknown extension, unnormalized style number, other prefixes.
+		"products/AB-001/gallery/2026/03/01/4b0c1c3e-8f5e-4d8c-9f59-3f1c2d9b7a10.gif",
+		"products/ab-001/gallery/2026/03/01/4b0c1c3e-8f5e-4d8c-9f59-3f1c2d9b7a10.png",
+		"updates/AB-001/gallery/2026/03/01/4b0c1c3e-8f5e-4d8c-9f59-3f1c2d9b7a10.png",
+		"products/AB-001/gallery/2026/03/01/../../4b0c1c3e-8f5e-4d8c-9f59-3f1c2d9b7a10.png",
+	} {
+		if _, _, _, ok := parseDirectUploadKey(key); ok {
+			t.Fatalf("expected %s to be rejected", key)
+		}
+	}
+}
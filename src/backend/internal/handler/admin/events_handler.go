@@ -9,9 +9,11 @@ import (
 	"strings"
 	"time"
 
+	"evening-gown/internal/audit"
 	"evening-gown/internal/cache"
 	"evening-gown/internal/logging"
 	"evening-gown/internal/model"
+	"evening-gown/internal/pagination"
 
 	"github.com/gin-gonic/gin"
 	"github.com/redis/go-redis/v9"
@@ -159,6 +161,7 @@ func (h *EventsHandler) Metrics(c *gin.Context) {
 
 func (h *EventsHandler) computeMetrics(ctx context.Context, fromUTC, toUTC time.Time, loc *time.Location, tzName, eventType string, productID uint) (eventsMetricsResponse, error) {
 	q := h.db.WithContext(ctx).Model(&model.Event{}).
+		Where("deleted_at IS NULL").
 		Where("occurred_at >= ?", fromUTC).
 		Where("occurred_at < ?", toUTC)
 	if strings.TrimSpace(eventType) != "" {
@@ -261,7 +264,8 @@ func (h *EventsHandler) List(c *gin.Context) {
 		return
 	}
 
-	q := h.db.WithContext(c.Request.Context()).Model(&model.Event{})
+	q := h.db.WithContext(c.Request.Context()).Model(&model.Event{}).
+		Where("deleted_at IS NULL")
 
 	if et := strings.TrimSpace(c.Query("event_type")); et != "" {
 		q = q.Where("event_type = ?", et)
@@ -283,33 +287,7 @@ func (h *EventsHandler) List(c *gin.Context) {
 		}
 	}
 
-	limit := parseIntQuery(c, "limit", 100)
-	offset := parseIntQuery(c, "offset", 0)
-	if limit <= 0 {
-		limit = 100
-	}
-	if limit > 500 {
-		limit = 500
-	}
-	if offset < 0 {
-		offset = 0
-	}
-
-	var total int64
-	if err := q.Count(&total).Error; err != nil {
-		logging.ErrorWithStack(logging.FromGin(c), "admin events query count failed", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
-		return
-	}
-
-	var items []model.Event
-	if err := q.Order("occurred_at desc, id desc").Limit(limit).Offset(offset).Find(&items).Error; err != nil {
-		logging.ErrorWithStack(logging.FromGin(c), "admin events query list failed", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
-		return
-	}
-
-	c.JSON(http.StatusOK, gin.H{"total": total, "items": items})
+	listSorted(c, q, adminEventSorts, pagination.ParseParams(c.Request.URL.Query(), 100, 500), "events")
 }
 
 func (h *EventsHandler) Get(c *gin.Context) {
@@ -325,7 +303,9 @@ func (h *EventsHandler) Get(c *gin.Context) {
 	}
 
 	var e model.Event
-	if err := h.db.WithContext(c.Request.Context()).First(&e, uint(id)).Error; err != nil {
+	if err := h.db.WithContext(c.Request.Context()).
+		Where("deleted_at IS NULL").
+		First(&e, uint(id)).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
 		return
 	}
@@ -345,7 +325,12 @@ func (h *EventsHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	res := h.db.WithContext(c.Request.Context()).Delete(&model.Event{}, uint(id))
+	// Soft delete: the event stays in the trash until restored or purged.
+	before := reloadForAudit[model.Event](c.Request.Context(), h.db, uint(id))
+	now := time.Now().UTC()
+	res := h.db.WithContext(c.Request.Context()).Model(&model.Event{}).
+		Where("id = ? AND deleted_at IS NULL", uint(id)).
+		Update("deleted_at", &now)
 	if res.Error != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": res.Error.Error()})
 		return
@@ -354,6 +339,7 @@ func (h *EventsHandler) Delete(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
 		return
 	}
+	recordAudit(c, h.db, audit.Entry{Action: "event.delete", EntityType: "events", EntityID: auditID(uint(id)), Before: before})
 
 	c.Status(http.StatusNoContent)
 }
@@ -0,0 +1,111 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+
+	"evening-gown/internal/logging"
+	"evening-gown/internal/model"
+	"evening-gown/internal/pagination"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// listSorted answers a list request ordered by the ?sort= whitelist:
+// {"total" (offset mode only), "items", "next_cursor" (when there are more rows)}.
+func listSorted[T any](c *gin.Context, q *gorm.DB, sorts pagination.Sorts[T], page pagination.Params, what string) {
+	items, next, total, ok := fetchSorted(c, q, sorts, page, what)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, pagination.Response(items, next, total))
+}
+
+// fetchSorted loads one page for listSorted, for handlers that decorate the rows before
+// answering. On failure it has already answered and returns ok=false.
+func fetchSorted[T any](c *gin.Context, q *gorm.DB, sorts pagination.Sorts[T], page pagination.Params, what string) (items []T, next string, total *int64, ok bool) {
+	sort, found := sorts.Lookup(c.Query("sort"))
+	if !found {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid sort", "sorts": sorts.Names()})
+		return nil, "", nil, false
+	}
+
+	if !page.CursorMode {
+		var n int64
+		if err := q.Count(&n).Error; err != nil {
+			logging.ErrorWithStack(logging.FromGin(c), "admin "+what+" query count failed", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+			return nil, "", nil, false
+		}
+		total = &n
+	}
+
+	listQ, err := sort.After(q, page.Cursor)
+	if errors.Is(err, pagination.ErrInvalidCursor) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, "", nil, false
+	}
+	if err := sort.Order(listQ).Limit(page.Limit + 1).Offset(page.Offset).Find(&items).Error; err != nil {
+		logging.ErrorWithStack(logging.FromGin(c), "admin "+what+" query list failed", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return nil, "", nil, false
+	}
+
+	items, next = sort.Page(items, page.Limit)
+	if items == nil {
+		items = []T{}
+	}
+	return items, next, total, true
+}
+
+// Sort whitelists of the admin list endpoints.
+var (
+	adminProductSorts = pagination.Sorts[model.Product]{
+		{Name: "default", Keys: []pagination.Key{{Column: "is_new", Desc: true}, {Column: "new_rank", Desc: true}, {Column: "id", Desc: true}},
+			Values: func(p model.Product) []any { return []any{p.IsNew, p.NewRank, p.ID} }},
+		{Name: "newest", Keys: []pagination.Key{{Column: "created_at", Desc: true}, {Column: "id", Desc: true}},
+			Values: func(p model.Product) []any { return []any{p.CreatedAt, p.ID} }},
+		{Name: "oldest", Keys: []pagination.Key{{Column: "created_at"}, {Column: "id"}},
+			Values: func(p model.Product) []any { return []any{p.CreatedAt, p.ID} }},
+		{Name: "updated", Keys: []pagination.Key{{Column: "updated_at", Desc: true}, {Column: "id", Desc: true}},
+			Values: func(p model.Product) []any { return []any{p.UpdatedAt, p.ID} }},
+		{Name: "style_no", Keys: []pagination.Key{{Column: "style_no"}, {Column: "id"}},
+			Values: func(p model.Product) []any { return []any{p.StyleNo, p.ID} }},
+	}
+
+	// Drafts have no published_at; they sort by creation time among published posts.
+	adminUpdateSorts = pagination.Sorts[model.UpdatePost]{
+		{Name: "default", Keys: []pagination.Key{{Column: "pinned_rank", Desc: true}, {Column: "COALESCE(published_at, created_at)", Desc: true}, {Column: "id", Desc: true}},
+			Values: func(u model.UpdatePost) []any { return []any{u.PinnedRank, u.SortTime(), u.ID} }},
+		{Name: "newest", Keys: []pagination.Key{{Column: "created_at", Desc: true}, {Column: "id", Desc: true}},
+			Values: func(u model.UpdatePost) []any { return []any{u.CreatedAt, u.ID} }},
+		{Name: "updated", Keys: []pagination.Key{{Column: "updated_at", Desc: true}, {Column: "id", Desc: true}},
+			Values: func(u model.UpdatePost) []any { return []any{u.UpdatedAt, u.ID} }},
+	}
+
+	adminContactSorts = pagination.Sorts[model.ContactLead]{
+		{Name: "newest", Keys: []pagination.Key{{Column: "id", Desc: true}},
+			Values: func(l model.ContactLead) []any { return []any{l.ID} }},
+		{Name: "oldest", Keys: []pagination.Key{{Column: "id"}},
+			Values: func(l model.ContactLead) []any { return []any{l.ID} }},
+		{Name: "updated", Keys: []pagination.Key{{Column: "updated_at", Desc: true}, {Column: "id", Desc: true}},
+			Values: func(l model.ContactLead) []any { return []any{l.UpdatedAt, l.ID} }},
+	}
+
+	adminEventSorts = pagination.Sorts[model.Event]{
+		{Name: "newest", Keys: []pagination.Key{{Column: "occurred_at", Desc: true}, {Column: "id", Desc: true}},
+			Values: func(e model.Event) []any { return []any{e.OccurredAt, e.ID} }},
+		{Name: "oldest", Keys: []pagination.Key{{Column: "occurred_at"}, {Column: "id"}},
+			Values: func(e model.Event) []any { return []any{e.OccurredAt, e.ID} }},
+	}
+
+	adminAssetSorts = pagination.Sorts[model.Asset]{
+		{Name: "newest", Keys: []pagination.Key{{Column: "created_at", Desc: true}, {Column: "id", Desc: true}},
+			Values: func(a model.Asset) []any { return []any{a.CreatedAt, a.ID} }},
+		{Name: "oldest", Keys: []pagination.Key{{Column: "created_at"}, {Column: "id"}},
+			Values: func(a model.Asset) []any { return []any{a.CreatedAt, a.ID} }},
+		{Name: "size", Keys: []pagination.Key{{Column: "size", Desc: true}, {Column: "id", Desc: true}},
+			Values: func(a model.Asset) []any { return []any{a.Size, a.ID} }},
+	}
+)
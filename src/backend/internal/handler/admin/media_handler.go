@@ -0,0 +1,79 @@
+package admin
+
+import (
+	"net/http"
+	"strings"
+
+	"evening-gown/internal/logging"
+	"evening-gown/internal/media"
+	"evening-gown/internal/model"
+	"evening-gown/internal/pagination"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// MediaHandler lists uploaded images (the media library).
+type MediaHandler struct {
+	db *gorm.DB
+}
+
+func NewMediaHandler(db *gorm.DB) *MediaHandler {
+	return &MediaHandler{db: db}
+}
+
+type mediaItem struct {
+	model.Asset
+	URL string `json:"url"`
+	// References counts the products (deleted ones included), drafts and revisions using the image.
+	References int64 `json:"references"`
+}
+
+// List returns uploaded images with their reference counts.
+// Query: ?styleNo=&kind=cover|hover|gallery|video&sort=newest|oldest|size&limit=&offset= (or &cursor=)
+// Route: GET /api/v1/admin/media
+func (h *MediaHandler) List(c *gin.Context) {
+	if h == nil || h.db == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "service unavailable"})
+		return
+	}
+
+	q := h.db.WithContext(c.Request.Context()).Model(&model.Asset{})
+	if raw := strings.TrimSpace(c.Query("styleNo")); raw != "" {
+		styleNo, err := model.NormalizeStyleNo(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid styleNo"})
+			return
+		}
+		q = q.Where("style_no = ?", styleNo)
+	}
+	if kind := strings.TrimSpace(c.Query("kind")); kind != "" {
+		if kind != "cover" && kind != "hover" && kind != "gallery" && kind != "video" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid kind"})
+			return
+		}
+		q = q.Where("kind = ?", kind)
+	}
+
+	assets, next, total, ok := fetchSorted(c, q, adminAssetSorts, pagination.ParseParams(c.Request.URL.Query(), 50, 200), "media")
+	if !ok {
+		return
+	}
+
+	keys := make([]string, len(assets))
+	for i, a := range assets {
+		keys[i] = a.ObjectKey
+	}
+	counts, err := media.ReferenceCounts(h.db.WithContext(c.Request.Context()), keys)
+	if err != nil {
+		logging.ErrorWithStack(logging.FromGin(c), "admin media query references failed", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return
+	}
+
+	items := make([]mediaItem, len(assets))
+	for i, a := range assets {
+		items[i] = mediaItem{Asset: a, URL: "/api/v1/assets/" + a.ObjectKey, References: counts[a.ObjectKey]}
+	}
+	c.JSON(http.StatusOK, pagination.Response(items, next, total))
+}
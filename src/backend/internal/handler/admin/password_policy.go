@@ -0,0 +1,97 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"evening-gown/internal/model"
+	"evening-gown/internal/security"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// passwordPolicyOrDefault lets handlers built with zero-value options keep sane defaults.
+func passwordPolicyOrDefault(p security.PasswordPolicy) security.PasswordPolicy {
+	if p == (security.PasswordPolicy{}) {
+		return security.DefaultPasswordPolicy()
+	}
+	return p
+}
+
+// respondPasswordRejected writes the 400 for policy and history failures.
+func respondPasswordRejected(c *gin.Context, err error) {
+	var perr *security.PasswordPolicyError
+	if errors.As(err, &perr) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "weak_password",
+			"message": "password does not meet the policy",
+			"error":   err.Error(),
+			"reasons": perr.Reasons,
+		})
+		return
+	}
+	if errors.Is(err, security.ErrPasswordReused) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "password_reused",
+			"message": "password was used recently",
+			"error":   err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+}
+
+// checkPasswordHistory returns security.ErrPasswordReused when password matches the current hash
+// or one of the last HistorySize-1 previous hashes.
+func checkPasswordHistory(ctx context.Context, db *gorm.DB, policy security.PasswordPolicy, user model.User, password string) error {
+	if policy.HistorySize <= 0 {
+		return nil
+	}
+	if security.CheckPassword(user.PasswordHash, password) {
+		return security.ErrPasswordReused
+	}
+	if policy.HistorySize == 1 {
+		return nil
+	}
+
+	var previous []model.PasswordHistory
+	if err := db.WithContext(ctx).
+		Where("user_id = ?", user.ID).
+		Order("id desc").
+		Limit(policy.HistorySize - 1).
+		Find(&previous).Error; err != nil {
+		return err
+	}
+	for _, ph := range previous {
+		if security.CheckPassword(ph.PasswordHash, password) {
+			return security.ErrPasswordReused
+		}
+	}
+	return nil
+}
+
+// recordPasswordHistory stores the hash being replaced and prunes entries beyond the history size.
+// Must run in the same transaction as the password update.
+func recordPasswordHistory(tx *gorm.DB, policy security.PasswordPolicy, userID uint, oldHash string) error {
+	if policy.HistorySize <= 1 {
+		return tx.Where("user_id = ?", userID).Delete(&model.PasswordHistory{}).Error
+	}
+	if oldHash == "" {
+		return nil
+	}
+	if err := tx.Create(&model.PasswordHistory{UserID: userID, PasswordHash: oldHash}).Error; err != nil {
+		return err
+	}
+
+	var keep []uint
+	if err := tx.Model(&model.PasswordHistory{}).
+		Where("user_id = ?", userID).
+		Order("id desc").
+		Limit(policy.HistorySize-1).
+		Pluck("id", &keep).Error; err != nil {
+		return err
+	}
+	return tx.Where("user_id = ? AND id NOT IN ?", userID, keep).Delete(&model.PasswordHistory{}).Error
+}
@@ -0,0 +1,304 @@
+package admin
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"evening-gown/internal/logging"
+	"evening-gown/internal/mail"
+	"evening-gown/internal/model"
+	"evening-gown/internal/security"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultPasswordResetTTL = 30 * time.Minute
+	// passwordResetSendTimeout bounds background delivery of a reset email.
+	passwordResetSendTimeout = 30 * time.Second
+)
+
+var errResetTokenInvalid = errors.New("reset token invalid")
+
+// PasswordResetHandler implements "forgot password" via single-use emailed tokens.
+//
+// Routes are unauthenticated; the login rate limiter should be applied in the router.
+type PasswordResetHandler struct {
+	db        *gorm.DB
+	mailer    mail.Mailer
+	resetURL  string
+	ttl       time.Duration
+	passwords security.PasswordPolicy
+}
+
+// PasswordResetOptions configures the reset link sent by email.
+type PasswordResetOptions struct {
+	// ResetURL is the frontend page; the token is appended as ?token=...
+	ResetURL string
+	TTL      time.Duration
+	// PasswordPolicy applies to the new password (zero value: defaults).
+	PasswordPolicy security.PasswordPolicy
+}
+
+func NewPasswordResetHandler(db *gorm.DB, mailer mail.Mailer, opts PasswordResetOptions) *PasswordResetHandler {
+	ttl := opts.TTL
+	if ttl <= 0 {
+		ttl = defaultPasswordResetTTL
+	}
+	return &PasswordResetHandler{
+		db:        db,
+		mailer:    mailer,
+		resetURL:  strings.TrimSpace(opts.ResetURL),
+		ttl:       ttl,
+		passwords: passwordPolicyOrDefault(opts.PasswordPolicy),
+	}
+}
+
+type forgotPasswordRequest struct {
+	Email string `json:"email" binding:"required"`
+}
+
+type resetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"newPassword" binding:"required"`
+}
+
+// Forgot emails a reset link when the account exists.
+// The response is identical either way so the endpoint cannot be used to enumerate accounts;
+// the email is sent in the background so SMTP latency does not reveal it either.
+// Route: POST /api/v1/admin/auth/forgot
+func (h *PasswordResetHandler) Forgot(c *gin.Context) {
+	if h == nil || h.db == nil || h.mailer == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"code":    "service_unavailable",
+			"message": "service unavailable",
+			"error":   "service unavailable",
+		})
+		return
+	}
+
+	var req forgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ok := gin.H{"ok": true}
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	if email == "" {
+		c.JSON(http.StatusOK, ok)
+		return
+	}
+
+	ctx := c.Request.Context()
+	var user model.User
+	if err := h.db.WithContext(ctx).
+		Where("email = ? AND deleted_at IS NULL AND status IN ?", email, []string{"active", "locked"}).
+		First(&user).Error; err != nil {
+		c.JSON(http.StatusOK, ok)
+		return
+	}
+
+	token, hash, err := newResetToken()
+	if err != nil {
+		logging.ErrorWithStack(logging.FromGin(c), "admin password reset generate token failed", err, "user_id", user.ID)
+		c.JSON(http.StatusOK, ok)
+		return
+	}
+
+	now := time.Now().UTC()
+	err = h.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Only the latest link is valid.
+		if err := tx.Where("user_id = ? AND used_at IS NULL", user.ID).Delete(&model.PasswordResetToken{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&model.PasswordResetToken{
+			UserID:    user.ID,
+			TokenHash: hash,
+			ExpiresAt: now.Add(h.ttl),
+			RequestIP: c.ClientIP(),
+		}).Error
+	})
+	if err != nil {
+		logging.ErrorWithStack(logging.FromGin(c), "admin password reset save token failed", err, "user_id", user.ID)
+		c.JSON(http.StatusOK, ok)
+		return
+	}
+
+	msg := mail.Message{
+		To:      []string{user.Email},
+		Subject: "Reset your password",
+		Text: fmt.Sprintf("A password reset was requested for your account.\n\n"+
+			"Open the link below within %s to choose a new password:\n\n%s\n\n"+
+			"If you did not request this, you can ignore this email.\n",
+			h.ttl, h.resetLink(token)),
+	}
+	logger := logging.FromGin(c)
+	go func() {
+		sendCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), passwordResetSendTimeout)
+		defer cancel()
+		if err := h.mailer.Send(sendCtx, msg); err != nil {
+			logging.ErrorWithStack(logger, "admin password reset send mail failed", err, "user_id", user.ID)
+		}
+	}()
+
+	c.JSON(http.StatusOK, ok)
+}
+
+// Reset sets a new password with a valid reset token.
+//
+// It bumps PasswordUpdatedAt (invalidating all issued tokens), revokes sessions and clears lockouts.
+// Route: POST /api/v1/admin/auth/reset
+func (h *PasswordResetHandler) Reset(c *gin.Context) {
+	if h == nil || h.db == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"code":    "service_unavailable",
+			"message": "service unavailable",
+			"error":   "service unavailable",
+		})
+		return
+	}
+
+	var req resetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	newPassword := strings.TrimSpace(req.NewPassword)
+	if newPassword == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	tokenHash := hashResetToken(strings.TrimSpace(req.Token))
+	now := time.Now().UTC()
+
+	// Policy failures roll back the transaction, so the token stays usable for another attempt.
+	err := h.db.WithContext(c.Request.Context()).Transaction(func(tx *gorm.DB) error {
+		var rt model.PasswordResetToken
+		if err := tx.Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", tokenHash, now).First(&rt).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errResetTokenInvalid
+			}
+			return err
+		}
+
+		// Conditional update: concurrent requests with the same token cannot both succeed.
+		res := tx.Model(&model.PasswordResetToken{}).Where("id = ? AND used_at IS NULL", rt.ID).Update("used_at", now)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return errResetTokenInvalid
+		}
+
+		var user model.User
+		if err := tx.Where("id = ? AND deleted_at IS NULL", rt.UserID).First(&user).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errResetTokenInvalid
+			}
+			return err
+		}
+
+		if err := h.passwords.Validate(newPassword, user.Email); err != nil {
+			return err
+		}
+		if err := checkPasswordHistory(tx.Statement.Context, tx, h.passwords, user, newPassword); err != nil {
+			return err
+		}
+		hash, err := h.passwords.Hash(newPassword)
+		if err != nil {
+			return err
+		}
+		if err := recordPasswordHistory(tx, h.passwords, user.ID, user.PasswordHash); err != nil {
+			return err
+		}
+
+		pwdAt := nextPasswordUpdatedAt(user.PasswordUpdatedAt)
+		updates := map[string]any{
+			"password_hash":       hash,
+			"password_updated_at": &pwdAt,
+			"failed_login_count":  0,
+			"locked_until":        nil,
+			"updated_at":          now,
+		}
+		if user.Status == "locked" {
+			updates["status"] = "active"
+		}
+		if err := tx.Model(&model.User{}).Where("id = ?", user.ID).Updates(updates).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&model.Session{}).
+			Where("user_id = ? AND revoked_at IS NULL", user.ID).
+			Updates(map[string]any{
+				"revoked_at":     now,
+				"revoked_reason": model.SessionRevokedPasswordReset,
+				"updated_at":     now,
+			}).Error
+	})
+	if errors.Is(err, errResetTokenInvalid) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "invalid_reset_token",
+			"message": "invalid or expired reset token",
+			"error":   "invalid or expired reset token",
+		})
+		return
+	}
+	if errors.Is(err, security.ErrWeakPassword) || errors.Is(err, security.ErrPasswordReused) {
+		respondPasswordRejected(c, err)
+		return
+	}
+	if err != nil {
+		logging.ErrorWithStack(logging.FromGin(c), "admin password reset failed", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    "reset_password_failed",
+			"message": "reset password failed",
+			"error":   "reset password failed",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
+
+func (h *PasswordResetHandler) resetLink(token string) string {
+	if h.resetURL == "" {
+		return token
+	}
+	u, err := url.Parse(h.resetURL)
+	if err != nil {
+		return h.resetURL + "?token=" + url.QueryEscape(token)
+	}
+	q := u.Query()
+	q.Set("token", token)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// newResetToken returns a 256-bit URL-safe token and its storage hash.
+func newResetToken() (string, string, error) {
+	var b [32]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(b[:])
+	return token, hashResetToken(token), nil
+}
+
+// hashResetToken uses SHA-256 (not bcrypt): tokens are high-entropy and must be looked up by hash.
+func hashResetToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
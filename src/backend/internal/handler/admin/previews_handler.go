@@ -0,0 +1,137 @@
+package admin
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"evening-gown/internal/audit"
+	"evening-gown/internal/logging"
+	"evening-gown/internal/model"
+	"evening-gown/internal/security"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultPreviewTTL    = 72 * time.Hour
+	defaultPreviewMaxTTL = 30 * 24 * time.Hour
+)
+
+// PreviewsHandler mints signed preview links so unpublished items can be shared with clients.
+//
+// Tokens are stateless (see security.PreviewSigner) and honored by the public product, update
+// and asset endpoints via ?preview=<token>.
+type PreviewsHandler struct {
+	db     *gorm.DB
+	signer *security.PreviewSigner
+	ttl    time.Duration
+	maxTTL time.Duration
+}
+
+// PreviewOptions bounds the validity of minted links.
+type PreviewOptions struct {
+	TTL    time.Duration
+	MaxTTL time.Duration
+}
+
+func NewPreviewsHandler(db *gorm.DB, signer *security.PreviewSigner, opts PreviewOptions) *PreviewsHandler {
+	maxTTL := opts.MaxTTL
+	if maxTTL <= 0 {
+		maxTTL = defaultPreviewMaxTTL
+	}
+	ttl := opts.TTL
+	if ttl <= 0 {
+		ttl = defaultPreviewTTL
+	}
+	if ttl > maxTTL {
+		ttl = maxTTL
+	}
+	return &PreviewsHandler{db: db, signer: signer, ttl: ttl, maxTTL: maxTTL}
+}
+
+type previewLinkRequest struct {
+	// TTL is a Go duration ("48h"); empty uses the configured default.
+	TTL string `json:"ttl"`
+}
+
+// Product mints a preview link for a product in any publication state.
+// Route: POST /api/v1/admin/products/:id/preview-link
+func (h *PreviewsHandler) Product(c *gin.Context) {
+	h.mint(c, security.PreviewProduct, &model.Product{}, "/api/v1/products/")
+}
+
+// Update mints a preview link for an update post in any status.
+// Route: POST /api/v1/admin/updates/:id/preview-link
+func (h *PreviewsHandler) Update(c *gin.Context) {
+	h.mint(c, security.PreviewUpdate, &model.UpdatePost{}, "/api/v1/updates/")
+}
+
+func (h *PreviewsHandler) mint(c *gin.Context, kind string, row any, publicPrefix string) {
+	if h == nil || h.db == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "service unavailable"})
+		return
+	}
+	if h.signer == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "preview links disabled"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req previewLinkRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	ttl := h.ttl
+	if raw := strings.TrimSpace(req.TTL); raw != "" {
+		d, err := time.ParseDuration(raw)
+		if err != nil || d <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ttl"})
+			return
+		}
+		if d > h.maxTTL {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "ttl exceeds maximum", "maxTtl": h.maxTTL.String()})
+			return
+		}
+		ttl = d
+	}
+
+	ctx := c.Request.Context()
+	if err := h.db.WithContext(ctx).
+		Where("deleted_at IS NULL").
+		First(row, uint(id)).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+		return
+	}
+
+	expiresAt := time.Now().UTC().Add(ttl).Truncate(time.Second)
+	token, err := h.signer.Sign(kind, uint(id), expiresAt)
+	if err != nil {
+		logging.ErrorWithStack(logging.FromGin(c), "admin preview sign failed", err, "kind", kind, "id", id)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "sign failed"})
+		return
+	}
+	recordAudit(c, h.db, audit.Entry{
+		Action:     kind + ".preview_link",
+		EntityType: kind + "s",
+		EntityID:   auditID(uint(id)),
+		After:      gin.H{"expiresAt": expiresAt},
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"token":     token,
+		"expiresAt": expiresAt,
+		"path":      publicPrefix + strconv.FormatUint(id, 10) + "?preview=" + url.QueryEscape(token),
+	})
+}
@@ -0,0 +1,303 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"evening-gown/internal/audit"
+	"evening-gown/internal/catalog"
+	"evening-gown/internal/logging"
+	"evening-gown/internal/model"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var errProductTaken = errors.New("slug or styleNo already in use")
+
+// Edits to a published product (or one that already has a pending draft) are written to
+// product_drafts instead of the live row; see ProductsHandler.Update. The public API keeps
+// serving the live row until PublishChanges (or Publish, after an unpublish) promotes the draft
+// in one transaction; see catalog.PromoteDraft.
+
+// saveDraft applies updates (Update's column map) to the product's working copy.
+// Responds 202 with the working copy: the change is stored but not live.
+func (h *ProductsHandler) saveDraft(c *gin.Context, live model.Product, updates map[string]any) {
+	ctx := c.Request.Context()
+
+	var before, working model.Product
+	err := h.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		before, working, err = stageProductDraft(ctx, tx, live, updates)
+		return err
+	})
+	if errors.Is(err, errProductTaken) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		logging.ErrorWithStack(logging.FromGin(c), "admin product draft save failed", err, "product_id", live.ID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "save draft failed"})
+		return
+	}
+	recordAudit(c, h.db, audit.Entry{
+		Action:     "product.update_draft",
+		EntityType: "products",
+		EntityID:   auditID(live.ID),
+		Before:     before,
+		After:      working,
+	})
+
+	c.JSON(http.StatusAccepted, working)
+}
+
+// stageProductDraft writes live+updates to the product's draft and returns the working copy
+// before and after the change. db is usually a transaction.
+func stageProductDraft(ctx context.Context, db *gorm.DB, live model.Product, updates map[string]any) (before, working model.Product, err error) {
+	tx := db.WithContext(ctx)
+	var d model.ProductDraft
+	err = tx.Where("product_id = ?", live.ID).First(&d).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return before, working, err
+	}
+	if before, err = draftWorkingCopy(live, &d); err != nil {
+		return before, working, err
+	}
+	working = before
+	applyProductColumns(&working, updates)
+
+	// Unique columns are only enforced on the live table, so check them now rather than
+	// letting the draft fail when it is published.
+	if working.Slug != live.Slug || working.StyleNo != live.StyleNo {
+		var taken int64
+		if err := tx.Model(&model.Product{}).
+			Where("id <> ?", live.ID).
+			Where("slug = ? OR style_no = ?", working.Slug, working.StyleNo).
+			Count(&taken).Error; err != nil {
+			return before, working, err
+		}
+		if taken > 0 {
+			return before, working, errProductTaken
+		}
+	}
+
+	snapshot, err := json.Marshal(working)
+	if err != nil {
+		return before, working, err
+	}
+	d.ProductID = live.ID
+	d.Snapshot = snapshot
+	d.ActorID, d.ActorEmail = nil, ""
+	if actor, ok := audit.ActorFrom(ctx); ok {
+		if actor.UserID != 0 {
+			id := actor.UserID
+			d.ActorID = &id
+		}
+		d.ActorEmail = actor.Email
+	}
+	return before, working, tx.Save(&d).Error
+}
+
+// GetDraft previews the pending working copy and what publishing it would change.
+// Route: GET /api/v1/admin/products/:id/draft
+func (h *ProductsHandler) GetDraft(c *gin.Context) {
+	if h == nil || h.db == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "service unavailable"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	live, d, err := loadProductDraft(c.Request.Context(), h.db, uint(id))
+	if errors.Is(err, errProductGone) || errors.Is(err, catalog.ErrNoDraft) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		logging.ErrorWithStack(logging.FromGin(c), "admin product draft load failed", err, "product_id", id)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return
+	}
+	working, err := draftWorkingCopy(live, &d)
+	if err != nil {
+		logging.ErrorWithStack(logging.FromGin(c), "admin product draft decode failed", err, "product_id", id)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid draft"})
+		return
+	}
+	changes, err := diffProducts(live, working)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "invalid detail"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"draft":   d,
+		"product": working,
+		"changes": changes,
+	})
+}
+
+// PublishChanges promotes the pending draft to the live row atomically.
+// The live row is snapshotted into product_revisions first.
+// Route: POST /api/v1/admin/products/:id/publish-changes
+func (h *ProductsHandler) PublishChanges(c *gin.Context) {
+	if h == nil || h.db == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "service unavailable"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	var (
+		live          model.Product
+		assetsChanged bool
+	)
+	err = h.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var d model.ProductDraft
+		var err error
+		if live, d, err = loadProductDraft(ctx, tx, uint(id)); err != nil {
+			return err
+		}
+		assetsChanged, err = catalog.PromoteDraft(ctx, tx, live, d)
+		return err
+	})
+	if errors.Is(err, errProductGone) || errors.Is(err, catalog.ErrNoDraft) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		// Typically a slug/styleNo taken since the draft was saved.
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	recordAudit(c, h.db, audit.Entry{
+		Action:     "product.publish_changes",
+		EntityType: "products",
+		EntityID:   auditID(live.ID),
+		Before:     live,
+		After:      reloadForAudit[model.Product](ctx, h.db, live.ID),
+	})
+
+	if live.PublishedAt != nil && h.cache != nil {
+		_, _ = h.cache.BumpProductsVersion(ctx)
+		if assetsChanged {
+			_, _ = h.cache.BumpAssetsVersion(ctx)
+		}
+	}
+
+	h.Get(c)
+}
+
+// DiscardDraft drops the pending draft; the live row is untouched.
+// Route: DELETE /api/v1/admin/products/:id/draft
+func (h *ProductsHandler) DiscardDraft(c *gin.Context) {
+	if h == nil || h.db == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "service unavailable"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	live, d, err := loadProductDraft(ctx, h.db, uint(id))
+	if errors.Is(err, errProductGone) || errors.Is(err, catalog.ErrNoDraft) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		logging.ErrorWithStack(logging.FromGin(c), "admin product draft load failed", err, "product_id", id)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return
+	}
+	if err := h.db.WithContext(ctx).Delete(&model.ProductDraft{}, d.ID).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	working, _ := draftWorkingCopy(live, &d)
+	recordAudit(c, h.db, audit.Entry{
+		Action:     "product.discard_draft",
+		EntityType: "products",
+		EntityID:   auditID(live.ID),
+		Before:     working,
+		After:      live,
+	})
+
+	c.Status(http.StatusNoContent)
+}
+
+func loadProductDraft(ctx context.Context, db *gorm.DB, productID uint) (model.Product, model.ProductDraft, error) {
+	var live model.Product
+	var d model.ProductDraft
+	if err := db.WithContext(ctx).Where("deleted_at IS NULL").First(&live, productID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return live, d, errProductGone
+		}
+		return live, d, err
+	}
+	if err := db.WithContext(ctx).Where("product_id = ?", productID).First(&d).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return live, d, catalog.ErrNoDraft
+		}
+		return live, d, err
+	}
+	return live, d, nil
+}
+
+// draftWorkingCopy overlays the draft's content on the live row (see ProductDraft.WorkingCopy);
+// a nil or zero draft yields the live row itself.
+func draftWorkingCopy(live model.Product, d *model.ProductDraft) (model.Product, error) {
+	if d == nil {
+		return live, nil
+	}
+	return d.WorkingCopy(live)
+}
+
+// applyProductColumns mirrors a gorm column map (as built by Update) onto p.
+func applyProductColumns(p *model.Product, updates map[string]any) {
+	for col, v := range updates {
+		switch col {
+		case "slug":
+			p.Slug, _ = v.(string)
+		case "style_no":
+			p.StyleNo, _ = v.(string)
+		case "season":
+			p.Season, _ = v.(string)
+		case "category":
+			p.Category, _ = v.(string)
+		case "availability":
+			p.Availability, _ = v.(string)
+		case "is_new":
+			p.IsNew, _ = v.(bool)
+		case "new_rank":
+			p.NewRank, _ = v.(int)
+		case "cover_image_url":
+			p.CoverImageURL, _ = v.(string)
+		case "cover_image_key":
+			p.CoverImageKey, _ = v.(string)
+		case "hover_image_url":
+			p.HoverImageURL, _ = v.(string)
+		case "hover_image_key":
+			p.HoverImageKey, _ = v.(string)
+		case "price_mode":
+			p.PriceMode, _ = v.(string)
+		case "detail_json":
+			p.DetailJSON, _ = v.(json.RawMessage)
+		}
+	}
+}
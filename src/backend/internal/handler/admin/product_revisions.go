@@ -0,0 +1,277 @@
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"evening-gown/internal/audit"
+	"evening-gown/internal/catalog"
+	"evening-gown/internal/logging"
+	"evening-gown/internal/media"
+	"evening-gown/internal/model"
+	"evening-gown/internal/pagination"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ListRevisions returns the snapshots of a product, newest first (without the snapshot body).
+// Route: GET /api/v1/admin/products/:id/revisions
+func (h *ProductsHandler) ListRevisions(c *gin.Context) {
+	if h == nil || h.db == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "service unavailable"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	var p model.Product
+	if err := h.db.WithContext(ctx).
+		Where("deleted_at IS NULL").
+		First(&p, uint(id)).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+		return
+	}
+
+	page := pagination.ParseParams(c.Request.URL.Query(), 50, 200)
+
+	q := h.db.WithContext(ctx).Model(&model.ProductRevision{}).Where("product_id = ?", p.ID)
+
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		logging.ErrorWithStack(logging.FromGin(c), "admin product revisions count failed", err, "product_id", p.ID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return
+	}
+
+	var items []model.ProductRevision
+	if err := q.Omit("snapshot").Order("id desc").Limit(page.Limit).Offset(page.Offset).Find(&items).Error; err != nil {
+		logging.ErrorWithStack(logging.FromGin(c), "admin product revisions list failed", err, "product_id", p.ID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"total": total, "items": items})
+}
+
+// GetRevision returns one revision including its snapshot.
+// Route: GET /api/v1/admin/products/:id/revisions/:rev
+func (h *ProductsHandler) GetRevision(c *gin.Context) {
+	if h == nil || h.db == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "service unavailable"})
+		return
+	}
+
+	rev, ok := h.loadRevision(c, c.Param("rev"))
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, rev)
+}
+
+// DiffRevision shows what changed between a revision and the current product
+// (or another revision of the same product with ?against=<revId>).
+// "from" is always the revision in the path.
+// Route: GET /api/v1/admin/products/:id/revisions/:rev/diff
+func (h *ProductsHandler) DiffRevision(c *gin.Context) {
+	if h == nil || h.db == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "service unavailable"})
+		return
+	}
+
+	rev, ok := h.loadRevision(c, c.Param("rev"))
+	if !ok {
+		return
+	}
+	from, err := revisionProduct(rev)
+	if err != nil {
+		logging.ErrorWithStack(logging.FromGin(c), "admin product revision decode failed", err, "revision_id", rev.ID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid revision"})
+		return
+	}
+
+	against := "current"
+	var to model.Product
+	if raw := c.Query("against"); raw != "" && raw != against {
+		other, ok := h.loadRevision(c, raw)
+		if !ok {
+			return
+		}
+		if to, err = revisionProduct(other); err != nil {
+			logging.ErrorWithStack(logging.FromGin(c), "admin product revision decode failed", err, "revision_id", other.ID)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid revision"})
+			return
+		}
+		against = auditID(other.ID)
+	} else if err := h.db.WithContext(c.Request.Context()).
+		Where("deleted_at IS NULL").
+		First(&to, rev.ProductID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+		return
+	}
+
+	changes, err := diffProducts(from, to)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "invalid detail"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"revision": rev.ID,
+		"against":  against,
+		"fields":   changes.Fields,
+		"detail":   changes.Detail,
+	})
+}
+
+// productChanges is the editor-facing diff between two versions of a product.
+type productChanges struct {
+	// Fields is {"field": {"from": ..., "to": ...}} over model.ProductContent, or null when equal.
+	Fields json.RawMessage         `json:"fields"`
+	Detail model.ProductDetailDiff `json:"detail"`
+}
+
+func diffProducts(from, to model.Product) (productChanges, error) {
+	var out productChanges
+	fields, err := audit.Diff(model.ProductContentOf(from), model.ProductContentOf(to))
+	if err != nil {
+		return out, err
+	}
+	out.Fields = fields
+	out.Detail, err = model.DiffProductDetail(from.DetailJSON, to.DetailJSON)
+	return out, err
+}
+
+var errProductGone = errors.New("product not found")
+
+// RestoreRevision copies a revision's content back onto the product.
+// The current row is snapshotted first, so a restore can itself be undone.
+// Published products (and ones with a pending draft) get the content staged in their draft
+// instead, replacing pending edits, and answer 202 like Update; see product_drafts.go.
+// Route: POST /api/v1/admin/products/:id/revisions/:rev/restore
+func (h *ProductsHandler) RestoreRevision(c *gin.Context) {
+	if h == nil || h.db == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "service unavailable"})
+		return
+	}
+
+	rev, ok := h.loadRevision(c, c.Param("rev"))
+	if !ok {
+		return
+	}
+	snapshot, err := revisionProduct(rev)
+	if err != nil {
+		logging.ErrorWithStack(logging.FromGin(c), "admin product revision decode failed", err, "revision_id", rev.ID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid revision"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	var (
+		before model.Product
+		// staged is set when the restore went to the draft; draftBefore/working are its copies.
+		staged               bool
+		draftBefore, working model.Product
+	)
+	err = h.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("deleted_at IS NULL").First(&before, rev.ProductID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errProductGone
+			}
+			return err
+		}
+		var drafts int64
+		if err := tx.Model(&model.ProductDraft{}).Where("product_id = ?", before.ID).Count(&drafts).Error; err != nil {
+			return err
+		}
+		if before.PublishedAt != nil || drafts > 0 {
+			staged = true
+			var err error
+			draftBefore, working, err = stageProductDraft(ctx, tx, before, model.ProductContentOf(snapshot).Columns())
+			return err
+		}
+		if err := catalog.SaveRevision(ctx, tx, before, "restore"); err != nil {
+			return err
+		}
+		if err := tx.Model(&model.Product{}).
+			Where("id = ?", before.ID).
+			Where("deleted_at IS NULL").
+			Updates(model.ProductContentOf(snapshot).Columns()).Error; err != nil {
+			return err
+		}
+		if _, err := catalog.SyncVariants(ctx, tx, before.ID, snapshot.DetailJSON); err != nil {
+			return err
+		}
+		_, err := media.SyncProductAssets(ctx, tx, before.ID)
+		return err
+	})
+	if errors.Is(err, errProductGone) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+		return
+	}
+	if errors.Is(err, errProductTaken) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		// Typically a slug/styleNo now taken by another product.
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if staged {
+		recordAudit(c, h.db, audit.Entry{
+			Action:     "product.restore_draft",
+			EntityType: "products",
+			EntityID:   auditID(before.ID),
+			Before:     draftBefore,
+			After:      working,
+		})
+		c.JSON(http.StatusAccepted, working)
+		return
+	}
+	recordAudit(c, h.db, audit.Entry{
+		Action:     "product.restore",
+		EntityType: "products",
+		EntityID:   auditID(before.ID),
+		Before:     before,
+		After:      reloadForAudit[model.Product](ctx, h.db, before.ID),
+	})
+
+	// Only unpublished rows are restored in place, so the public cache is unaffected.
+	h.Get(c)
+}
+
+// loadRevision resolves rawRev for the product in the path and writes the error response when missing.
+func (h *ProductsHandler) loadRevision(c *gin.Context, rawRev string) (model.ProductRevision, bool) {
+	var rev model.ProductRevision
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return rev, false
+	}
+	revID, err := strconv.ParseUint(rawRev, 10, 64)
+	if err != nil || revID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid revision"})
+		return rev, false
+	}
+	if err := h.db.WithContext(c.Request.Context()).
+		Where("product_id = ?", uint(id)).
+		First(&rev, uint(revID)).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+		return rev, false
+	}
+	return rev, true
+}
+
+func revisionProduct(rev model.ProductRevision) (model.Product, error) {
+	var p model.Product
+	err := json.Unmarshal(rev.Snapshot, &p)
+	return p, err
+}
@@ -0,0 +1,312 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"evening-gown/internal/audit"
+	"evening-gown/internal/catalog"
+	"evening-gown/internal/logging"
+	"evening-gown/internal/model"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type variantCreateRequest struct {
+	Color        string `json:"color"`
+	Size         string `json:"size"`
+	StyleSuffix  string `json:"styleSuffix"`
+	Availability string `json:"availability"`
+	MOQ          int    `json:"moq"`
+	LeadTime     string `json:"leadTime"`
+}
+
+type variantUpdateRequest struct {
+	StyleSuffix  *string `json:"styleSuffix"`
+	Availability *string `json:"availability"`
+	MOQ          *int    `json:"moq"`
+	LeadTime     *string `json:"leadTime"`
+}
+
+var errVariantNotInOptions = errors.New("color/size not in the product's option groups")
+
+// ListVariants returns the variants of a product in option order.
+// Route: GET /api/v1/admin/products/:id/variants
+func (h *ProductsHandler) ListVariants(c *gin.Context) {
+	if h == nil || h.db == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "service unavailable"})
+		return
+	}
+
+	p, ok := h.loadLiveProduct(c)
+	if !ok {
+		return
+	}
+
+	var items []model.ProductVariant
+	if err := h.db.WithContext(c.Request.Context()).
+		Where("product_id = ?", p.ID).
+		Order("position asc, id asc").
+		Find(&items).Error; err != nil {
+		logging.ErrorWithStack(logging.FromGin(c), "admin product variants list failed", err, "product_id", p.ID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"total": len(items), "items": items})
+}
+
+// CreateVariant adds a variant for a color/size that exists in the option groups
+// (e.g. one that was deleted earlier).
+// Route: POST /api/v1/admin/products/:id/variants
+func (h *ProductsHandler) CreateVariant(c *gin.Context) {
+	if h == nil || h.db == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "service unavailable"})
+		return
+	}
+
+	p, ok := h.loadLiveProduct(c)
+	if !ok {
+		return
+	}
+
+	var req variantCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	v := model.ProductVariant{
+		ProductID:    p.ID,
+		Color:        strings.TrimSpace(req.Color),
+		Size:         strings.TrimSpace(req.Size),
+		Availability: strings.TrimSpace(req.Availability),
+		MOQ:          req.MOQ,
+		LeadTime:     strings.TrimSpace(req.LeadTime),
+	}
+	suffix := strings.TrimSpace(req.StyleSuffix)
+	if msg := validateVariantFields(&v, &suffix); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	plan, err := model.PlanProductVariants(p.DetailJSON)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid detail"})
+		return
+	}
+	found := false
+	for _, combo := range plan {
+		if combo.Color == v.Color && combo.Size == v.Size {
+			v.Position = combo.Position
+			found = true
+			break
+		}
+	}
+	if !found {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errVariantNotInOptions.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	var dup int64
+	if err := h.db.WithContext(ctx).Model(&model.ProductVariant{}).
+		Where("product_id = ? AND color = ? AND size = ?", p.ID, v.Color, v.Size).
+		Count(&dup).Error; err != nil {
+		logging.ErrorWithStack(logging.FromGin(c), "admin product variant lookup failed", err, "product_id", p.ID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return
+	}
+	if dup > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "variant already exists"})
+		return
+	}
+	if err := h.db.WithContext(ctx).Create(&v).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	recordAudit(c, h.db, audit.Entry{Action: "product.variant_create", EntityType: "product_variants", EntityID: auditID(v.ID), After: v})
+	h.bumpIfPublished(ctx, p)
+
+	c.JSON(http.StatusCreated, v)
+}
+
+// UpdateVariant changes the backoffice-owned fields of a variant.
+// Color/size are derived from the option groups and cannot be edited here.
+// Route: PATCH /api/v1/admin/products/:id/variants/:variantId
+func (h *ProductsHandler) UpdateVariant(c *gin.Context) {
+	if h == nil || h.db == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "service unavailable"})
+		return
+	}
+
+	p, before, ok := h.loadVariant(c)
+	if !ok {
+		return
+	}
+
+	var req variantUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	v := before
+	suffix := v.StyleSuffix
+	if req.StyleSuffix != nil {
+		suffix = strings.TrimSpace(*req.StyleSuffix)
+	}
+	if req.Availability != nil {
+		v.Availability = strings.TrimSpace(*req.Availability)
+	}
+	if req.MOQ != nil {
+		v.MOQ = *req.MOQ
+	}
+	if req.LeadTime != nil {
+		v.LeadTime = strings.TrimSpace(*req.LeadTime)
+	}
+	if msg := validateVariantFields(&v, &suffix); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	ctx := c.Request.Context()
+	if err := h.db.WithContext(ctx).Model(&model.ProductVariant{}).
+		Where("id = ?", v.ID).
+		Updates(map[string]any{
+			"style_suffix": v.StyleSuffix,
+			"availability": v.Availability,
+			"moq":          v.MOQ,
+			"lead_time":    v.LeadTime,
+		}).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	after := reloadForAudit[model.ProductVariant](ctx, h.db, v.ID)
+	recordAudit(c, h.db, audit.Entry{Action: "product.variant_update", EntityType: "product_variants", EntityID: auditID(v.ID), Before: before, After: after})
+	h.bumpIfPublished(ctx, p)
+
+	c.JSON(http.StatusOK, after)
+}
+
+// DeleteVariant removes a variant. It is recreated by the next sync while the combination
+// is still in the option groups; set availability to "archived" to hide it for good.
+// Route: DELETE /api/v1/admin/products/:id/variants/:variantId
+func (h *ProductsHandler) DeleteVariant(c *gin.Context) {
+	if h == nil || h.db == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "service unavailable"})
+		return
+	}
+
+	p, v, ok := h.loadVariant(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	if err := h.db.WithContext(ctx).Delete(&model.ProductVariant{}, v.ID).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	recordAudit(c, h.db, audit.Entry{Action: "product.variant_delete", EntityType: "product_variants", EntityID: auditID(v.ID), Before: v})
+	h.bumpIfPublished(ctx, p)
+
+	c.Status(http.StatusNoContent)
+}
+
+// SyncVariants regenerates variants from the live detail's option groups.
+// Saving the detail already does this; the endpoint repairs products edited before variants existed.
+// Route: POST /api/v1/admin/products/:id/variants/sync
+func (h *ProductsHandler) SyncVariants(c *gin.Context) {
+	if h == nil || h.db == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "service unavailable"})
+		return
+	}
+
+	p, ok := h.loadLiveProduct(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	var changed bool
+	if err := h.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		changed, err = catalog.SyncVariants(ctx, tx, p.ID, p.DetailJSON)
+		return err
+	}); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if changed {
+		recordAudit(c, h.db, audit.Entry{Action: "product.variant_sync", EntityType: "products", EntityID: auditID(p.ID)})
+		h.bumpIfPublished(ctx, p)
+	}
+
+	h.ListVariants(c)
+}
+
+// validateVariantFields normalizes suffix into v and returns a client error message, if any.
+func validateVariantFields(v *model.ProductVariant, suffix *string) string {
+	if !model.IsValidVariantAvailability(v.Availability) {
+		return "invalid availability"
+	}
+	if v.MOQ < 0 {
+		return "invalid moq"
+	}
+	v.StyleSuffix = ""
+	if *suffix != "" {
+		norm, err := model.NormalizeStyleNo(*suffix)
+		if err != nil {
+			return "invalid styleSuffix"
+		}
+		v.StyleSuffix = norm
+	}
+	return ""
+}
+
+func (h *ProductsHandler) loadLiveProduct(c *gin.Context) (model.Product, bool) {
+	var p model.Product
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return p, false
+	}
+	if err := h.db.WithContext(c.Request.Context()).
+		Where("deleted_at IS NULL").
+		First(&p, uint(id)).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+		return p, false
+	}
+	return p, true
+}
+
+func (h *ProductsHandler) loadVariant(c *gin.Context) (model.Product, model.ProductVariant, bool) {
+	var v model.ProductVariant
+	p, ok := h.loadLiveProduct(c)
+	if !ok {
+		return p, v, false
+	}
+	variantID, err := strconv.ParseUint(c.Param("variantId"), 10, 64)
+	if err != nil || variantID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid variant id"})
+		return p, v, false
+	}
+	if err := h.db.WithContext(c.Request.Context()).
+		Where("product_id = ?", p.ID).
+		First(&v, uint(variantID)).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+		return p, v, false
+	}
+	return p, v, true
+}
+
+// bumpIfPublished invalidates public caches when a change to p is visible on the site.
+func (h *ProductsHandler) bumpIfPublished(ctx context.Context, p model.Product) {
+	if p.PublishedAt != nil && h.cache != nil {
+		_, _ = h.cache.BumpProductsVersion(ctx)
+	}
+}
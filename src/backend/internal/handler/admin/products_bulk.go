@@ -0,0 +1,280 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"evening-gown/internal/audit"
+	"evening-gown/internal/catalog"
+	"evening-gown/internal/logging"
+	"evening-gown/internal/media"
+	"evening-gown/internal/model"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const productBulkMaxItems = 500
+
+// Bulk actions.
+const (
+	bulkPublish   = "publish"
+	bulkUnpublish = "unpublish"
+	bulkDelete    = "delete"
+	bulkSetSeason = "set_season"
+)
+
+// Per-item bulk results.
+const (
+	bulkOK        = "ok"
+	bulkDrafted   = "drafted"
+	bulkUnchanged = "unchanged"
+	bulkNotFound  = "not_found"
+)
+
+var errBulkTooMany = errors.New("too many products")
+
+type productBulkRequest struct {
+	Action string         `json:"action" binding:"required"`
+	IDs    []uint         `json:"ids"`
+	Filter *productFilter `json:"filter"`
+	// Season is the target for set_season.
+	Season string `json:"season"`
+}
+
+type productBulkResult struct {
+	ID      uint   `json:"id"`
+	StyleNo string `json:"styleNo,omitempty"`
+	Result  string `json:"result"`
+}
+
+// bulkItem is a product the action changed, kept for the audit entries written after commit.
+type bulkItem struct {
+	before  model.Product
+	drafted bool
+	working model.Product
+}
+
+// Bulk applies one action to many products in a single transaction.
+//
+// Targets are either "ids" or a "filter" (the List filters; an empty filter is rejected so a
+// typo cannot hit the whole catalog). Every action behaves like its single-product endpoint:
+// set_season on a published product is staged in its draft. Products already in the
+// requested state are reported as unchanged, missing IDs as not_found. The public cache is
+// bumped at most once.
+// Route: POST /api/v1/admin/products/bulk
+func (h *ProductsHandler) Bulk(c *gin.Context) {
+	if h == nil || h.db == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "service unavailable"})
+		return
+	}
+
+	var req productBulkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var season string
+	switch req.Action {
+	case bulkPublish, bulkUnpublish, bulkDelete:
+	case bulkSetSeason:
+		var ok bool
+		if season, ok = resolveTaxonomy(c, h.db, model.TaxonomySeason, req.Season); !ok {
+			return
+		}
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid action"})
+		return
+	}
+
+	if req.Filter != nil && !req.Filter.validStatus() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid filter status"})
+		return
+	}
+	ids := uniqueIDs(req.IDs)
+	hasFilter := req.Filter != nil && !req.Filter.empty()
+	if (len(ids) > 0) == hasFilter {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "specify either ids or a filter"})
+		return
+	}
+	if len(ids) > productBulkMaxItems {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errBulkTooMany.Error(), "max": productBulkMaxItems})
+		return
+	}
+
+	ctx := c.Request.Context()
+	now := time.Now().UTC()
+	var (
+		results    []productBulkResult
+		changed    []bulkItem
+		bumpPublic bool
+		publicIDs  []uint
+	)
+	err := h.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		q := tx.Model(&model.Product{}).Where("deleted_at IS NULL")
+		if hasFilter {
+			q = req.Filter.apply(q)
+		} else {
+			q = q.Where("id IN ?", ids)
+		}
+		var products []model.Product
+		if err := q.Order("id asc").Limit(productBulkMaxItems + 1).Find(&products).Error; err != nil {
+			return err
+		}
+		if len(products) > productBulkMaxItems {
+			return errBulkTooMany
+		}
+
+		found := make(map[uint]bool, len(products))
+		for _, p := range products {
+			found[p.ID] = true
+			item := bulkItem{before: p}
+			result, err := applyBulkAction(ctx, tx, req.Action, season, now, &item)
+			if err != nil {
+				return err
+			}
+			results = append(results, productBulkResult{ID: p.ID, StyleNo: p.StyleNo, Result: result})
+			if result == bulkUnchanged {
+				continue
+			}
+			changed = append(changed, item)
+			// Season edits never touch a published live row (they are drafted), so only
+			// publish state changes are visible on the site.
+			if req.Action != bulkSetSeason && (req.Action == bulkPublish || p.PublishedAt != nil) {
+				bumpPublic = true
+				publicIDs = append(publicIDs, p.ID)
+			}
+		}
+		for _, id := range ids {
+			if !found[id] {
+				results = append(results, productBulkResult{ID: id, Result: bulkNotFound})
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, errBulkTooMany) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "max": productBulkMaxItems})
+		return
+	}
+	if err != nil {
+		logging.ErrorWithStack(logging.FromGin(c), "admin products bulk failed", err, "action", req.Action)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	for _, item := range changed {
+		e := audit.Entry{
+			Action:     bulkAuditAction(req.Action, item.drafted),
+			EntityType: "products",
+			EntityID:   auditID(item.before.ID),
+			Before:     item.before,
+		}
+		switch {
+		case item.drafted:
+			e.After = item.working
+		case req.Action != bulkDelete:
+			// Delete keeps After nil, like the single endpoint.
+			e.After = reloadForAudit[model.Product](ctx, h.db, item.before.ID)
+		}
+		recordAudit(c, h.db, e)
+	}
+	if bumpPublic && h.cache != nil {
+		_, _ = h.cache.BumpProductsVersion(ctx)
+		media.BumpAssetsCache(ctx, h.db, h.cache, publicIDs...)
+	}
+
+	if results == nil {
+		results = []productBulkResult{}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"action":  req.Action,
+		"total":   len(results),
+		"changed": len(changed),
+		"results": results,
+	})
+}
+
+func applyBulkAction(ctx context.Context, tx *gorm.DB, action, season string, now time.Time, item *bulkItem) (string, error) {
+	p := item.before
+	q := tx.Model(&model.Product{}).Where("id = ?", p.ID).Where("deleted_at IS NULL")
+
+	switch action {
+	case bulkPublish:
+		// Unlike the single endpoint, keep the original published_at of already live products.
+		if p.PublishedAt != nil && p.PublishAt == nil {
+			return bulkUnchanged, nil
+		}
+		updates := map[string]any{"publish_at": nil}
+		if p.PublishedAt == nil {
+			updates["published_at"] = &now
+		}
+		return bulkOK, q.Updates(updates).Error
+
+	case bulkUnpublish:
+		if p.PublishedAt == nil && p.UnpublishAt == nil {
+			return bulkUnchanged, nil
+		}
+		return bulkOK, q.Updates(map[string]any{"published_at": nil, "unpublish_at": nil}).Error
+
+	case bulkDelete:
+		return bulkOK, q.Update("deleted_at", &now).Error
+
+	case bulkSetSeason:
+		var d model.ProductDraft
+		err := tx.Where("product_id = ?", p.ID).First(&d).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", err
+		}
+		updates := map[string]any{"season": season}
+		// Same rule as Update: published (or already drafted) products are edited via the draft.
+		if p.PublishedAt != nil || err == nil {
+			current, err := draftWorkingCopy(p, &d)
+			if err != nil {
+				return "", err
+			}
+			if current.Season == season {
+				return bulkUnchanged, nil
+			}
+			before, working, err := stageProductDraft(ctx, tx, p, updates)
+			if err != nil {
+				return "", err
+			}
+			item.drafted, item.before, item.working = true, before, working
+			return bulkDrafted, nil
+		}
+		if p.Season == season {
+			return bulkUnchanged, nil
+		}
+		if err := catalog.SaveRevision(ctx, tx, p, "update"); err != nil {
+			return "", err
+		}
+		return bulkOK, q.Updates(updates).Error
+	}
+	return "", errors.New("invalid action")
+}
+
+func bulkAuditAction(action string, drafted bool) string {
+	switch {
+	case drafted:
+		return "product.update_draft"
+	case action == bulkSetSeason:
+		return "product.update"
+	}
+	return "product." + action
+}
+
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	out := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if id == 0 || seen[id] {
+			continue
+		}
+		seen[id] = true
+		out = append(out, id)
+	}
+	return out
+}
@@ -3,14 +3,20 @@ package admin
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
 
+	"evening-gown/internal/audit"
+	"evening-gown/internal/catalog"
 	"evening-gown/internal/cache"
 	"evening-gown/internal/logging"
+	"evening-gown/internal/media"
 	"evening-gown/internal/model"
+	"evening-gown/internal/pagination"
+	"evening-gown/internal/search"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -66,40 +72,30 @@ func (h *ProductsHandler) List(c *gin.Context) {
 		return
 	}
 
-	q := h.db.WithContext(c.Request.Context()).Model(&model.Product{}).
-		Where("deleted_at IS NULL")
-
-	if status := strings.TrimSpace(c.Query("status")); status != "" {
-		if status == "published" {
-			q = q.Where("published_at IS NOT NULL")
-		} else if status == "draft" {
-			q = q.Where("published_at IS NULL")
-		}
-	}
-	if isNew := strings.TrimSpace(c.Query("is_new")); isNew != "" {
-		if isNew == "true" {
-			q = q.Where("is_new = true")
-		} else if isNew == "false" {
-			q = q.Where("is_new = false")
-		}
+	filter := productFilter{
+		Status:   c.Query("status"),
+		Season:   c.Query("season"),
+		Category: c.Query("category"),
+		Q:        c.Query("q"),
 	}
-	if season := strings.TrimSpace(c.Query("season")); season != "" {
-		q = q.Where("season = ?", season)
-	}
-	if category := strings.TrimSpace(c.Query("category")); category != "" {
-		q = q.Where("category = ?", category)
+	if isNew := strings.TrimSpace(c.Query("is_new")); isNew == "true" || isNew == "false" {
+		v := isNew == "true"
+		filter.IsNew = &v
 	}
+	q := filter.apply(h.db.WithContext(c.Request.Context()).Model(&model.Product{}).
+		Where("deleted_at IS NULL"))
 
-	limit := parseIntQuery(c, "limit", 50)
-	offset := parseIntQuery(c, "offset", 0)
-	if limit <= 0 {
-		limit = 50
-	}
-	if limit > 200 {
-		limit = 200
+	page := pagination.ParseParams(c.Request.URL.Query(), 50, 200)
+	query := search.Normalize(filter.Q)
+	if query == "" || strings.TrimSpace(c.Query("sort")) != "" {
+		listSorted(c, q, adminProductSorts, page, "products")
+		return
 	}
-	if offset < 0 {
-		offset = 0
+
+	// Searches without an explicit sort are ordered by relevance, which has no cursor.
+	if page.CursorMode {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cursor requires an explicit sort when searching"})
+		return
 	}
 
 	var total int64
@@ -110,7 +106,7 @@ func (h *ProductsHandler) List(c *gin.Context) {
 	}
 
 	var items []model.Product
-	if err := q.Order("is_new desc, new_rank desc, id desc").Limit(limit).Offset(offset).Find(&items).Error; err != nil {
+	if err := search.OrderProducts(q, query, "is_new desc, new_rank desc, id desc").Limit(page.Limit).Offset(page.Offset).Find(&items).Error; err != nil {
 		logging.ErrorWithStack(logging.FromGin(c), "admin products query list failed", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
 		return
@@ -122,6 +118,54 @@ func (h *ProductsHandler) List(c *gin.Context) {
 	})
 }
 
+// productFilter selects products for List and bulk actions. List ignores unknown status
+// values; Bulk rejects them (see validStatus) so a typo cannot widen the selection.
+type productFilter struct {
+	Status   string `json:"status"` // published|draft|scheduled
+	IsNew    *bool  `json:"isNew"`
+	Season   string `json:"season"`
+	Category string `json:"category"`
+	// Q is a free-text search (style number, slug, detail texts); see package search.
+	Q string `json:"q"`
+}
+
+func (f productFilter) validStatus() bool {
+	switch strings.TrimSpace(f.Status) {
+	case "", "published", "draft", "scheduled":
+		return true
+	}
+	return false
+}
+
+// empty reports whether the filter selects every product. Only recognized statuses count.
+func (f productFilter) empty() bool {
+	status := strings.TrimSpace(f.Status)
+	return (status == "" || !f.validStatus()) && f.IsNew == nil &&
+		strings.TrimSpace(f.Season) == "" && strings.TrimSpace(f.Category) == "" &&
+		search.Normalize(f.Q) == ""
+}
+
+func (f productFilter) apply(q *gorm.DB) *gorm.DB {
+	switch strings.TrimSpace(f.Status) {
+	case "published":
+		q = q.Where("published_at IS NOT NULL")
+	case "draft":
+		q = q.Where("published_at IS NULL")
+	case "scheduled":
+		q = q.Where("(publish_at IS NOT NULL OR unpublish_at IS NOT NULL)")
+	}
+	if f.IsNew != nil {
+		q = q.Where("is_new = ?", *f.IsNew)
+	}
+	if season := strings.TrimSpace(f.Season); season != "" {
+		q = q.Where("season = ?", season)
+	}
+	if category := strings.TrimSpace(f.Category); category != "" {
+		q = q.Where("category = ?", category)
+	}
+	return search.MatchProducts(q, search.Normalize(f.Q))
+}
+
 func (h *ProductsHandler) Create(c *gin.Context) {
 	if h == nil || h.db == nil {
 		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "service unavailable"})
@@ -139,6 +183,19 @@ func (h *ProductsHandler) Create(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid styleNo"})
 		return
 	}
+	season, ok := resolveTaxonomy(c, h.db, model.TaxonomySeason, req.Season)
+	if !ok {
+		return
+	}
+	category, ok := resolveTaxonomy(c, h.db, model.TaxonomyCategory, req.Category)
+	if !ok {
+		return
+	}
+	availability := strings.TrimSpace(req.Availability)
+	if !model.IsValidProductAvailability(availability) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid availability", "allowed": model.ProductAvailabilities})
+		return
+	}
 
 	slug := strings.TrimSpace(req.Slug)
 	if slug == "" {
@@ -167,9 +224,9 @@ func (h *ProductsHandler) Create(c *gin.Context) {
 	p := model.Product{
 		Slug:          slug,
 		StyleNo:       styleNo,
-		Season:        req.Season,
-		Category:      req.Category,
-		Availability:  req.Availability,
+		Season:        season,
+		Category:      category,
+		Availability:  availability,
 		IsNew:         isNew,
 		NewRank:       newRank,
 		CoverImageURL: strings.TrimSpace(req.CoverImageURL),
@@ -180,10 +237,20 @@ func (h *ProductsHandler) Create(c *gin.Context) {
 		DetailJSON:    mergedDetail,
 	}
 
-	if err := h.db.WithContext(ctx).Create(&p).Error; err != nil {
+	if err := h.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&p).Error; err != nil {
+			return err
+		}
+		if _, err := catalog.SyncVariants(ctx, tx, p.ID, p.DetailJSON); err != nil {
+			return err
+		}
+		_, err := media.SyncProductAssets(ctx, tx, p.ID)
+		return err
+	}); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	recordAudit(c, h.db, audit.Entry{Action: "product.create", EntityType: "products", EntityID: auditID(p.ID), After: p})
 
 	c.JSON(http.StatusCreated, p)
 }
@@ -257,18 +324,37 @@ func (h *ProductsHandler) Update(c *gin.Context) {
 			updates["style_no"] = norm
 		}
 	}
+	// Unchanged values pass even if their term was deactivated since.
 	if req.Season != nil {
 		if s := strings.TrimSpace(*req.Season); s != "" {
-			updates["season"] = s
+			season := before.Season
+			if !strings.EqualFold(s, before.Season) {
+				var ok bool
+				if season, ok = resolveTaxonomy(c, h.db, model.TaxonomySeason, s); !ok {
+					return
+				}
+			}
+			updates["season"] = season
 		}
 	}
 	if req.Category != nil {
 		if s := strings.TrimSpace(*req.Category); s != "" {
-			updates["category"] = s
+			category := before.Category
+			if !strings.EqualFold(s, before.Category) {
+				var ok bool
+				if category, ok = resolveTaxonomy(c, h.db, model.TaxonomyCategory, s); !ok {
+					return
+				}
+			}
+			updates["category"] = category
 		}
 	}
 	if req.Availability != nil {
 		if s := strings.TrimSpace(*req.Availability); s != "" {
+			if !model.IsValidProductAvailability(s) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid availability", "allowed": model.ProductAvailabilities})
+				return
+			}
 			updates["availability"] = s
 		}
 	}
@@ -305,18 +391,52 @@ func (h *ProductsHandler) Update(c *gin.Context) {
 		return
 	}
 
-	if err := h.db.WithContext(ctx).Model(&model.Product{}).
-		Where("id = ?", uint(id)).
-		Where("deleted_at IS NULL").
-		Updates(updates).Error; err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	// Published products (and ones already being drafted) are edited through a working copy
+	// so the public site does not change until "publish changes"; see product_drafts.go.
+	var drafts int64
+	if err := h.db.WithContext(ctx).Model(&model.ProductDraft{}).
+		Where("product_id = ?", before.ID).
+		Count(&drafts).Error; err != nil {
+		logging.ErrorWithStack(logging.FromGin(c), "admin product draft lookup failed", err, "product_id", before.ID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return
+	}
+	if wasPublished || drafts > 0 {
+		h.saveDraft(c, before, updates)
 		return
 	}
 
-	if wasPublished && h.cache != nil {
-		_, _ = h.cache.BumpProductsVersion(ctx)
+	// The prior row is snapshotted in the same transaction, see product_revisions.go.
+	if err := h.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := catalog.SaveRevision(ctx, tx, before, "update"); err != nil {
+			return err
+		}
+		if err := tx.Model(&model.Product{}).
+			Where("id = ?", uint(id)).
+			Where("deleted_at IS NULL").
+			Updates(updates).Error; err != nil {
+			return err
+		}
+		if detail, ok := updates["detail_json"].(json.RawMessage); ok {
+			if _, err := catalog.SyncVariants(ctx, tx, before.ID, detail); err != nil {
+				return err
+			}
+		}
+		_, err := media.SyncProductAssets(ctx, tx, before.ID)
+		return err
+	}); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
+	recordAudit(c, h.db, audit.Entry{
+		Action:     "product.update",
+		EntityType: "products",
+		EntityID:   auditID(before.ID),
+		Before:     before,
+		After:      reloadForAudit[model.Product](ctx, h.db, before.ID),
+	})
 
+	// Only unpublished products reach this point, so the public cache is unaffected.
 	h.Get(c)
 }
 
@@ -354,20 +474,41 @@ func (h *ProductsHandler) Publish(c *gin.Context) {
 
 	now := time.Now().UTC()
 	ctx := c.Request.Context()
-	res := h.db.WithContext(ctx).Model(&model.Product{}).
-		Where("id = ?", uint(id)).
-		Where("deleted_at IS NULL").
-		Update("published_at", &now)
-	if res.Error != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": res.Error.Error()})
+	before := reloadForAudit[model.Product](ctx, h.db, uint(id))
+	// Edits saved while the product was unpublished may still sit in its draft; they go live
+	// together with the product.
+	err = h.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&model.Product{}).
+			Where("id = ?", uint(id)).
+			Where("deleted_at IS NULL").
+			Updates(map[string]any{"published_at": &now, "publish_at": nil})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return errProductGone
+		}
+		_, _, err := catalog.PromotePendingDraft(ctx, tx, uint(id))
+		return err
+	})
+	if errors.Is(err, errProductGone) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
 		return
 	}
-	if res.RowsAffected == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	recordAudit(c, h.db, audit.Entry{
+		Action:     "product.publish",
+		EntityType: "products",
+		EntityID:   auditID(uint(id)),
+		Before:     before,
+		After:      reloadForAudit[model.Product](ctx, h.db, uint(id)),
+	})
 	if h.cache != nil {
 		_, _ = h.cache.BumpProductsVersion(ctx)
+		media.BumpAssetsCache(ctx, h.db, h.cache, uint(id))
 	}
 
 	h.Get(c)
@@ -386,10 +527,11 @@ func (h *ProductsHandler) Unpublish(c *gin.Context) {
 	}
 
 	ctx := c.Request.Context()
+	before := reloadForAudit[model.Product](ctx, h.db, uint(id))
 	res := h.db.WithContext(ctx).Model(&model.Product{}).
 		Where("id = ?", uint(id)).
 		Where("deleted_at IS NULL").
-		Update("published_at", nil)
+		Updates(map[string]any{"published_at": nil, "unpublish_at": nil})
 	if res.Error != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": res.Error.Error()})
 		return
@@ -398,23 +540,72 @@ func (h *ProductsHandler) Unpublish(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
 		return
 	}
+	recordAudit(c, h.db, audit.Entry{
+		Action:     "product.unpublish",
+		EntityType: "products",
+		EntityID:   auditID(uint(id)),
+		Before:     before,
+		After:      reloadForAudit[model.Product](ctx, h.db, uint(id)),
+	})
 	if h.cache != nil {
 		_, _ = h.cache.BumpProductsVersion(ctx)
+		media.BumpAssetsCache(ctx, h.db, h.cache, uint(id))
 	}
 
 	h.Get(c)
 }
 
-func parseIntQuery(c *gin.Context, key string, fallback int) int {
-	raw := strings.TrimSpace(c.Query(key))
-	if raw == "" {
-		return fallback
+// Schedule sets (or clears) future publish/unpublish times.
+// Route: PUT /api/v1/admin/products/:id/schedule
+func (h *ProductsHandler) Schedule(c *gin.Context) {
+	if h == nil || h.db == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "service unavailable"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req scheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
-	v, err := strconv.Atoi(raw)
+	updates, err := scheduleUpdates(req, time.Now().UTC())
 	if err != nil {
-		return fallback
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
-	return v
+
+	ctx := c.Request.Context()
+	var before model.Product
+	if err := h.db.WithContext(ctx).
+		Where("id = ?", uint(id)).
+		Where("deleted_at IS NULL").
+		First(&before).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+		return
+	}
+
+	if err := h.db.WithContext(ctx).Model(&model.Product{}).
+		Where("id = ?", before.ID).
+		Where("deleted_at IS NULL").
+		Updates(updates).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	recordAudit(c, h.db, audit.Entry{
+		Action:     "product.schedule",
+		EntityType: "products",
+		EntityID:   auditID(before.ID),
+		Before:     before,
+		After:      reloadForAudit[model.Product](ctx, h.db, before.ID),
+	})
+
+	h.Get(c)
 }
 
 func (h *ProductsHandler) Delete(c *gin.Context) {
@@ -453,8 +644,11 @@ func (h *ProductsHandler) Delete(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
 		return
 	}
+	// After is nil so the entry keeps a full copy of what was deleted.
+	recordAudit(c, h.db, audit.Entry{Action: "product.delete", EntityType: "products", EntityID: auditID(before.ID), Before: before})
 	if wasPublished && h.cache != nil {
 		_, _ = h.cache.BumpProductsVersion(ctx)
+		media.BumpAssetsCache(ctx, h.db, h.cache, before.ID)
 	}
 
 	c.Status(http.StatusNoContent)
@@ -0,0 +1,677 @@
+package admin
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"evening-gown/internal/audit"
+	"evening-gown/internal/catalog"
+	"evening-gown/internal/logging"
+	"evening-gown/internal/media"
+	"evening-gown/internal/model"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Bulk import/export of the catalog as CSV.
+//
+// Export and import share one layout, so an exported file can be edited in a spreadsheet and
+// uploaded again. Rows are matched to products by styleNo: unknown style numbers are created
+// (unpublished), known ones are updated the same way PATCH /products/:id would update them —
+// in place with a revision for unpublished products, as a pending draft for published ones.
+// Empty cells leave the current value unchanged.
+
+const (
+	productImportMaxBytes = 8 << 20
+	productImportMaxRows  = 1000
+)
+
+// productCSVHeader is the export layout. publishedAt is informational and ignored on import.
+var productCSVHeader = []string{
+	"styleNo", "slug", "season", "category", "availability", "isNew", "newRank",
+	"coverImage", "coverImageKey", "hoverImage", "hoverImageKey", "detail", "publishedAt",
+}
+
+// productImportColumns maps a normalized header (see csvColumnKey) to its column name.
+var productImportColumns = func() map[string]string {
+	out := map[string]string{}
+	for _, col := range productCSVHeader {
+		if col != "publishedAt" {
+			out[csvColumnKey(col)] = col
+		}
+	}
+	return out
+}()
+
+// UTF-8 byte order mark: without it Excel reads UTF-8 CSV as the local code page.
+const utf8BOM = "\ufeff"
+
+// Import row actions.
+const (
+	importCreate    = "create"
+	importUpdate    = "update"
+	importDraft     = "draft"
+	importUnchanged = "unchanged"
+)
+
+var errImportInvalid = errors.New("import has invalid rows")
+
+type importCellError struct {
+	Column  string `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+// productImportRow is one data row of the file and what importing it does.
+type productImportRow struct {
+	Row       int               `json:"row"` // spreadsheet row number; the header is row 1
+	StyleNo   string            `json:"styleNo"`
+	Action    string            `json:"action,omitempty"`
+	ProductID uint              `json:"productId,omitempty"`
+	Errors    []importCellError `json:"errors,omitempty"`
+
+	rec     []string
+	live    model.Product
+	create  model.Product
+	updates map[string]any
+}
+
+func (r *productImportRow) fail(column, format string, args ...any) {
+	r.Errors = append(r.Errors, importCellError{Column: column, Message: fmt.Sprintf(format, args...)})
+}
+
+type productImportReport struct {
+	DryRun         bool               `json:"dryRun"`
+	Applied        bool               `json:"applied"`
+	Total          int                `json:"total"`
+	Created        int                `json:"created"`
+	Updated        int                `json:"updated"`
+	Drafted        int                `json:"drafted"`
+	Unchanged      int                `json:"unchanged"`
+	Invalid        int                `json:"invalid"`
+	IgnoredColumns []string           `json:"ignoredColumns,omitempty"`
+	Rows           []productImportRow `json:"rows"`
+}
+
+func (rep *productImportReport) count() {
+	rep.Total = len(rep.Rows)
+	rep.Created, rep.Updated, rep.Drafted, rep.Unchanged, rep.Invalid = 0, 0, 0, 0, 0
+	for _, r := range rep.Rows {
+		if len(r.Errors) > 0 {
+			rep.Invalid++
+			continue
+		}
+		switch r.Action {
+		case importCreate:
+			rep.Created++
+		case importUpdate:
+			rep.Updated++
+		case importDraft:
+			rep.Drafted++
+		case importUnchanged:
+			rep.Unchanged++
+		}
+	}
+}
+
+// Import validates a CSV of products and, with ?mode=apply, writes it.
+//
+// The default is a dry run: nothing is written and the report lists, per row, the action it
+// would take or the errors found. In apply mode the rows are validated again and written in one
+// transaction; if any row is invalid nothing is written and the report is returned with 422.
+// The file is sent as multipart field "file" or as the raw request body.
+// Route: POST /api/v1/admin/products/import
+func (h *ProductsHandler) Import(c *gin.Context) {
+	if h == nil || h.db == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "service unavailable"})
+		return
+	}
+
+	mode := strings.TrimSpace(c.DefaultQuery("mode", "dry_run"))
+	if mode != "dry_run" && mode != "apply" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid mode"})
+		return
+	}
+
+	data, err := readImportUpload(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	header, records, ignored, err := parseProductCSV(data)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	tpl := h.loadProductDetailTemplate(ctx)
+	report := productImportReport{DryRun: mode != "apply", IgnoredColumns: ignored}
+
+	if report.DryRun {
+		rows, err := planProductImport(ctx, h.db, tpl, header, records)
+		if err != nil {
+			logging.ErrorWithStack(logging.FromGin(c), "admin products import plan failed", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+			return
+		}
+		report.Rows = rows
+		report.count()
+		c.JSON(http.StatusOK, report)
+		return
+	}
+
+	// Planning happens inside the transaction so the checks hold for the rows being written.
+	err = h.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		rows, err := planProductImport(ctx, tx, tpl, header, records)
+		if err != nil {
+			return err
+		}
+		report.Rows = rows
+		report.count()
+		if report.Invalid > 0 {
+			return errImportInvalid
+		}
+		for i := range rows {
+			if err := applyProductImportRow(ctx, tx, &rows[i]); err != nil {
+				return fmt.Errorf("row %d: %w", rows[i].Row, err)
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, errImportInvalid) {
+		c.JSON(http.StatusUnprocessableEntity, report)
+		return
+	}
+	if err != nil {
+		logging.ErrorWithStack(logging.FromGin(c), "admin products import apply failed", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	report.Applied = true
+
+	type importedProduct struct {
+		StyleNo   string `json:"styleNo"`
+		Action    string `json:"action"`
+		ProductID uint   `json:"productId"`
+	}
+	summary := make([]importedProduct, 0, len(report.Rows))
+	for _, r := range report.Rows {
+		if r.Action != importUnchanged {
+			summary = append(summary, importedProduct{StyleNo: r.StyleNo, Action: r.Action, ProductID: r.ProductID})
+		}
+	}
+	recordAudit(c, h.db, audit.Entry{
+		Action:     "product.import",
+		EntityType: "products",
+		After: gin.H{
+			"created":  report.Created,
+			"updated":  report.Updated,
+			"drafted":  report.Drafted,
+			"products": summary,
+		},
+	})
+
+	// New products are unpublished and published ones only get drafts, so nothing visible
+	// changed and the public cache is left alone.
+	c.JSON(http.StatusOK, report)
+}
+
+// Export streams all (non-deleted) products in the import layout.
+// The file starts with a UTF-8 BOM so spreadsheet apps detect the encoding.
+// Route: GET /api/v1/admin/products/export
+func (h *ProductsHandler) Export(c *gin.Context) {
+	if h == nil || h.db == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "service unavailable"})
+		return
+	}
+
+	rows, err := h.db.WithContext(c.Request.Context()).Model(&model.Product{}).
+		Where("deleted_at IS NULL").
+		Order("id asc").
+		Rows()
+	if err != nil {
+		logging.ErrorWithStack(logging.FromGin(c), "admin products query export failed", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return
+	}
+	defer rows.Close()
+
+	filename := fmt.Sprintf("products-%s.csv", time.Now().UTC().Format("20060102-150405"))
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Status(http.StatusOK)
+
+	_, _ = io.WriteString(c.Writer, utf8BOM)
+	w := csv.NewWriter(c.Writer)
+	_ = w.Write(productCSVHeader)
+	for rows.Next() {
+		var p model.Product
+		if err := h.db.ScanRows(rows, &p); err != nil {
+			// Headers are already sent; log and truncate the file.
+			logging.ErrorWithStack(logging.FromGin(c), "admin products export scan failed", err)
+			break
+		}
+		publishedAt := ""
+		if p.PublishedAt != nil {
+			publishedAt = p.PublishedAt.UTC().Format(time.RFC3339)
+		}
+		_ = w.Write([]string{
+			p.StyleNo,
+			csvSafe(p.Slug),
+			p.Season,
+			p.Category,
+			p.Availability,
+			strconv.FormatBool(p.IsNew),
+			strconv.Itoa(p.NewRank),
+			csvSafe(p.CoverImageURL),
+			csvSafe(p.CoverImageKey),
+			csvSafe(p.HoverImageURL),
+			csvSafe(p.HoverImageKey),
+			string(p.DetailJSON),
+			publishedAt,
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		logging.FromGin(c).Warn("admin products export write failed", "err", err)
+	}
+}
+
+func readImportUpload(c *gin.Context) ([]byte, error) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, productImportMaxBytes)
+
+	var r io.Reader = c.Request.Body
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		fh, err := c.FormFile("file")
+		if err != nil {
+			return nil, errors.New("missing file")
+		}
+		f, err := fh.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+	data, err := io.ReadAll(io.LimitReader(r, productImportMaxBytes+1))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, errors.New("file too large")
+		}
+		return nil, err
+	}
+	if len(data) > productImportMaxBytes {
+		return nil, errors.New("file too large")
+	}
+	return data, nil
+}
+
+// parseProductCSV returns the column index of each known column, the data records and the
+// header cells that were not recognized. Comma and semicolon (Excel in some locales) are accepted.
+func parseProductCSV(data []byte) (map[string]int, [][]string, []string, error) {
+	data = bytes.TrimPrefix(data, []byte(utf8BOM))
+
+	r := csv.NewReader(bytes.NewReader(data))
+	firstLine, _, _ := bytes.Cut(data, []byte("\n"))
+	if bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+		r.Comma = ';'
+	}
+	r.FieldsPerRecord = -1
+
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid csv: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, nil, nil, errors.New("empty file")
+	}
+
+	header := map[string]int{}
+	var ignored []string
+	for i, cell := range records[0] {
+		col, ok := productImportColumns[csvColumnKey(cell)]
+		if !ok {
+			if name := strings.TrimSpace(cell); name != "" {
+				ignored = append(ignored, name)
+			}
+			continue
+		}
+		if _, dup := header[col]; dup {
+			return nil, nil, nil, fmt.Errorf("duplicate column %q", col)
+		}
+		header[col] = i
+	}
+	if _, ok := header["styleNo"]; !ok {
+		return nil, nil, nil, errors.New("missing styleNo column")
+	}
+
+	body := records[1:]
+	if len(body) > productImportMaxRows {
+		return nil, nil, nil, fmt.Errorf("too many rows (max %d)", productImportMaxRows)
+	}
+	return header, body, ignored, nil
+}
+
+// csvColumnKey makes "Style No", "style_no" and "styleNo" the same column.
+func csvColumnKey(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	return strings.NewReplacer(" ", "", "_", "", "-", "").Replace(s)
+}
+
+// csvCell reads a trimmed cell, undoing csvSafe's formula escaping.
+func csvCell(rec []string, header map[string]int, col string) string {
+	i, ok := header[col]
+	if !ok || i >= len(rec) {
+		return ""
+	}
+	s := strings.TrimSpace(rec[i])
+	if len(s) > 1 && s[0] == '\'' && strings.ContainsRune("=+-@", rune(s[1])) {
+		s = s[1:]
+	}
+	return s
+}
+
+func parseCSVBool(s string) (bool, error) {
+	switch strings.ToLower(s) {
+	case "true", "1", "yes", "y":
+		return true, nil
+	case "false", "0", "no", "n":
+		return false, nil
+	}
+	return false, errors.New("invalid bool")
+}
+
+// planProductImport validates the records against db and decides what each row does.
+// Row-level problems are reported on the rows; the error is for database failures only.
+func planProductImport(ctx context.Context, db *gorm.DB, tpl json.RawMessage, header map[string]int, records [][]string) ([]productImportRow, error) {
+	rows := make([]productImportRow, 0, len(records))
+	var styleNos []string
+	for i, rec := range records {
+		if isBlankRecord(rec) {
+			continue
+		}
+		row := productImportRow{Row: i + 2, rec: rec}
+		raw := csvCell(rec, header, "styleNo")
+		if styleNo, err := model.NormalizeStyleNo(raw); err != nil {
+			row.StyleNo = raw
+			row.fail("styleNo", "invalid styleNo")
+		} else {
+			row.StyleNo = styleNo
+			styleNos = append(styleNos, styleNo)
+		}
+		rows = append(rows, row)
+	}
+
+	// Existing rows by styleNo (soft-deleted ones too: they keep their unique values).
+	existing := map[string]model.Product{}
+	if len(styleNos) > 0 {
+		var found []model.Product
+		if err := db.WithContext(ctx).Where("style_no IN ?", styleNos).Find(&found).Error; err != nil {
+			return nil, err
+		}
+		for _, p := range found {
+			existing[p.StyleNo] = p
+		}
+	}
+	drafted := map[uint]model.ProductDraft{}
+	if len(existing) > 0 {
+		ids := make([]uint, 0, len(existing))
+		for _, p := range existing {
+			ids = append(ids, p.ID)
+		}
+		var found []model.ProductDraft
+		if err := db.WithContext(ctx).Where("product_id IN ?", ids).Find(&found).Error; err != nil {
+			return nil, err
+		}
+		for _, d := range found {
+			drafted[d.ProductID] = d
+		}
+	}
+
+	terms, err := activeTaxonomyCodes(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	seenStyle := map[string]int{}
+	rowSlugs := map[*productImportRow]string{}
+	for i := range rows {
+		row := &rows[i]
+		rec := row.rec
+
+		validStyleNo := len(row.Errors) == 0
+		if validStyleNo {
+			if first, dup := seenStyle[row.StyleNo]; dup {
+				row.fail("styleNo", "duplicate of row %d", first)
+				validStyleNo = false
+			} else {
+				seenStyle[row.StyleNo] = row.Row
+			}
+		}
+
+		live, exists := existing[row.StyleNo]
+		if exists && live.DeletedAt != nil {
+			row.fail("styleNo", "styleNo belongs to a deleted product")
+			exists = false
+		}
+
+		// Seasons and categories must be active taxonomy terms, unless the product already uses them.
+		updates := map[string]any{}
+		if s := csvCell(rec, header, "slug"); s != "" {
+			updates["slug"] = s
+		}
+		if s := csvCell(rec, header, "season"); s != "" {
+			if season, ok := model.NormalizeTaxonomyCode(model.TaxonomySeason, s); ok && (terms[model.TaxonomySeason][season] || exists && season == live.Season) {
+				updates["season"] = season
+			} else {
+				row.fail("season", "season %q is not an active taxonomy term", s)
+			}
+		} else if !exists {
+			row.fail("season", "season is required")
+		}
+		if s := csvCell(rec, header, "category"); s != "" {
+			if category, ok := model.NormalizeTaxonomyCode(model.TaxonomyCategory, s); ok && (terms[model.TaxonomyCategory][category] || exists && category == live.Category) {
+				updates["category"] = category
+			} else {
+				row.fail("category", "category %q is not an active taxonomy term", s)
+			}
+		} else if !exists {
+			row.fail("category", "category is required")
+		}
+		if s := csvCell(rec, header, "availability"); s != "" {
+			if model.IsValidProductAvailability(s) {
+				updates["availability"] = s
+			} else {
+				row.fail("availability", "availability must be one of %s", strings.Join(model.ProductAvailabilities, ", "))
+			}
+		} else if !exists {
+			row.fail("availability", "availability is required")
+		}
+		if s := csvCell(rec, header, "isNew"); s != "" {
+			if v, err := parseCSVBool(s); err == nil {
+				updates["is_new"] = v
+			} else {
+				row.fail("isNew", "isNew must be true or false")
+			}
+		}
+		if s := csvCell(rec, header, "newRank"); s != "" {
+			if v, err := strconv.Atoi(s); err == nil {
+				updates["new_rank"] = v
+			} else {
+				row.fail("newRank", "newRank must be an integer")
+			}
+		}
+		for col, dbCol := range map[string]string{
+			"coverImage":    "cover_image_url",
+			"coverImageKey": "cover_image_key",
+			"hoverImage":    "hover_image_url",
+			"hoverImageKey": "hover_image_key",
+		} {
+			if s := csvCell(rec, header, col); s != "" {
+				updates[dbCol] = s
+			}
+		}
+		if s := csvCell(rec, header, "detail"); s != "" {
+			if merged, err := model.MergeProductDetailWithTemplate(tpl, json.RawMessage(s)); err == nil {
+				updates["detail_json"] = merged
+			} else {
+				row.fail("detail", "detail must be a JSON object")
+			}
+		} else if !exists {
+			updates["detail_json"], _ = model.MergeProductDetailWithTemplate(tpl, nil)
+		}
+
+		if !exists {
+			slug, _ := updates["slug"].(string)
+			if slug == "" {
+				slug = "style-" + strings.ToLower(row.StyleNo)
+			}
+			row.Action = importCreate
+			row.create = model.Product{StyleNo: row.StyleNo, PriceMode: "negotiable"}
+			applyProductColumns(&row.create, updates)
+			row.create.Slug = slug
+			if validStyleNo {
+				rowSlugs[row] = slug
+			}
+			continue
+		}
+
+		row.ProductID = live.ID
+		row.live = live
+		row.updates = updates
+
+		// Compare against what the editor currently sees: the draft if there is one.
+		current := live
+		d, hasDraft := drafted[live.ID]
+		if hasDraft {
+			var err error
+			if current, err = draftWorkingCopy(live, &d); err != nil {
+				return nil, err
+			}
+		}
+		working := current
+		applyProductColumns(&working, updates)
+		rowSlugs[row] = working.Slug
+
+		changes, err := diffProducts(current, working)
+		switch {
+		case err != nil:
+			row.fail("detail", "invalid detail")
+		case changes.Detail.Empty() && len(changes.Fields) == 0:
+			row.Action = importUnchanged
+		case live.PublishedAt != nil || hasDraft:
+			row.Action = importDraft
+		default:
+			row.Action = importUpdate
+		}
+	}
+
+	if err := checkImportSlugs(ctx, db, rowSlugs); err != nil {
+		return nil, err
+	}
+	for i := range rows {
+		if len(rows[i].Errors) > 0 {
+			rows[i].Action = ""
+		}
+	}
+	return rows, nil
+}
+
+// checkImportSlugs reports slugs used twice in the file or by another product.
+func checkImportSlugs(ctx context.Context, db *gorm.DB, rowSlugs map[*productImportRow]string) error {
+	if len(rowSlugs) == 0 {
+		return nil
+	}
+	slugs := make([]string, 0, len(rowSlugs))
+	bySlug := map[string][]*productImportRow{}
+	for row, slug := range rowSlugs {
+		if len(bySlug[slug]) == 0 {
+			slugs = append(slugs, slug)
+		}
+		bySlug[slug] = append(bySlug[slug], row)
+	}
+
+	var owners []model.Product
+	if err := db.WithContext(ctx).Select("id", "slug", "style_no").Where("slug IN ?", slugs).Find(&owners).Error; err != nil {
+		return err
+	}
+	owner := map[string]model.Product{}
+	for _, p := range owners {
+		owner[p.Slug] = p
+	}
+
+	for slug, rows := range bySlug {
+		first := rows[0]
+		for _, r := range rows[1:] {
+			if r.Row < first.Row {
+				first = r
+			}
+		}
+		for _, r := range rows {
+			if r != first {
+				r.fail("slug", "slug %q is also used by row %d", slug, first.Row)
+			}
+			if p, ok := owner[slug]; ok && p.ID != r.ProductID {
+				r.fail("slug", "slug %q is already used by %s", slug, p.StyleNo)
+			}
+		}
+	}
+	return nil
+}
+
+func applyProductImportRow(ctx context.Context, tx *gorm.DB, row *productImportRow) error {
+	switch row.Action {
+	case importCreate:
+		p := row.create
+		if err := tx.Create(&p).Error; err != nil {
+			return err
+		}
+		row.ProductID = p.ID
+		if _, err := catalog.SyncVariants(ctx, tx, p.ID, p.DetailJSON); err != nil {
+			return err
+		}
+		_, err := media.SyncProductAssets(ctx, tx, p.ID)
+		return err
+	case importUpdate:
+		if err := catalog.SaveRevision(ctx, tx, row.live, "import"); err != nil {
+			return err
+		}
+		if err := tx.Model(&model.Product{}).
+			Where("id = ?", row.live.ID).
+			Where("deleted_at IS NULL").
+			Updates(row.updates).Error; err != nil {
+			return err
+		}
+		if detail, ok := row.updates["detail_json"].(json.RawMessage); ok {
+			if _, err := catalog.SyncVariants(ctx, tx, row.live.ID, detail); err != nil {
+				return err
+			}
+		}
+		_, err := media.SyncProductAssets(ctx, tx, row.live.ID)
+		return err
+	case importDraft:
+		_, _, err := stageProductDraft(ctx, tx, row.live, row.updates)
+		return err
+	}
+	return nil
+}
+
+func isBlankRecord(rec []string) bool {
+	for _, cell := range rec {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,37 @@
+package admin
+
+import (
+	"errors"
+	"time"
+)
+
+// scheduleRequest replaces the publish schedule of a product or update.
+// A null/missing field clears that side of the schedule.
+type scheduleRequest struct {
+	PublishAt   *time.Time `json:"publishAt"`
+	UnpublishAt *time.Time `json:"unpublishAt"`
+}
+
+// scheduleUpdates validates req and returns the columns to store.
+// The scheduler job (internal/scheduler) applies and clears them once due.
+func scheduleUpdates(req scheduleRequest, now time.Time) (map[string]any, error) {
+	updates := map[string]any{"publish_at": nil, "unpublish_at": nil}
+	if req.PublishAt != nil {
+		at := req.PublishAt.UTC()
+		if !at.After(now) {
+			return nil, errors.New("publishAt must be in the future")
+		}
+		updates["publish_at"] = at
+	}
+	if req.UnpublishAt != nil {
+		at := req.UnpublishAt.UTC()
+		if !at.After(now) {
+			return nil, errors.New("unpublishAt must be in the future")
+		}
+		if req.PublishAt != nil && !at.After(req.PublishAt.UTC()) {
+			return nil, errors.New("unpublishAt must be after publishAt")
+		}
+		updates["unpublish_at"] = at
+	}
+	return updates, nil
+}
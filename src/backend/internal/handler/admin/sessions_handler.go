@@ -0,0 +1,322 @@
+package admin
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"evening-gown/internal/auth"
+	"evening-gown/internal/logging"
+	"evening-gown/internal/middleware"
+	"evening-gown/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Server-side sessions for AuthHandler: issuing, rotating and revoking refresh token families.
+
+const maxSessionUserAgentLen = 512
+
+type tokenPair struct {
+	accessToken  string
+	accessExp    time.Time
+	refreshToken string
+	refreshExp   time.Time
+}
+
+func (p tokenPair) response() gin.H {
+	return gin.H{
+		"token":              p.accessToken,
+		"expires_at":         p.accessExp.UTC().Format(time.RFC3339),
+		"refresh_token":      p.refreshToken,
+		"refresh_expires_at": p.refreshExp.UTC().Format(time.RFC3339),
+	}
+}
+
+type sessionView struct {
+	model.Session
+	Current bool `json:"current"`
+}
+
+// issueTokens signs an access/refresh pair bound to sessionID. It writes the error response itself.
+func (h *AuthHandler) issueTokens(c *gin.Context, user model.User, sessionID, jti string) (tokenPair, bool) {
+	pwdAt := int64(0)
+	if user.PasswordUpdatedAt != nil {
+		pwdAt = user.PasswordUpdatedAt.UTC().Unix()
+	}
+	subject := strconv.FormatUint(uint64(user.ID), 10)
+
+	var (
+		pair tokenPair
+		err  error
+	)
+	pair.accessToken, pair.accessExp, err = h.jwtSvc.IssueAdminToken(subject, pwdAt, sessionID)
+	if err != nil {
+		logging.ErrorWithStack(logging.FromGin(c), "admin issue token failed", err, "user_id", user.ID)
+		respondIssueTokenFailed(c)
+		return tokenPair{}, false
+	}
+
+	pair.refreshToken, pair.refreshExp, err = h.jwtSvc.IssueAdminRefreshToken(subject, pwdAt, sessionID, jti)
+	if err != nil {
+		logging.ErrorWithStack(logging.FromGin(c), "admin issue refresh token failed", err, "user_id", user.ID)
+		respondIssueTokenFailed(c)
+		return tokenPair{}, false
+	}
+
+	return pair, true
+}
+
+// startSession creates a new session for user and responds with its token pair.
+func (h *AuthHandler) startSession(c *gin.Context, user model.User) {
+	sessionID, err := auth.NewTokenID()
+	if err != nil {
+		logging.ErrorWithStack(logging.FromGin(c), "admin generate session id failed", err, "user_id", user.ID)
+		respondIssueTokenFailed(c)
+		return
+	}
+	jti, err := auth.NewTokenID()
+	if err != nil {
+		logging.ErrorWithStack(logging.FromGin(c), "admin generate jti failed", err, "user_id", user.ID)
+		respondIssueTokenFailed(c)
+		return
+	}
+
+	pair, ok := h.issueTokens(c, user, sessionID, jti)
+	if !ok {
+		return
+	}
+
+	now := time.Now().UTC()
+	session := model.Session{
+		ID:         sessionID,
+		UserID:     user.ID,
+		CurrentJTI: jti,
+		UserAgent:  clientUserAgent(c),
+		IP:         c.ClientIP(),
+		LastUsedAt: now,
+		ExpiresAt:  pair.refreshExp.UTC(),
+	}
+	if err := h.db.WithContext(c.Request.Context()).Create(&session).Error; err != nil {
+		logging.ErrorWithStack(logging.FromGin(c), "admin create session failed", err, "user_id", user.ID)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    "create_session_failed",
+			"message": "create session failed",
+			"error":   "create session failed",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, pair.response())
+}
+
+// rotateSession exchanges the session's current refresh token for a new pair.
+//
+// Presenting any other jti of the family means the token was replayed (stolen or leaked):
+// the whole session is revoked so neither party can keep using it.
+func (h *AuthHandler) rotateSession(c *gin.Context, user model.User, claims *auth.AdminClaims) {
+	ctx := c.Request.Context()
+	now := time.Now().UTC()
+
+	var session model.Session
+	if err := h.db.WithContext(ctx).Where("id = ? AND user_id = ?", claims.SessionID, user.ID).First(&session).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"code":    "unauthorized",
+			"message": "unauthorized",
+			"error":   "unauthorized",
+		})
+		return
+	}
+	if !session.Active(now) {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"code":    "session_revoked",
+			"message": "session revoked",
+			"error":   "session revoked",
+		})
+		return
+	}
+	if strings.TrimSpace(claims.ID) == "" || claims.ID != session.CurrentJTI {
+		h.revokeReusedSession(c, session)
+		return
+	}
+
+	jti, err := auth.NewTokenID()
+	if err != nil {
+		logging.ErrorWithStack(logging.FromGin(c), "admin generate jti failed", err, "user_id", user.ID)
+		respondIssueTokenFailed(c)
+		return
+	}
+	pair, ok := h.issueTokens(c, user, session.ID, jti)
+	if !ok {
+		return
+	}
+
+	updates := map[string]any{
+		"current_jti":  jti,
+		"ip":           c.ClientIP(),
+		"last_used_at": now,
+		"expires_at":   pair.refreshExp.UTC(),
+		"updated_at":   now,
+	}
+	if ua := clientUserAgent(c); ua != "" {
+		updates["user_agent"] = ua
+	}
+	// Conditional on the old jti: of two concurrent exchanges of the same token only one wins.
+	res := h.db.WithContext(ctx).Model(&model.Session{}).
+		Where("id = ? AND current_jti = ? AND revoked_at IS NULL", session.ID, claims.ID).
+		Updates(updates)
+	if res.Error != nil {
+		logging.ErrorWithStack(logging.FromGin(c), "admin rotate session failed", res.Error, "user_id", user.ID, "session_id", session.ID)
+		respondIssueTokenFailed(c)
+		return
+	}
+	if res.RowsAffected == 0 {
+		h.revokeReusedSession(c, session)
+		return
+	}
+
+	c.JSON(http.StatusOK, pair.response())
+}
+
+func (h *AuthHandler) revokeReusedSession(c *gin.Context, session model.Session) {
+	logging.FromGin(c).Warn("admin refresh token reuse detected", "user_id", session.UserID, "session_id", session.ID)
+	if _, err := h.revokeSessions(c.Request.Context(), model.SessionRevokedRefreshReused, "id = ?", session.ID); err != nil {
+		logging.ErrorWithStack(logging.FromGin(c), "admin revoke reused session failed", err, "session_id", session.ID)
+	}
+	c.JSON(http.StatusUnauthorized, gin.H{
+		"code":    "refresh_token_reused",
+		"message": "refresh token reused",
+		"error":   "refresh token reused",
+	})
+}
+
+// revokeSessions marks all active sessions matching query as revoked.
+func (h *AuthHandler) revokeSessions(ctx context.Context, reason string, query string, args ...any) (int64, error) {
+	now := time.Now().UTC()
+	res := h.db.WithContext(ctx).Model(&model.Session{}).
+		Where("revoked_at IS NULL").
+		Where(query, args...).
+		Updates(map[string]any{
+			"revoked_at":     now,
+			"revoked_reason": reason,
+			"updated_at":     now,
+		})
+	return res.RowsAffected, res.Error
+}
+
+// ListSessions lists the caller's active sessions (devices).
+// Route: GET /api/v1/admin/me/sessions
+func (h *AuthHandler) ListSessions(c *gin.Context) {
+	if h == nil || h.db == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "service unavailable"})
+		return
+	}
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"code":    "unauthorized",
+			"message": "unauthorized",
+			"error":   "unauthorized",
+		})
+		return
+	}
+
+	var sessions []model.Session
+	if err := h.db.WithContext(c.Request.Context()).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", user.ID, time.Now().UTC()).
+		Order("last_used_at desc").
+		Find(&sessions).Error; err != nil {
+		logging.ErrorWithStack(logging.FromGin(c), "admin sessions query list failed", err, "user_id", user.ID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return
+	}
+
+	current := middleware.CurrentSessionID(c)
+	items := make([]sessionView, 0, len(sessions))
+	for _, s := range sessions {
+		items = append(items, sessionView{Session: s, Current: s.ID == current})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"total": len(items), "items": items})
+}
+
+// RevokeOtherSessions signs out every other device of the caller.
+// Route: DELETE /api/v1/admin/me/sessions
+func (h *AuthHandler) RevokeOtherSessions(c *gin.Context) {
+	if h == nil || h.db == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "service unavailable"})
+		return
+	}
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"code":    "unauthorized",
+			"message": "unauthorized",
+			"error":   "unauthorized",
+		})
+		return
+	}
+
+	n, err := h.revokeSessions(c.Request.Context(), model.SessionRevokedByUser, "user_id = ? AND id <> ?", user.ID, middleware.CurrentSessionID(c))
+	if err != nil {
+		logging.ErrorWithStack(logging.FromGin(c), "admin revoke sessions failed", err, "user_id", user.ID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "revoke failed"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"revoked": n})
+}
+
+// RevokeSession signs out one session of the caller (including the current one, i.e. logout).
+// Route: DELETE /api/v1/admin/me/sessions/:id
+func (h *AuthHandler) RevokeSession(c *gin.Context) {
+	if h == nil || h.db == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "service unavailable"})
+		return
+	}
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"code":    "unauthorized",
+			"message": "unauthorized",
+			"error":   "unauthorized",
+		})
+		return
+	}
+
+	id := strings.TrimSpace(c.Param("id"))
+	reason := model.SessionRevokedByUser
+	if id == middleware.CurrentSessionID(c) {
+		reason = model.SessionRevokedLogout
+	}
+	n, err := h.revokeSessions(c.Request.Context(), reason, "id = ? AND user_id = ?", id, user.ID)
+	if err != nil {
+		logging.ErrorWithStack(logging.FromGin(c), "admin revoke session failed", err, "user_id", user.ID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "revoke failed"})
+		return
+	}
+	if n == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func respondIssueTokenFailed(c *gin.Context) {
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"code":    "issue_token_failed",
+		"message": "issue token failed",
+		"error":   "issue token failed",
+	})
+}
+
+func clientUserAgent(c *gin.Context) string {
+	ua := strings.TrimSpace(c.Request.UserAgent())
+	if len(ua) > maxSessionUserAgentLen {
+		ua = ua[:maxSessionUserAgentLen]
+	}
+	return ua
+}
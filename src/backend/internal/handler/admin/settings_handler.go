@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"net/http"
 
+	"evening-gown/internal/audit"
 	"evening-gown/internal/model"
 
 	"github.com/gin-gonic/gin"
@@ -90,11 +91,18 @@ func (h *SettingsHandler) PutProductDetailTemplate(c *gin.Context) {
 		return
 	}
 
+	var before *model.AppSetting
+	var existing model.AppSetting
+	if err := h.db.WithContext(c.Request.Context()).Where("key = ?", model.SettingKeyProductDetailTemplate).First(&existing).Error; err == nil {
+		before = &existing
+	}
+
 	set := model.AppSetting{Key: model.SettingKeyProductDetailTemplate, ValueJSON: req.Value}
 	if err := h.db.WithContext(c.Request.Context()).Save(&set).Error; err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	recordAudit(c, h.db, audit.Entry{Action: "setting.update", EntityType: "settings", EntityID: set.Key, Before: before, After: set})
 
 	c.JSON(http.StatusOK, productDetailTemplateResponse{Key: set.Key, Value: set.ValueJSON})
 }
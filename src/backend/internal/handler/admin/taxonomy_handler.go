@@ -0,0 +1,298 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"evening-gown/internal/audit"
+	"evening-gown/internal/cache"
+	"evening-gown/internal/logging"
+	"evening-gown/internal/model"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var errUnknownTaxonomyTerm = errors.New("unknown or inactive term")
+
+// TaxonomyHandler manages the season and category terms products may use.
+type TaxonomyHandler struct {
+	db    *gorm.DB
+	cache *cache.PublicCache
+}
+
+func NewTaxonomyHandler(db *gorm.DB, publicCache *cache.PublicCache) *TaxonomyHandler {
+	return &TaxonomyHandler{db: db, cache: publicCache}
+}
+
+type taxonomyCreateRequest struct {
+	Kind      string          `json:"kind" binding:"required"`
+	Code      string          `json:"code" binding:"required"`
+	LabelI18n json.RawMessage `json:"labelI18n"`
+	SortOrder int             `json:"sortOrder"`
+	// Active defaults to true.
+	Active *bool `json:"active"`
+}
+
+// Codes are immutable: products reference them. Create a new term and move products instead.
+type taxonomyUpdateRequest struct {
+	LabelI18n *json.RawMessage `json:"labelI18n"`
+	SortOrder *int             `json:"sortOrder"`
+	Active    *bool            `json:"active"`
+}
+
+// List returns all terms (inactive included) in menu order.
+// Query: ?kind=season|category
+// Route: GET /api/v1/admin/taxonomy
+func (h *TaxonomyHandler) List(c *gin.Context) {
+	if h == nil || h.db == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "service unavailable"})
+		return
+	}
+
+	q := h.db.WithContext(c.Request.Context()).Model(&model.TaxonomyTerm{})
+	if kind := strings.TrimSpace(c.Query("kind")); kind != "" {
+		if !model.IsValidTaxonomyKind(kind) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid kind"})
+			return
+		}
+		q = q.Where("kind = ?", kind)
+	}
+
+	var terms []model.TaxonomyTerm
+	if err := q.Order("kind asc, sort_order asc, code asc").Find(&terms).Error; err != nil {
+		logging.ErrorWithStack(logging.FromGin(c), "admin taxonomy query list failed", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"total": len(terms), "items": terms})
+}
+
+// Create adds a term. 409 when the code already exists for the kind.
+// Route: POST /api/v1/admin/taxonomy
+func (h *TaxonomyHandler) Create(c *gin.Context) {
+	if h == nil || h.db == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "service unavailable"})
+		return
+	}
+
+	var req taxonomyCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	kind := strings.TrimSpace(req.Kind)
+	if !model.IsValidTaxonomyKind(kind) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid kind"})
+		return
+	}
+	code, ok := model.NormalizeTaxonomyCode(kind, req.Code)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid code"})
+		return
+	}
+	labels, err := model.NormalizeTaxonomyLabels(req.LabelI18n)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	var n int64
+	if err := h.db.WithContext(ctx).Model(&model.TaxonomyTerm{}).Where("kind = ? AND code = ?", kind, code).Count(&n).Error; err != nil {
+		logging.ErrorWithStack(logging.FromGin(c), "admin taxonomy query failed", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return
+	}
+	if n > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "code already exists"})
+		return
+	}
+
+	term := model.TaxonomyTerm{
+		Kind:      kind,
+		Code:      code,
+		LabelI18n: labels,
+		SortOrder: req.SortOrder,
+		Active:    req.Active == nil || *req.Active,
+	}
+	if err := h.db.WithContext(ctx).Create(&term).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	recordAudit(c, h.db, audit.Entry{Action: "taxonomy.create", EntityType: "taxonomy_terms", EntityID: auditID(term.ID), After: term})
+	h.bumpCache(ctx)
+
+	c.JSON(http.StatusCreated, term)
+}
+
+// Update changes labels, order or the active flag.
+// Route: PATCH /api/v1/admin/taxonomy/:id
+func (h *TaxonomyHandler) Update(c *gin.Context) {
+	if h == nil || h.db == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "service unavailable"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	var before model.TaxonomyTerm
+	if err := h.db.WithContext(ctx).First(&before, uint(id)).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+		return
+	}
+
+	var req taxonomyUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updates := map[string]any{}
+	if req.LabelI18n != nil {
+		labels, err := model.NormalizeTaxonomyLabels(*req.LabelI18n)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		updates["label_i18n"] = labels
+	}
+	if req.SortOrder != nil {
+		updates["sort_order"] = *req.SortOrder
+	}
+	if req.Active != nil {
+		updates["active"] = *req.Active
+	}
+	if len(updates) == 0 {
+		c.JSON(http.StatusOK, before)
+		return
+	}
+
+	if err := h.db.WithContext(ctx).Model(&model.TaxonomyTerm{}).Where("id = ?", before.ID).Updates(updates).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var term model.TaxonomyTerm
+	if err := h.db.WithContext(ctx).First(&term, before.ID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+		return
+	}
+	recordAudit(c, h.db, audit.Entry{Action: "taxonomy.update", EntityType: "taxonomy_terms", EntityID: auditID(term.ID), Before: before, After: term})
+	h.bumpCache(ctx)
+
+	c.JSON(http.StatusOK, term)
+}
+
+// Delete removes a term no product uses (trashed products included, since they can be restored).
+// Terms in use answer 409; deactivate them instead.
+// Route: DELETE /api/v1/admin/taxonomy/:id
+func (h *TaxonomyHandler) Delete(c *gin.Context) {
+	if h == nil || h.db == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "service unavailable"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	var before model.TaxonomyTerm
+	if err := h.db.WithContext(ctx).First(&before, uint(id)).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+		return
+	}
+
+	column, ok := model.TaxonomyColumn(before.Kind)
+	if !ok {
+		c.JSON(http.StatusConflict, gin.H{"error": "unknown taxonomy kind", "kind": before.Kind})
+		return
+	}
+	var used int64
+	if err := h.db.WithContext(ctx).Model(&model.Product{}).Where(column+" = ?", before.Code).Count(&used).Error; err != nil {
+		logging.ErrorWithStack(logging.FromGin(c), "admin taxonomy query usage failed", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return
+	}
+	if used > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "term is used by products; deactivate it instead", "products": used})
+		return
+	}
+
+	if err := h.db.WithContext(ctx).Delete(&model.TaxonomyTerm{}, before.ID).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	recordAudit(c, h.db, audit.Entry{Action: "taxonomy.delete", EntityType: "taxonomy_terms", EntityID: auditID(before.ID), Before: before})
+	h.bumpCache(ctx)
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *TaxonomyHandler) bumpCache(ctx context.Context) {
+	if h.cache != nil {
+		_, _ = h.cache.BumpTaxonomyVersion(ctx)
+	}
+}
+
+// activeTaxonomyCode resolves raw to the code of an active term of kind.
+func activeTaxonomyCode(ctx context.Context, db *gorm.DB, kind, raw string) (string, error) {
+	code, ok := model.NormalizeTaxonomyCode(kind, raw)
+	if !ok {
+		return "", errUnknownTaxonomyTerm
+	}
+	var n int64
+	if err := db.WithContext(ctx).Model(&model.TaxonomyTerm{}).
+		Where("kind = ? AND code = ? AND active = ?", kind, code, true).
+		Count(&n).Error; err != nil {
+		return "", err
+	}
+	if n == 0 {
+		return "", errUnknownTaxonomyTerm
+	}
+	return code, nil
+}
+
+// activeTaxonomyCodes returns the active codes per kind.
+func activeTaxonomyCodes(ctx context.Context, db *gorm.DB) (map[string]map[string]bool, error) {
+	var terms []model.TaxonomyTerm
+	if err := db.WithContext(ctx).Where("active = ?", true).Find(&terms).Error; err != nil {
+		return nil, err
+	}
+	out := map[string]map[string]bool{}
+	for _, t := range terms {
+		if out[t.Kind] == nil {
+			out[t.Kind] = map[string]bool{}
+		}
+		out[t.Kind][t.Code] = true
+	}
+	return out, nil
+}
+
+// resolveTaxonomy validates a product's season/category value, answering 400 (or 500) itself.
+func resolveTaxonomy(c *gin.Context, db *gorm.DB, kind, raw string) (string, bool) {
+	code, err := activeTaxonomyCode(c.Request.Context(), db, kind, raw)
+	if errors.Is(err, errUnknownTaxonomyTerm) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + kind})
+		return "", false
+	}
+	if err != nil {
+		logging.ErrorWithStack(logging.FromGin(c), "admin taxonomy lookup failed", err, "kind", kind)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return "", false
+	}
+	return code, true
+}
@@ -0,0 +1,242 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"evening-gown/internal/audit"
+	"evening-gown/internal/cache"
+	"evening-gown/internal/logging"
+	"evening-gown/internal/media"
+	"evening-gown/internal/middleware"
+	"evening-gown/internal/model"
+	"evening-gown/internal/pagination"
+	"evening-gown/internal/trash"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// trashPermissions is the scope needed to see, restore or purge each type of deleted record:
+// the same one that allows deleting it.
+var trashPermissions = map[string]string{
+	trash.Products: model.PermProductsWrite,
+	trash.Updates:  model.PermUpdatesWrite,
+	trash.Users:    model.PermUsersManage,
+	trash.Contacts: model.PermContactsWrite,
+	trash.Events:   model.PermEventsWrite,
+}
+
+// TrashHandler exposes soft-deleted records across entity types.
+type TrashHandler struct {
+	db       *gorm.DB
+	cache    *cache.PublicCache
+	contacts *ContactsHandler
+	purger   *trash.Purger
+}
+
+// NewTrashHandler builds the handler. rdb keeps the unread-leads counter in sync on restore;
+// purger performs hard deletes (and carries the retention shown in listings).
+func NewTrashHandler(db *gorm.DB, publicCache *cache.PublicCache, rdb *redis.Client, purger *trash.Purger) *TrashHandler {
+	return &TrashHandler{
+		db:       db,
+		cache:    publicCache,
+		contacts: NewContactsHandlerWithRedis(db, rdb),
+		purger:   purger,
+	}
+}
+
+// List returns deleted records the caller may manage, most recently deleted first.
+// Query: ?type=products|updates|users|contacts|events (default: all allowed types).
+// Route: GET /api/v1/admin/trash
+func (h *TrashHandler) List(c *gin.Context) {
+	if h == nil || h.db == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "service unavailable"})
+		return
+	}
+
+	types := allowedTrashTypes(c)
+	if t := strings.TrimSpace(c.Query("type")); t != "" {
+		if !trash.IsValidType(t) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid type"})
+			return
+		}
+		if !trashAllowed(c, t) {
+			respondForbidden(c)
+			return
+		}
+		types = []string{t}
+	}
+
+	page := pagination.ParseParams(c.Request.URL.Query(), 50, 200)
+
+	items, total, err := trash.List(c.Request.Context(), h.db, types, h.purger.Retention(), page.Limit, page.Offset)
+	if err != nil {
+		logging.ErrorWithStack(logging.FromGin(c), "admin trash query list failed", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"total": total, "items": items})
+}
+
+// Restore brings a deleted record back. 409 when its slug/styleNo (or email) was taken meanwhile.
+// Route: POST /api/v1/admin/trash/:type/:id/restore
+func (h *TrashHandler) Restore(c *gin.Context) {
+	if h == nil || h.db == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "service unavailable"})
+		return
+	}
+
+	t, id, ok := trashTarget(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	before, err := trash.Load(ctx, h.db, t, id)
+	if err == nil {
+		err = trash.Restore(ctx, h.db, t, id)
+	}
+	if errors.Is(err, trash.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+		return
+	}
+	if errors.Is(err, trash.ErrConflict) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		logging.ErrorWithStack(logging.FromGin(c), "admin trash restore failed", err, "type", t, "id", id)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "restore failed"})
+		return
+	}
+
+	after := h.reload(ctx, t, id)
+	recordAudit(c, h.db, audit.Entry{
+		Action:     trash.AuditAction(t, "undelete"),
+		EntityType: t,
+		EntityID:   auditID(id),
+		Before:     before,
+		After:      after,
+	})
+
+	switch v := before.(type) {
+	case *model.Product:
+		if v.PublishedAt != nil && h.cache != nil {
+			_, _ = h.cache.BumpProductsVersion(ctx)
+			media.BumpAssetsCache(ctx, h.db, h.cache, v.ID)
+		}
+	case *model.UpdatePost:
+		if h.cache != nil && strings.TrimSpace(v.Type) == "company" && strings.TrimSpace(v.Status) == "published" {
+			_, _ = h.cache.BumpUpdatesVersion(ctx)
+		}
+	case *model.ContactLead:
+		if strings.TrimSpace(v.Status) == "new" {
+			if err := h.contacts.applyNewLeadsDelta(ctx, 1); err != nil {
+				logging.ErrorWithStack(logging.FromGin(c), "admin contacts unread-count delta failed", err)
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, after)
+}
+
+// Purge permanently deletes a record that is already in the trash.
+// Route: DELETE /api/v1/admin/trash/:type/:id
+func (h *TrashHandler) Purge(c *gin.Context) {
+	if h == nil || h.db == nil || h.purger == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "service unavailable"})
+		return
+	}
+
+	t, id, ok := trashTarget(c)
+	if !ok {
+		return
+	}
+
+	before, err := h.purger.Purge(c.Request.Context(), t, id)
+	if errors.Is(err, trash.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+		return
+	}
+	if err != nil {
+		logging.ErrorWithStack(logging.FromGin(c), "admin trash purge failed", err, "type", t, "id", id)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "purge failed"})
+		return
+	}
+	recordAudit(c, h.db, audit.Entry{
+		Action:     trash.AuditAction(t, "purge"),
+		EntityType: t,
+		EntityID:   auditID(id),
+		Before:     before,
+	})
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *TrashHandler) reload(ctx context.Context, t string, id uint) any {
+	switch t {
+	case trash.Products:
+		return reloadForAudit[model.Product](ctx, h.db, id)
+	case trash.Updates:
+		return reloadForAudit[model.UpdatePost](ctx, h.db, id)
+	case trash.Users:
+		return reloadForAudit[model.User](ctx, h.db, id)
+	case trash.Contacts:
+		return reloadForAudit[model.ContactLead](ctx, h.db, id)
+	case trash.Events:
+		return reloadForAudit[model.Event](ctx, h.db, id)
+	}
+	return nil
+}
+
+// trashTarget parses :type/:id and checks the caller's permission for the type.
+func trashTarget(c *gin.Context) (string, uint, bool) {
+	t := c.Param("type")
+	if !trash.IsValidType(t) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+		return "", 0, false
+	}
+	if !trashAllowed(c, t) {
+		respondForbidden(c)
+		return "", 0, false
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return "", 0, false
+	}
+	return t, uint(id), true
+}
+
+// trashAllowed checks the per-type scope. Without an authenticated user (auth disabled) all are allowed.
+func trashAllowed(c *gin.Context, t string) bool {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		return true
+	}
+	return model.HasPermission(user.Role, trashPermissions[t])
+}
+
+func allowedTrashTypes(c *gin.Context) []string {
+	var out []string
+	for _, t := range trash.Types() {
+		if trashAllowed(c, t) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+func respondForbidden(c *gin.Context) {
+	c.JSON(http.StatusForbidden, gin.H{
+		"code":    "forbidden",
+		"message": "forbidden",
+		"error":   "forbidden",
+	})
+}
@@ -0,0 +1,449 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"evening-gown/internal/logging"
+	"evening-gown/internal/middleware"
+	"evening-gown/internal/model"
+	"evening-gown/internal/security"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// TOTP two-factor authentication for AuthHandler: enrollment under /me/2fa and the
+// second login step under /auth/login/2fa.
+
+const (
+	defaultTOTPIssuer    = "Evening Gown"
+	defaultMFAPendingTTL = 5 * time.Minute
+	recoveryCodeCount    = 10
+)
+
+type loginTwoFactorRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	// Code is either a 6-digit TOTP code or a recovery code.
+	Code string `json:"code" binding:"required"`
+}
+
+type twoFactorBeginRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+type twoFactorVerifyRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type twoFactorDisableRequest struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+// requireSecondFactor answers the password step of a 2FA login with a short-lived mfa_pending token.
+func (h *AuthHandler) requireSecondFactor(c *gin.Context, user model.User) {
+	pwdAt := int64(0)
+	if user.PasswordUpdatedAt != nil {
+		pwdAt = user.PasswordUpdatedAt.UTC().Unix()
+	}
+
+	token, exp, err := h.jwtSvc.IssueAdminMFAPendingToken(strconv.FormatUint(uint64(user.ID), 10), pwdAt, h.mfaPendingTTL)
+	if err != nil {
+		logging.ErrorWithStack(logging.FromGin(c), "admin issue mfa token failed", err, "user_id", user.ID)
+		respondIssueTokenFailed(c)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"mfa_required":   true,
+		"mfa_token":      token,
+		"mfa_expires_at": exp.UTC().Format(time.RFC3339),
+	})
+}
+
+// LoginTwoFactor completes a login with a TOTP (or recovery) code.
+// Route: POST /api/v1/admin/auth/login/2fa
+func (h *AuthHandler) LoginTwoFactor(c *gin.Context) {
+	if h == nil || h.db == nil || h.jwtSvc == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"code":    "service_unavailable",
+			"message": "service unavailable",
+			"error":   "service unavailable",
+		})
+		return
+	}
+
+	var req loginTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	unauthorized := func() {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"code":    "unauthorized",
+			"message": "unauthorized",
+			"error":   "unauthorized",
+		})
+	}
+
+	claims, err := h.jwtSvc.ParseAdminMFAPendingToken(req.MFAToken)
+	if err != nil {
+		unauthorized()
+		return
+	}
+	uid, err := strconv.ParseUint(strings.TrimSpace(claims.Subject), 10, 64)
+	if err != nil || uid == 0 {
+		unauthorized()
+		return
+	}
+
+	var user model.User
+	if err := h.db.WithContext(c.Request.Context()).Where("id = ? AND deleted_at IS NULL", uint(uid)).First(&user).Error; err != nil {
+		unauthorized()
+		return
+	}
+	if !model.IsValidRole(user.Role) || user.Status != "active" || !user.TwoFactorEnabled() {
+		unauthorized()
+		return
+	}
+	// A password change between the two steps invalidates the pending login.
+	dbPwdAt := int64(0)
+	if user.PasswordUpdatedAt != nil {
+		dbPwdAt = user.PasswordUpdatedAt.UTC().Unix()
+	}
+	if claims.PasswordUpdatedAt != dbPwdAt {
+		unauthorized()
+		return
+	}
+	if user.LockedUntil != nil && user.LockedUntil.After(time.Now()) {
+		respondAccountLocked(c, *user.LockedUntil)
+		return
+	}
+
+	ok, err := h.verifySecondFactor(c.Request.Context(), user, req.Code)
+	if err != nil {
+		logging.ErrorWithStack(logging.FromGin(c), "admin verify 2fa failed", err, "user_id", user.ID)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    "verify_failed",
+			"message": "verify failed",
+			"error":   "verify failed",
+		})
+		return
+	}
+	if !ok {
+		// Wrong codes count towards the same lockout as wrong passwords.
+		if lockedUntil, locked := h.recordFailedLogin(c, user.ID); locked {
+			respondAccountLocked(c, lockedUntil)
+			return
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"code":    "invalid_mfa_code",
+			"message": "invalid code",
+			"error":   "invalid code",
+		})
+		return
+	}
+
+	h.completeLogin(c, user)
+}
+
+// TwoFactorStatus reports the caller's 2FA state.
+// Route: GET /api/v1/admin/me/2fa
+func (h *AuthHandler) TwoFactorStatus(c *gin.Context) {
+	user, ok := h.requireMe(c)
+	if !ok {
+		return
+	}
+
+	var remaining int64
+	if err := h.db.WithContext(c.Request.Context()).Model(&model.RecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", user.ID).
+		Count(&remaining).Error; err != nil {
+		logging.ErrorWithStack(logging.FromGin(c), "admin 2fa count recovery codes failed", err, "user_id", user.ID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"enabled":                user.TwoFactorEnabled(),
+		"enabledAt":              user.TOTPEnabledAt,
+		"pending":                user.TOTPPendingSecret != "",
+		"recoveryCodesRemaining": remaining,
+	})
+}
+
+// BeginTwoFactor starts enrollment: it stores a pending secret and returns the provisioning URI.
+// The password is re-checked so a hijacked access token cannot enroll its own authenticator.
+// Route: POST /api/v1/admin/me/2fa
+func (h *AuthHandler) BeginTwoFactor(c *gin.Context) {
+	user, ok := h.requireMe(c)
+	if !ok {
+		return
+	}
+
+	var req twoFactorBeginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !security.CheckPassword(user.PasswordHash, req.Password) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "password is incorrect"})
+		return
+	}
+	if user.TwoFactorEnabled() {
+		c.JSON(http.StatusConflict, gin.H{"error": "two-factor authentication already enabled"})
+		return
+	}
+
+	secret, err := security.GenerateTOTPSecret()
+	if err != nil {
+		logging.ErrorWithStack(logging.FromGin(c), "admin 2fa generate secret failed", err, "user_id", user.ID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "enroll failed"})
+		return
+	}
+	sealed, err := h.totpKeys.Seal(secret)
+	if err != nil {
+		logging.ErrorWithStack(logging.FromGin(c), "admin 2fa seal secret failed", err, "user_id", user.ID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "enroll failed"})
+		return
+	}
+	if err := h.db.WithContext(c.Request.Context()).Model(&model.User{}).Where("id = ?", user.ID).Updates(map[string]any{
+		"totp_pending_secret": sealed,
+		"updated_at":          time.Now().UTC(),
+	}).Error; err != nil {
+		logging.ErrorWithStack(logging.FromGin(c), "admin 2fa save pending secret failed", err, "user_id", user.ID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "enroll failed"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"secret":      secret,
+		"otpauth_uri": security.TOTPProvisioningURI(h.totpIssuer, user.Email, secret),
+	})
+}
+
+// ConfirmTwoFactor activates the pending secret once the user proves their authenticator works.
+// The recovery codes are returned only in this response.
+// Route: POST /api/v1/admin/me/2fa/verify
+func (h *AuthHandler) ConfirmTwoFactor(c *gin.Context) {
+	user, ok := h.requireMe(c)
+	if !ok {
+		return
+	}
+
+	var req twoFactorVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if user.TOTPPendingSecret == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "no pending enrollment"})
+		return
+	}
+	pending, err := h.totpKeys.Open(user.TOTPPendingSecret)
+	if err != nil {
+		logging.ErrorWithStack(logging.FromGin(c), "admin 2fa open pending secret failed", err, "user_id", user.ID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "enroll failed"})
+		return
+	}
+	step, valid := security.ValidateTOTP(pending, req.Code, time.Now())
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid code"})
+		return
+	}
+
+	codes, err := security.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		logging.ErrorWithStack(logging.FromGin(c), "admin 2fa generate recovery codes failed", err, "user_id", user.ID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "enroll failed"})
+		return
+	}
+	rows := make([]model.RecoveryCode, 0, len(codes))
+	for _, code := range codes {
+		hash, err := security.HashPassword(code)
+		if err != nil {
+			logging.ErrorWithStack(logging.FromGin(c), "admin 2fa hash recovery code failed", err, "user_id", user.ID)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "enroll failed"})
+			return
+		}
+		rows = append(rows, model.RecoveryCode{UserID: user.ID, CodeHash: hash})
+	}
+	// Sealed again so that a pending secret stored before AUTH_TOTP_KEY was set is not kept in plaintext.
+	secret, err := h.totpKeys.Seal(pending)
+	if err != nil {
+		logging.ErrorWithStack(logging.FromGin(c), "admin 2fa seal secret failed", err, "user_id", user.ID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "enroll failed"})
+		return
+	}
+
+	now := time.Now().UTC()
+	err = h.db.WithContext(c.Request.Context()).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&model.User{}).
+			Where("id = ? AND totp_pending_secret = ?", user.ID, user.TOTPPendingSecret).
+			Updates(map[string]any{
+				"totp_secret":         secret,
+				"totp_pending_secret": "",
+				"totp_enabled_at":     now,
+				"totp_last_used_step": step,
+				"updated_at":          now,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return errPendingSecretChanged
+		}
+		if err := tx.Where("user_id = ?", user.ID).Delete(&model.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&rows).Error
+	})
+	if errors.Is(err, errPendingSecretChanged) {
+		c.JSON(http.StatusConflict, gin.H{"error": "enrollment changed, start again"})
+		return
+	}
+	if err != nil {
+		logging.ErrorWithStack(logging.FromGin(c), "admin 2fa enable failed", err, "user_id", user.ID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "enroll failed"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"enabled":        true,
+		"recovery_codes": codes,
+	})
+}
+
+// DisableTwoFactor turns 2FA off after re-checking the password and a current code.
+// Route: DELETE /api/v1/admin/me/2fa
+func (h *AuthHandler) DisableTwoFactor(c *gin.Context) {
+	user, ok := h.requireMe(c)
+	if !ok {
+		return
+	}
+
+	var req twoFactorDisableRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !user.TwoFactorEnabled() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "two-factor authentication not enabled"})
+		return
+	}
+	if !security.CheckPassword(user.PasswordHash, req.Password) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "password is incorrect"})
+		return
+	}
+	valid, err := h.verifySecondFactor(c.Request.Context(), user, req.Code)
+	if err != nil {
+		logging.ErrorWithStack(logging.FromGin(c), "admin verify 2fa failed", err, "user_id", user.ID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "verify failed"})
+		return
+	}
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid code"})
+		return
+	}
+
+	now := time.Now().UTC()
+	err = h.db.WithContext(c.Request.Context()).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.User{}).Where("id = ?", user.ID).Updates(map[string]any{
+			"totp_secret":         "",
+			"totp_pending_secret": "",
+			"totp_enabled_at":     nil,
+			"totp_last_used_step": 0,
+			"updated_at":          now,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", user.ID).Delete(&model.RecoveryCode{}).Error
+	})
+	if err != nil {
+		logging.ErrorWithStack(logging.FromGin(c), "admin 2fa disable failed", err, "user_id", user.ID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "disable failed"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"enabled": false})
+}
+
+var errPendingSecretChanged = errors.New("pending totp secret changed")
+
+// verifySecondFactor accepts a TOTP code (each time step only once) or an unused recovery code.
+func (h *AuthHandler) verifySecondFactor(ctx context.Context, user model.User, code string) (bool, error) {
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return false, nil
+	}
+
+	secret, openErr := h.totpKeys.Open(user.TOTPSecret)
+	if step, ok := security.ValidateTOTP(secret, code, time.Now()); openErr == nil && ok {
+		updates := map[string]any{"totp_last_used_step": step}
+		// Secrets enrolled before AUTH_TOTP_KEY was set are encrypted on their next use.
+		if h.totpKeys != nil && !security.IsSealed(user.TOTPSecret) {
+			sealed, err := h.totpKeys.Seal(secret)
+			if err != nil {
+				return false, err
+			}
+			updates["totp_secret"] = sealed
+		}
+		// Conditional update: a code (time step) can be used once, even under concurrency.
+		res := h.db.WithContext(ctx).Model(&model.User{}).
+			Where("id = ? AND totp_last_used_step < ?", user.ID, step).
+			Updates(updates)
+		if res.Error != nil {
+			return false, res.Error
+		}
+		return res.RowsAffected == 1, nil
+	}
+
+	normalized := security.NormalizeRecoveryCode(code)
+	var codes []model.RecoveryCode
+	if err := h.db.WithContext(ctx).Where("user_id = ? AND used_at IS NULL", user.ID).Find(&codes).Error; err != nil {
+		return false, err
+	}
+	for _, rc := range codes {
+		if !security.CheckPassword(rc.CodeHash, normalized) {
+			continue
+		}
+		res := h.db.WithContext(ctx).Model(&model.RecoveryCode{}).
+			Where("id = ? AND used_at IS NULL", rc.ID).
+			Update("used_at", time.Now().UTC())
+		if res.Error != nil {
+			return false, res.Error
+		}
+		return res.RowsAffected == 1, nil
+	}
+	// An undecryptable secret (AUTH_TOTP_KEY missing or changed) leaves only recovery codes
+	// working; report it instead of a wrong code.
+	return false, openErr
+}
+
+// requireMe returns the authenticated caller or writes the error response.
+func (h *AuthHandler) requireMe(c *gin.Context) (model.User, bool) {
+	if h == nil || h.db == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"code":    "service_unavailable",
+			"message": "service unavailable",
+			"error":   "service unavailable",
+		})
+		return model.User{}, false
+	}
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"code":    "unauthorized",
+			"message": "unauthorized",
+			"error":   "unauthorized",
+		})
+		return model.User{}, false
+	}
+	return user, true
+}
@@ -6,9 +6,10 @@ import (
 	"strings"
 	"time"
 
+	"evening-gown/internal/audit"
 	"evening-gown/internal/cache"
-	"evening-gown/internal/logging"
 	"evening-gown/internal/model"
+	"evening-gown/internal/pagination"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -64,33 +65,7 @@ func (h *UpdatesHandler) List(c *gin.Context) {
 		q = q.Where("status = ?", st)
 	}
 
-	limit := parseIntQuery(c, "limit", 50)
-	offset := parseIntQuery(c, "offset", 0)
-	if limit <= 0 {
-		limit = 50
-	}
-	if limit > 200 {
-		limit = 200
-	}
-	if offset < 0 {
-		offset = 0
-	}
-
-	var total int64
-	if err := q.Count(&total).Error; err != nil {
-		logging.ErrorWithStack(logging.FromGin(c), "admin updates query count failed", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
-		return
-	}
-
-	var items []model.UpdatePost
-	if err := q.Order("pinned_rank desc, published_at desc, id desc").Limit(limit).Offset(offset).Find(&items).Error; err != nil {
-		logging.ErrorWithStack(logging.FromGin(c), "admin updates query list failed", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
-		return
-	}
-
-	c.JSON(http.StatusOK, gin.H{"total": total, "items": items})
+	listSorted(c, q, adminUpdateSorts, pagination.ParseParams(c.Request.URL.Query(), 50, 200), "updates")
 }
 
 func (h *UpdatesHandler) Create(c *gin.Context) {
@@ -136,6 +111,7 @@ func (h *UpdatesHandler) Create(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	recordAudit(c, h.db, audit.Entry{Action: "update.create", EntityType: "updates", EntityID: auditID(post.ID), After: post})
 	if h.cache != nil && isPublicCompanyUpdate(post) {
 		_, _ = h.cache.BumpUpdatesVersion(c.Request.Context())
 	}
@@ -259,6 +235,7 @@ func (h *UpdatesHandler) Update(c *gin.Context) {
 		Where("id = ?", uint(id)).
 		Where("deleted_at IS NULL").
 		First(&after).Error; err == nil {
+		recordAudit(c, h.db, audit.Entry{Action: "update.update", EntityType: "updates", EntityID: auditID(after.ID), Before: before, After: after})
 		if h.cache != nil && (isPublicCompanyUpdate(before) || isPublicCompanyUpdate(after)) {
 			_, _ = h.cache.BumpUpdatesVersion(ctx)
 		}
@@ -296,6 +273,7 @@ func (h *UpdatesHandler) Publish(c *gin.Context) {
 		Updates(map[string]any{
 			"status":       "published",
 			"published_at": &now,
+			"publish_at":   nil,
 		})
 	if res.Error != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": res.Error.Error()})
@@ -305,6 +283,13 @@ func (h *UpdatesHandler) Publish(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
 		return
 	}
+	recordAudit(c, h.db, audit.Entry{
+		Action:     "update.publish",
+		EntityType: "updates",
+		EntityID:   auditID(before.ID),
+		Before:     before,
+		After:      reloadForAudit[model.UpdatePost](ctx, h.db, before.ID),
+	})
 	if h.cache != nil && strings.TrimSpace(before.Type) == "company" {
 		_, _ = h.cache.BumpUpdatesVersion(ctx)
 	}
@@ -340,6 +325,7 @@ func (h *UpdatesHandler) Unpublish(c *gin.Context) {
 		Updates(map[string]any{
 			"status":       "draft",
 			"published_at": nil,
+			"unpublish_at": nil,
 		})
 	if res.Error != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": res.Error.Error()})
@@ -349,6 +335,13 @@ func (h *UpdatesHandler) Unpublish(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
 		return
 	}
+	recordAudit(c, h.db, audit.Entry{
+		Action:     "update.unpublish",
+		EntityType: "updates",
+		EntityID:   auditID(before.ID),
+		Before:     before,
+		After:      reloadForAudit[model.UpdatePost](ctx, h.db, before.ID),
+	})
 	if h.cache != nil && isPublicCompanyUpdate(before) {
 		_, _ = h.cache.BumpUpdatesVersion(ctx)
 	}
@@ -356,6 +349,59 @@ func (h *UpdatesHandler) Unpublish(c *gin.Context) {
 	h.Get(c)
 }
 
+// Schedule sets (or clears) future publish/unpublish times.
+// Route: PUT /api/v1/admin/updates/:id/schedule
+func (h *UpdatesHandler) Schedule(c *gin.Context) {
+	if h == nil || h.db == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "service unavailable"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req scheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	updates, err := scheduleUpdates(req, time.Now().UTC())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	var before model.UpdatePost
+	if err := h.db.WithContext(ctx).
+		Where("id = ?", uint(id)).
+		Where("deleted_at IS NULL").
+		First(&before).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+		return
+	}
+
+	if err := h.db.WithContext(ctx).Model(&model.UpdatePost{}).
+		Where("id = ?", before.ID).
+		Where("deleted_at IS NULL").
+		Updates(updates).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	recordAudit(c, h.db, audit.Entry{
+		Action:     "update.schedule",
+		EntityType: "updates",
+		EntityID:   auditID(before.ID),
+		Before:     before,
+		After:      reloadForAudit[model.UpdatePost](ctx, h.db, before.ID),
+	})
+
+	h.Get(c)
+}
+
 func (h *UpdatesHandler) Delete(c *gin.Context) {
 	if h == nil || h.db == nil {
 		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "service unavailable"})
@@ -391,6 +437,7 @@ func (h *UpdatesHandler) Delete(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
 		return
 	}
+	recordAudit(c, h.db, audit.Entry{Action: "update.delete", EntityType: "updates", EntityID: auditID(before.ID), Before: before})
 	if h.cache != nil && isPublicCompanyUpdate(before) {
 		_, _ = h.cache.BumpUpdatesVersion(ctx)
 	}
@@ -2,6 +2,9 @@ package admin
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -9,32 +12,83 @@ import (
 	"time"
 
 	"evening-gown/internal/config"
+	"evening-gown/internal/imaging"
+	"evening-gown/internal/logging"
+	"evening-gown/internal/middleware"
 	"evening-gown/internal/model"
 	"evening-gown/internal/storage"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/minio/minio-go/v7"
+	"gorm.io/gorm"
 )
 
 type UploadsHandler struct {
+	db          *gorm.DB
 	minioClient *minio.Client
 	minioCfg    config.MinioConfig
 	maxBytes    int64
+	widths      []int
+	quality     int
+	// decoders bounds concurrent decodes; a decoded image holds up to 4 bytes per pixel.
+	decoders chan struct{}
+	// Presigned direct uploads, see direct_uploads.go.
+	maxDirectBytes int64
+	directTTL      time.Duration
 }
 
-func NewUploadsHandler(minioClient *minio.Client, minioCfg config.MinioConfig, uploadCfg config.UploadConfig) *UploadsHandler {
+// NewUploadsHandler returns the upload handler. With a db, each upload is registered in the
+// media library (model.Asset).
+func NewUploadsHandler(db *gorm.DB, minioClient *minio.Client, minioCfg config.MinioConfig, uploadCfg config.UploadConfig) *UploadsHandler {
 	maxBytes := uploadCfg.MaxImageUploadBytes
 	if maxBytes <= 0 {
-		maxBytes = 1048576
+		maxBytes = 20971520
+	}
+	decoders := uploadCfg.ImageDecodeWorkers
+	if decoders <= 0 {
+		decoders = 2
+	}
+	widths := uploadCfg.ImageRenditionWidths
+	if len(widths) == 0 {
+		widths = []int{320, 768, 1600}
+	}
+	maxDirectBytes := uploadCfg.MaxDirectUploadBytes
+	if maxDirectBytes <= 0 {
+		maxDirectBytes = 524288000
+	}
+	directTTL := uploadCfg.DirectUploadTTL
+	if directTTL <= 0 {
+		directTTL = 15 * time.Minute
+	}
+	return &UploadsHandler{
+		db:             db,
+		minioClient:    minioClient,
+		minioCfg:       minioCfg,
+		maxBytes:       maxBytes,
+		widths:         widths,
+		quality:        uploadCfg.ImageQuality,
+		decoders:       make(chan struct{}, decoders),
+		maxDirectBytes: maxDirectBytes,
+		directTTL:      directTTL,
 	}
-	return &UploadsHandler{minioClient: minioClient, minioCfg: minioCfg, maxBytes: maxBytes}
 }
 
-// UploadImage accepts an already-compressed webp image and uploads it to MinIO.
+type uploadedRendition struct {
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+	URL         string `json:"url"`
+	ObjectKey   string `json:"objectKey"`
+	ContentType string `json:"contentType"`
+	Size        int64  `json:"size"`
+}
+
+// UploadImage decodes a JPEG/PNG/WebP image, writes its width renditions to MinIO and lists them.
+// The top-level url/objectKey point at the widest rendition, which products store as their image key;
+// assetId is its media library entry.
 //
 // Form fields:
-// - file: image/webp
+// - file: image/jpeg|image/png|image/webp (HEIC is not supported; export to JPEG first)
 // - kind: cover|hover|gallery
 // - styleNo: int
 func (h *UploadsHandler) UploadImage(c *gin.Context) {
@@ -92,25 +146,43 @@ func (h *UploadsHandler) UploadImage(c *gin.Context) {
 	}
 	defer f.Close()
 
-	buf := make([]byte, 512)
-	n, _ := f.Read(buf)
-	headCT := strings.TrimSpace(fh.Header.Get("Content-Type"))
-	detectCT := ""
-	if n > 0 {
-		detectCT = http.DetectContentType(buf[:n])
-	}
-	if headCT != "image/webp" && detectCT != "image/webp" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":      "only image/webp is accepted",
-			"contentType": headCT,
+	data, err := io.ReadAll(io.LimitReader(f, h.maxBytes+1))
+	if err != nil || int64(len(data)) > h.maxBytes {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unable to read file"})
+		return
+	}
+
+	select {
+	case h.decoders <- struct{}{}:
+	case <-c.Request.Context().Done():
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "upload canceled"})
+		return
+	}
+	img, _, err := imaging.Decode(data)
+	if err != nil {
+		<-h.decoders
+		status := http.StatusBadRequest
+		if errors.Is(err, imaging.ErrUnsupportedFormat) {
+			status = http.StatusUnsupportedMediaType
+		}
+		c.JSON(status, gin.H{
+			"error":       err.Error(),
+			"contentType": strings.TrimSpace(fh.Header.Get("Content-Type")),
 		})
 		return
 	}
+	renditions, err := imaging.Renditions(img, h.widths, h.quality)
+	<-h.decoders
+	if err != nil {
+		logging.ErrorWithStack(logging.FromGin(c), "admin uploads render failed", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "image processing failed"})
+		return
+	}
 
 	ctx := c.Request.Context()
 	now := time.Now().UTC()
-	objectKey := fmt.Sprintf(
-		"products/%s/%s/%04d/%02d/%02d/%s.webp",
+	base := fmt.Sprintf(
+		"products/%s/%s/%04d/%02d/%02d/%s",
 		styleNo,
 		kind,
 		now.Year(),
@@ -119,18 +191,67 @@ func (h *UploadsHandler) UploadImage(c *gin.Context) {
 		uuid.NewString(),
 	)
 
-	r := io.MultiReader(bytes.NewReader(buf[:n]), f)
-	if err := storage.PutObject(ctx, h.minioClient, h.minioCfg, objectKey, r, fh.Size, "image/webp"); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
+	items := make([]uploadedRendition, 0, len(renditions))
+	// Do not leave a partial set behind.
+	cleanup := func() {
+		for _, done := range items {
+			_ = storage.RemoveObject(ctx, h.minioClient, h.minioCfg, done.ObjectKey)
+		}
+	}
+	var total int64
+	for _, r := range renditions {
+		objectKey := imaging.RenditionKey(base, r.Width)
+		if err := storage.PutObject(ctx, h.minioClient, h.minioCfg, objectKey, bytes.NewReader(r.Data), int64(len(r.Data)), imaging.RenditionContentType); err != nil {
+			cleanup()
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		total += int64(len(r.Data))
+		items = append(items, uploadedRendition{
+			Width:       r.Width,
+			Height:      r.Height,
+			URL:         "/api/v1/assets/" + objectKey,
+			ObjectKey:   objectKey,
+			ContentType: imaging.RenditionContentType,
+			Size:        int64(len(r.Data)),
+		})
 	}
 
-	assetURL := "/api/v1/assets/" + strings.TrimPrefix(objectKey, "/")
+	largest := items[len(items)-1]
+	var assetID uint
+	if h.db != nil {
+		sum := sha256.Sum256(data)
+		asset := model.Asset{
+			ObjectKey:   largest.ObjectKey,
+			Base:        base,
+			Kind:        kind,
+			StyleNo:     styleNo,
+			ContentType: largest.ContentType,
+			Size:        total,
+			Width:       largest.Width,
+			Height:      largest.Height,
+			Checksum:    hex.EncodeToString(sum[:]),
+		}
+		if user, ok := middleware.CurrentUser(c); ok {
+			asset.UploadedBy = &user.ID
+		}
+		if err := h.db.WithContext(ctx).Create(&asset).Error; err != nil {
+			cleanup()
+			logging.ErrorWithStack(logging.FromGin(c), "admin uploads register asset failed", err, "objectKey", largest.ObjectKey)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "upload failed"})
+			return
+		}
+		assetID = asset.ID
+	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"url":         assetURL,
-		"objectKey":   objectKey,
-		"contentType": "image/webp",
-		"size":        fh.Size,
+		"assetId":     assetID,
+		"url":         largest.URL,
+		"objectKey":   largest.ObjectKey,
+		"contentType": largest.ContentType,
+		"size":        largest.Size,
+		"width":       largest.Width,
+		"height":      largest.Height,
+		"renditions":  items,
 	})
 }
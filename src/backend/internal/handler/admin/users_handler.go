@@ -0,0 +1,385 @@
+package admin
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"evening-gown/internal/audit"
+	"evening-gown/internal/logging"
+	"evening-gown/internal/middleware"
+	"evening-gown/internal/model"
+	"evening-gown/internal/security"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// UsersHandler manages backoffice accounts (invite/disable/delete, role changes).
+//
+// All routes require the users:manage permission (super admin only).
+type UsersHandler struct {
+	db        *gorm.DB
+	passwords security.PasswordPolicy
+}
+
+func NewUsersHandler(db *gorm.DB) *UsersHandler {
+	return NewUsersHandlerWithPasswordPolicy(db, security.DefaultPasswordPolicy())
+}
+
+// NewUsersHandlerWithPasswordPolicy validates explicit invite passwords against policy.
+func NewUsersHandlerWithPasswordPolicy(db *gorm.DB, policy security.PasswordPolicy) *UsersHandler {
+	return &UsersHandler{db: db, passwords: passwordPolicyOrDefault(policy)}
+}
+
+type userInviteRequest struct {
+	Email string `json:"email" binding:"required"`
+	Role  string `json:"role" binding:"required"`
+	// Password is optional; when empty a temporary password is generated and returned once.
+	Password string `json:"password"`
+}
+
+type userUpdateRequest struct {
+	Role *string `json:"role"`
+}
+
+func (h *UsersHandler) List(c *gin.Context) {
+	if h == nil || h.db == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "service unavailable"})
+		return
+	}
+
+	q := h.db.WithContext(c.Request.Context()).Model(&model.User{}).
+		Where("deleted_at IS NULL")
+	if role := strings.TrimSpace(c.Query("role")); role != "" {
+		q = q.Where("role = ?", role)
+	}
+	if st := strings.TrimSpace(c.Query("status")); st != "" {
+		q = q.Where("status = ?", st)
+	}
+
+	var items []model.User
+	if err := q.Order("id asc").Find(&items).Error; err != nil {
+		logging.ErrorWithStack(logging.FromGin(c), "admin users query list failed", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"total": len(items), "items": items, "roles": model.Roles()})
+}
+
+func (h *UsersHandler) Get(c *gin.Context) {
+	if h == nil || h.db == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "service unavailable"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var user model.User
+	if err := h.db.WithContext(c.Request.Context()).
+		Where("deleted_at IS NULL").
+		First(&user, uint(id)).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// Invite creates a new backoffice account with a scoped role.
+// Route: POST /api/v1/admin/users
+func (h *UsersHandler) Invite(c *gin.Context) {
+	if h == nil || h.db == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "service unavailable"})
+		return
+	}
+
+	var req userInviteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	if email == "" || !strings.Contains(email, "@") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid email"})
+		return
+	}
+	role := strings.TrimSpace(req.Role)
+	if !model.IsValidRole(role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid role", "roles": model.Roles()})
+		return
+	}
+
+	password := strings.TrimSpace(req.Password)
+	generated := false
+	if password == "" {
+		p, err := security.GenerateTemporaryPassword()
+		if err != nil {
+			logging.ErrorWithStack(logging.FromGin(c), "admin users generate password failed", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "invite failed"})
+			return
+		}
+		password = p
+		generated = true
+	} else if err := h.passwords.Validate(password, email); err != nil {
+		respondPasswordRejected(c, err)
+		return
+	}
+	hash, err := h.passwords.Hash(password)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	var exists int64
+	if err := h.db.WithContext(ctx).Model(&model.User{}).Where("email = ? AND deleted_at IS NULL", email).Count(&exists).Error; err != nil {
+		logging.ErrorWithStack(logging.FromGin(c), "admin users query email failed", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return
+	}
+	if exists > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "email already exists"})
+		return
+	}
+
+	// JWT iat is second-precision. Truncate to seconds so AdminAuth comparison is stable.
+	now := time.Now().UTC().Truncate(time.Second)
+	user := model.User{
+		Email:             email,
+		PasswordHash:      hash,
+		Role:              role,
+		Status:            "active",
+		PasswordUpdatedAt: &now,
+	}
+	if err := h.db.WithContext(ctx).Create(&user).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	recordAudit(c, h.db, audit.Entry{Action: "user.invite", EntityType: "users", EntityID: auditID(user.ID), After: user})
+
+	resp := gin.H{"user": user}
+	if generated {
+		// Only returned once; the admin shares it with the invitee out of band.
+		resp["temporaryPassword"] = password
+	}
+	c.JSON(http.StatusCreated, resp)
+}
+
+// Update changes a user's role.
+// Route: PATCH /api/v1/admin/users/:id
+func (h *UsersHandler) Update(c *gin.Context) {
+	if h == nil || h.db == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "service unavailable"})
+		return
+	}
+
+	target, ok := h.loadTarget(c)
+	if !ok {
+		return
+	}
+
+	var req userUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Role == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "no updates"})
+		return
+	}
+	role := strings.TrimSpace(*req.Role)
+	if !model.IsValidRole(role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid role", "roles": model.Roles()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	if target.Role == model.RoleAdmin && role != model.RoleAdmin {
+		if !h.ensureOtherActiveAdmin(c, ctx, target.ID) {
+			return
+		}
+	}
+
+	if err := h.db.WithContext(ctx).Model(&model.User{}).
+		Where("id = ? AND deleted_at IS NULL", target.ID).
+		Updates(map[string]any{"role": role, "updated_at": time.Now().UTC()}).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	h.auditTarget(c, "user.update_role", target)
+
+	h.Get(c)
+}
+
+// Disable blocks a user from logging in. Existing tokens stop working immediately
+// because AdminAuth re-checks the user status on every request.
+// Route: POST /api/v1/admin/users/:id/disable
+func (h *UsersHandler) Disable(c *gin.Context) {
+	h.setStatus(c, "disabled", "user.disable")
+}
+
+// Enable re-activates a disabled user.
+// Route: POST /api/v1/admin/users/:id/enable
+func (h *UsersHandler) Enable(c *gin.Context) {
+	h.setStatus(c, "active", "user.enable")
+}
+
+func (h *UsersHandler) setStatus(c *gin.Context, status, action string) {
+	if h == nil || h.db == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "service unavailable"})
+		return
+	}
+
+	target, ok := h.loadTarget(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	if status != "active" && target.Role == model.RoleAdmin {
+		if !h.ensureOtherActiveAdmin(c, ctx, target.ID) {
+			return
+		}
+	}
+
+	if err := h.db.WithContext(ctx).Model(&model.User{}).
+		Where("id = ? AND deleted_at IS NULL", target.ID).
+		Updates(map[string]any{"status": status, "updated_at": time.Now().UTC()}).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	h.auditTarget(c, action, target)
+
+	h.Get(c)
+}
+
+// Unlock clears a login lockout (failed attempts counter, lock window, locked status).
+// Route: POST /api/v1/admin/users/:id/unlock
+func (h *UsersHandler) Unlock(c *gin.Context) {
+	if h == nil || h.db == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "service unavailable"})
+		return
+	}
+
+	target, ok := h.loadTarget(c)
+	if !ok {
+		return
+	}
+
+	updates := map[string]any{
+		"failed_login_count": 0,
+		"locked_until":       nil,
+		"updated_at":         time.Now().UTC(),
+	}
+	if target.Status == "locked" {
+		updates["status"] = "active"
+	}
+	if err := h.db.WithContext(c.Request.Context()).Model(&model.User{}).
+		Where("id = ? AND deleted_at IS NULL", target.ID).
+		Updates(updates).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	h.auditTarget(c, "user.unlock", target)
+
+	h.Get(c)
+}
+
+// Delete soft-deletes a user.
+// Route: DELETE /api/v1/admin/users/:id
+func (h *UsersHandler) Delete(c *gin.Context) {
+	if h == nil || h.db == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "service unavailable"})
+		return
+	}
+
+	target, ok := h.loadTarget(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	if target.Role == model.RoleAdmin {
+		if !h.ensureOtherActiveAdmin(c, ctx, target.ID) {
+			return
+		}
+	}
+
+	now := time.Now().UTC()
+	res := h.db.WithContext(ctx).Model(&model.User{}).
+		Where("id = ? AND deleted_at IS NULL", target.ID).
+		Updates(map[string]any{"deleted_at": &now, "updated_at": now})
+	if res.Error != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": res.Error.Error()})
+		return
+	}
+	if res.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+		return
+	}
+	h.auditTarget(c, "user.delete", target)
+
+	c.Status(http.StatusNoContent)
+}
+
+// auditTarget records a change to the user loaded by loadTarget, diffing against its current row.
+func (h *UsersHandler) auditTarget(c *gin.Context, action string, before model.User) {
+	recordAudit(c, h.db, audit.Entry{
+		Action:     action,
+		EntityType: "users",
+		EntityID:   auditID(before.ID),
+		Before:     before,
+		After:      reloadForAudit[model.User](c.Request.Context(), h.db, before.ID),
+	})
+}
+
+// loadTarget resolves the :id user and rejects attempts to modify one's own account,
+// so an admin cannot lock themselves out by accident.
+func (h *UsersHandler) loadTarget(c *gin.Context) (model.User, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return model.User{}, false
+	}
+
+	if me, ok := middleware.CurrentUser(c); ok && me.ID == uint(id) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cannot modify your own account"})
+		return model.User{}, false
+	}
+
+	var target model.User
+	if err := h.db.WithContext(c.Request.Context()).
+		Where("deleted_at IS NULL").
+		First(&target, uint(id)).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+		return model.User{}, false
+	}
+	return target, true
+}
+
+// ensureOtherActiveAdmin guarantees at least one active super admin remains.
+func (h *UsersHandler) ensureOtherActiveAdmin(c *gin.Context, ctx context.Context, excludeID uint) bool {
+	var cnt int64
+	if err := h.db.WithContext(ctx).Model(&model.User{}).
+		Where("role = ? AND status = ? AND deleted_at IS NULL AND id <> ?", model.RoleAdmin, "active", excludeID).
+		Count(&cnt).Error; err != nil {
+		logging.ErrorWithStack(logging.FromGin(c), "admin users count admins failed", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return false
+	}
+	if cnt == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "at least one active admin is required"})
+		return false
+	}
+	return true
+}
@@ -23,11 +23,16 @@ func New(cfg config.JWTConfig) *Handler {
 	return &Handler{svc: svc}
 }
 
+// NewWithService shares an existing JWT service (same keys as the admin auth middleware).
+func NewWithService(svc *auth.Service) *Handler {
+	return &Handler{svc: svc}
+}
+
 type issueTokenRequest struct {
 	Subject string `json:"sub" binding:"required"`
 }
 
-// IssueToken issues a HS256 JWT.
+// IssueToken issues a JWT signed with the active key.
 func (h *Handler) IssueToken(c *gin.Context) {
 	if h == nil || h.svc == nil {
 		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "jwt disabled"})
@@ -80,6 +85,20 @@ func (h *Handler) VerifyToken(c *gin.Context) {
 	})
 }
 
+// JWKS publishes the public verification keys so other services can validate admin tokens
+// without sharing a secret.
+// Route: GET /.well-known/jwks.json
+func (h *Handler) JWKS(c *gin.Context) {
+	if h == nil || h.svc == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "jwt disabled"})
+		return
+	}
+
+	// Keys change only on rotation; let verifiers cache briefly.
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.svc.JWKS())
+}
+
 func tokenFromRequest(c *gin.Context) string {
 	if c == nil {
 		return ""
@@ -1,14 +1,20 @@
 package public
 
 import (
+	"bytes"
+	"errors"
 	"net/http"
 	"path"
+	"strconv"
 	"strings"
 	"time"
 
 	"evening-gown/internal/cache"
 	"evening-gown/internal/config"
+	"evening-gown/internal/imaging"
+	"evening-gown/internal/logging"
 	"evening-gown/internal/model"
+	"evening-gown/internal/security"
 
 	"github.com/gin-gonic/gin"
 	"github.com/minio/minio-go/v7"
@@ -20,18 +26,36 @@ type AssetsHandler struct {
 	minioClient *minio.Client
 	minioCfg    config.MinioConfig
 	cache       *cache.PublicCache
+	previews    *security.PreviewSigner
+	variants    *ImageVariants
 }
 
 func NewAssetsHandler(db *gorm.DB, minioClient *minio.Client, minioCfg config.MinioConfig, publicCache *cache.PublicCache) *AssetsHandler {
 	return &AssetsHandler{db: db, minioClient: minioClient, minioCfg: minioCfg, cache: publicCache}
 }
 
+// NewAssetsHandlerWithPreview also serves assets of unpublished products to holders of a
+// signed product preview token (?preview=...).
+func NewAssetsHandlerWithPreview(db *gorm.DB, minioClient *minio.Client, minioCfg config.MinioConfig, publicCache *cache.PublicCache, previews *security.PreviewSigner) *AssetsHandler {
+	return &AssetsHandler{db: db, minioClient: minioClient, minioCfg: minioCfg, cache: publicCache, previews: previews}
+}
+
+// WithVariants enables ?w= / ?fmt= width variants.
+func (h *AssetsHandler) WithVariants(variants *ImageVariants) *AssetsHandler {
+	h.variants = variants
+	return h
+}
+
 const publicAssetAllowTTL = 15 * time.Minute
 
 // Get streams an object from MinIO through the application.
 //
 // Route: GET /api/v1/assets/*key
 //
+// Query:
+// - w: serve the image resized to that width (snapped to IMAGE_RENDITION_WIDTHS, never upscaled)
+// - fmt: jpeg (the only variant format; implies the widest variant without w)
+//
 // Notes:
 // - Intended for public website consumption (published products).
 // - Keeps MinIO buckets private; browsers never talk to MinIO directly.
@@ -77,15 +101,30 @@ func (h *AssetsHandler) Get(c *gin.Context) {
 		return
 	}
 
+	width, ok := h.variantWidth(c)
+	if !ok {
+		return
+	}
+
+	// A product preview token unlocks that product's assets whatever its state.
+	// The allow-cache only covers published products, so it is bypassed here.
+	preview := false
+	if token := strings.TrimSpace(c.Query("preview")); token != "" && h.db != nil && h.previews != nil {
+		if claims, err := h.previews.Verify(token, time.Now()); err == nil && claims.Kind == security.PreviewProduct {
+			if ok, err := h.isProductAsset(c, cleanKey, claims.ID); err == nil && ok {
+				preview = true
+				goto allowed
+			}
+		}
+	}
+
 	// Prevent unauthorized reads of draft/backoffice-managed images.
 	// Only allow assets that are referenced by a published product.
 	// NOTE: Admin backoffice can fetch draft assets via /api/v1/admin/assets/*key.
 	if h.db != nil {
 		ctx := c.Request.Context()
-		productsVer := int64(0)
 		if h.cache != nil {
-			productsVer = h.cache.ProductsVersion(ctx)
-			allowKey := h.cache.AssetAllowKey(productsVer, cleanKey)
+			allowKey := h.cache.AssetAllowKey(h.cache.AssetsVersion(ctx), cleanKey)
 			if v, hit := h.cache.BoolFromCache(ctx, allowKey); hit {
 				if !v {
 					c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
@@ -115,13 +154,41 @@ allowed:
 
 	ctx := c.Request.Context()
 
-	stat, err := h.minioClient.StatObject(ctx, h.minioCfg.Bucket, cleanKey, minio.StatObjectOptions{})
+	// Cache aggressively: object keys are content-addressed-ish (include uuid/date),
+	// so updates generate new keys and won't break caches.
+	headers := map[string]string{
+		"Cache-Control": "public, max-age=31536000, immutable",
+	}
+	if preview {
+		headers["Cache-Control"] = "private, no-store"
+	}
+
+	objectKey := cleanKey
+	if width > 0 {
+		variantKey, generated, err := h.variants.resolve(ctx, cleanKey, width)
+		if errors.Is(err, errVariantSourceMissing) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+			return
+		}
+		if err != nil {
+			logging.ErrorWithStack(logging.FromGin(c), "public assets variant failed", err, "key", cleanKey, "width", width)
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "image variant unavailable"})
+			return
+		}
+		if generated != nil {
+			c.DataFromReader(http.StatusOK, int64(len(generated)), imaging.RenditionContentType, bytes.NewReader(generated), headers)
+			return
+		}
+		objectKey = variantKey
+	}
+
+	stat, err := h.minioClient.StatObject(ctx, h.minioCfg.Bucket, objectKey, minio.StatObjectOptions{})
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
 		return
 	}
 
-	obj, err := h.minioClient.GetObject(ctx, h.minioCfg.Bucket, cleanKey, minio.GetObjectOptions{})
+	obj, err := h.minioClient.GetObject(ctx, h.minioCfg.Bucket, objectKey, minio.GetObjectOptions{})
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
 		return
@@ -133,11 +200,6 @@ allowed:
 		contentType = "application/octet-stream"
 	}
 
-	// Cache aggressively: object keys are content-addressed-ish (include uuid/date),
-	// so updates generate new keys and won't break caches.
-	headers := map[string]string{
-		"Cache-Control": "public, max-age=31536000, immutable",
-	}
 	if strings.TrimSpace(stat.ETag) != "" {
 		c.Header("ETag", stat.ETag)
 	}
@@ -145,33 +207,84 @@ allowed:
 	c.DataFromReader(http.StatusOK, stat.Size, contentType, obj, headers)
 }
 
+// variantWidth parses ?w= and ?fmt=, answering 400 itself. 0 means the original object.
+func (h *AssetsHandler) variantWidth(c *gin.Context) (int, bool) {
+	rawW := strings.TrimSpace(c.Query("w"))
+	format := strings.ToLower(strings.TrimSpace(c.Query("fmt")))
+	if rawW == "" && format == "" {
+		return 0, true
+	}
+	if format != "" && format != "jpeg" && format != "jpg" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported fmt", "formats": []string{"jpeg"}})
+		return 0, false
+	}
+	if h.variants == nil {
+		// Variants disabled: serve the original.
+		return 0, true
+	}
+	if rawW == "" {
+		widths := h.variants.Widths()
+		return widths[len(widths)-1], true
+	}
+	w, err := strconv.Atoi(rawW)
+	if err != nil || w <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid w"})
+		return 0, false
+	}
+	return w, true
+}
+
 func (h *AssetsHandler) isPublishedProductAsset(c *gin.Context, objectKey string) (bool, error) {
-	// products/{styleNo}/...
-	parts := strings.Split(objectKey, "/")
-	if len(parts) < 2 {
+	q := h.productAssetRefs(c, objectKey)
+	if q == nil {
 		return false, nil
 	}
-	styleNo, err := model.NormalizeStyleNo(strings.TrimSpace(parts[1]))
-	if err != nil {
-		return false, nil
+	var cnt int64
+	if err := q.Where("published_at IS NOT NULL").Limit(1).Count(&cnt).Error; err != nil {
+		return false, err
 	}
+	return cnt > 0, nil
+}
 
-	objectKey = strings.TrimSpace(strings.TrimPrefix(objectKey, "/"))
-	if objectKey == "" {
+// isProductAsset reports whether productID (in any publication state) references objectKey,
+// either live or in its pending draft.
+func (h *AssetsHandler) isProductAsset(c *gin.Context, objectKey string, productID uint) (bool, error) {
+	q := h.productAssetRefs(c, objectKey)
+	if q == nil {
 		return false, nil
 	}
-
 	var cnt int64
-	like := "%" + objectKey + "%"
-	err = h.db.WithContext(c.Request.Context()).Model(&model.Product{}).
-		Where("style_no = ?", styleNo).
-		Where("published_at IS NOT NULL").
-		Where("deleted_at IS NULL").
-		Where("(cover_image_key = ? OR hover_image_key = ? OR CAST(detail_json AS TEXT) LIKE ?)", objectKey, objectKey, like).
-		Limit(1).
-		Count(&cnt).Error
+	if err := q.Where("id = ?", productID).Limit(1).Count(&cnt).Error; err != nil {
+		return false, err
+	}
+	if cnt > 0 {
+		return true, nil
+	}
+	// Draft images are only recorded in product_assets once the draft is published.
+	working, err := previewProduct(c.Request.Context(), h.db, productID)
 	if err != nil {
 		return false, err
 	}
-	return cnt > 0, nil
+	return productUsesImage(working, objectKey), nil
+}
+
+// productAssetRefs selects live products referencing objectKey through the product_assets index;
+// nil when the key cannot be a product asset.
+func (h *AssetsHandler) productAssetRefs(c *gin.Context, objectKey string) *gorm.DB {
+	objectKey = strings.TrimSpace(strings.TrimPrefix(objectKey, "/"))
+	if objectKey == "" {
+		return nil
+	}
+
+	db := h.db.WithContext(c.Request.Context())
+	refs := db.Model(&model.ProductAsset{}).Select("product_id")
+	// Products store one width of an upload; the other renditions share its access.
+	if base, _, ok := imaging.ParseRenditionKey(objectKey); ok {
+		refs = refs.Where("base = ?", base)
+	} else {
+		refs = refs.Where("object_key = ?", objectKey)
+	}
+	return db.Model(&model.Product{}).
+		Where("id IN (?)", refs).
+		Where("deleted_at IS NULL")
 }
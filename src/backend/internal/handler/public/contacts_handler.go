@@ -86,7 +86,7 @@ func (h *ContactsHandler) Create(c *gin.Context) {
 		if exists, err := h.rdb.Exists(ctx, cache.AdminContactsNewCountKey).Result(); err == nil && exists == 0 {
 			// Counter key missing (e.g., Redis restart/eviction). Reconcile from DB.
 			var newLeads int64
-			if err := h.db.WithContext(ctx).Model(&model.ContactLead{}).Where("status = ?", "new").Count(&newLeads).Error; err == nil {
+			if err := h.db.WithContext(ctx).Model(&model.ContactLead{}).Where("status = ? AND deleted_at IS NULL", "new").Count(&newLeads).Error; err == nil {
 				_ = h.rdb.Set(ctx, cache.AdminContactsNewCountKey, newLeads, 0).Err()
 			}
 		} else {
@@ -0,0 +1,325 @@
+package public
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"evening-gown/internal/config"
+	"evening-gown/internal/imaging"
+	"evening-gown/internal/model"
+	"evening-gown/internal/storage"
+
+	"github.com/minio/minio-go/v7"
+	"golang.org/x/sync/singleflight"
+	"gorm.io/gorm"
+)
+
+const (
+	// maxVariantSourceBytes bounds the source objects decoded to generate a variant.
+	maxVariantSourceBytes = 32 << 20
+	// maxKnownSources bounds the in-process memo of source facts (see ImageVariants.sources).
+	maxKnownSources = 10000
+)
+
+var (
+	errVariantSourceMissing = errors.New("variant source not found")
+	// errVariantUnsupported marks sources that cannot be resized (not an image, too large,
+	// undecodable); the original is served instead.
+	errVariantUnsupported = errors.New("variant source cannot be resized")
+)
+
+// sourceInfo is what is known about a source object without downloading it again.
+type sourceInfo struct {
+	width int  // pixels; 0 when unknown
+	raw   bool // not resizable: always serve the original
+}
+
+// variantStore is the object storage used by ImageVariants (MinIO in production).
+type variantStore interface {
+	Exists(ctx context.Context, key string) (bool, error)
+	Get(ctx context.Context, key string, maxBytes int64) ([]byte, error)
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+}
+
+// ImageVariants resolves width variants of product images for AssetsHandler. Variants are
+// the renditions written at upload time; missing ones (older single-size uploads, widths
+// added later) are generated on demand by a bounded number of workers and stored back, so
+// each is generated once.
+//
+// Requests that cannot produce a variant (the source is not wider, or cannot be resized)
+// serve the original. Source widths learned while generating are saved on the asset row,
+// so such requests do not download the source again and srcsets stop at the real width.
+type ImageVariants struct {
+	store   variantStore
+	db      *gorm.DB
+	widths  []int
+	quality int
+	workers chan struct{}
+	group   singleflight.Group
+
+	mu      sync.Mutex
+	sources map[string]sourceInfo
+}
+
+// NewImageVariants serves the widths configured for uploads (IMAGE_RENDITION_WIDTHS).
+// db (optional) holds the asset rows used to remember source widths.
+func NewImageVariants(db *gorm.DB, minioClient *minio.Client, minioCfg config.MinioConfig, uploadCfg config.UploadConfig) *ImageVariants {
+	if minioClient == nil {
+		return nil
+	}
+	v := newImageVariants(minioStore{client: minioClient, cfg: minioCfg}, uploadCfg)
+	v.db = db
+	return v
+}
+
+func newImageVariants(store variantStore, uploadCfg config.UploadConfig) *ImageVariants {
+	var widths []int
+	for _, w := range uploadCfg.ImageRenditionWidths {
+		if w > 0 {
+			widths = append(widths, w)
+		}
+	}
+	if len(widths) == 0 {
+		widths = []int{320, 768, 1600}
+	}
+	sort.Ints(widths)
+	workers := uploadCfg.ImageVariantWorkers
+	if workers <= 0 {
+		workers = 2
+	}
+	return &ImageVariants{
+		store:   store,
+		widths:  widths,
+		quality: uploadCfg.ImageQuality,
+		workers: make(chan struct{}, workers),
+		sources: map[string]sourceInfo{},
+	}
+}
+
+// Widths returns the variant widths, ascending.
+func (v *ImageVariants) Widths() []int {
+	if v == nil {
+		return nil
+	}
+	return v.widths
+}
+
+// resolve finds the object to serve for key at width w. It returns either the key of a stored
+// object (the variant, or key itself when the image is not wider than w or cannot be resized)
+// or the bytes of a variant it just generated.
+func (v *ImageVariants) resolve(ctx context.Context, key string, w int) (string, []byte, error) {
+	w = imaging.SnapWidth(w, v.widths)
+	base := imaging.VariantBase(key)
+	if _, own, ok := imaging.ParseRenditionKey(key); ok && own <= w {
+		// Renditions are never upscaled: a narrower key is the widest there is.
+		return key, nil, nil
+	}
+	if info, ok := v.source(ctx, key); ok && (info.raw || (info.width > 0 && info.width <= w)) {
+		return key, nil, nil
+	}
+
+	variantKey := imaging.RenditionKey(base, w)
+	if ok, err := v.store.Exists(ctx, variantKey); err != nil {
+		return "", nil, err
+	} else if ok {
+		return variantKey, nil, nil
+	}
+
+	res, err, _ := v.group.Do(variantKey, func() (any, error) {
+		return v.generate(ctx, key, variantKey, w)
+	})
+	if errors.Is(err, errVariantUnsupported) {
+		return key, nil, nil
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	if res == nil {
+		return key, nil, nil
+	}
+	return variantKey, res.([]byte), nil
+}
+
+// generate renders the w variant of key into variantKey. It returns nil bytes when the source
+// is not wider than w, in which case the source itself is served, and errVariantUnsupported
+// when the source cannot be resized. Both outcomes are remembered.
+func (v *ImageVariants) generate(ctx context.Context, key, variantKey string, w int) (any, error) {
+	select {
+	case v.workers <- struct{}{}:
+		defer func() { <-v.workers }()
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	data, err := v.store.Get(ctx, key, maxVariantSourceBytes)
+	if errors.Is(err, errVariantUnsupported) {
+		v.remember(key, sourceInfo{raw: true})
+		return nil, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	img, _, err := imaging.Decode(data)
+	if err != nil {
+		v.remember(key, sourceInfo{raw: true})
+		return nil, fmt.Errorf("%w: decode %s: %v", errVariantUnsupported, key, err)
+	}
+	v.recordWidth(ctx, key, img.Bounds().Dx(), img.Bounds().Dy())
+	if img.Bounds().Dx() <= w {
+		return nil, nil
+	}
+	out, err := imaging.Renditions(img, []int{w}, v.quality)
+	if err != nil {
+		return nil, err
+	}
+	if err := v.store.Put(ctx, variantKey, out[0].Data, imaging.RenditionContentType); err != nil {
+		return nil, err
+	}
+	return out[0].Data, nil
+}
+
+// source returns what is known about key: first the in-process memo, then its asset row
+// (the width saved by recordWidth or at upload; non-image content types are never resized).
+func (v *ImageVariants) source(ctx context.Context, key string) (sourceInfo, bool) {
+	v.mu.Lock()
+	info, ok := v.sources[key]
+	v.mu.Unlock()
+	if ok || v.db == nil {
+		return info, ok
+	}
+
+	var a model.Asset
+	if err := v.db.WithContext(ctx).Select("width", "content_type").Where("object_key = ?", key).Take(&a).Error; err != nil {
+		return info, false
+	}
+	switch {
+	case a.ContentType != "" && !strings.HasPrefix(a.ContentType, "image/"):
+		info = sourceInfo{raw: true}
+	case a.Width > 0:
+		info = sourceInfo{width: a.Width}
+	default:
+		return info, false
+	}
+	v.remember(key, info)
+	return info, true
+}
+
+func (v *ImageVariants) remember(key string, info sourceInfo) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if len(v.sources) >= maxKnownSources {
+		clear(v.sources)
+	}
+	v.sources[key] = info
+}
+
+// recordWidth remembers a measured source size and saves it on the asset row when it has none.
+// Best effort: without a row (not registered yet) only the memo is updated.
+func (v *ImageVariants) recordWidth(ctx context.Context, key string, width, height int) {
+	v.remember(key, sourceInfo{width: width})
+	if v.db == nil {
+		return
+	}
+	_ = v.db.WithContext(ctx).Model(&model.Asset{}).
+		Where("object_key = ? AND width = 0", key).
+		Updates(map[string]any{"width": width, "height": height}).Error
+}
+
+// assetWidths returns the known source widths of asset URLs (keyed by URL) from their
+// asset rows, for capping srcsets. Unknown widths are left out.
+func assetWidths(ctx context.Context, db *gorm.DB, urls ...string) map[string]int {
+	byKey := map[string]string{}
+	for _, u := range urls {
+		if key, ok := strings.CutPrefix(u, "/api/v1/assets/"); ok && key != "" {
+			byKey[key] = u
+		}
+	}
+	if db == nil || len(byKey) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(byKey))
+	for key := range byKey {
+		keys = append(keys, key)
+	}
+	var rows []model.Asset
+	if err := db.WithContext(ctx).Select("object_key", "width").
+		Where("object_key IN ? AND width > 0", keys).
+		Find(&rows).Error; err != nil {
+		return nil
+	}
+	out := make(map[string]int, len(rows))
+	for _, r := range rows {
+		out[byKey[r.ObjectKey]] = r.Width
+	}
+	return out
+}
+
+// imageSrcset lists the width variants of an asset URL for <img srcset>, e.g.
+// "/api/v1/assets/k?w=320 320w, /api/v1/assets/k?w=768 768w". Empty for non-asset URLs.
+// Widths at or above the source's own width (a rendition's, or srcWidth when known) are
+// replaced by the original.
+func imageSrcset(assetURL string, widths []int, srcWidth int) string {
+	key, ok := strings.CutPrefix(assetURL, "/api/v1/assets/")
+	if !ok || key == "" || len(widths) == 0 {
+		return ""
+	}
+	maxW := srcWidth
+	if _, own, ok := imaging.ParseRenditionKey(key); ok {
+		maxW = own
+	}
+	var parts []string
+	for _, w := range widths {
+		if maxW > 0 && w >= maxW {
+			parts = append(parts, assetURL+" "+strconv.Itoa(maxW)+"w")
+			break
+		}
+		parts = append(parts, assetURL+"?w="+strconv.Itoa(w)+" "+strconv.Itoa(w)+"w")
+	}
+	return strings.Join(parts, ", ")
+}
+
+type minioStore struct {
+	client *minio.Client
+	cfg    config.MinioConfig
+}
+
+func (s minioStore) Exists(ctx context.Context, key string) (bool, error) {
+	_, err := s.client.StatObject(ctx, s.cfg.Bucket, key, minio.StatObjectOptions{})
+	if err == nil {
+		return true, nil
+	}
+	if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+		return false, nil
+	}
+	return false, err
+}
+
+func (s minioStore) Get(ctx context.Context, key string, maxBytes int64) ([]byte, error) {
+	obj, err := s.client.GetObject(ctx, s.cfg.Bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer obj.Close()
+	data, err := io.ReadAll(io.LimitReader(obj, maxBytes+1))
+	if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+		return nil, errVariantSourceMissing
+	}
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxBytes {
+		return nil, fmt.Errorf("%w: source %s exceeds %d bytes", errVariantUnsupported, key, maxBytes)
+	}
+	return data, nil
+}
+
+func (s minioStore) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	return storage.PutObject(ctx, s.client, s.cfg, key, bytes.NewReader(data), int64(len(data)), contentType)
+}
@@ -0,0 +1,155 @@
+package public
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"sync"
+	"testing"
+
+	"evening-gown/internal/config"
+)
+
+type memStore struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	gets    int
+}
+
+func (s *memStore) Exists(_ context.Context, key string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.objects[key]
+	return ok, nil
+}
+
+func (s *memStore) Get(_ context.Context, key string, _ int64) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.gets++
+	b, ok := s.objects[key]
+	if !ok {
+		return nil, errVariantSourceMissing
+	}
+	return b, nil
+}
+
+func (s *memStore) Put(_ context.Context, key string, data []byte, _ string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[key] = data
+	return nil
+}
+
+func pngBytes(t *testing.T, w, h int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, w, h))); err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestImageVariants_Resolve(t *testing.T) {
+	legacy := "products/7001/cover/2025/03/01/abc.webp"
+	rendition := "products/7001/cover/2025/03/01/def/w768.jpg"
+	store := &memStore{objects: map[string][]byte{
+		// A PNG stored under a .webp key still decodes: the format is sniffed.
+		legacy:    pngBytes(t, 1000, 500),
+		rendition: {0xFF, 0xD8, 0xFF},
+		"products/7001/cover/2025/03/01/def/w320.jpg": {0xFF, 0xD8, 0xFF},
+	}}
+	v := newImageVariants(store, config.UploadConfig{ImageRenditionWidths: []int{1600, 320, 768}, ImageVariantWorkers: 1})
+	ctx := context.Background()
+
+	// Pre-generated renditions are served as stored; wider requests never upscale.
+	for w, want := range map[int]string{200: "products/7001/cover/2025/03/01/def/w320.jpg", 768: rendition, 1200: rendition} {
+		key, generated, err := v.resolve(ctx, rendition, w)
+		if err != nil || generated != nil || key != want {
+			t.Fatalf("w=%d: expected %q, got %q (generated=%v, err=%v)", w, want, key, generated != nil, err)
+		}
+	}
+
+	// Missing variants are generated once, stored back and then served from storage.
+	key, generated, err := v.resolve(ctx, legacy, 500)
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if key != "products/7001/cover/2025/03/01/abc/w768.jpg" || generated == nil {
+		t.Fatalf("expected generated w768 variant, got %q (generated=%v)", key, generated != nil)
+	}
+	img, err := jpeg.Decode(bytes.NewReader(store.objects[key]))
+	if err != nil || img.Bounds().Dx() != 768 || img.Bounds().Dy() != 384 {
+		t.Fatalf("unexpected stored variant: err=%v bounds=%v", err, img.Bounds())
+	}
+	if key2, generated2, _ := v.resolve(ctx, legacy, 768); key2 != key || generated2 != nil {
+		t.Fatalf("expected stored variant on second request, got %q (generated=%v)", key2, generated2 != nil)
+	}
+
+	// The source is narrower than 1600px: it is served itself. Its width was measured while
+	// generating w768, so this does not download it again.
+	gets := store.gets
+	if key, generated, err := v.resolve(ctx, legacy, 1600); err != nil || key != legacy || generated != nil {
+		t.Fatalf("expected source for upscale request, got %q (generated=%v, err=%v)", key, generated != nil, err)
+	}
+	if store.gets != gets {
+		t.Fatalf("expected no source read, got %d", store.gets-gets)
+	}
+
+	// A source narrower than the request is read once; later requests reuse its width.
+	narrow := "products/7001/cover/2025/03/01/narrow.webp"
+	store.objects[narrow] = pngBytes(t, 600, 300)
+	gets = store.gets
+	for i := 0; i < 2; i++ {
+		if key, generated, err := v.resolve(ctx, narrow, 1600); err != nil || key != narrow || generated != nil {
+			t.Fatalf("expected narrow source itself, got %q (generated=%v, err=%v)", key, generated != nil, err)
+		}
+	}
+	if store.gets != gets+1 {
+		t.Fatalf("expected the narrow source to be read once, got %d reads", store.gets-gets)
+	}
+
+	// Sources that cannot be resized (e.g. a video) serve the original, also remembered.
+	video := "products/7001/video/2025/03/01/clip.mp4"
+	store.objects[video] = []byte("\x00\x00\x00\x18ftypmp42")
+	gets = store.gets
+	for i := 0; i < 2; i++ {
+		if key, generated, err := v.resolve(ctx, video, 768); err != nil || key != video || generated != nil {
+			t.Fatalf("expected original for undecodable source, got %q (generated=%v, err=%v)", key, generated != nil, err)
+		}
+	}
+	if store.gets != gets+1 {
+		t.Fatalf("expected the undecodable source to be read once, got %d reads", store.gets-gets)
+	}
+
+	if _, _, err := v.resolve(ctx, "products/7001/cover/2025/03/01/missing.webp", 320); err != errVariantSourceMissing {
+		t.Fatalf("expected errVariantSourceMissing, got %v", err)
+	}
+}
+
+func TestImageSrcset(t *testing.T) {
+	widths := []int{320, 768, 1600}
+	if got := imageSrcset("https://cdn.example.com/a.jpg", widths, 0); got != "" {
+		t.Fatalf("expected no srcset for external URLs, got %q", got)
+	}
+	if got := imageSrcset("/api/v1/assets/products/1/cover/a.webp", nil, 0); got != "" {
+		t.Fatalf("expected no srcset without widths, got %q", got)
+	}
+
+	want := "/api/v1/assets/p/a.webp?w=320 320w, /api/v1/assets/p/a.webp?w=768 768w, /api/v1/assets/p/a.webp?w=1600 1600w"
+	if got := imageSrcset("/api/v1/assets/p/a.webp", widths, 0); got != want {
+		t.Fatalf("unexpected srcset:\n got %q\nwant %q", got, want)
+	}
+	// A known 1000px source stops at its own width too.
+	want = "/api/v1/assets/p/a.webp?w=320 320w, /api/v1/assets/p/a.webp?w=768 768w, /api/v1/assets/p/a.webp 1000w"
+	if got := imageSrcset("/api/v1/assets/p/a.webp", widths, 1000); got != want {
+		t.Fatalf("unexpected srcset:\n got %q\nwant %q", got, want)
+	}
+	// A 1000px rendition set stops at its own width.
+	want = "/api/v1/assets/p/b/w1000.jpg?w=320 320w, /api/v1/assets/p/b/w1000.jpg?w=768 768w, /api/v1/assets/p/b/w1000.jpg 1000w"
+	if got := imageSrcset("/api/v1/assets/p/b/w1000.jpg", widths, 0); got != want {
+		t.Fatalf("unexpected srcset:\n got %q\nwant %q", got, want)
+	}
+}
@@ -0,0 +1,68 @@
+package public
+
+import (
+	"errors"
+	"net/http"
+
+	"evening-gown/internal/logging"
+	"evening-gown/internal/model"
+	"evening-gown/internal/pagination"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// lookupSort resolves ?sort= against sorts, answering 400 itself for unknown names.
+func lookupSort[T any](c *gin.Context, sorts pagination.Sorts[T]) (pagination.Sort[T], bool) {
+	sort, ok := sorts.Lookup(c.Query("sort"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid sort", "sorts": sorts.Names()})
+	}
+	return sort, ok
+}
+
+// fetchPage counts q (offset mode only; total is nil in cursor mode) and loads one page of
+// listQ in sort order. It answers 400/500 itself and reports ok=false then.
+func fetchPage[T any](c *gin.Context, q, listQ *gorm.DB, sort pagination.Sort[T], page pagination.Params, what string) (rows []T, next string, total *int64, ok bool) {
+	if !page.CursorMode {
+		var n int64
+		if err := q.Count(&n).Error; err != nil {
+			logging.ErrorWithStack(logging.FromGin(c), "public "+what+" query count failed", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+			return nil, "", nil, false
+		}
+		total = &n
+	}
+
+	listQ, err := sort.After(listQ, page.Cursor)
+	if errors.Is(err, pagination.ErrInvalidCursor) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, "", nil, false
+	}
+	if err := sort.Order(listQ).Limit(page.Limit + 1).Offset(page.Offset).Find(&rows).Error; err != nil {
+		logging.ErrorWithStack(logging.FromGin(c), "public "+what+" query list failed", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return nil, "", nil, false
+	}
+	rows, next = sort.Page(rows, page.Limit)
+	return rows, next, total, true
+}
+
+// Sort whitelists of the public list endpoints.
+var (
+	publicProductSorts = pagination.Sorts[model.Product]{
+		{Name: "default", Keys: []pagination.Key{{Column: "is_new", Desc: true}, {Column: "new_rank", Desc: true}, {Column: "id", Desc: true}},
+			Values: func(p model.Product) []any { return []any{p.IsNew, p.NewRank, p.ID} }},
+		{Name: "newest", Keys: []pagination.Key{{Column: "created_at", Desc: true}, {Column: "id", Desc: true}},
+			Values: func(p model.Product) []any { return []any{p.CreatedAt, p.ID} }},
+		{Name: "style_no", Keys: []pagination.Key{{Column: "style_no"}, {Column: "id"}},
+			Values: func(p model.Product) []any { return []any{p.StyleNo, p.ID} }},
+	}
+
+	publicUpdateSorts = pagination.Sorts[model.UpdatePost]{
+		{Name: "default", Keys: []pagination.Key{{Column: "pinned_rank", Desc: true}, {Column: "COALESCE(published_at, created_at)", Desc: true}, {Column: "id", Desc: true}},
+			Values: func(u model.UpdatePost) []any { return []any{u.PinnedRank, u.SortTime(), u.ID} }},
+		{Name: "newest", Keys: []pagination.Key{{Column: "COALESCE(published_at, created_at)", Desc: true}, {Column: "id", Desc: true}},
+			Values: func(u model.UpdatePost) []any { return []any{u.SortTime(), u.ID} }},
+	}
+)
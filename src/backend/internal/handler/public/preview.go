@@ -0,0 +1,110 @@
+package public
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"evening-gown/internal/imaging"
+	"evening-gown/internal/model"
+	"evening-gown/internal/security"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// previewGrant returns the ?preview= token when it is a valid grant for kind/id.
+//
+// Invalid or expired tokens are ignored (the request is served like a normal public one),
+// so an old link to an item that has since been published keeps working.
+func previewGrant(c *gin.Context, signer *security.PreviewSigner, kind string, id uint) (string, bool) {
+	token := strings.TrimSpace(c.Query("preview"))
+	if token == "" || signer == nil {
+		return "", false
+	}
+	if !signer.Allows(token, kind, id, time.Now()) {
+		return "", false
+	}
+	return token, true
+}
+
+// setPreviewHeaders keeps preview responses out of shared caches and search engines.
+// Preview responses must never be written to PublicCache either.
+func setPreviewHeaders(c *gin.Context) {
+	c.Header("Cache-Control", "private, no-store")
+	c.Header("X-Robots-Tag", "noindex, nofollow")
+}
+
+// withPreviewToken appends the token to an /api/v1/assets URL so draft images load too.
+func withPreviewToken(assetURL, token string) string {
+	if !strings.HasPrefix(assetURL, "/api/v1/assets/") {
+		return assetURL
+	}
+	sep := "?"
+	if strings.Contains(assetURL, "?") {
+		sep = "&"
+	}
+	return assetURL + sep + "preview=" + url.QueryEscape(token)
+}
+
+// withPreviewSrcset adds the token to every candidate URL of an imageSrcset value.
+func withPreviewSrcset(srcset, token string) string {
+	if srcset == "" {
+		return ""
+	}
+	parts := strings.Split(srcset, ", ")
+	for i, part := range parts {
+		u, descriptor, _ := strings.Cut(part, " ")
+		parts[i] = withPreviewToken(u, token) + " " + descriptor
+	}
+	return strings.Join(parts, ", ")
+}
+
+// withPreviewTokens rewrites every /api/v1/assets URL inside a decoded JSON value
+// (e.g. gallery and section images in a product detail).
+func withPreviewTokens(v any, token string) any {
+	switch x := v.(type) {
+	case string:
+		return withPreviewToken(x, token)
+	case []any:
+		for i, it := range x {
+			x[i] = withPreviewTokens(it, token)
+		}
+	case map[string]any:
+		for k, it := range x {
+			x[k] = withPreviewTokens(it, token)
+		}
+	}
+	return v
+}
+
+// previewProduct loads a product in any publication state with its pending draft (if any)
+// overlaid, so a preview shows what publishing would put live.
+func previewProduct(ctx context.Context, db *gorm.DB, id uint) (model.Product, error) {
+	var p model.Product
+	if err := db.WithContext(ctx).Where("deleted_at IS NULL").First(&p, id).Error; err != nil {
+		return p, err
+	}
+	var d model.ProductDraft
+	if err := db.WithContext(ctx).Where("product_id = ?", id).First(&d).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return p, nil
+		}
+		return p, err
+	}
+	return d.WorkingCopy(p)
+}
+
+// productUsesImage reports whether objectKey (or, for a rendition, its upload) is one of
+// p's images. It mirrors the product_assets lookup for content not saved to that table yet.
+func productUsesImage(p model.Product, objectKey string) bool {
+	base, _, isRendition := imaging.ParseRenditionKey(objectKey)
+	for _, key := range model.ProductImageKeys(p) {
+		if key == objectKey || (isRendition && imaging.VariantBase(key) == base) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,27 @@
+package public
+
+import (
+	"encoding/json"
+	"testing"
+
+	"evening-gown/internal/model"
+)
+
+func TestProductUsesImage(t *testing.T) {
+	p := model.Product{
+		CoverImageKey: "products/6001/2026/01/abc/w1600.jpg",
+		DetailJSON:    json.RawMessage(`{"gallery":[{"url":"/api/v1/assets/products/6001/gallery/1.webp"}]}`),
+	}
+	for key, want := range map[string]bool{
+		"products/6001/2026/01/abc/w1600.jpg": true,
+		"products/6001/2026/01/abc/w320.jpg":  true, // another rendition of the cover
+		"products/6001/gallery/1.webp":        true,
+		"products/6001/gallery/1/w320.jpg":    true, // a width variant of a single-size upload
+		"products/6001/2026/01/def/w320.jpg":  false,
+		"products/6002/cover.jpg":             false,
+	} {
+		if got := productUsesImage(p, key); got != want {
+			t.Errorf("productUsesImage(%q) = %v, want %v", key, got, want)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package public
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	"evening-gown/internal/cache"
+	"evening-gown/internal/model"
+	"evening-gown/internal/search"
+
+	"gorm.io/gorm"
+)
+
+// productListFilter is the set of public catalog filters of ProductsHandler.List.
+type productListFilter struct {
+	season       string
+	category     string
+	availability string
+	isNew        string // "true", "false" or "" (any)
+	query        string // normalized, see search.Normalize
+}
+
+// apply narrows q to published products matching f. The filter on the column named skip is
+// left out; facet counts use this so that a selected value does not hide its alternatives.
+func (f productListFilter) apply(q *gorm.DB, skip string) *gorm.DB {
+	q = q.Where("published_at IS NOT NULL").Where("deleted_at IS NULL")
+	if f.season != "" && skip != "season" {
+		q = q.Where("season = ?", f.season)
+	}
+	if f.category != "" && skip != "category" {
+		q = q.Where("category = ?", f.category)
+	}
+	if f.availability != "" && skip != "availability" {
+		q = q.Where("availability = ?", f.availability)
+	}
+	if skip != "is_new" {
+		switch f.isNew {
+		case "true":
+			q = q.Where("is_new = true")
+		case "false":
+			q = q.Where("is_new = false")
+		}
+	}
+	return search.MatchProducts(q, f.query)
+}
+
+type facetCount struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+// productFacetCounts maps each filter to the number of products per value. Values without
+// products are omitted.
+type productFacetCounts struct {
+	Season       []facetCount `json:"season"`
+	Category     []facetCount `json:"category"`
+	Availability []facetCount `json:"availability"`
+	IsNew        []facetCount `json:"is_new"`
+}
+
+// productFacets counts the products matching f per season, category, availability and
+// is_new. Each facet applies every filter except its own (the usual faceted-search rule), so
+// it shows what selecting another value would yield. The result is cached under the
+// products version, independently of paging.
+func (h *ProductsHandler) productFacets(ctx context.Context, f productListFilter) (json.RawMessage, error) {
+	var cacheKey string
+	if h.cache != nil {
+		ver := h.cache.ProductsVersion(ctx)
+		cacheKey = h.cache.ProductFacetsKey(ver, f.season, f.category, f.availability, f.isNew, f.query)
+		if b, hit, _ := h.cache.GetJSONBytes(ctx, cacheKey); hit {
+			return b, nil
+		}
+	}
+
+	// One grouped COUNT per facet; the catalog is small and the result is cached.
+	base := func(skip string) *gorm.DB {
+		return f.apply(h.db.WithContext(ctx).Model(&model.Product{}), skip)
+	}
+	var (
+		out productFacetCounts
+		err error
+	)
+	if out.Season, err = countByColumn(base("season"), "season"); err != nil {
+		return nil, err
+	}
+	if out.Category, err = countByColumn(base("category"), "category"); err != nil {
+		return nil, err
+	}
+	if out.Availability, err = countByColumn(base("availability"), "availability"); err != nil {
+		return nil, err
+	}
+	var flags []struct {
+		Value bool
+		Count int64
+	}
+	if err := base("is_new").Select("is_new AS value, COUNT(*) AS count").Group("is_new").Order("is_new desc").Scan(&flags).Error; err != nil {
+		return nil, err
+	}
+	out.IsNew = make([]facetCount, 0, len(flags))
+	for _, it := range flags {
+		out.IsNew = append(out.IsNew, facetCount{Value: strconv.FormatBool(it.Value), Count: it.Count})
+	}
+
+	b, err := json.Marshal(out)
+	if err != nil {
+		return nil, err
+	}
+	if h.cache != nil && cacheKey != "" {
+		h.cache.SetJSONBytes(ctx, cacheKey, b, cache.TTLWithKeyJitter(publicProductsListTTL, cacheKey, 0.2))
+	}
+	return b, nil
+}
+
+// countByColumn groups q by column (a fixed column name), most frequent value first.
+func countByColumn(q *gorm.DB, column string) ([]facetCount, error) {
+	out := []facetCount{}
+	err := q.Select(column + " AS value, COUNT(*) AS count").
+		Group(column).
+		Order("count desc, value asc").
+		Scan(&out).Error
+	return out, err
+}
+
+// withFacets adds facets to a cached list response.
+func withFacets(body []byte, facets json.RawMessage) ([]byte, error) {
+	var resp map[string]json.RawMessage
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+	resp["facets"] = facets
+	return json.Marshal(resp)
+}
@@ -2,6 +2,7 @@ package public
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
@@ -10,20 +11,38 @@ import (
 	"evening-gown/internal/cache"
 	"evening-gown/internal/logging"
 	"evening-gown/internal/model"
+	"evening-gown/internal/pagination"
+	"evening-gown/internal/search"
+	"evening-gown/internal/security"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
 type ProductsHandler struct {
-	db    *gorm.DB
-	cache *cache.PublicCache
+	db       *gorm.DB
+	cache    *cache.PublicCache
+	previews *security.PreviewSigner
+	// imageWidths are the ?w= variants advertised in image srcsets; none without variants.
+	imageWidths []int
 }
 
 func NewProductsHandler(db *gorm.DB, publicCache *cache.PublicCache) *ProductsHandler {
 	return &ProductsHandler{db: db, cache: publicCache}
 }
 
+// NewProductsHandlerWithPreview also serves unpublished products to holders of a signed preview token.
+func NewProductsHandlerWithPreview(db *gorm.DB, publicCache *cache.PublicCache, previews *security.PreviewSigner) *ProductsHandler {
+	return &ProductsHandler{db: db, cache: publicCache, previews: previews}
+}
+
+// WithImageWidths adds srcset variant URLs for those widths to product images
+// (see ImageVariants.Widths).
+func (h *ProductsHandler) WithImageWidths(widths []int) *ProductsHandler {
+	h.imageWidths = widths
+	return h
+}
+
 const (
 	publicProductsListTTL   = 5 * time.Minute
 	publicProductDetailTTL  = 30 * time.Minute
@@ -40,6 +59,9 @@ type productListItem struct {
 	HoverImage   string `json:"hoverImage"`
 	IsNew        bool   `json:"isNew"`
 
+	CoverImageSrcset string `json:"coverImageSrcset,omitempty"`
+	HoverImageSrcset string `json:"hoverImageSrcset,omitempty"`
+
 	PriceMode string `json:"priceMode"`
 	PriceText string `json:"priceText"`
 }
@@ -52,42 +74,40 @@ func (h *ProductsHandler) List(c *gin.Context) {
 
 	ctx := c.Request.Context()
 
-	q := h.db.WithContext(c.Request.Context()).Model(&model.Product{}).
-		Where("published_at IS NOT NULL").
-		Where("deleted_at IS NULL")
-
 	season := strings.TrimSpace(c.Query("season"))
 	category := strings.TrimSpace(c.Query("category"))
 	availability := strings.TrimSpace(c.Query("availability"))
 	isNew := strings.TrimSpace(c.Query("is_new"))
+	query := search.Normalize(c.Query("q"))
 
-	if season != "" {
-		q = q.Where("season = ?", season)
-	}
-	if category != "" {
-		q = q.Where("category = ?", category)
-	}
-	if availability != "" {
-		q = q.Where("availability = ?", availability)
+	filter := productListFilter{season: season, category: category, availability: availability, isNew: isNew, query: query}
+	q := filter.apply(h.db.WithContext(ctx).Model(&model.Product{}), "")
+
+	page := pagination.ParseParams(c.Request.URL.Query(), 50, 200)
+	sort, ok := lookupSort(c, publicProductSorts)
+	if !ok {
+		return
 	}
-	if isNew != "" {
-		if isNew == "true" {
-			q = q.Where("is_new = true")
-		} else if isNew == "false" {
-			q = q.Where("is_new = false")
+	// Searches without an explicit sort are ordered by relevance, which has no cursor.
+	sortName := sort.Name
+	relevance := query != "" && strings.TrimSpace(c.Query("sort")) == ""
+	if relevance {
+		if page.CursorMode {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "cursor requires an explicit sort when searching"})
+			return
 		}
+		sortName = "relevance"
 	}
 
-	limit := parseIntQuery(c, "limit", 50)
-	offset := parseIntQuery(c, "offset", 0)
-	if limit <= 0 {
-		limit = 50
-	}
-	if limit > 200 {
-		limit = 200
-	}
-	if offset < 0 {
-		offset = 0
+	// facets=true adds per-value counts for the filter menus; see productFacets.
+	var facets json.RawMessage
+	if strings.TrimSpace(c.Query("facets")) == "true" {
+		var err error
+		if facets, err = h.productFacets(ctx, filter); err != nil {
+			logging.ErrorWithStack(logging.FromGin(c), "public products query facets failed", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+			return
+		}
 	}
 
 	// Cache-aside with versioned key: after admin writes bump the products version,
@@ -95,45 +115,69 @@ func (h *ProductsHandler) List(c *gin.Context) {
 	var cacheKey string
 	if h.cache != nil {
 		ver := h.cache.ProductsVersion(ctx)
-		cacheKey = h.cache.ProductsListKey(ver, season, category, availability, isNew, limit, offset)
+		cacheKey = h.cache.ProductsListKey(ver, season, category, availability, isNew, query, sortName, page.CursorOrNil(), page.Limit, page.Offset)
 		if b, hit, _ := h.cache.GetJSONBytes(ctx, cacheKey); hit {
+			if facets != nil {
+				if merged, err := withFacets(b, facets); err == nil {
+					b = merged
+				}
+			}
 			c.Data(http.StatusOK, "application/json; charset=utf-8", b)
 			return
 		}
 	}
 
-	var total int64
-	if err := q.Count(&total).Error; err != nil {
-		logging.ErrorWithStack(logging.FromGin(c), "public products query count failed", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+	var (
+		products []model.Product
+		next     string
+		total    *int64
+	)
+	listQ := q.Session(&gorm.Session{}).Select("id, style_no, season, category, availability, cover_image_url, cover_image_key, hover_image_url, hover_image_key, is_new, new_rank, created_at")
+	if relevance {
+		var n int64
+		if err := q.Count(&n).Error; err != nil {
+			logging.ErrorWithStack(logging.FromGin(c), "public products query count failed", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+			return
+		}
+		total = &n
+		if err := search.OrderProducts(listQ, query, "is_new desc, new_rank desc, id desc").Limit(page.Limit).Offset(page.Offset).Find(&products).Error; err != nil {
+			logging.ErrorWithStack(logging.FromGin(c), "public products query list failed", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+			return
+		}
+	} else if products, next, total, ok = fetchPage(c, q, listQ, sort, page, "products"); !ok {
 		return
 	}
 
-	var products []model.Product
-	if err := q.Select("id, style_no, season, category, availability, cover_image_url, cover_image_key, hover_image_url, hover_image_key, is_new, new_rank").
-		Order("is_new desc, new_rank desc, id desc").Limit(limit).Offset(offset).Find(&products).Error; err != nil {
-		logging.ErrorWithStack(logging.FromGin(c), "public products query list failed", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
-		return
+	urls := make([]string, 0, 2*len(products))
+	for _, p := range products {
+		urls = append(urls, pickPublicImageURL(p.CoverImageKey, p.CoverImageURL), pickPublicImageURL(p.HoverImageKey, p.HoverImageURL))
 	}
+	srcWidths := h.sourceWidths(c, urls...)
 
 	items := make([]productListItem, 0, len(products))
 	for _, p := range products {
+		cover := pickPublicImageURL(p.CoverImageKey, p.CoverImageURL)
+		hover := pickPublicImageURL(p.HoverImageKey, p.HoverImageURL)
 		items = append(items, productListItem{
 			ID:           p.ID,
 			StyleNo:      p.StyleNo,
 			Season:       p.Season,
 			Category:     p.Category,
 			Availability: p.Availability,
-			CoverImage:   pickPublicImageURL(p.CoverImageKey, p.CoverImageURL),
-			HoverImage:   pickPublicImageURL(p.HoverImageKey, p.HoverImageURL),
+			CoverImage:   cover,
+			HoverImage:   hover,
 			IsNew:        p.IsNew,
 			PriceMode:    "negotiable",
 			PriceText:    "面议",
+
+			CoverImageSrcset: imageSrcset(cover, h.imageWidths, srcWidths[cover]),
+			HoverImageSrcset: imageSrcset(hover, h.imageWidths, srcWidths[hover]),
 		})
 	}
 
-	resp := gin.H{"total": total, "items": items}
+	resp := pagination.Response(items, next, total)
 	if h.cache != nil && cacheKey != "" {
 		b, err := json.Marshal(resp)
 		if err == nil {
@@ -142,6 +186,10 @@ func (h *ProductsHandler) List(c *gin.Context) {
 		}
 	}
 
+	// Facets are cached on their own: they do not depend on the page or sort.
+	if facets != nil {
+		resp["facets"] = facets
+	}
 	c.JSON(http.StatusOK, resp)
 }
 
@@ -159,6 +207,11 @@ func (h *ProductsHandler) Get(c *gin.Context) {
 		return
 	}
 
+	if token, ok := previewGrant(c, h.previews, security.PreviewProduct, uint(id)); ok {
+		h.getPreview(c, uint(id), token)
+		return
+	}
+
 	var cacheKey string
 	if h.cache != nil {
 		ver := h.cache.ProductsVersion(ctx)
@@ -186,6 +239,111 @@ func (h *ProductsHandler) Get(c *gin.Context) {
 		return
 	}
 
+	variants, err := h.loadVariants(c, p.ID)
+	if err != nil {
+		logging.ErrorWithStack(logging.FromGin(c), "public product variants query failed", err, "product_id", p.ID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return
+	}
+	resp := h.detailResponse(c, p, variants)
+
+	if h.cache != nil && cacheKey != "" {
+		b, err := json.Marshal(resp)
+		if err == nil {
+			ttl := cache.TTLWithKeyJitter(publicProductDetailTTL, cacheKey, 0.2)
+			h.cache.SetJSONBytes(ctx, cacheKey, b, ttl)
+		}
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// getPreview serves a product in any publication state to a preview-token holder, with its
+// pending draft applied. The response bypasses PublicCache entirely.
+func (h *ProductsHandler) getPreview(c *gin.Context, id uint, token string) {
+	setPreviewHeaders(c)
+
+	p, err := previewProduct(c.Request.Context(), h.db, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+		return
+	}
+	if err != nil {
+		logging.ErrorWithStack(logging.FromGin(c), "public product preview query failed", err, "product_id", id)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return
+	}
+
+	variants, err := h.loadVariants(c, p.ID)
+	if err != nil {
+		logging.ErrorWithStack(logging.FromGin(c), "public product variants query failed", err, "product_id", p.ID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return
+	}
+	resp := h.detailResponse(c, p, variants)
+	resp["coverImage"] = withPreviewToken(resp["coverImage"].(string), token)
+	resp["hoverImage"] = withPreviewToken(resp["hoverImage"].(string), token)
+	for _, field := range []string{"coverImageSrcset", "hoverImageSrcset"} {
+		if srcset, ok := resp[field].(string); ok {
+			resp[field] = withPreviewSrcset(srcset, token)
+		}
+	}
+	resp["detail"] = withPreviewTokens(resp["detail"], token)
+	resp["preview"] = true
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *ProductsHandler) loadVariants(c *gin.Context, productID uint) ([]model.ProductVariant, error) {
+	var variants []model.ProductVariant
+	err := h.db.WithContext(c.Request.Context()).
+		Where("product_id = ?", productID).
+		Order("position asc, id asc").
+		Find(&variants).Error
+	return variants, err
+}
+
+type publicVariant struct {
+	Color        string `json:"color"`
+	Size         string `json:"size"`
+	StyleNo      string `json:"styleNo"`
+	Availability string `json:"availability"`
+	MOQ          int    `json:"moq,omitempty"`
+	LeadTime     string `json:"leadTime,omitempty"`
+}
+
+// sourceWidths looks up the known source widths of image URLs when srcsets are enabled.
+func (h *ProductsHandler) sourceWidths(c *gin.Context, urls ...string) map[string]int {
+	if len(h.imageWidths) == 0 {
+		return nil
+	}
+	return assetWidths(c.Request.Context(), h.db, urls...)
+}
+
+func (h *ProductsHandler) detailResponse(c *gin.Context, p model.Product, variants []model.ProductVariant) gin.H {
+	srcWidths := h.sourceWidths(c,
+		pickPublicImageURL(p.CoverImageKey, p.CoverImageURL),
+		pickPublicImageURL(p.HoverImageKey, p.HoverImageURL))
+	return productDetailResponse(p, variants, h.imageWidths, srcWidths)
+}
+
+// productDetailResponse is the public detail shape. Archived variants are left out.
+// Image srcsets are added when imageWidths is set, capped at srcWidths (keyed by image URL).
+func productDetailResponse(p model.Product, variants []model.ProductVariant, imageWidths []int, srcWidths map[string]int) gin.H {
+	items := make([]publicVariant, 0, len(variants))
+	for _, v := range variants {
+		availability := v.EffectiveAvailability(p.Availability)
+		if availability == "archived" {
+			continue
+		}
+		items = append(items, publicVariant{
+			Color:        v.Color,
+			Size:         v.Size,
+			StyleNo:      v.StyleNo(p.StyleNo),
+			Availability: availability,
+			MOQ:          v.MOQ,
+			LeadTime:     v.LeadTime,
+		})
+	}
 	resp := gin.H{
 		"id":           p.ID,
 		"slug":         p.Slug,
@@ -199,17 +357,16 @@ func (h *ProductsHandler) Get(c *gin.Context) {
 		"priceMode":    "negotiable",
 		"priceText":    "面议",
 		"detail":       jsonOrNull(p.DetailJSON),
+		"variants":     items,
 	}
-
-	if h.cache != nil && cacheKey != "" {
-		b, err := json.Marshal(resp)
-		if err == nil {
-			ttl := cache.TTLWithKeyJitter(publicProductDetailTTL, cacheKey, 0.2)
-			h.cache.SetJSONBytes(ctx, cacheKey, b, ttl)
-		}
+	cover, hover := resp["coverImage"].(string), resp["hoverImage"].(string)
+	if srcset := imageSrcset(cover, imageWidths, srcWidths[cover]); srcset != "" {
+		resp["coverImageSrcset"] = srcset
 	}
-
-	c.JSON(http.StatusOK, resp)
+	if srcset := imageSrcset(hover, imageWidths, srcWidths[hover]); srcset != "" {
+		resp["hoverImageSrcset"] = srcset
+	}
+	return resp
 }
 
 func pickPublicImageURL(objectKey string, legacyURL string) string {
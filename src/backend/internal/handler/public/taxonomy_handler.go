@@ -0,0 +1,98 @@
+package public
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"evening-gown/internal/cache"
+	"evening-gown/internal/logging"
+	"evening-gown/internal/model"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// TaxonomyHandler serves the active seasons and categories for the site's filter menus.
+type TaxonomyHandler struct {
+	db    *gorm.DB
+	cache *cache.PublicCache
+}
+
+func NewTaxonomyHandler(db *gorm.DB, publicCache *cache.PublicCache) *TaxonomyHandler {
+	return &TaxonomyHandler{db: db, cache: publicCache}
+}
+
+const publicTaxonomyTTL = 30 * time.Minute
+
+type taxonomyItem struct {
+	Code      string          `json:"code"`
+	LabelI18n json.RawMessage `json:"labelI18n"`
+}
+
+// List returns active terms grouped by kind, in menu order:
+// {"season":[{"code":"fw25","labelI18n":{...}}],"category":[...]}.
+// Query: ?kind=season|category
+// Route: GET /api/v1/taxonomy
+func (h *TaxonomyHandler) List(c *gin.Context) {
+	if h == nil || h.db == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "service unavailable"})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	kinds := model.TaxonomyKinds
+	kind := strings.TrimSpace(c.Query("kind"))
+	if kind != "" {
+		if !model.IsValidTaxonomyKind(kind) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid kind"})
+			return
+		}
+		kinds = []string{kind}
+	}
+
+	var cacheKey string
+	if h.cache != nil {
+		ver := h.cache.TaxonomyVersion(ctx)
+		cacheKey = h.cache.TaxonomyKey(ver, kind)
+		if b, hit, _ := h.cache.GetJSONBytes(ctx, cacheKey); hit {
+			c.Data(http.StatusOK, "application/json; charset=utf-8", b)
+			return
+		}
+	}
+
+	var terms []model.TaxonomyTerm
+	if err := h.db.WithContext(ctx).
+		Where("active = ?", true).
+		Where("kind IN ?", kinds).
+		Order("sort_order asc, code asc").
+		Find(&terms).Error; err != nil {
+		logging.ErrorWithStack(logging.FromGin(c), "public taxonomy query failed", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return
+	}
+
+	resp := make(map[string][]taxonomyItem, len(kinds))
+	for _, k := range kinds {
+		resp[k] = []taxonomyItem{}
+	}
+	for _, t := range terms {
+		labels := t.LabelI18n
+		if len(labels) == 0 {
+			labels = json.RawMessage(`{}`)
+		}
+		resp[t.Kind] = append(resp[t.Kind], taxonomyItem{Code: t.Code, LabelI18n: labels})
+	}
+
+	if h.cache != nil && cacheKey != "" {
+		b, err := json.Marshal(resp)
+		if err == nil {
+			ttl := cache.TTLWithKeyJitter(publicTaxonomyTTL, cacheKey, 0.2)
+			h.cache.SetJSONBytes(ctx, cacheKey, b, ttl)
+		}
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
@@ -8,22 +8,29 @@ import (
 	"time"
 
 	"evening-gown/internal/cache"
-	"evening-gown/internal/logging"
 	"evening-gown/internal/model"
+	"evening-gown/internal/pagination"
+	"evening-gown/internal/security"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
 type UpdatesHandler struct {
-	db    *gorm.DB
-	cache *cache.PublicCache
+	db       *gorm.DB
+	cache    *cache.PublicCache
+	previews *security.PreviewSigner
 }
 
 func NewUpdatesHandler(db *gorm.DB, publicCache *cache.PublicCache) *UpdatesHandler {
 	return &UpdatesHandler{db: db, cache: publicCache}
 }
 
+// NewUpdatesHandlerWithPreview also serves unpublished updates to holders of a signed preview token.
+func NewUpdatesHandlerWithPreview(db *gorm.DB, publicCache *cache.PublicCache, previews *security.PreviewSigner) *UpdatesHandler {
+	return &UpdatesHandler{db: db, cache: publicCache, previews: previews}
+}
+
 const (
 	publicUpdatesListTTL    = 5 * time.Minute
 	publicUpdateDetailTTL   = 30 * time.Minute
@@ -47,22 +54,16 @@ func (h *UpdatesHandler) List(c *gin.Context) {
 
 	ctx := c.Request.Context()
 
-	limit := parseIntQuery(c, "limit", 3)
-	offset := parseIntQuery(c, "offset", 0)
-	if limit <= 0 {
-		limit = 3
-	}
-	if limit > 50 {
-		limit = 50
-	}
-	if offset < 0 {
-		offset = 0
+	page := pagination.ParseParams(c.Request.URL.Query(), 3, 50)
+	sort, ok := lookupSort(c, publicUpdateSorts)
+	if !ok {
+		return
 	}
 
 	var cacheKey string
 	if h.cache != nil {
 		ver := h.cache.UpdatesVersion(ctx)
-		cacheKey = h.cache.UpdatesListKey(ver, limit, offset)
+		cacheKey = h.cache.UpdatesListKey(ver, sort.Name, page.CursorOrNil(), page.Limit, page.Offset)
 		if b, hit, _ := h.cache.GetJSONBytes(ctx, cacheKey); hit {
 			c.Data(http.StatusOK, "application/json; charset=utf-8", b)
 			return
@@ -75,17 +76,8 @@ func (h *UpdatesHandler) List(c *gin.Context) {
 		Where("status = ?", "published").
 		Where("deleted_at IS NULL")
 
-	var total int64
-	if err := q.Count(&total).Error; err != nil {
-		logging.ErrorWithStack(logging.FromGin(c), "public updates query count failed", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
-		return
-	}
-
-	var posts []model.UpdatePost
-	if err := q.Order("pinned_rank desc, published_at desc, id desc").Limit(limit).Offset(offset).Find(&posts).Error; err != nil {
-		logging.ErrorWithStack(logging.FromGin(c), "public updates query list failed", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+	posts, next, total, ok := fetchPage(c, q, q, sort, page, "updates")
+	if !ok {
 		return
 	}
 
@@ -105,7 +97,7 @@ func (h *UpdatesHandler) List(c *gin.Context) {
 		})
 	}
 
-	resp := gin.H{"total": total, "items": items}
+	resp := pagination.Response(items, next, total)
 	if h.cache != nil && cacheKey != "" {
 		b, err := json.Marshal(resp)
 		if err == nil {
@@ -131,6 +123,11 @@ func (h *UpdatesHandler) Get(c *gin.Context) {
 		return
 	}
 
+	if _, ok := previewGrant(c, h.previews, security.PreviewUpdate, uint(id)); ok {
+		h.getPreview(c, uint(id))
+		return
+	}
+
 	var cacheKey string
 	if h.cache != nil {
 		ver := h.cache.UpdatesVersion(ctx)
@@ -159,11 +156,43 @@ func (h *UpdatesHandler) Get(c *gin.Context) {
 		return
 	}
 
+	resp := updateDetailResponse(p)
+
+	if h.cache != nil && cacheKey != "" {
+		b, err := json.Marshal(resp)
+		if err == nil {
+			ttl := cache.TTLWithKeyJitter(publicUpdateDetailTTL, cacheKey, 0.2)
+			h.cache.SetJSONBytes(ctx, cacheKey, b, ttl)
+		}
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// getPreview serves an update in any status to a preview-token holder, bypassing PublicCache.
+func (h *UpdatesHandler) getPreview(c *gin.Context, id uint) {
+	setPreviewHeaders(c)
+
+	var p model.UpdatePost
+	if err := h.db.WithContext(c.Request.Context()).
+		Where("type = ?", "company").
+		Where("deleted_at IS NULL").
+		First(&p, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+		return
+	}
+
+	resp := updateDetailResponse(p)
+	resp["preview"] = true
+	c.JSON(http.StatusOK, resp)
+}
+
+func updateDetailResponse(p model.UpdatePost) gin.H {
 	date := ""
 	if p.PublishedAt != nil {
 		date = p.PublishedAt.UTC().Format(time.RFC3339)
 	}
-	resp := gin.H{
+	return gin.H{
 		"id":    p.ID,
 		"type":  p.Type,
 		"date":  date,
@@ -172,16 +201,6 @@ func (h *UpdatesHandler) Get(c *gin.Context) {
 		"body":  p.Body,
 		"ref":   p.RefCode,
 	}
-
-	if h.cache != nil && cacheKey != "" {
-		b, err := json.Marshal(resp)
-		if err == nil {
-			ttl := cache.TTLWithKeyJitter(publicUpdateDetailTTL, cacheKey, 0.2)
-			h.cache.SetJSONBytes(ctx, cacheKey, b, ttl)
-		}
-	}
-
-	c.JSON(http.StatusOK, resp)
 }
 
 func firstNonEmpty(a, b string) string {
@@ -0,0 +1,149 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+)
+
+const (
+	// headerWindow is how much DecodeConfigAt reads per request; small headers need one read.
+	headerWindow = 64 << 10
+	// maxJPEGSegments bounds the markers walked before giving up on finding a frame header.
+	maxJPEGSegments = 4096
+)
+
+// ErrInvalidHeader marks data that DecodeConfigAt could not parse as an image header, as
+// opposed to an error reading it.
+var ErrInvalidHeader = errors.New("invalid image header")
+
+var errNoJPEGFrame = fmt.Errorf("%w: jpeg: no frame header before scan data", ErrInvalidHeader)
+
+// DecodeConfigAt is DecodeConfig for an object read through r (size bytes long), e.g. a
+// ranged MinIO object. JPEG markers are walked segment by segment, skipping metadata
+// (XMP, ICC, MPF, ...) of any size, so only the segment headers, EXIF and the frame header
+// are read. PNG and WebP keep their dimensions in the first bytes. Errors reading r are
+// returned as is; malformed data wraps ErrInvalidHeader or is ErrUnsupportedFormat.
+func DecodeConfigAt(r io.ReaderAt, size int64) (width, height int, format string, err error) {
+	w := &windowReader{r: r, size: size}
+	head, err := w.at(0, min(size, 32))
+	if err != nil {
+		return 0, 0, "", err
+	}
+	format = Sniff(head)
+	if format == "" {
+		return 0, 0, "", ErrUnsupportedFormat
+	}
+	if format != FormatJPEG {
+		head, err := w.at(0, min(size, headerWindow))
+		if err != nil {
+			return 0, 0, format, err
+		}
+		cfg, _, err := image.DecodeConfig(bytes.NewReader(head))
+		if err != nil {
+			return 0, 0, format, fmt.Errorf("%w: decode %s header: %w", ErrInvalidHeader, format, err)
+		}
+		return cfg.Width, cfg.Height, format, nil
+	}
+
+	width, height, orientation, err := jpegConfigAt(w)
+	if err != nil {
+		return 0, 0, format, err
+	}
+	if orientation >= 5 {
+		// Orientations 5-8 rotate by 90 degrees.
+		return height, width, format, nil
+	}
+	return width, height, format, nil
+}
+
+// jpegConfigAt walks the markers after SOI up to the first SOF segment and returns the frame
+// size plus the EXIF orientation (1 when absent).
+func jpegConfigAt(w *windowReader) (width, height, orientation int, err error) {
+	orientation = 1
+	off := int64(2) // after SOI
+	for range maxJPEGSegments {
+		hdr, err := w.at(off, 4)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		if hdr[0] != 0xFF {
+			return 0, 0, 0, fmt.Errorf("%w: jpeg: invalid marker at offset %d", ErrInvalidHeader, off)
+		}
+		marker := hdr[1]
+		switch {
+		case marker == 0xFF: // fill byte
+			off++
+			continue
+		case marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7): // standalone markers
+			off += 2
+			continue
+		case marker == 0xDA || marker == 0xD9: // start of scan, end of image
+			return 0, 0, 0, errNoJPEGFrame
+		}
+		length := int64(binary.BigEndian.Uint16(hdr[2:4]))
+		if length < 2 {
+			return 0, 0, 0, fmt.Errorf("%w: jpeg: invalid segment length at offset %d", ErrInvalidHeader, off)
+		}
+
+		switch {
+		case isJPEGFrameMarker(marker):
+			// P(1) Y(2) X(2)
+			seg, err := w.at(off+4, 5)
+			if err != nil {
+				return 0, 0, 0, err
+			}
+			height = int(binary.BigEndian.Uint16(seg[1:3]))
+			width = int(binary.BigEndian.Uint16(seg[3:5]))
+			if width == 0 || height == 0 {
+				return 0, 0, 0, fmt.Errorf("%w: jpeg: invalid frame size", ErrInvalidHeader)
+			}
+			return width, height, orientation, nil
+		case marker == 0xE1 && length > 8:
+			seg, err := w.at(off+4, length-2)
+			if err != nil {
+				return 0, 0, 0, err
+			}
+			if string(seg[:6]) == "Exif\x00\x00" {
+				if o := tiffOrientation(seg[6:]); o != 0 {
+					orientation = o
+				}
+			}
+		}
+		off += 2 + length
+	}
+	return 0, 0, 0, errNoJPEGFrame
+}
+
+// isJPEGFrameMarker reports SOF0-SOF15, excluding DHT (C4), JPG (C8) and DAC (CC).
+func isJPEGFrameMarker(m byte) bool {
+	return m >= 0xC0 && m <= 0xCF && m != 0xC4 && m != 0xC8 && m != 0xCC
+}
+
+// windowReader serves small reads from one cached window of r, so walking nearby JPEG
+// segments costs a single ranged request.
+type windowReader struct {
+	r     io.ReaderAt
+	size  int64
+	start int64
+	buf   []byte
+}
+
+func (w *windowReader) at(off, n int64) ([]byte, error) {
+	if off < 0 || n < 0 || off+n > w.size {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidHeader, io.ErrUnexpectedEOF)
+	}
+	if off >= w.start && off+n <= w.start+int64(len(w.buf)) {
+		return w.buf[off-w.start : off-w.start+n], nil
+	}
+	want := min(max(n, headerWindow), w.size-off)
+	buf := make([]byte, want)
+	if _, err := w.r.ReadAt(buf, off); err != nil && !(errors.Is(err, io.EOF) && off+want == w.size) {
+		return nil, err
+	}
+	w.start, w.buf = off, buf
+	return buf[:n], nil
+}
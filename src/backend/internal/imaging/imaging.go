@@ -0,0 +1,224 @@
+// Package imaging turns uploaded product photos into resized renditions, in pure Go.
+//
+// JPEG, PNG and WebP sources are decoded with the standard library and golang.org/x/image.
+// JPEG EXIF orientation is applied to the pixels; no metadata (EXIF, GPS, ICC) is carried
+// over, since every rendition is re-encoded from the decoded image. Renditions are JPEG:
+// there is no pure-Go WebP encoder, and transparency is flattened onto white, the catalog's
+// background.
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	xdraw "golang.org/x/image/draw"
+	"golang.org/x/image/webp"
+)
+
+// Source formats accepted by Decode.
+const (
+	FormatJPEG = "jpeg"
+	FormatPNG  = "png"
+	FormatWebP = "webp"
+)
+
+// Renditions are written as JPEG.
+const (
+	RenditionContentType = "image/jpeg"
+	RenditionExt         = "jpg"
+)
+
+// MaxPixels bounds the decoded size of a source image, so that a small file cannot expand
+// into gigabytes of pixels.
+const MaxPixels = 50_000_000
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported image format (JPEG, PNG or WebP expected)")
+	ErrTooManyPixels     = errors.New("image dimensions too large")
+)
+
+// Sniff returns the format of an image from its first bytes, or "" when it is not one Decode
+// accepts (HEIC included: it has no pure-Go decoder).
+func Sniff(head []byte) string {
+	switch {
+	case len(head) >= 3 && head[0] == 0xFF && head[1] == 0xD8 && head[2] == 0xFF:
+		return FormatJPEG
+	case len(head) >= 8 && string(head[:8]) == "\x89PNG\r\n\x1a\n":
+		return FormatPNG
+	case len(head) >= 12 && string(head[:4]) == "RIFF" && string(head[8:12]) == "WEBP":
+		return FormatWebP
+	}
+	return ""
+}
+
+// Decode decodes a JPEG, PNG or WebP image and applies its EXIF orientation.
+func Decode(data []byte) (image.Image, string, error) {
+	format := Sniff(data)
+	var (
+		decodeConfig func([]byte) (image.Config, error)
+		decode       func([]byte) (image.Image, error)
+	)
+	switch format {
+	case FormatJPEG:
+		decodeConfig = func(b []byte) (image.Config, error) { return jpeg.DecodeConfig(bytes.NewReader(b)) }
+		decode = func(b []byte) (image.Image, error) { return jpeg.Decode(bytes.NewReader(b)) }
+	case FormatPNG:
+		decodeConfig = func(b []byte) (image.Config, error) { return png.DecodeConfig(bytes.NewReader(b)) }
+		decode = func(b []byte) (image.Image, error) { return png.Decode(bytes.NewReader(b)) }
+	case FormatWebP:
+		decodeConfig = func(b []byte) (image.Config, error) { return webp.DecodeConfig(bytes.NewReader(b)) }
+		decode = func(b []byte) (image.Image, error) { return webp.Decode(bytes.NewReader(b)) }
+	default:
+		return nil, "", ErrUnsupportedFormat
+	}
+
+	cfg, err := decodeConfig(data)
+	if err != nil {
+		return nil, format, fmt.Errorf("decode %s header: %w", format, err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || int64(cfg.Width)*int64(cfg.Height) > MaxPixels {
+		return nil, format, ErrTooManyPixels
+	}
+	img, err := decode(data)
+	if err != nil {
+		return nil, format, fmt.Errorf("decode %s: %w", format, err)
+	}
+	if format == FormatJPEG {
+		img = applyOrientation(img, jpegOrientation(data))
+	}
+	return img, format, nil
+}
+
+// DecodeConfig reads the format and dimensions of a JPEG, PNG or WebP image from its leading
+// bytes, without decoding the pixels. JPEG dimensions account for EXIF orientation, like Decode.
+func DecodeConfig(head []byte) (width, height int, format string, err error) {
+	return DecodeConfigAt(bytes.NewReader(head), int64(len(head)))
+}
+
+// Rendition is one encoded size of an image.
+type Rendition struct {
+	Width  int
+	Height int
+	Data   []byte
+}
+
+// Renditions resizes img to each of widths, never upscaling: widths at or above the image
+// width are replaced by a single rendition at the original width. The result is ordered by
+// width.
+func Renditions(img image.Image, widths []int, quality int) ([]Rendition, error) {
+	if quality < 1 || quality > 100 {
+		quality = jpeg.DefaultQuality
+	}
+	b := img.Bounds()
+	srcW, srcH := b.Dx(), b.Dy()
+	if srcW <= 0 || srcH <= 0 {
+		return nil, errors.New("empty image")
+	}
+
+	var out []Rendition
+	for _, w := range targetWidths(widths, srcW) {
+		h := (srcH*w + srcW/2) / srcW
+		if h < 1 {
+			h = 1
+		}
+		dst := image.NewRGBA(image.Rect(0, 0, w, h))
+		draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+		if w == srcW {
+			draw.Draw(dst, dst.Bounds(), img, b.Min, draw.Over)
+		} else {
+			xdraw.CatmullRom.Scale(dst, dst.Bounds(), img, b, xdraw.Over, nil)
+		}
+
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: quality}); err != nil {
+			return nil, fmt.Errorf("encode %dpx rendition: %w", w, err)
+		}
+		out = append(out, Rendition{Width: w, Height: h, Data: buf.Bytes()})
+	}
+	return out, nil
+}
+
+// targetWidths sorts and de-duplicates widths, capping them at srcW.
+func targetWidths(widths []int, srcW int) []int {
+	seen := map[int]bool{}
+	var out []int
+	for _, w := range widths {
+		if w <= 0 {
+			continue
+		}
+		if w > srcW {
+			w = srcW
+		}
+		if !seen[w] {
+			seen[w] = true
+			out = append(out, w)
+		}
+	}
+	if len(out) == 0 {
+		out = append(out, srcW)
+	}
+	sort.Ints(out)
+	return out
+}
+
+// RenditionKey is the object key of the width rendition of the image stored under base.
+// Renditions of one upload share base: products/{styleNo}/{kind}/{yyyy}/{mm}/{dd}/{uuid}.
+func RenditionKey(base string, width int) string {
+	return fmt.Sprintf("%s/w%d.%s", base, width, RenditionExt)
+}
+
+var renditionKeyRe = regexp.MustCompile(`^(.+)/w([1-9][0-9]{0,4})\.` + RenditionExt + `$`)
+
+// ParseRenditionKey splits a key made by RenditionKey into its base and width.
+func ParseRenditionKey(key string) (base string, width int, ok bool) {
+	m := renditionKeyRe.FindStringSubmatch(key)
+	if m == nil {
+		return "", 0, false
+	}
+	width, err := strconv.Atoi(m[2])
+	if err != nil {
+		return "", 0, false
+	}
+	return m[1], width, true
+}
+
+// VariantBase is the base under which width variants of key live: the rendition base for a
+// rendition key, otherwise key without its extension, so that variants generated for an
+// older single-size upload ".../{uuid}.webp" are stored as ".../{uuid}/w320.jpg".
+func VariantBase(key string) string {
+	if base, _, ok := ParseRenditionKey(key); ok {
+		return base
+	}
+	if i := strings.LastIndex(key, "."); i > strings.LastIndex(key, "/") {
+		return key[:i]
+	}
+	return key
+}
+
+// SnapWidth rounds w up to the nearest of widths, or down to the largest one, so that
+// arbitrary requested widths map onto a small set of stored variants.
+func SnapWidth(w int, widths []int) int {
+	best, largest := 0, 0
+	for _, it := range widths {
+		if it > largest {
+			largest = it
+		}
+		if it >= w && (best == 0 || it < best) {
+			best = it
+		}
+	}
+	if best == 0 {
+		return largest
+	}
+	return best
+}
@@ -0,0 +1,194 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// quadrants is a w×h image whose left half is red and right half blue.
+func quadrants(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(img, image.Rect(0, 0, w/2, h), image.NewUniform(color.RGBA{R: 255, A: 255}), image.Point{}, draw.Src)
+	draw.Draw(img, image.Rect(w/2, 0, w, h), image.NewUniform(color.RGBA{B: 255, A: 255}), image.Point{}, draw.Src)
+	return img
+}
+
+// withOrientation inserts an EXIF APP1 segment holding orientation after the JPEG SOI.
+func withOrientation(t *testing.T, jpg []byte, orientation uint16) []byte {
+	t.Helper()
+	tiff := []byte("II*\x00\x08\x00\x00\x00")
+	entry := make([]byte, 2+12+4)
+	binary.LittleEndian.PutUint16(entry[0:], 1)      // one IFD entry
+	binary.LittleEndian.PutUint16(entry[2:], 0x0112) // Orientation
+	binary.LittleEndian.PutUint16(entry[4:], 3)      // SHORT
+	binary.LittleEndian.PutUint32(entry[6:], 1)
+	binary.LittleEndian.PutUint16(entry[10:], orientation)
+	payload := append([]byte("Exif\x00\x00"), append(tiff, entry...)...)
+
+	seg := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(seg[2:], uint16(len(payload)+2))
+	seg = append(seg, payload...)
+
+	out := append([]byte{}, jpg[:2]...)
+	out = append(out, seg...)
+	return append(out, jpg[2:]...)
+}
+
+func TestDecode_AppliesEXIFOrientation(t *testing.T) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, quadrants(80, 40), &jpeg.Options{Quality: 95}); err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	if got := jpegOrientation(buf.Bytes()); got != 1 {
+		t.Fatalf("expected orientation 1 without EXIF, got %d", got)
+	}
+
+	// Orientation 6: the stored pixels must be turned 90° clockwise, so red ends up on top.
+	img, format, err := Decode(withOrientation(t, buf.Bytes(), 6))
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if format != FormatJPEG {
+		t.Fatalf("expected jpeg, got %q", format)
+	}
+	if b := img.Bounds(); b.Dx() != 40 || b.Dy() != 80 {
+		t.Fatalf("expected 40x80 after rotation, got %dx%d", b.Dx(), b.Dy())
+	}
+	top := color.RGBAModel.Convert(img.At(20, 10)).(color.RGBA)
+	bottom := color.RGBAModel.Convert(img.At(20, 70)).(color.RGBA)
+	if top.R < 200 || top.B > 60 || bottom.B < 200 || bottom.R > 60 {
+		t.Fatalf("unexpected colors after rotation: top=%v bottom=%v", top, bottom)
+	}
+
+	// DecodeConfig reports the same, displayed dimensions without decoding the pixels.
+	if w, h, format, err := DecodeConfig(withOrientation(t, buf.Bytes(), 6)); err != nil || format != FormatJPEG || w != 40 || h != 80 {
+		t.Fatalf("expected jpeg 40x80 from the header, got %q %dx%d err=%v", format, w, h, err)
+	}
+}
+
+// failingReaderAt fails every read, like an unreachable object store.
+type failingReaderAt struct{ err error }
+
+func (f failingReaderAt) ReadAt([]byte, int64) (int, error) { return 0, f.err }
+
+func TestDecodeConfigAt_WalksMetadataBeforeFrameHeader(t *testing.T) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, quadrants(80, 40), &jpeg.Options{Quality: 90}); err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	jpg := withOrientation(t, buf.Bytes(), 6)
+
+	// Five full APP2 segments (~320KB, e.g. an ICC profile) push SOF far past the first window.
+	out := append([]byte{}, jpg[:2]...)
+	for range 5 {
+		seg := []byte{0xFF, 0xE2, 0xFF, 0xFF}
+		out = append(out, seg...)
+		out = append(out, make([]byte, 0xFFFF-2)...)
+	}
+	out = append(out, jpg[2:]...)
+
+	w, h, format, err := DecodeConfigAt(bytes.NewReader(out), int64(len(out)))
+	if err != nil || format != FormatJPEG || w != 40 || h != 80 {
+		t.Fatalf("expected jpeg 40x80, got %q %dx%d err=%v", format, w, h, err)
+	}
+
+	// Truncated before SOF: malformed, not a read error.
+	if _, _, _, err := DecodeConfigAt(bytes.NewReader(out[:300000]), 300000); !errors.Is(err, ErrInvalidHeader) {
+		t.Fatalf("expected ErrInvalidHeader for a truncated header, got %v", err)
+	}
+	// Read errors are passed through so callers can tell them from bad data.
+	readErr := errors.New("storage down")
+	if _, _, _, err := DecodeConfigAt(failingReaderAt{readErr}, int64(len(out))); !errors.Is(err, readErr) || errors.Is(err, ErrInvalidHeader) {
+		t.Fatalf("expected the read error, got %v", err)
+	}
+}
+
+func TestDecode_RejectsUnsupportedAndOversized(t *testing.T) {
+	heic := []byte("\x00\x00\x00\x18ftypheic\x00\x00\x00\x00mif1heic")
+	if _, _, err := Decode(heic); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Fatalf("expected ErrUnsupportedFormat, got %v", err)
+	}
+
+	// A tiny PNG whose header claims 100000x100000 pixels.
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 1, 1))); err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	data := buf.Bytes()
+	ihdr := data[8+8 : 8+8+13]
+	binary.BigEndian.PutUint32(ihdr[0:], 100000)
+	binary.BigEndian.PutUint32(ihdr[4:], 100000)
+	binary.BigEndian.PutUint32(data[8+8+13:], crc32.ChecksumIEEE(data[8+4:8+8+13]))
+	if _, _, err := Decode(data); !errors.Is(err, ErrTooManyPixels) {
+		t.Fatalf("expected ErrTooManyPixels, got %v", err)
+	}
+}
+
+func TestRenditions_NeverUpscale(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 1000, 500)) // fully transparent
+	out, err := Renditions(src, []int{1600, 320, 768, 320}, 80)
+	if err != nil {
+		t.Fatalf("renditions: %v", err)
+	}
+	want := [][2]int{{320, 160}, {768, 384}, {1000, 500}}
+	if len(out) != len(want) {
+		t.Fatalf("expected %d renditions, got %d", len(want), len(out))
+	}
+	for i, r := range out {
+		if r.Width != want[i][0] || r.Height != want[i][1] {
+			t.Fatalf("rendition %d: expected %v, got %dx%d", i, want[i], r.Width, r.Height)
+		}
+		img, err := jpeg.Decode(bytes.NewReader(r.Data))
+		if err != nil {
+			t.Fatalf("rendition %d is not a JPEG: %v", i, err)
+		}
+		if b := img.Bounds(); b.Dx() != r.Width || b.Dy() != r.Height {
+			t.Fatalf("rendition %d: encoded size %v", i, b)
+		}
+		// Transparency is flattened onto white.
+		if c := color.GrayModel.Convert(img.At(r.Width/2, r.Height/2)).(color.Gray); c.Y < 245 {
+			t.Fatalf("rendition %d: expected white background, got %v", i, c)
+		}
+	}
+}
+
+func TestRenditionKey(t *testing.T) {
+	base := "products/7001/cover/2025/03/01/1b4e28ba-2fa1-11d2-883f-0016d3cca427"
+	key := RenditionKey(base, 768)
+	gotBase, width, ok := ParseRenditionKey(key)
+	if !ok || gotBase != base || width != 768 {
+		t.Fatalf("round trip failed: %q -> %q %d %v", key, gotBase, width, ok)
+	}
+	for _, bad := range []string{base + ".webp", base + "/w0.jpg", base + "/wide.jpg", "w320.jpg"} {
+		if _, _, ok := ParseRenditionKey(bad); ok {
+			t.Fatalf("expected %q not to parse as a rendition key", bad)
+		}
+	}
+}
+
+func TestVariantBaseAndSnapWidth(t *testing.T) {
+	for key, want := range map[string]string{
+		"products/7001/cover/2025/03/01/abc/w1600.jpg": "products/7001/cover/2025/03/01/abc",
+		"products/7001/cover/2025/03/01/abc.webp":      "products/7001/cover/2025/03/01/abc",
+		"products/7001/cover/v1.2/abc":                 "products/7001/cover/v1.2/abc",
+	} {
+		if got := VariantBase(key); got != want {
+			t.Fatalf("VariantBase(%q) = %q, want %q", key, got, want)
+		}
+	}
+
+	widths := []int{768, 320, 1600}
+	for w, want := range map[int]int{1: 320, 320: 320, 321: 768, 1000: 1600, 4000: 1600} {
+		if got := SnapWidth(w, widths); got != want {
+			t.Fatalf("SnapWidth(%d) = %d, want %d", w, got, want)
+		}
+	}
+}
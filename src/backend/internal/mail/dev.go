@@ -0,0 +1,62 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// LogMailer writes messages to the logger instead of delivering them.
+type LogMailer struct {
+	logger *slog.Logger
+}
+
+func NewLogMailer(logger *slog.Logger) *LogMailer {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &LogMailer{logger: logger}
+}
+
+func (m *LogMailer) Send(_ context.Context, msg Message) error {
+	if err := validate(msg); err != nil {
+		return err
+	}
+	m.logger.Info("mail (log driver)", "to", strings.Join(msg.To, ","), "subject", msg.Subject, "body", msg.Text)
+	return nil
+}
+
+// FileMailer stores each message as an .eml file, handy for local testing.
+type FileMailer struct {
+	dir  string
+	from string
+	seq  atomic.Uint64
+}
+
+func NewFileMailer(dir, from string) (*FileMailer, error) {
+	dir = strings.TrimSpace(dir)
+	if dir == "" {
+		return nil, fmt.Errorf("mail: MAIL_FILE_DIR is required for the file driver")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("mail: create %s: %w", dir, err)
+	}
+	return &FileMailer{dir: dir, from: from}, nil
+}
+
+func (m *FileMailer) Send(_ context.Context, msg Message) error {
+	if err := validate(msg); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%04d.eml", time.Now().UTC().Format("20060102T150405.000000000"), m.seq.Add(1)%10000)
+	path := filepath.Join(m.dir, name)
+	if err := os.WriteFile(path, buildRFC822(m.from, msg), 0o600); err != nil {
+		return fmt.Errorf("mail: write %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package mail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"evening-gown/internal/config"
+)
+
+var ErrNoRecipients = errors.New("mail: no recipients")
+
+// Message is a plain-text email.
+type Message struct {
+	To      []string
+	Subject string
+	Text    string
+}
+
+// Mailer sends transactional emails (password reset, ...).
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// New builds the Mailer selected by cfg.Driver.
+//
+// Drivers:
+// - none (default): mail disabled; returns a nil Mailer so dependent features report unavailable
+// - log: writes the message to the application log (dev only, opt-in; contains links/tokens)
+// - file: writes one .eml file per message into cfg.FileDir (dev/testing)
+// - smtp: delivers via cfg.SMTP
+func New(cfg config.MailConfig, logger *slog.Logger) (Mailer, error) {
+	switch strings.ToLower(strings.TrimSpace(cfg.Driver)) {
+	case "", "none":
+		return nil, nil
+	case "log":
+		return NewLogMailer(logger), nil
+	case "file":
+		return NewFileMailer(cfg.FileDir, cfg.From)
+	case "smtp":
+		return NewSMTPMailer(cfg)
+	default:
+		return nil, fmt.Errorf("mail: unsupported MAIL_DRIVER %q (want none, log, file or smtp)", cfg.Driver)
+	}
+}
+
+func validate(msg Message) error {
+	if len(msg.To) == 0 {
+		return ErrNoRecipients
+	}
+	for _, to := range msg.To {
+		// Header injection guard: addresses and subject must be single-line.
+		if strings.ContainsAny(to, "\r\n") {
+			return fmt.Errorf("mail: invalid recipient %q", to)
+		}
+	}
+	if strings.ContainsAny(msg.Subject, "\r\n") {
+		return errors.New("mail: invalid subject")
+	}
+	return nil
+}
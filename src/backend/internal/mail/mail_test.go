@@ -0,0 +1,62 @@
+package mail
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"evening-gown/internal/config"
+)
+
+func TestNew_LogDriverIsOptIn(t *testing.T) {
+	for _, driver := range []string{"", "none"} {
+		if m, err := New(config.MailConfig{Driver: driver}, nil); err != nil || m != nil {
+			t.Fatalf("driver %q: expected mail disabled, got %T err=%v", driver, m, err)
+		}
+	}
+	if m, err := New(config.MailConfig{Driver: "log"}, nil); err != nil {
+		t.Fatalf("log driver: %v", err)
+	} else if _, ok := m.(*LogMailer); !ok {
+		t.Fatalf("expected LogMailer, got %T", m)
+	}
+}
+
+func TestFileMailer_WritesEML(t *testing.T) {
+	dir := t.TempDir()
+	m, err := NewFileMailer(dir, "Evening Gown <no-reply@example.com>")
+	if err != nil {
+		t.Fatalf("new file mailer: %v", err)
+	}
+
+	msg := Message{To: []string{"a@example.com"}, Subject: "Reset your password", Text: "line1\nline2"}
+	if err := m.Send(context.Background(), msg); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if len(files) != 1 {
+		t.Fatalf("expected 1 file, got %v", files)
+	}
+	raw, _ := os.ReadFile(files[0])
+	body := string(raw)
+	for _, part := range []string{"From: Evening Gown <no-reply@example.com>\r\n", "To: a@example.com\r\n", "Subject: Reset your password\r\n", "\r\n\r\nline1\r\nline2\r\n"} {
+		if !strings.Contains(body, part) {
+			t.Fatalf("expected %q in %q", part, body)
+		}
+	}
+}
+
+func TestValidate_RejectsHeaderInjection(t *testing.T) {
+	m := NewLogMailer(nil)
+	if err := m.Send(context.Background(), Message{To: []string{"a@example.com\r\nBcc: x@example.com"}, Subject: "x"}); err == nil {
+		t.Fatalf("expected recipient with CRLF to be rejected")
+	}
+	if err := m.Send(context.Background(), Message{To: []string{"a@example.com"}, Subject: "x\r\nBcc: y"}); err == nil {
+		t.Fatalf("expected subject with CRLF to be rejected")
+	}
+	if err := m.Send(context.Background(), Message{}); err != ErrNoRecipients {
+		t.Fatalf("expected ErrNoRecipients, got %v", err)
+	}
+}
@@ -0,0 +1,147 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	netmail "net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"evening-gown/internal/config"
+)
+
+// SMTPMailer delivers messages through an SMTP relay.
+//
+// Port 465 style implicit TLS is used when cfg.SMTP.ImplicitTLS is set; otherwise the
+// connection is upgraded with STARTTLS whenever the server offers it.
+type SMTPMailer struct {
+	addr string
+	host string
+	from string
+	// envelopeFrom is the bare address used for MAIL FROM (from may include a display name).
+	envelopeFrom string
+	auth         smtp.Auth
+	implicitTLS  bool
+	timeout      time.Duration
+}
+
+func NewSMTPMailer(cfg config.MailConfig) (*SMTPMailer, error) {
+	host := strings.TrimSpace(cfg.SMTP.Host)
+	if host == "" {
+		return nil, fmt.Errorf("mail: SMTP_HOST is required for the smtp driver")
+	}
+	from, err := netmail.ParseAddress(strings.TrimSpace(cfg.From))
+	if err != nil {
+		return nil, fmt.Errorf("mail: invalid MAIL_FROM: %w", err)
+	}
+
+	port := cfg.SMTP.Port
+	if port <= 0 {
+		port = 587
+	}
+
+	m := &SMTPMailer{
+		addr:        net.JoinHostPort(host, strconv.Itoa(port)),
+		host:        host,
+		from:        from.String(),
+		implicitTLS: cfg.SMTP.ImplicitTLS,
+		timeout:     10 * time.Second,
+
+		envelopeFrom: from.Address,
+	}
+	if cfg.SMTP.Username != "" {
+		// PlainAuth refuses to send credentials over an unencrypted connection (except localhost).
+		m.auth = smtp.PlainAuth("", cfg.SMTP.Username, cfg.SMTP.Password, host)
+	}
+	return m, nil
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	if err := validate(msg); err != nil {
+		return err
+	}
+
+	dialer := &net.Dialer{Timeout: m.timeout}
+	var conn net.Conn
+	var err error
+	if m.implicitTLS {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: m.host}}).DialContext(ctx, "tcp", m.addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", m.addr)
+	}
+	if err != nil {
+		return fmt.Errorf("mail: dial %s: %w", m.addr, err)
+	}
+	deadline := time.Now().Add(m.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	_ = conn.SetDeadline(deadline)
+
+	c, err := smtp.NewClient(conn, m.host)
+	if err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("mail: smtp handshake: %w", err)
+	}
+	defer c.Close()
+
+	if !m.implicitTLS {
+		if ok, _ := c.Extension("STARTTLS"); ok {
+			if err := c.StartTLS(&tls.Config{ServerName: m.host}); err != nil {
+				return fmt.Errorf("mail: starttls: %w", err)
+			}
+		}
+	}
+	if m.auth != nil {
+		if err := c.Auth(m.auth); err != nil {
+			return fmt.Errorf("mail: smtp auth: %w", err)
+		}
+	}
+	if err := c.Mail(m.envelopeFrom); err != nil {
+		return fmt.Errorf("mail: MAIL FROM: %w", err)
+	}
+	for _, to := range msg.To {
+		if err := c.Rcpt(to); err != nil {
+			return fmt.Errorf("mail: RCPT TO %s: %w", to, err)
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("mail: DATA: %w", err)
+	}
+	if _, err := w.Write(buildRFC822(m.from, msg)); err != nil {
+		_ = w.Close()
+		return fmt.Errorf("mail: write body: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("mail: finish body: %w", err)
+	}
+	return c.Quit()
+}
+
+// buildRFC822 renders a minimal UTF-8 plain-text message with CRLF line endings.
+func buildRFC822(from string, msg Message) []byte {
+	var b bytes.Buffer
+	if from != "" {
+		fmt.Fprintf(&b, "From: %s\r\n", from)
+	}
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(msg.To, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().UTC().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+	text := strings.ReplaceAll(msg.Text, "\r\n", "\n")
+	b.WriteString(strings.ReplaceAll(text, "\n", "\r\n"))
+	if !strings.HasSuffix(text, "\n") {
+		b.WriteString("\r\n")
+	}
+	return b.Bytes()
+}
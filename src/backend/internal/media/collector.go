@@ -0,0 +1,222 @@
+package media
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"evening-gown/internal/audit"
+	"evening-gown/internal/imaging"
+	"evening-gown/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// collectBatchSize bounds the assets loaded per query.
+const collectBatchSize = 100
+
+// Collector removes uploaded images nothing references any more.
+//
+// A run first registers objects found under "products/" without an Asset row (uploads from
+// before the table existed), then removes every asset older than the grace period that no
+// product, draft or revision references (see ReferenceCounts), objects first. The grace
+// period covers the time between an upload and the product save that references it.
+type Collector struct {
+	db     *gorm.DB
+	store  Store
+	grace  time.Duration
+	logger *slog.Logger
+	now    func() time.Time
+}
+
+// NewCollector returns a collector. A non-positive grace disables removal; discovery still runs.
+func NewCollector(db *gorm.DB, store Store, grace time.Duration, logger *slog.Logger) *Collector {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Collector{db: db, store: store, grace: grace, logger: logger, now: time.Now}
+}
+
+// CollectResult counts what one RunOnce did.
+type CollectResult struct {
+	Discovered int
+	Removed    int
+}
+
+// Run registers untracked objects and removes expired orphans. It implements scheduler.Job.Run.
+func (c *Collector) Run(ctx context.Context) error {
+	_, err := c.RunOnce(ctx)
+	return err
+}
+
+// RunOnce performs one discovery and collection pass.
+func (c *Collector) RunOnce(ctx context.Context) (CollectResult, error) {
+	var res CollectResult
+	if c == nil || c.db == nil || c.store == nil {
+		return res, nil
+	}
+	ctx = audit.WithActor(ctx, audit.Actor{Email: "scheduler", Role: "system"})
+
+	n, err := c.discover(ctx)
+	res.Discovered = n
+	if err != nil {
+		return res, err
+	}
+	if c.grace <= 0 {
+		return res, nil
+	}
+	res.Removed, err = c.collect(ctx)
+	return res, err
+}
+
+// discover creates Asset rows for stored images that have none. The objects of one upload
+// (renditions, width variants) are grouped by imaging.VariantBase into a single asset.
+func (c *Collector) discover(ctx context.Context) (int, error) {
+	objects, err := c.store.List(ctx, "products/")
+	if err != nil {
+		return 0, err
+	}
+
+	type group struct {
+		primary  Object
+		width    int // of primary when it is a rendition; -1 for an original
+		size     int64
+		modified time.Time
+	}
+	groups := map[string]*group{}
+	var order []string
+	for _, obj := range objects {
+		base := imaging.VariantBase(obj.Key)
+		g := groups[base]
+		if g == nil {
+			g = &group{}
+			groups[base] = g
+			order = append(order, base)
+		}
+		g.size += obj.Size
+		if obj.LastModified.After(g.modified) {
+			g.modified = obj.LastModified
+		}
+		// An original (non-rendition key) is what products reference; otherwise the widest rendition.
+		w := -1
+		if _, rw, ok := imaging.ParseRenditionKey(obj.Key); ok {
+			w = rw
+		}
+		if g.primary.Key == "" || w == -1 || (g.width != -1 && w > g.width) {
+			g.primary, g.width = obj, w
+		}
+	}
+	if len(order) == 0 {
+		return 0, nil
+	}
+
+	known := map[string]bool{}
+	for i := 0; i < len(order); i += collectBatchSize {
+		end := min(i+collectBatchSize, len(order))
+		var bases []string
+		if err := c.db.WithContext(ctx).Model(&model.Asset{}).Where("base IN ?", order[i:end]).Pluck("base", &bases).Error; err != nil {
+			return 0, err
+		}
+		for _, b := range bases {
+			known[b] = true
+		}
+	}
+
+	created := 0
+	for _, base := range order {
+		if known[base] {
+			continue
+		}
+		g := groups[base]
+		kind, styleNo := keyParts(g.primary.Key)
+		asset := model.Asset{
+			ObjectKey: g.primary.Key,
+			Base:      base,
+			Kind:      kind,
+			StyleNo:   styleNo,
+			Size:      g.size,
+			CreatedAt: g.modified.UTC(),
+		}
+		if g.width > 0 {
+			asset.ContentType = imaging.RenditionContentType
+			asset.Width = g.width
+		}
+		if err := c.db.WithContext(ctx).Create(&asset).Error; err != nil {
+			return created, err
+		}
+		created++
+	}
+	return created, nil
+}
+
+// collect removes expired assets that nothing references.
+func (c *Collector) collect(ctx context.Context) (int, error) {
+	cutoff := c.now().UTC().Add(-c.grace)
+	removed := 0
+	var lastID uint
+	for {
+		var assets []model.Asset
+		if err := c.db.WithContext(ctx).
+			Where("created_at < ? AND id > ?", cutoff, lastID).
+			Order("id asc").
+			Limit(collectBatchSize).
+			Find(&assets).Error; err != nil {
+			return removed, err
+		}
+		if len(assets) == 0 {
+			return removed, nil
+		}
+		lastID = assets[len(assets)-1].ID
+
+		keys := make([]string, len(assets))
+		for i, a := range assets {
+			keys[i] = a.ObjectKey
+		}
+		counts, err := ReferenceCounts(c.db.WithContext(ctx), keys)
+		if err != nil {
+			return removed, err
+		}
+		for _, a := range assets {
+			if counts[a.ObjectKey] > 0 {
+				continue
+			}
+			if err := c.remove(ctx, a); err != nil {
+				return removed, err
+			}
+			removed++
+		}
+	}
+}
+
+func (c *Collector) remove(ctx context.Context, a model.Asset) error {
+	if err := c.store.RemoveImage(ctx, a.ObjectKey); err != nil {
+		return err
+	}
+	if err := c.db.WithContext(ctx).Delete(&model.Asset{}, a.ID).Error; err != nil {
+		return err
+	}
+	if err := audit.Record(ctx, c.db, audit.Entry{
+		Action:     "asset.purge",
+		EntityType: "assets",
+		EntityID:   strconv.FormatUint(uint64(a.ID), 10),
+		Before:     a,
+	}); err != nil {
+		c.logger.Warn("media collect audit failed", "id", a.ID, "err", err)
+	}
+	return nil
+}
+
+// keyParts extracts kind and style number from products/{styleNo}/{kind}/... keys.
+func keyParts(key string) (kind, styleNo string) {
+	parts := strings.Split(key, "/")
+	if len(parts) < 4 || parts[0] != "products" {
+		return "", ""
+	}
+	switch parts[2] {
+	case "cover", "hover", "gallery", "video":
+		return parts[2], parts[1]
+	}
+	return "", parts[1]
+}
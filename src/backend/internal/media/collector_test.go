@@ -0,0 +1,124 @@
+package media
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"evening-gown/internal/bootstrap"
+	"evening-gown/internal/model"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err == nil {
+		t.Cleanup(func() { _ = sqlDB.Close() })
+	}
+	if err := bootstrap.AutoMigrate(db); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return db
+}
+
+type memStore struct {
+	objects []Object
+	removed []string
+}
+
+func (s *memStore) List(_ context.Context, prefix string) ([]Object, error) {
+	var out []Object
+	for _, o := range s.objects {
+		if strings.HasPrefix(o.Key, prefix) {
+			out = append(out, o)
+		}
+	}
+	return out, nil
+}
+
+func (s *memStore) RemoveImage(_ context.Context, key string) error {
+	s.removed = append(s.removed, key)
+	return nil
+}
+
+func TestCollector_RunOnce(t *testing.T) {
+	db := openTestDB(t)
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	old := now.Add(-10 * 24 * time.Hour)
+	recent := now.Add(-time.Hour)
+
+	const used = "products/A1/cover/2026/02/01/u1"
+	const orphan = "products/A1/hover/2026/02/01/u2"
+	store := &memStore{objects: []Object{
+		{Key: used + "/w320.jpg", Size: 10, LastModified: old},
+		{Key: used + "/w1600.jpg", Size: 100, LastModified: old},
+		{Key: orphan + "/w320.jpg", Size: 10, LastModified: old},
+		{Key: orphan + "/w768.jpg", Size: 40, LastModified: old},
+		// Legacy single-size upload with a generated width variant, unreferenced.
+		{Key: "products/A1/gallery/2025/12/01/u3.webp", Size: 70, LastModified: old},
+		{Key: "products/A1/gallery/2025/12/01/u3/w320.jpg", Size: 5, LastModified: old},
+		// Unreferenced but within the grace period.
+		{Key: "products/A1/gallery/2026/03/01/u4/w320.jpg", Size: 5, LastModified: recent},
+	}}
+
+	// Products reference any width; the upload counts as used.
+	product := model.Product{
+		Slug: "a1", StyleNo: "A1", Season: "ss26", Category: "gown", Availability: "in_stock",
+		CoverImageKey: used + "/w320.jpg",
+	}
+	if err := db.Create(&product).Error; err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+	if _, err := SyncProductAssets(context.Background(), db, product.ID); err != nil {
+		t.Fatalf("index product assets: %v", err)
+	}
+
+	c := NewCollector(db, store, 7*24*time.Hour, nil)
+	c.now = func() time.Time { return now }
+
+	res, err := c.RunOnce(context.Background())
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if res.Discovered != 4 || res.Removed != 2 {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+
+	sort.Strings(store.removed)
+	if len(store.removed) != 2 || store.removed[0] != "products/A1/gallery/2025/12/01/u3.webp" || store.removed[1] != orphan+"/w768.jpg" {
+		t.Fatalf("unexpected removals: %v", store.removed)
+	}
+
+	var assets []model.Asset
+	if err := db.Order("object_key asc").Find(&assets).Error; err != nil {
+		t.Fatalf("list assets: %v", err)
+	}
+	if len(assets) != 2 || assets[0].ObjectKey != "products/A1/cover/2026/02/01/u1/w1600.jpg" {
+		t.Fatalf("unexpected remaining assets: %+v", assets)
+	}
+	if a := assets[0]; a.Kind != "cover" || a.StyleNo != "A1" || a.Size != 110 || a.Width != 1600 {
+		t.Fatalf("unexpected discovered asset: %+v", a)
+	}
+
+	var audits int64
+	db.Model(&model.AuditLog{}).Where("action = ?", "asset.purge").Count(&audits)
+	if audits != 2 {
+		t.Fatalf("expected 2 purge audit entries, got %d", audits)
+	}
+
+	// A second run finds nothing new and keeps what is referenced.
+	store.objects = nil
+	res, err = c.RunOnce(context.Background())
+	if err != nil || res.Discovered != 0 || res.Removed != 0 {
+		t.Fatalf("second run: %+v, %v", res, err)
+	}
+}
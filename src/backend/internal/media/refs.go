@@ -0,0 +1,105 @@
+// Package media tracks uploaded product images (model.Asset): which ones products still
+// reference, and collecting the ones nothing references any more.
+package media
+
+import (
+	"context"
+
+	"evening-gown/internal/cache"
+	"evening-gown/internal/imaging"
+	"evening-gown/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// ReferenceCounts returns, per object key, how many products (deleted ones included: they can
+// be restored), drafts and revisions reference it. A rendition key counts references to any
+// width of the same upload, since its renditions are stored and removed together.
+//
+// Products are counted through the product_assets index; drafts and revisions, which are
+// not indexed, by matching their snapshots.
+func ReferenceCounts(db *gorm.DB, keys []string) (map[string]int64, error) {
+	out := make(map[string]int64, len(keys))
+	for _, key := range keys {
+		if _, ok := out[key]; ok {
+			continue
+		}
+		products := db.Model(&model.ProductAsset{}).Distinct("product_id")
+		like := "%" + key + "%"
+		if base, _, ok := imaging.ParseRenditionKey(key); ok {
+			products = products.Where("base = ?", base)
+			like = "%" + base + "/%"
+		} else {
+			products = products.Where("object_key = ?", key)
+		}
+
+		var nProducts, drafts, revisions int64
+		if err := products.Count(&nProducts).Error; err != nil {
+			return nil, err
+		}
+		if err := db.Model(&model.ProductDraft{}).Where("CAST(snapshot AS TEXT) LIKE ?", like).Count(&drafts).Error; err != nil {
+			return nil, err
+		}
+		if err := db.Model(&model.ProductRevision{}).Where("CAST(snapshot AS TEXT) LIKE ?", like).Count(&revisions).Error; err != nil {
+			return nil, err
+		}
+		out[key] = nProducts + drafts + revisions
+	}
+	return out, nil
+}
+
+// SyncProductAssets rewrites the product_assets rows of a product from its current row (see
+// model.PlanProductAssets). db is usually the transaction that wrote the product. It
+// reports whether the set of referenced keys changed.
+func SyncProductAssets(ctx context.Context, db *gorm.DB, productID uint) (bool, error) {
+	var p model.Product
+	if err := db.WithContext(ctx).First(&p, productID).Error; err != nil {
+		return false, err
+	}
+
+	var existing []model.ProductAsset
+	if err := db.WithContext(ctx).Where("product_id = ?", productID).Find(&existing).Error; err != nil {
+		return false, err
+	}
+	stale := make(map[string]uint, len(existing))
+	for _, a := range existing {
+		stale[a.ObjectKey] = a.ID
+	}
+
+	changed := false
+	for _, a := range model.PlanProductAssets(p) {
+		if _, ok := stale[a.ObjectKey]; ok {
+			delete(stale, a.ObjectKey)
+			continue
+		}
+		if err := db.WithContext(ctx).Create(&a).Error; err != nil {
+			return false, err
+		}
+		changed = true
+	}
+	if len(stale) > 0 {
+		ids := make([]uint, 0, len(stale))
+		for _, id := range stale {
+			ids = append(ids, id)
+		}
+		if err := db.WithContext(ctx).Where("id IN ?", ids).Delete(&model.ProductAsset{}).Error; err != nil {
+			return false, err
+		}
+		changed = true
+	}
+	return changed, nil
+}
+
+// BumpAssetsCache invalidates the public asset allow-cache when any of the products
+// references an image, after a change to what the site shows of them (publication, deletion
+// or their images). Products without images leave it alone.
+func BumpAssetsCache(ctx context.Context, db *gorm.DB, publicCache *cache.PublicCache, productIDs ...uint) {
+	if publicCache == nil || db == nil || len(productIDs) == 0 {
+		return
+	}
+	var n int64
+	if err := db.WithContext(ctx).Model(&model.ProductAsset{}).Where("product_id IN ?", productIDs).Limit(1).Count(&n).Error; err == nil && n == 0 {
+		return
+	}
+	_, _ = publicCache.BumpAssetsVersion(ctx)
+}
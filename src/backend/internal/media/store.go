@@ -0,0 +1,54 @@
+package media
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"evening-gown/internal/config"
+	"evening-gown/internal/storage"
+
+	"github.com/minio/minio-go/v7"
+)
+
+// Object is one stored object as listed by a Store.
+type Object struct {
+	Key          string
+	Size         int64
+	LastModified time.Time
+}
+
+// Store lists and removes uploaded images (MinIO in production).
+type Store interface {
+	List(ctx context.Context, prefix string) ([]Object, error)
+	// RemoveImage deletes an image together with its renditions and width variants.
+	RemoveImage(ctx context.Context, key string) error
+}
+
+type minioStore struct {
+	client *minio.Client
+	cfg    config.MinioConfig
+}
+
+// NewMinioStore returns a Store over the configured bucket, or nil without a client.
+func NewMinioStore(client *minio.Client, cfg config.MinioConfig) Store {
+	if client == nil {
+		return nil
+	}
+	return &minioStore{client: client, cfg: cfg}
+}
+
+func (s *minioStore) List(ctx context.Context, prefix string) ([]Object, error) {
+	var out []Object
+	for obj := range s.client.ListObjects(ctx, s.cfg.Bucket, minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+		if obj.Err != nil {
+			return nil, fmt.Errorf("list objects: %w", obj.Err)
+		}
+		out = append(out, Object{Key: obj.Key, Size: obj.Size, LastModified: obj.LastModified})
+	}
+	return out, nil
+}
+
+func (s *minioStore) RemoveImage(ctx context.Context, key string) error {
+	return storage.RemoveImage(ctx, s.client, s.cfg, key)
+}
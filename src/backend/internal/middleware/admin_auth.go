@@ -13,7 +13,13 @@ import (
 	"gorm.io/gorm"
 )
 
-const ContextUserKey = "auth.user"
+const (
+	ContextUserKey    = "auth.user"
+	ContextSessionKey = "auth.session_id"
+)
+
+// sessionTouchInterval throttles last_used_at writes to at most one per session per interval.
+const sessionTouchInterval = time.Minute
 
 func AdminAuth(db *gorm.DB, jwtSvc *auth.Service) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -59,7 +65,7 @@ func AdminAuth(db *gorm.DB, jwtSvc *auth.Service) gin.HandlerFunc {
 			})
 			return
 		}
-		if user.Role != "admin" || user.Status != "active" {
+		if !model.IsValidRole(user.Role) || user.Status != "active" {
 			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
 				"code":    "forbidden",
 				"message": "forbidden",
@@ -104,12 +110,79 @@ func AdminAuth(db *gorm.DB, jwtSvc *auth.Service) gin.HandlerFunc {
 			}
 		}
 
+		// Tokens bound to a server-side session die with it (logout, remote revoke, reuse detection).
+		// Legacy tokens without sid are only guarded by pwd_at above.
+		if sid := strings.TrimSpace(claims.SessionID); sid != "" {
+			var session model.Session
+			now := time.Now().UTC()
+			if err := db.WithContext(c.Request.Context()).Where("id = ? AND user_id = ?", sid, user.ID).First(&session).Error; err != nil || !session.Active(now) {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+					"code":    "unauthorized",
+					"message": "unauthorized",
+					"error":   "unauthorized",
+				})
+				return
+			}
+			if now.Sub(session.LastUsedAt) > sessionTouchInterval {
+				_ = db.WithContext(c.Request.Context()).Model(&model.Session{}).Where("id = ?", sid).Update("last_used_at", now).Error
+			}
+			c.Set(ContextSessionKey, sid)
+		}
+
 		c.Set(ContextUserKey, user)
 		EnrichLoggerWithAdmin(c, user)
 		c.Next()
 	}
 }
 
+// RequirePermission rejects requests whose authenticated user lacks perm.
+//
+// It must run after AdminAuth (it reads the user stored under ContextUserKey).
+func RequirePermission(perm string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := CurrentUser(c)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"code":    "unauthorized",
+				"message": "unauthorized",
+				"error":   "unauthorized",
+			})
+			return
+		}
+		if !model.HasPermission(user.Role, perm) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"code":       "forbidden",
+				"message":    "forbidden",
+				"error":      "forbidden",
+				"permission": perm,
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+// CurrentUser returns the admin user resolved by AdminAuth, if any.
+func CurrentUser(c *gin.Context) (model.User, bool) {
+	if c == nil {
+		return model.User{}, false
+	}
+	v, ok := c.Get(ContextUserKey)
+	if !ok {
+		return model.User{}, false
+	}
+	user, ok := v.(model.User)
+	return user, ok
+}
+
+// CurrentSessionID returns the session id of the access token, or "" for legacy tokens.
+func CurrentSessionID(c *gin.Context) string {
+	if c == nil {
+		return ""
+	}
+	return c.GetString(ContextSessionKey)
+}
+
 func tokenFromRequest(c *gin.Context) string {
 	if c == nil {
 		return ""
@@ -158,6 +158,47 @@ func TestAdminAuth_ErrorsAndSuccess(t *testing.T) {
 	})
 }
 
+func TestRequirePermission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	withUser := func(user *model.User) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			if user != nil {
+				c.Set(ContextUserKey, *user)
+			}
+			c.Next()
+		}
+	}
+
+	cases := []struct {
+		name string
+		user *model.User
+		perm string
+		want int
+	}{
+		{name: "no user", user: nil, perm: model.PermProductsRead, want: http.StatusUnauthorized},
+		{name: "admin has everything", user: &model.User{Role: model.RoleAdmin}, perm: model.PermUsersManage, want: http.StatusOK},
+		{name: "merchandiser products", user: &model.User{Role: model.RoleMerchandiser}, perm: model.PermProductsWrite, want: http.StatusOK},
+		{name: "merchandiser contacts", user: &model.User{Role: model.RoleMerchandiser}, perm: model.PermContactsRead, want: http.StatusForbidden},
+		{name: "sales contacts", user: &model.User{Role: model.RoleSales}, perm: model.PermContactsWrite, want: http.StatusOK},
+		{name: "analyst events read", user: &model.User{Role: model.RoleAnalyst}, perm: model.PermEventsRead, want: http.StatusOK},
+		{name: "analyst events write", user: &model.User{Role: model.RoleAnalyst}, perm: model.PermEventsWrite, want: http.StatusForbidden},
+		{name: "unknown role", user: &model.User{Role: "staff"}, perm: model.PermEventsRead, want: http.StatusForbidden},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := gin.New()
+			r.GET("/p", withUser(tc.user), RequirePermission(tc.perm), func(c *gin.Context) { c.JSON(200, gin.H{"ok": true}) })
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/p", nil)
+			r.ServeHTTP(w, req)
+			if w.Code != tc.want {
+				t.Fatalf("expected %d got %d", tc.want, w.Code)
+			}
+		})
+	}
+}
+
 func openTestDB(t *testing.T) *gorm.DB {
 	t.Helper()
 
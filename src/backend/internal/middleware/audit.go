@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"evening-gown/internal/audit"
+	"evening-gown/internal/logging"
+
+	"github.com/gin-contrib/requestid"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Audit attaches the acting admin to the request context (see audit.ActorFrom) and makes sure
+// every successful mutation leaves a trail.
+//
+// Handlers record detailed entries (with before/after diffs) via audit.Record. When a successful
+// POST/PUT/PATCH/DELETE did not record anything, a generic entry named after the route is written.
+// It must run after AdminAuth.
+func Audit(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		actor := audit.Actor{
+			RequestID: strings.TrimSpace(requestid.Get(c)),
+			IP:        c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+		}
+		if user, ok := CurrentUser(c); ok {
+			actor.UserID = user.ID
+			actor.Email = user.Email
+			actor.Role = user.Role
+		}
+		ctx := audit.WithActor(c.Request.Context(), actor)
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		if db == nil || !isMutation(c.Request.Method) || c.Writer.Status() >= http.StatusBadRequest || audit.Recorded(ctx) {
+			return
+		}
+		route := c.FullPath()
+		if route == "" {
+			return
+		}
+		entityID := c.Param("id")
+		if entityID == "" {
+			entityID = strings.TrimPrefix(c.Param("key"), "/")
+		}
+		entry := audit.Entry{
+			Action:     c.Request.Method + " " + route,
+			EntityType: routeResource(route),
+			EntityID:   entityID,
+		}
+		if err := audit.Record(ctx, db, entry); err != nil {
+			logging.FromGin(c).Warn("audit record failed", "err", err, "action", entry.Action)
+		}
+	}
+}
+
+func isMutation(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// routeResource returns the first path segment after /api/v1/admin ("products", "me", ...).
+func routeResource(route string) string {
+	rest := strings.TrimPrefix(route, "/api/v1/admin/")
+	if i := strings.IndexByte(rest, '/'); i >= 0 {
+		rest = rest[:i]
+	}
+	return rest
+}
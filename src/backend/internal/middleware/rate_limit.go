@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+
+	"evening-gown/internal/logging"
+	"evening-gown/internal/ratelimit"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimit throttles requests per client IP using limiter.
+//
+// name namespaces the key so several endpoints can share one limiter backend.
+// Limiter errors fail open (logged): availability of login beats strict throttling.
+func RateLimit(limiter ratelimit.Limiter, name string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if limiter == nil {
+			c.Next()
+			return
+		}
+
+		res, err := limiter.Allow(c.Request.Context(), name+":"+c.ClientIP())
+		if err != nil {
+			logging.FromGin(c).Warn("rate limit check failed", "err", err, "limiter", name)
+			c.Next()
+			return
+		}
+		if !res.Allowed {
+			retryAfter := int64(res.RetryAfter.Seconds())
+			if retryAfter < 1 {
+				retryAfter = 1
+			}
+			c.Header("Retry-After", strconv.FormatInt(retryAfter, 10))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"code":        "rate_limited",
+				"message":     "too many requests",
+				"error":       "too many requests",
+				"retry_after": retryAfter,
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
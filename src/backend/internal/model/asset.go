@@ -0,0 +1,35 @@
+package model
+
+import "time"
+
+// Asset records one uploaded product image (or video) stored in MinIO.
+//
+// An image uploaded through the server is stored as several width renditions sharing Base
+// (see imaging.RenditionKey); ObjectKey is the widest, the one products reference. Files
+// uploaded directly to MinIO (presigned slots) are stored as uploaded under ObjectKey.
+// Width variants generated later live under Base too and go with the asset when it is
+// removed. Objects found in the bucket without a row (uploads from before this table) are
+// registered by the media collector with an empty checksum and no uploader.
+type Asset struct {
+	ID uint `gorm:"primaryKey" json:"id"`
+
+	ObjectKey string `gorm:"type:text;not null;uniqueIndex" json:"objectKey"`
+	Base      string `gorm:"type:text;not null;index" json:"-"`
+
+	Kind    string `gorm:"type:text;not null;default:''" json:"kind"` // cover|hover|gallery|video
+	StyleNo string `gorm:"type:text;not null;default:'';index" json:"styleNo"`
+
+	ContentType string `gorm:"type:text;not null;default:''" json:"contentType"`
+	// Size is the total of all stored renditions, in bytes.
+	Size   int64 `gorm:"not null;default:0" json:"size"`
+	Width  int   `gorm:"not null;default:0" json:"width"`
+	Height int   `gorm:"not null;default:0" json:"height"`
+	// Checksum is the hex SHA-256 of the uploaded original; empty for direct uploads, which
+	// never pass through the server.
+	Checksum string `gorm:"type:text;not null;default:''" json:"checksum"`
+
+	UploadedBy *uint `gorm:"index" json:"uploadedBy,omitempty"`
+
+	CreatedAt time.Time `gorm:"index" json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditLog records one backoffice mutation: who did what to which entity.
+//
+// Rows are append-only. Changes holds a field-level diff of the entity's JSON form
+// ({"field": {"from": ..., "to": ...}}); fields hidden from JSON (password hashes, secrets) never appear.
+type AuditLog struct {
+	ID uint `gorm:"primaryKey" json:"id"`
+
+	// Actor is denormalized so the trail survives account deletion.
+	ActorID    *uint  `gorm:"index" json:"actorId,omitempty"`
+	ActorEmail string `gorm:"type:text;not null;default:''" json:"actorEmail"`
+	ActorRole  string `gorm:"type:text;not null;default:''" json:"actorRole"`
+
+	Action     string `gorm:"type:text;not null;index" json:"action"` // e.g. product.publish
+	EntityType string `gorm:"type:text;not null;default:'';index:idx_audit_logs_entity" json:"entityType"`
+	EntityID   string `gorm:"type:text;not null;default:'';index:idx_audit_logs_entity" json:"entityId"`
+
+	Changes json.RawMessage `gorm:"type:jsonb" json:"changes,omitempty"`
+
+	RequestID string `gorm:"type:text;not null;default:''" json:"requestId"`
+	IP        string `gorm:"type:text;not null;default:''" json:"ip"`
+	UserAgent string `gorm:"type:text;not null;default:''" json:"userAgent"`
+
+	CreatedAt time.Time `gorm:"index" json:"createdAt"`
+}
@@ -20,6 +20,7 @@ type ContactLead struct {
 
 	Status string `gorm:"type:text;not null;default:new" json:"status"` // new|contacted|closed
 
-	CreatedAt time.Time `json:"createdAt"`
-	UpdatedAt time.Time `json:"updatedAt"`
+	CreatedAt time.Time  `json:"createdAt"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+	DeletedAt *time.Time `gorm:"index" json:"deletedAt,omitempty"`
 }
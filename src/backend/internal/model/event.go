@@ -30,5 +30,6 @@ type Event struct {
 
 	Payload json.RawMessage `gorm:"type:jsonb" json:"payload"`
 
-	CreatedAt time.Time `json:"createdAt"`
+	CreatedAt time.Time  `json:"createdAt"`
+	DeletedAt *time.Time `gorm:"index" json:"deletedAt,omitempty"`
 }
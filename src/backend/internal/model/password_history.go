@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+// PasswordHistory keeps previous password hashes so recent passwords cannot be reused.
+//
+// Rows are pruned to the configured history size whenever a password changes.
+type PasswordHistory struct {
+	ID     uint `gorm:"primaryKey" json:"id"`
+	UserID uint `gorm:"not null;index" json:"userId"`
+
+	PasswordHash string `gorm:"type:text;not null" json:"-"`
+
+	CreatedAt time.Time `json:"createdAt"`
+}
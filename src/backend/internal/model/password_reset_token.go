@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+// PasswordResetToken is a single-use "forgot password" token.
+//
+// Only the SHA-256 hash of the token is stored; the plaintext is sent by email once.
+type PasswordResetToken struct {
+	ID     uint `gorm:"primaryKey" json:"id"`
+	UserID uint `gorm:"not null;index" json:"userId"`
+
+	TokenHash string     `gorm:"type:text;not null;uniqueIndex" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null;index" json:"expiresAt"`
+	UsedAt    *time.Time `gorm:"" json:"usedAt,omitempty"`
+
+	RequestIP string `gorm:"type:text;not null;default:''" json:"requestIp"`
+
+	CreatedAt time.Time `json:"createdAt"`
+}
@@ -12,8 +12,8 @@ type Product struct {
 	Slug    string `gorm:"type:text;uniqueIndex" json:"slug"`
 	StyleNo string `gorm:"type:text;uniqueIndex;not null" json:"styleNo"`
 
-	Season       string `gorm:"type:text;not null" json:"season"`       // ss25|fw25
-	Category     string `gorm:"type:text;not null" json:"category"`     // gown|couture|bridal
+	Season       string `gorm:"type:text;not null" json:"season"`       // taxonomy term, e.g. fw25
+	Category     string `gorm:"type:text;not null" json:"category"`     // taxonomy term, e.g. gown
 	Availability string `gorm:"type:text;not null" json:"availability"` // in_stock|preorder|archived
 
 	IsNew   bool `gorm:"not null;default:false" json:"isNew"`
@@ -36,6 +36,10 @@ type Product struct {
 
 	PublishedAt *time.Time `gorm:"index" json:"publishedAt,omitempty"`
 
+	// PublishAt / UnpublishAt schedule a state change; the scheduler applies and clears them.
+	PublishAt   *time.Time `gorm:"index" json:"publishAt,omitempty"`
+	UnpublishAt *time.Time `gorm:"index" json:"unpublishAt,omitempty"`
+
 	CreatedAt time.Time  `json:"createdAt"`
 	UpdatedAt time.Time  `json:"updatedAt"`
 	DeletedAt *time.Time `gorm:"index" json:"deletedAt,omitempty"`
@@ -0,0 +1,75 @@
+package model
+
+import (
+	"encoding/json"
+	"strings"
+
+	"evening-gown/internal/imaging"
+)
+
+// ProductAsset indexes the stored images a product's live row references: its cover and
+// hover keys and every "products/..." key (or /api/v1/assets/ URL) in DetailJSON.
+//
+// Rows are derived with PlanProductAssets and rewritten on every product content write, so
+// asset reads can be authorized with an indexed lookup instead of scanning product JSON.
+// Drafts and revisions are not indexed; they do not make an image public.
+type ProductAsset struct {
+	ID        uint   `gorm:"primaryKey" json:"id"`
+	ProductID uint   `gorm:"not null;uniqueIndex:idx_product_assets_key" json:"productId"`
+	ObjectKey string `gorm:"type:text;not null;uniqueIndex:idx_product_assets_key;index" json:"objectKey"`
+	// Base groups the renditions and width variants of one upload (imaging.VariantBase).
+	Base string `gorm:"type:text;not null;index" json:"-"`
+}
+
+// PlanProductAssets lists the index rows of p, one per distinct object key.
+func PlanProductAssets(p Product) []ProductAsset {
+	keys := ProductImageKeys(p)
+	out := make([]ProductAsset, len(keys))
+	for i, key := range keys {
+		out[i] = ProductAsset{ProductID: p.ID, ObjectKey: key, Base: imaging.VariantBase(key)}
+	}
+	return out
+}
+
+// ProductImageKeys collects the stored images of a product: cover/hover keys and every
+// "products/..." key (or /api/v1/assets/ URL) in the detail.
+func ProductImageKeys(p Product) []string {
+	seen := map[string]bool{}
+	var out []string
+	add := func(s string) {
+		s = strings.TrimSpace(s)
+		s = strings.TrimPrefix(s, "/api/v1/assets/")
+		s = strings.TrimPrefix(s, "/")
+		if i := strings.IndexAny(s, "?#"); i >= 0 {
+			s = s[:i]
+		}
+		if !strings.HasPrefix(s, "products/") || seen[s] {
+			return
+		}
+		seen[s] = true
+		out = append(out, s)
+	}
+	add(p.CoverImageKey)
+	add(p.HoverImageKey)
+
+	var walk func(v any)
+	walk = func(v any) {
+		switch x := v.(type) {
+		case string:
+			add(x)
+		case []any:
+			for _, it := range x {
+				walk(it)
+			}
+		case map[string]any:
+			for _, it := range x {
+				walk(it)
+			}
+		}
+	}
+	var detail any
+	if err := json.Unmarshal(p.DetailJSON, &detail); err == nil {
+		walk(detail)
+	}
+	return out
+}
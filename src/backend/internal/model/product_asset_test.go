@@ -0,0 +1,35 @@
+package model
+
+import (
+	"encoding/json"
+	"sort"
+	"testing"
+)
+
+func TestPlanProductAssets(t *testing.T) {
+	p := Product{
+		ID:            7,
+		CoverImageKey: " products/A1/cover/2026/02/01/u1/w1600.jpg ",
+		HoverImageKey: "products/A1/cover/2026/02/01/u1/w1600.jpg",
+		DetailJSON: json.RawMessage(`{"sections":[
+			{"images":["/api/v1/assets/products/A1/gallery/2026/02/01/u2/w768.jpg?w=320","https://cdn.example.com/x.jpg"]},
+			{"gallery":[{"key":"products/A1/gallery/2025/12/01/u3.webp"}]}
+		]}`),
+	}
+	got := PlanProductAssets(p)
+	sort.Slice(got, func(i, j int) bool { return got[i].ObjectKey < got[j].ObjectKey })
+
+	want := []ProductAsset{
+		{ProductID: 7, ObjectKey: "products/A1/cover/2026/02/01/u1/w1600.jpg", Base: "products/A1/cover/2026/02/01/u1"},
+		{ProductID: 7, ObjectKey: "products/A1/gallery/2025/12/01/u3.webp", Base: "products/A1/gallery/2025/12/01/u3"},
+		{ProductID: 7, ObjectKey: "products/A1/gallery/2026/02/01/u2/w768.jpg", Base: "products/A1/gallery/2026/02/01/u2"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("row %d: expected %+v, got %+v", i, want[i], got[i])
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package model
+
+import "encoding/json"
+
+// ProductContent is the editable part of a product: everything an editor can change through
+// the admin API. Publish state, schedules and timestamps are deliberately left out, so that
+// restoring a revision or promoting a draft never (un)publishes anything by surprise.
+type ProductContent struct {
+	Slug          string          `json:"slug"`
+	StyleNo       string          `json:"styleNo"`
+	Season        string          `json:"season"`
+	Category      string          `json:"category"`
+	Availability  string          `json:"availability"`
+	IsNew         bool            `json:"isNew"`
+	NewRank       int             `json:"newRank"`
+	CoverImageURL string          `json:"coverImage"`
+	CoverImageKey string          `json:"coverImageKey"`
+	HoverImageURL string          `json:"hoverImage"`
+	HoverImageKey string          `json:"hoverImageKey"`
+	PriceMode     string          `json:"priceMode"`
+	Detail        json.RawMessage `json:"-"`
+}
+
+func ProductContentOf(p Product) ProductContent {
+	return ProductContent{
+		Slug:          p.Slug,
+		StyleNo:       p.StyleNo,
+		Season:        p.Season,
+		Category:      p.Category,
+		Availability:  p.Availability,
+		IsNew:         p.IsNew,
+		NewRank:       p.NewRank,
+		CoverImageURL: p.CoverImageURL,
+		CoverImageKey: p.CoverImageKey,
+		HoverImageURL: p.HoverImageURL,
+		HoverImageKey: p.HoverImageKey,
+		PriceMode:     p.PriceMode,
+		Detail:        p.DetailJSON,
+	}
+}
+
+// Columns is the content as a gorm column map, for Updates on the products table.
+func (pc ProductContent) Columns() map[string]any {
+	return map[string]any{
+		"slug":            pc.Slug,
+		"style_no":        pc.StyleNo,
+		"season":          pc.Season,
+		"category":        pc.Category,
+		"availability":    pc.Availability,
+		"is_new":          pc.IsNew,
+		"new_rank":        pc.NewRank,
+		"cover_image_url": pc.CoverImageURL,
+		"cover_image_key": pc.CoverImageKey,
+		"hover_image_url": pc.HoverImageURL,
+		"hover_image_key": pc.HoverImageKey,
+		"price_mode":      pc.PriceMode,
+		"detail_json":     pc.Detail,
+	}
+}
+
+// applyTo overwrites the content fields of p.
+func (pc ProductContent) applyTo(p *Product) {
+	p.Slug = pc.Slug
+	p.StyleNo = pc.StyleNo
+	p.Season = pc.Season
+	p.Category = pc.Category
+	p.Availability = pc.Availability
+	p.IsNew = pc.IsNew
+	p.NewRank = pc.NewRank
+	p.CoverImageURL = pc.CoverImageURL
+	p.CoverImageKey = pc.CoverImageKey
+	p.HoverImageURL = pc.HoverImageURL
+	p.HoverImageKey = pc.HoverImageKey
+	p.PriceMode = pc.PriceMode
+	p.DetailJSON = pc.Detail
+}
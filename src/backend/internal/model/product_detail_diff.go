@@ -0,0 +1,225 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strconv"
+)
+
+// Detail diff operations.
+const (
+	DetailAdded   = "added"
+	DetailRemoved = "removed"
+	DetailChanged = "changed"
+	DetailMoved   = "moved"
+)
+
+// DetailChange describes one item (or top-level field) that differs between two details.
+type DetailChange struct {
+	Key  string          `json:"key"`
+	Op   string          `json:"op"`
+	From json.RawMessage `json:"from,omitempty"`
+	To   json.RawMessage `json:"to,omitempty"`
+
+	// FromIndex / ToIndex locate list items; set when the item exists on that side.
+	FromIndex *int `json:"fromIndex,omitempty"`
+	ToIndex   *int `json:"toIndex,omitempty"`
+}
+
+// ProductDetailDiff is a structured diff of two Product.DetailJSON values.
+//
+// Sections are matched by id, specs and option groups by the same keys the template
+// merge uses, so renaming a label shows up as a change rather than remove+add.
+// Fields covers every other top-level key (gallery, schema_version, ...).
+type ProductDetailDiff struct {
+	Sections     []DetailChange `json:"sections"`
+	Specs        []DetailChange `json:"specs"`
+	OptionGroups []DetailChange `json:"optionGroups"`
+	Fields       []DetailChange `json:"fields"`
+}
+
+// Empty reports whether the two details are equivalent.
+func (d ProductDetailDiff) Empty() bool {
+	return len(d.Sections) == 0 && len(d.Specs) == 0 && len(d.OptionGroups) == 0 && len(d.Fields) == 0
+}
+
+// DiffProductDetail compares two details. Empty input is treated as an empty object.
+func DiffProductDetail(before, after json.RawMessage) (ProductDetailDiff, error) {
+	var out ProductDetailDiff
+	b, err := asObject(before)
+	if err != nil {
+		return out, err
+	}
+	a, err := asObject(after)
+	if err != nil {
+		return out, err
+	}
+
+	out.Sections = diffDetailList(b["sections"], a["sections"], func(m map[string]any) string {
+		return pickString(m, "id", "key")
+	})
+	out.Specs = diffDetailList(b["specs"], a["specs"], func(m map[string]any) string {
+		return pickString(m, "k", "label", "key", "name")
+	})
+	out.OptionGroups = diffDetailList(b["option_groups"], a["option_groups"], func(m map[string]any) string {
+		return pickString(m, "key", "name", "title", "label")
+	})
+
+	keys := map[string]bool{}
+	for k := range b {
+		keys[k] = true
+	}
+	for k := range a {
+		keys[k] = true
+	}
+	delete(keys, "sections")
+	delete(keys, "specs")
+	delete(keys, "option_groups")
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+	for _, k := range sorted {
+		bv, inBefore := b[k]
+		av, inAfter := a[k]
+		switch {
+		case !inBefore:
+			out.Fields = append(out.Fields, DetailChange{Key: k, Op: DetailAdded, To: canonicalJSON(av)})
+		case !inAfter:
+			out.Fields = append(out.Fields, DetailChange{Key: k, Op: DetailRemoved, From: canonicalJSON(bv)})
+		default:
+			from, to := canonicalJSON(bv), canonicalJSON(av)
+			if !bytes.Equal(from, to) {
+				out.Fields = append(out.Fields, DetailChange{Key: k, Op: DetailChanged, From: from, To: to})
+			}
+		}
+	}
+	return out, nil
+}
+
+type detailItem struct {
+	key   string
+	index int
+	raw   json.RawMessage
+}
+
+// diffDetailList matches items by key. Items without a key are matched by position ("#3").
+// An unchanged item is reported as moved only when it is not part of the longest run of
+// items that kept their relative order, so inserting or moving one section flags only that one.
+func diffDetailList(before, after any, keyOf func(map[string]any) string) []DetailChange {
+	bItems := detailItems(before, keyOf)
+	aItems := detailItems(after, keyOf)
+
+	bByKey := make(map[string]detailItem, len(bItems))
+	for _, it := range bItems {
+		bByKey[it.key] = it
+	}
+	aByKey := make(map[string]detailItem, len(aItems))
+	for _, it := range aItems {
+		aByKey[it.key] = it
+	}
+
+	// Items outside the longest common subsequence of surviving keys are the ones that moved.
+	var bCommon, aCommon []string
+	for _, it := range bItems {
+		if _, ok := aByKey[it.key]; ok {
+			bCommon = append(bCommon, it.key)
+		}
+	}
+	for _, it := range aItems {
+		if _, ok := bByKey[it.key]; ok {
+			aCommon = append(aCommon, it.key)
+		}
+	}
+	stayed := longestCommonKeys(bCommon, aCommon)
+
+	var out []DetailChange
+	for _, it := range bItems {
+		if _, ok := aByKey[it.key]; !ok {
+			out = append(out, DetailChange{Key: it.key, Op: DetailRemoved, From: it.raw, FromIndex: intPtr(it.index)})
+		}
+	}
+	for _, it := range aItems {
+		prev, ok := bByKey[it.key]
+		if !ok {
+			out = append(out, DetailChange{Key: it.key, Op: DetailAdded, To: it.raw, ToIndex: intPtr(it.index)})
+			continue
+		}
+		switch {
+		case !bytes.Equal(prev.raw, it.raw):
+			out = append(out, DetailChange{Key: it.key, Op: DetailChanged, From: prev.raw, To: it.raw, FromIndex: intPtr(prev.index), ToIndex: intPtr(it.index)})
+		case !stayed[it.key]:
+			out = append(out, DetailChange{Key: it.key, Op: DetailMoved, FromIndex: intPtr(prev.index), ToIndex: intPtr(it.index)})
+		}
+	}
+	return out
+}
+
+func longestCommonKeys(a, b []string) map[string]bool {
+	// lcs[i][j] is the LCS length of a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	out := map[string]bool{}
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] == b[j]:
+			out[a[i]] = true
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return out
+}
+
+func detailItems(raw any, keyOf func(map[string]any) string) []detailItem {
+	arr, ok := raw.([]any)
+	if !ok {
+		return nil
+	}
+	out := make([]detailItem, 0, len(arr))
+	seen := map[string]int{}
+	for i, v := range arr {
+		key := ""
+		if m, ok := v.(map[string]any); ok {
+			key = keyOf(m)
+		}
+		if key == "" {
+			key = "#" + strconv.Itoa(i)
+		}
+		// Duplicate keys stay distinguishable: "color", "color#2", ...
+		seen[key]++
+		if n := seen[key]; n > 1 {
+			key += "#" + strconv.Itoa(n)
+		}
+		out = append(out, detailItem{key: key, index: i, raw: canonicalJSON(v)})
+	}
+	return out
+}
+
+// canonicalJSON re-encodes a decoded value; map keys come out sorted, so equal values compare equal.
+func canonicalJSON(v any) json.RawMessage {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	return b
+}
+
+func intPtr(v int) *int { return &v }
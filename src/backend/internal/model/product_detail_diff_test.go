@@ -0,0 +1,75 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDiffProductDetail(t *testing.T) {
+	before := json.RawMessage(`{
+		"schema_version": 2,
+		"gallery": [],
+		"specs": [
+			{"key": "pieces", "value_i18n": {"zh": "1", "en": "1"}},
+			{"key": "lead_time", "value_i18n": {"zh": "", "en": ""}}
+		],
+		"option_groups": [
+			{"key": "color", "options": ["ivory"]},
+			{"key": "size", "options": []}
+		],
+		"sections": [
+			{"id": "gallery", "type": "gallery"},
+			{"id": "overview", "type": "richText"},
+			{"id": "specs", "type": "specs"}
+		]
+	}`)
+	after := json.RawMessage(`{
+		"schema_version": 2,
+		"gallery": ["a.jpg"],
+		"specs": [
+			{"value_i18n": {"en": "1", "zh": "1"}, "key": "pieces"},
+			{"key": "lead_time", "value_i18n": {"zh": "30天", "en": "30 days"}}
+		],
+		"option_groups": [
+			{"key": "size", "options": []}
+		],
+		"sections": [
+			{"id": "hero", "type": "richText"},
+			{"id": "specs", "type": "specs"},
+			{"id": "gallery", "type": "gallery"},
+			{"id": "overview", "type": "richText"}
+		]
+	}`)
+
+	d, err := DiffProductDetail(before, after)
+	if err != nil {
+		t.Fatalf("diff: %v", err)
+	}
+
+	// Key order inside an item does not matter.
+	if len(d.Specs) != 1 || d.Specs[0].Key != "lead_time" || d.Specs[0].Op != DetailChanged {
+		t.Fatalf("unexpected specs diff: %+v", d.Specs)
+	}
+	if len(d.OptionGroups) != 1 || d.OptionGroups[0].Key != "color" || d.OptionGroups[0].Op != DetailRemoved {
+		t.Fatalf("unexpected option groups diff: %+v", d.OptionGroups)
+	}
+	// "hero" is new; only "specs" changed its order relative to the other surviving sections.
+	if len(d.Sections) != 2 ||
+		d.Sections[0].Key != "hero" || d.Sections[0].Op != DetailAdded ||
+		d.Sections[1].Key != "specs" || d.Sections[1].Op != DetailMoved ||
+		*d.Sections[1].FromIndex != 2 || *d.Sections[1].ToIndex != 1 {
+		t.Fatalf("unexpected sections diff: %+v", d.Sections)
+	}
+	if len(d.Fields) != 1 || d.Fields[0].Key != "gallery" || string(d.Fields[0].To) != `["a.jpg"]` {
+		t.Fatalf("unexpected fields diff: %+v", d.Fields)
+	}
+
+	same, err := DiffProductDetail(before, before)
+	if err != nil || !same.Empty() {
+		t.Fatalf("expected empty diff, got %+v err=%v", same, err)
+	}
+
+	if _, err := DiffProductDetail(json.RawMessage(`[]`), nil); err == nil {
+		t.Fatalf("expected error for non-object detail")
+	}
+}
@@ -0,0 +1,41 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ProductDraft is the pending working copy of a published product.
+//
+// Edits to a published product land here instead of the live row, so the public site keeps
+// serving the last published content until the draft is promoted ("publish changes") or discarded.
+// Snapshot holds the full working copy in the product's JSON form; at most one draft per product.
+type ProductDraft struct {
+	ID        uint `gorm:"primaryKey" json:"id"`
+	ProductID uint `gorm:"not null;uniqueIndex" json:"productId"`
+
+	Snapshot json.RawMessage `gorm:"type:jsonb" json:"-"`
+
+	// Last editor, denormalized like ProductRevision.
+	ActorID    *uint  `json:"actorId,omitempty"`
+	ActorEmail string `gorm:"type:text;not null;default:''" json:"actorEmail"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// WorkingCopy overlays the draft's content (see ProductContent) on live, the product's
+// current row. Publish state, schedules and timestamps always come from live; an
+// empty draft yields live itself.
+func (d ProductDraft) WorkingCopy(live Product) (Product, error) {
+	working := live
+	if len(d.Snapshot) == 0 {
+		return working, nil
+	}
+	var snap Product
+	if err := json.Unmarshal(d.Snapshot, &snap); err != nil {
+		return working, err
+	}
+	ProductContentOf(snap).applyTo(&working)
+	return working, nil
+}
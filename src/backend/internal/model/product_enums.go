@@ -0,0 +1,34 @@
+package model
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Catalog enums. Seasons and categories are taxonomy terms (see TaxonomyTerm);
+// ProductCategories are only the categories seeded into an empty taxonomy.
+var (
+	ProductCategories     = []string{"gown", "couture", "bridal"}
+	ProductAvailabilities = []string{"in_stock", "preorder", "archived"}
+
+	seasonRe = regexp.MustCompile(`^(ss|fw)[0-9]{2}$`)
+)
+
+// NormalizeSeason lower-cases a season code and reports whether it looks like ss25 / fw25.
+func NormalizeSeason(raw string) (string, bool) {
+	s := strings.ToLower(strings.TrimSpace(raw))
+	return s, seasonRe.MatchString(s)
+}
+
+func IsValidProductAvailability(v string) bool {
+	return containsString(ProductAvailabilities, strings.TrimSpace(v))
+}
+
+func containsString(list []string, v string) bool {
+	for _, it := range list {
+		if it == v {
+			return true
+		}
+	}
+	return false
+}
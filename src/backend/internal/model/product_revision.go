@@ -0,0 +1,26 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ProductRevision is a snapshot of a product row taken right before it was changed.
+//
+// Snapshot holds the product's JSON form (same shape as the admin API), so a revision
+// can be diffed against the current row and restored without per-column bookkeeping.
+type ProductRevision struct {
+	ID        uint `gorm:"primaryKey" json:"id"`
+	ProductID uint `gorm:"not null;index" json:"productId"`
+
+	// Reason is the operation that replaced this snapshot: update | restore | publish_changes | import.
+	Reason string `gorm:"type:text;not null;default:''" json:"reason"`
+
+	Snapshot json.RawMessage `gorm:"type:jsonb" json:"snapshot,omitempty"`
+
+	// Actor is denormalized so revisions survive account deletion.
+	ActorID    *uint  `json:"actorId,omitempty"`
+	ActorEmail string `gorm:"type:text;not null;default:''" json:"actorEmail"`
+
+	CreatedAt time.Time `gorm:"index" json:"createdAt"`
+}
@@ -0,0 +1,129 @@
+package model
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// IsValidVariantAvailability reports whether v is accepted for ProductVariant.Availability:
+// a product availability, or empty for "same as the product".
+func IsValidVariantAvailability(v string) bool {
+	v = strings.TrimSpace(v)
+	return v == "" || IsValidProductAvailability(v)
+}
+
+// ProductVariant is one color × size combination of a product.
+//
+// Rows are generated from the "color" and "size" option groups in Product.DetailJSON
+// (see PlanProductVariants) and kept in sync when the detail changes; the per-variant
+// fields (suffix, availability, MOQ, lead time) are owned by the backoffice.
+// Color/Size hold option keys; an empty value means the product has no such option group.
+type ProductVariant struct {
+	ID        uint `gorm:"primaryKey" json:"id"`
+	ProductID uint `gorm:"not null;uniqueIndex:idx_product_variants_combo" json:"productId"`
+
+	Color string `gorm:"type:text;not null;default:'';uniqueIndex:idx_product_variants_combo" json:"color"`
+	Size  string `gorm:"type:text;not null;default:'';uniqueIndex:idx_product_variants_combo" json:"size"`
+
+	// StyleSuffix optionally gives the variant its own style number: {styleNo}-{suffix}.
+	StyleSuffix string `gorm:"type:text;not null;default:''" json:"styleSuffix"`
+
+	Availability string `gorm:"type:text;not null;default:''" json:"availability"` // ''|in_stock|preorder|archived
+	MOQ          int    `gorm:"not null;default:0" json:"moq"`
+	LeadTime     string `gorm:"type:text;not null;default:''" json:"leadTime"`
+
+	// Position follows the option order in the detail (color-major).
+	Position int `gorm:"not null;default:0" json:"position"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// StyleNo returns the variant's own style number, or the product's when no suffix is set.
+func (v ProductVariant) StyleNo(productStyleNo string) string {
+	if v.StyleSuffix == "" {
+		return productStyleNo
+	}
+	return productStyleNo + "-" + v.StyleSuffix
+}
+
+// EffectiveAvailability resolves an empty availability to the product's.
+func (v ProductVariant) EffectiveAvailability(productAvailability string) string {
+	if v.Availability == "" {
+		return productAvailability
+	}
+	return v.Availability
+}
+
+// VariantCombo is one color × size pair derived from the detail option groups.
+type VariantCombo struct {
+	Color    string
+	Size     string
+	Position int
+}
+
+// PlanProductVariants returns the combinations implied by the "color" and "size" option groups.
+//
+// Groups are recognized by key (or the legacy names 颜色/尺码, like the admin editor does);
+// options by key/id/value/label/name, or plain strings. When only one of the two groups has
+// options the other dimension is left empty; with neither there are no variants.
+func PlanProductVariants(detail json.RawMessage) ([]VariantCombo, error) {
+	obj, err := asObject(detail)
+	if err != nil {
+		return nil, err
+	}
+
+	var colors, sizes []string
+	for _, g := range normalizeOptionGroups(obj["option_groups"]) {
+		switch pickString(g, "key", "name", "title", "label") {
+		case "color", "颜色":
+			colors = appendOptionKeys(colors, g["options"])
+		case "size", "尺码":
+			sizes = appendOptionKeys(sizes, g["options"])
+		}
+	}
+	if len(colors) == 0 && len(sizes) == 0 {
+		return nil, nil
+	}
+	if len(colors) == 0 {
+		colors = []string{""}
+	}
+	if len(sizes) == 0 {
+		sizes = []string{""}
+	}
+
+	out := make([]VariantCombo, 0, len(colors)*len(sizes))
+	for _, c := range colors {
+		for _, s := range sizes {
+			out = append(out, VariantCombo{Color: c, Size: s, Position: len(out)})
+		}
+	}
+	return out, nil
+}
+
+func appendOptionKeys(dst []string, raw any) []string {
+	arr, ok := raw.([]any)
+	if !ok {
+		return dst
+	}
+	seen := map[string]bool{}
+	for _, k := range dst {
+		seen[k] = true
+	}
+	for _, it := range arr {
+		var key string
+		switch v := it.(type) {
+		case string:
+			key = strings.TrimSpace(v)
+		case map[string]any:
+			key = pickString(v, "key", "id", "value", "label", "name")
+		}
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		dst = append(dst, key)
+	}
+	return dst
+}
@@ -0,0 +1,38 @@
+package model
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestPlanProductVariants(t *testing.T) {
+	detail := json.RawMessage(`{"option_groups":[
+		{"key":"color","options":[{"key":"ivory","label_i18n":{"zh":"象牙白","en":"Ivory"}},"black",{"key":"ivory"}]},
+		{"name":"尺码","options":["S","M"]},
+		{"key":"fabric","options":["silk"]}
+	]}`)
+	got, err := PlanProductVariants(detail)
+	if err != nil {
+		t.Fatalf("plan: %v", err)
+	}
+	want := []VariantCombo{
+		{Color: "ivory", Size: "S", Position: 0},
+		{Color: "ivory", Size: "M", Position: 1},
+		{Color: "black", Size: "S", Position: 2},
+		{Color: "black", Size: "M", Position: 3},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+
+	// Only sizes: the color dimension is empty.
+	got, _ = PlanProductVariants(json.RawMessage(`{"option_groups":[{"key":"color","options":[]},{"key":"size","options":["S"]}]}`))
+	if !reflect.DeepEqual(got, []VariantCombo{{Size: "S"}}) {
+		t.Fatalf("unexpected size-only plan: %+v", got)
+	}
+
+	if got, err := PlanProductVariants(DefaultProductDetailTemplate()); err != nil || len(got) != 0 {
+		t.Fatalf("expected no variants for the empty template, got %+v err=%v", got, err)
+	}
+}
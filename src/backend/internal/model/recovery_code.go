@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// RecoveryCode is a one-time 2FA backup code (bcrypt hash, like passwords).
+//
+// Codes are generated in batches when 2FA is enabled; a new batch replaces the old one.
+type RecoveryCode struct {
+	ID     uint `gorm:"primaryKey" json:"id"`
+	UserID uint `gorm:"not null;index" json:"userId"`
+
+	CodeHash string     `gorm:"type:text;not null" json:"-"`
+	UsedAt   *time.Time `gorm:"" json:"usedAt,omitempty"`
+
+	CreatedAt time.Time `json:"createdAt"`
+}
@@ -0,0 +1,100 @@
+package model
+
+import "strings"
+
+// Backoffice roles.
+//
+// RoleAdmin is the super admin and implicitly holds every permission.
+// The other roles are scoped to a slice of the backoffice.
+const (
+	RoleAdmin        = "admin"
+	RoleMerchandiser = "merchandiser"
+	RoleSales        = "sales"
+	RoleAnalyst      = "analyst"
+)
+
+// Permission scopes checked per admin route.
+//
+// Format: {resource}:{read|write}. "write" does not imply "read"; roles list both explicitly.
+const (
+	PermProductsRead  = "products:read"
+	PermProductsWrite = "products:write"
+	PermUploadsWrite  = "uploads:write"
+	PermAssetsRead    = "assets:read"
+	PermUpdatesRead   = "updates:read"
+	PermUpdatesWrite  = "updates:write"
+	PermContactsRead  = "contacts:read"
+	PermContactsWrite = "contacts:write"
+	PermEventsRead    = "events:read"
+	PermEventsWrite   = "events:write"
+	PermSettingsRead  = "settings:read"
+	PermSettingsWrite = "settings:write"
+	PermUsersManage   = "users:manage"
+	PermAuditRead     = "audit:read"
+	// PermPreviewsWrite mints signed preview links for unpublished products/updates.
+	PermPreviewsWrite = "previews:write"
+)
+
+var rolePermissions = map[string][]string{
+	RoleAdmin: {
+		PermProductsRead, PermProductsWrite,
+		PermUploadsWrite, PermAssetsRead,
+		PermUpdatesRead, PermUpdatesWrite,
+		PermContactsRead, PermContactsWrite,
+		PermEventsRead, PermEventsWrite,
+		PermSettingsRead, PermSettingsWrite,
+		PermUsersManage,
+		PermAuditRead,
+		PermPreviewsWrite,
+	},
+	// Merchandisers manage the catalog: products, images and the detail template (read-only).
+	RoleMerchandiser: {
+		PermProductsRead, PermProductsWrite,
+		PermUploadsWrite, PermAssetsRead,
+		PermSettingsRead,
+		PermPreviewsWrite,
+	},
+	// Sales work the contact leads and share preview links with clients.
+	RoleSales: {
+		PermContactsRead, PermContactsWrite,
+		PermPreviewsWrite,
+	},
+	// Analysts read events/metrics only.
+	RoleAnalyst: {
+		PermEventsRead,
+	},
+}
+
+// Roles returns all known backoffice roles.
+func Roles() []string {
+	return []string{RoleAdmin, RoleMerchandiser, RoleSales, RoleAnalyst}
+}
+
+// IsValidRole reports whether role is a known backoffice role.
+func IsValidRole(role string) bool {
+	_, ok := rolePermissions[strings.TrimSpace(role)]
+	return ok
+}
+
+// RolePermissions returns a copy of the permission scopes granted to role.
+// Unknown roles have no permissions.
+func RolePermissions(role string) []string {
+	perms := rolePermissions[strings.TrimSpace(role)]
+	out := make([]string, len(perms))
+	copy(out, perms)
+	return out
+}
+
+// HasPermission reports whether role grants perm.
+func HasPermission(role, perm string) bool {
+	role = strings.TrimSpace(role)
+	if role == RoleAdmin {
+		return true
+	}
+	for _, p := range rolePermissions[role] {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
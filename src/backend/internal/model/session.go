@@ -0,0 +1,41 @@
+package model
+
+import "time"
+
+// Session revocation reasons (Session.RevokedReason).
+const (
+	SessionRevokedLogout         = "logout"
+	SessionRevokedByUser         = "revoked_by_user"
+	SessionRevokedRefreshReused  = "refresh_token_reused"
+	SessionRevokedPasswordChange = "password_changed"
+	SessionRevokedPasswordReset  = "password_reset"
+)
+
+// Session is a server-side admin login session (one per device/browser).
+//
+// The ID is the "sid" claim carried by access and refresh tokens and identifies the whole
+// refresh token family. CurrentJTI is the jti of the only refresh token that may still be
+// exchanged; presenting an older one means the token leaked and the session is revoked.
+type Session struct {
+	ID     string `gorm:"type:text;primaryKey" json:"id"`
+	UserID uint   `gorm:"not null;index" json:"userId"`
+
+	CurrentJTI string `gorm:"type:text;not null;uniqueIndex" json:"-"`
+
+	UserAgent string `gorm:"type:text;not null;default:''" json:"userAgent"`
+	IP        string `gorm:"type:text;not null;default:''" json:"ip"`
+
+	LastUsedAt time.Time `gorm:"not null" json:"lastUsedAt"`
+	ExpiresAt  time.Time `gorm:"not null;index" json:"expiresAt"`
+
+	RevokedAt     *time.Time `gorm:"index" json:"revokedAt,omitempty"`
+	RevokedReason string     `gorm:"type:text;not null;default:''" json:"revokedReason,omitempty"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Active reports whether the session can still be used at t.
+func (s Session) Active(t time.Time) bool {
+	return s.RevokedAt == nil && t.Before(s.ExpiresAt)
+}
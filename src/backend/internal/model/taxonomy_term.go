@@ -0,0 +1,127 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Taxonomy kinds: the product fields whose values are managed as terms.
+const (
+	TaxonomySeason   = "season"
+	TaxonomyCategory = "category"
+)
+
+// TaxonomyKinds lists every kind, in display order.
+var TaxonomyKinds = []string{TaxonomySeason, TaxonomyCategory}
+
+// taxonomyColumns maps each kind to the products column holding its codes.
+var taxonomyColumns = map[string]string{
+	TaxonomySeason:   "season",
+	TaxonomyCategory: "category",
+}
+
+var (
+	taxonomyCodeRe = regexp.MustCompile(`^[a-z][a-z0-9_-]{0,31}$`)
+
+	ErrInvalidTaxonomyLabels = errors.New("labelI18n must be an object of strings")
+)
+
+// TaxonomyTerm is one allowed value of Product.Season or Product.Category.
+//
+// Products store the term's Code. Inactive terms are hidden from the public filter menus and
+// can no longer be assigned; products that already use them keep their value.
+type TaxonomyTerm struct {
+	ID uint `gorm:"primaryKey" json:"id"`
+
+	Kind string `gorm:"type:text;not null;uniqueIndex:idx_taxonomy_terms_kind_code" json:"kind"`
+	Code string `gorm:"type:text;not null;uniqueIndex:idx_taxonomy_terms_kind_code" json:"code"`
+
+	// LabelI18n maps locales to display names, e.g. {"zh":"晚礼服","en":"Evening Gown"}.
+	LabelI18n json.RawMessage `gorm:"type:jsonb" json:"labelI18n"`
+
+	SortOrder int  `gorm:"not null;default:0" json:"sortOrder"`
+	Active    bool `gorm:"not null" json:"active"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// IsValidTaxonomyKind reports whether kind is one of TaxonomyKinds.
+func IsValidTaxonomyKind(kind string) bool {
+	return containsString(TaxonomyKinds, kind)
+}
+
+// TaxonomyColumn returns the products column that stores codes of kind. Queries must use it
+// rather than the kind string, which comes from stored rows.
+func TaxonomyColumn(kind string) (string, bool) {
+	col, ok := taxonomyColumns[kind]
+	return col, ok
+}
+
+// NormalizeTaxonomyCode lower-cases a code and checks it for kind:
+// seasons look like ss25 / fw25, other codes are short slugs (gown, ready_to_wear).
+func NormalizeTaxonomyCode(kind, raw string) (string, bool) {
+	if kind == TaxonomySeason {
+		return NormalizeSeason(raw)
+	}
+	s := strings.ToLower(strings.TrimSpace(raw))
+	return s, taxonomyCodeRe.MatchString(s)
+}
+
+// NormalizeTaxonomyLabels validates a {"locale":"label"} object, trimming labels and
+// dropping empty ones. An empty input yields {}.
+func NormalizeTaxonomyLabels(raw json.RawMessage) (json.RawMessage, error) {
+	labels := map[string]string{}
+	if len(raw) > 0 && string(raw) != "null" {
+		if err := json.Unmarshal(raw, &labels); err != nil {
+			return nil, ErrInvalidTaxonomyLabels
+		}
+	}
+	out := make(map[string]string, len(labels))
+	for locale, label := range labels {
+		locale = strings.TrimSpace(locale)
+		label = strings.TrimSpace(label)
+		if locale != "" && label != "" {
+			out[locale] = label
+		}
+	}
+	return json.Marshal(out)
+}
+
+// DefaultSeasons are the seasons the storefront and backoffice offered before seasons became
+// managed terms, newest first.
+var DefaultSeasons = []string{"fw25", "ss25"}
+
+// DefaultTaxonomyTerms are seeded into an empty taxonomy.
+func DefaultTaxonomyTerms() []TaxonomyTerm {
+	labels := map[string]string{
+		"fw25":    `{"en":"Fall/Winter 2025","zh":"2025 秋冬"}`,
+		"ss25":    `{"en":"Spring/Summer 2025","zh":"2025 春夏"}`,
+		"gown":    `{"en":"Evening Gown","zh":"晚礼服"}`,
+		"couture": `{"en":"Couture","zh":"高级定制"}`,
+		"bridal":  `{"en":"Bridal","zh":"婚纱"}`,
+	}
+	out := make([]TaxonomyTerm, 0, len(DefaultSeasons)+len(ProductCategories))
+	for i, code := range DefaultSeasons {
+		out = append(out, TaxonomyTerm{
+			Kind:      TaxonomySeason,
+			Code:      code,
+			LabelI18n: json.RawMessage(labels[code]),
+			SortOrder: (i + 1) * 10,
+			Active:    true,
+		})
+	}
+	for i, code := range ProductCategories {
+		out = append(out, TaxonomyTerm{
+			Kind:      TaxonomyCategory,
+			Code:      code,
+			LabelI18n: json.RawMessage(labels[code]),
+			SortOrder: (i + 1) * 10,
+			Active:    true,
+		})
+	}
+	return out
+}
@@ -19,7 +19,19 @@ type UpdatePost struct {
 
 	PublishedAt *time.Time `gorm:"index" json:"publishedAt,omitempty"`
 
+	// PublishAt / UnpublishAt schedule a state change; the scheduler applies and clears them.
+	PublishAt   *time.Time `gorm:"index" json:"publishAt,omitempty"`
+	UnpublishAt *time.Time `gorm:"index" json:"unpublishAt,omitempty"`
+
 	CreatedAt time.Time  `json:"createdAt"`
 	UpdatedAt time.Time  `json:"updatedAt"`
 	DeletedAt *time.Time `gorm:"index" json:"deletedAt,omitempty"`
 }
+
+// SortTime is when the post appears in date order: published_at, or created_at for drafts.
+func (u UpdatePost) SortTime() time.Time {
+	if u.PublishedAt != nil {
+		return *u.PublishedAt
+	}
+	return u.CreatedAt
+}
@@ -4,25 +4,42 @@ import "time"
 
 // User represents a backoffice user.
 //
-// This project uses a single super admin (no multi-tenant).
+// One super admin ("admin") is bootstrapped from env; further accounts are invited
+// with a scoped role (see role.go). No multi-tenancy.
 type User struct {
 	ID uint `gorm:"primaryKey" json:"id"`
 
-	Email        string `gorm:"type:text;uniqueIndex;not null" json:"email"`
+	// Email is unique among live users only, so a removed account's address can be re-invited.
+	Email        string `gorm:"type:text;uniqueIndex:idx_users_email_live,where:deleted_at IS NULL;not null" json:"email"`
 	PasswordHash string `gorm:"type:text;not null" json:"-"`
 
-	Role   string `gorm:"type:text;not null;default:admin" json:"role"`   // admin
+	Role   string `gorm:"type:text;not null;default:admin" json:"role"`   // admin|merchandiser|sales|analyst
 	Status string `gorm:"type:text;not null;default:active" json:"status"` // active|disabled|locked
 
 	FailedLoginCount  int        `gorm:"not null;default:0" json:"failedLoginCount"`
 	LockedUntil       *time.Time `gorm:"" json:"lockedUntil,omitempty"`
 	LastLoginAt       *time.Time `gorm:"" json:"lastLoginAt,omitempty"`
 	PasswordUpdatedAt *time.Time `gorm:"" json:"passwordUpdatedAt,omitempty"`
-	// RefreshTokenIssuedAt is used to invalidate older refresh tokens after rotation.
-	// Not exposed via APIs.
+	// RefreshTokenIssuedAt guards legacy refresh tokens issued before server-side sessions
+	// (see Session). Not exposed via APIs.
 	RefreshTokenIssuedAt *time.Time `gorm:"" json:"-"`
 
+	// TOTP two-factor authentication (RFC 6238). Secrets are never exposed via APIs and are
+	// encrypted with AUTH_TOTP_KEY (see security.SecretBox). Without a key they are stored in
+	// plaintext; such rows are encrypted on their next successful code once a key is set.
+	// TOTPPendingSecret holds an enrollment that has not been confirmed with a code yet.
+	TOTPSecret        string     `gorm:"type:text;not null;default:''" json:"-"`
+	TOTPPendingSecret string     `gorm:"type:text;not null;default:''" json:"-"`
+	TOTPEnabledAt     *time.Time `gorm:"" json:"totpEnabledAt,omitempty"`
+	// TOTPLastUsedStep rejects replays of a code within its validity window.
+	TOTPLastUsedStep int64 `gorm:"not null;default:0" json:"-"`
+
 	CreatedAt time.Time  `json:"createdAt"`
 	UpdatedAt time.Time  `json:"updatedAt"`
 	DeletedAt *time.Time `gorm:"index" json:"deletedAt,omitempty"`
 }
+
+// TwoFactorEnabled reports whether login requires a TOTP code.
+func (u User) TwoFactorEnabled() bool {
+	return u.TOTPEnabledAt != nil && u.TOTPSecret != ""
+}
@@ -0,0 +1,165 @@
+// Package pagination implements keyset ("cursor") pagination over whitelisted sort orders.
+//
+// A Sort lists the columns a list is ordered by, ending with a unique column (the primary
+// key) so that rows never tie. The cursor handed to clients is an opaque token holding the
+// sort name and the sort values of the last row of a page; the next page continues strictly
+// after that row, so it stays stable while rows are inserted and costs an index range scan
+// instead of an OFFSET.
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// ErrInvalidCursor is returned for a malformed cursor or one issued for another sort.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Key is one ORDER BY term.
+type Key struct {
+	// Column is a column name or SQL expression from code (never user input).
+	// It must not be NULL: wrap nullable columns in COALESCE.
+	Column string
+	Desc   bool
+}
+
+// Sort is a named ordering of rows of type T.
+type Sort[T any] struct {
+	Name string
+	Keys []Key
+	// Values returns a row's values for Keys, in order. Values are JSON round-tripped in
+	// cursors, so use plain types (integers, bool, string, time.Time).
+	Values func(T) []any
+}
+
+// Sorts is the whitelist of a list endpoint. The first entry is the default.
+type Sorts[T any] []Sort[T]
+
+// Lookup returns the sort named name; "" selects the default.
+func (s Sorts[T]) Lookup(name string) (Sort[T], bool) {
+	name = strings.TrimSpace(name)
+	if name == "" && len(s) > 0 {
+		return s[0], true
+	}
+	for _, it := range s {
+		if it.Name == name {
+			return it, true
+		}
+	}
+	return Sort[T]{}, false
+}
+
+// Names lists the accepted sort names.
+func (s Sorts[T]) Names() []string {
+	out := make([]string, len(s))
+	for i, it := range s {
+		out[i] = it.Name
+	}
+	return out
+}
+
+// Order applies the sort's ORDER BY.
+func (s Sort[T]) Order(db *gorm.DB) *gorm.DB {
+	parts := make([]string, len(s.Keys))
+	for i, k := range s.Keys {
+		parts[i] = k.Column + " asc"
+		if k.Desc {
+			parts[i] = k.Column + " desc"
+		}
+	}
+	return db.Order(strings.Join(parts, ", "))
+}
+
+// After restricts db to the rows following cursor. An empty cursor leaves db unchanged.
+func (s Sort[T]) After(db *gorm.DB, cursor string) (*gorm.DB, error) {
+	if strings.TrimSpace(cursor) == "" {
+		return db, nil
+	}
+	vals, err := s.decode(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	// (k0 > v0) OR (k0 = v0 AND k1 > v1) OR ... with < for descending keys.
+	var (
+		ors  []string
+		args []any
+	)
+	for i, k := range s.Keys {
+		var ands []string
+		for j := 0; j < i; j++ {
+			ands = append(ands, s.Keys[j].Column+" = ?")
+			args = append(args, vals[j])
+		}
+		op := " > ?"
+		if k.Desc {
+			op = " < ?"
+		}
+		ands = append(ands, k.Column+op)
+		args = append(args, vals[i])
+		ors = append(ors, "("+strings.Join(ands, " AND ")+")")
+	}
+	return db.Where("("+strings.Join(ors, " OR ")+")", args...), nil
+}
+
+// Page trims rows fetched with a limit of limit+1 to limit and returns the cursor of the
+// next page, or "" on the last page.
+func (s Sort[T]) Page(rows []T, limit int) ([]T, string) {
+	if limit <= 0 || len(rows) <= limit {
+		return rows, ""
+	}
+	rows = rows[:limit]
+	return rows, s.Cursor(rows[limit-1])
+}
+
+type cursorPayload struct {
+	Sort   string            `json:"s"`
+	Values []json.RawMessage `json:"v"`
+}
+
+// Cursor encodes the position right after row.
+func (s Sort[T]) Cursor(row T) string {
+	vals := s.Values(row)
+	p := cursorPayload{Sort: s.Name, Values: make([]json.RawMessage, len(vals))}
+	for i, v := range vals {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return ""
+		}
+		p.Values[i] = b
+	}
+	b, err := json.Marshal(p)
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func (s Sort[T]) decode(cursor string) ([]any, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(strings.TrimSpace(cursor))
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var p cursorPayload
+	if err := json.Unmarshal(raw, &p); err != nil || p.Sort != s.Name || len(p.Values) != len(s.Keys) {
+		return nil, ErrInvalidCursor
+	}
+
+	// The value types come from a zero row.
+	var zero T
+	protos := s.Values(zero)
+	out := make([]any, len(p.Values))
+	for i, v := range p.Values {
+		ptr := reflect.New(reflect.TypeOf(protos[i]))
+		if err := json.Unmarshal(v, ptr.Interface()); err != nil {
+			return nil, ErrInvalidCursor
+		}
+		out[i] = ptr.Elem().Interface()
+	}
+	return out, nil
+}
@@ -0,0 +1,130 @@
+package pagination
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type row struct {
+	ID        uint `gorm:"primaryKey"`
+	Rank      int
+	CreatedAt time.Time
+}
+
+var testSorts = Sorts[row]{
+	{Name: "default", Keys: []Key{{Column: "rank", Desc: true}, {Column: "id", Desc: true}},
+		Values: func(r row) []any { return []any{r.Rank, r.ID} }},
+	{Name: "oldest", Keys: []Key{{Column: "created_at"}, {Column: "id"}},
+		Values: func(r row) []any { return []any{r.CreatedAt, r.ID} }},
+}
+
+func TestSortsLookup(t *testing.T) {
+	if s, ok := testSorts.Lookup(""); !ok || s.Name != "default" {
+		t.Fatalf("expected default sort, got %q %v", s.Name, ok)
+	}
+	if s, ok := testSorts.Lookup(" oldest "); !ok || s.Name != "oldest" {
+		t.Fatalf("expected oldest sort, got %q %v", s.Name, ok)
+	}
+	if _, ok := testSorts.Lookup("id; drop table rows"); ok {
+		t.Fatalf("expected unknown sort to be rejected")
+	}
+}
+
+func TestKeysetPaging(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := db.AutoMigrate(&row{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	base := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	// Ranks and timestamps repeat so that pages have to break ties on id.
+	for i := 0; i < 7; i++ {
+		r := row{Rank: i % 3, CreatedAt: base.Add(time.Duration(i/2) * time.Hour)}
+		if err := db.Create(&r).Error; err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+
+	walk := func(name string) []uint {
+		t.Helper()
+		sort, _ := testSorts.Lookup(name)
+		var (
+			ids    []uint
+			cursor string
+		)
+		for pages := 0; pages < 10; pages++ {
+			q, err := sort.After(db.Model(&row{}), cursor)
+			if err != nil {
+				t.Fatalf("after: %v", err)
+			}
+			var rows []row
+			if err := sort.Order(q).Limit(3 + 1).Find(&rows).Error; err != nil {
+				t.Fatalf("list: %v", err)
+			}
+			rows, cursor = sort.Page(rows, 3)
+			for _, r := range rows {
+				ids = append(ids, r.ID)
+			}
+			if cursor == "" {
+				return ids
+			}
+		}
+		t.Fatalf("paging did not terminate")
+		return nil
+	}
+
+	want := func(name string, order string) {
+		t.Helper()
+		var all []row
+		if err := db.Order(order).Find(&all).Error; err != nil {
+			t.Fatalf("list all: %v", err)
+		}
+		got := walk(name)
+		if len(got) != len(all) {
+			t.Fatalf("%s: expected %d rows, got %v", name, len(all), got)
+		}
+		for i := range all {
+			if got[i] != all[i].ID {
+				t.Fatalf("%s: expected order %v, got %v", name, all, got)
+			}
+		}
+	}
+	want("default", "rank desc, id desc")
+	want("oldest", "created_at asc, id asc")
+}
+
+func TestCursorRejectsForeignOrMalformedInput(t *testing.T) {
+	def, _ := testSorts.Lookup("default")
+	oldest, _ := testSorts.Lookup("oldest")
+	cursor := def.Cursor(row{ID: 4, Rank: 1})
+
+	if _, err := def.decode(cursor); err != nil {
+		t.Fatalf("decode own cursor: %v", err)
+	}
+	for _, bad := range []string{"not base64!", "bm90IGpzb24", oldest.Cursor(row{ID: 1})} {
+		if _, err := def.After(nil, bad); !errors.Is(err, ErrInvalidCursor) {
+			t.Fatalf("expected ErrInvalidCursor for %q, got %v", bad, err)
+		}
+	}
+}
+
+func TestParseParams(t *testing.T) {
+	p := ParseParams(url.Values{"limit": {"500"}, "offset": {"-3"}}, 50, 200)
+	if p.Limit != 200 || p.Offset != 0 || p.CursorMode || p.CursorOrNil() != nil {
+		t.Fatalf("unexpected offset params: %+v", p)
+	}
+	p = ParseParams(url.Values{"limit": {"x"}, "offset": {"20"}, "cursor": {""}}, 50, 200)
+	if p.Limit != 50 || p.Offset != 0 || !p.CursorMode {
+		t.Fatalf("expected cursor mode to ignore offset, got %+v", p)
+	}
+	if c := p.CursorOrNil(); c == nil || *c != "" {
+		t.Fatalf("expected an empty first cursor, got %v", c)
+	}
+}
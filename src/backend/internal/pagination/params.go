@@ -0,0 +1,75 @@
+package pagination
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Params holds the paging parameters shared by list endpoints.
+//
+// A "cursor" parameter (even empty, to start) selects cursor mode: Offset is ignored and
+// the COUNT(*) behind "total" is skipped. Without it, limit/offset paging works as before.
+type Params struct {
+	Limit      int
+	Offset     int
+	Cursor     string
+	CursorMode bool
+}
+
+// ParseParams reads limit, offset and cursor from a query string. Limit falls back to
+// defaultLimit and is capped at maxLimit.
+func ParseParams(query url.Values, defaultLimit, maxLimit int) Params {
+	p := Params{
+		Limit:  queryInt(query, "limit", defaultLimit),
+		Offset: queryInt(query, "offset", 0),
+	}
+	if p.Limit <= 0 {
+		p.Limit = defaultLimit
+	}
+	if p.Limit > maxLimit {
+		p.Limit = maxLimit
+	}
+	if p.Offset < 0 {
+		p.Offset = 0
+	}
+	_, p.CursorMode = query["cursor"]
+	p.Cursor = strings.TrimSpace(query.Get("cursor"))
+	if p.CursorMode {
+		p.Offset = 0
+	}
+	return p
+}
+
+// CursorOrNil returns the cursor in cursor mode and nil in offset mode, e.g. for cache keys
+// that must tell "first cursor page" from "offset page".
+func (p Params) CursorOrNil() *string {
+	if !p.CursorMode {
+		return nil
+	}
+	return &p.Cursor
+}
+
+// Response builds the list body: {"total" (when counted), "items", "next_cursor" (when set)}.
+func Response(items any, next string, total *int64) map[string]any {
+	resp := map[string]any{"items": items}
+	if total != nil {
+		resp["total"] = *total
+	}
+	if next != "" {
+		resp["next_cursor"] = next
+	}
+	return resp
+}
+
+func queryInt(query url.Values, key string, fallback int) int {
+	raw := strings.TrimSpace(query.Get(key))
+	if raw == "" {
+		return fallback
+	}
+	v, err := strconv.Atoi(raw)
+	if err != nil {
+		return fallback
+	}
+	return v
+}
@@ -0,0 +1,128 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Result is the outcome of a single Allow call.
+type Result struct {
+	Allowed bool
+	// Remaining is the number of requests left in the current window.
+	Remaining int
+	// RetryAfter is the time until the current window resets (only meaningful when !Allowed).
+	RetryAfter time.Duration
+}
+
+// Limiter is a fixed-window rate limiter keyed by an arbitrary string (e.g. client IP).
+type Limiter interface {
+	Allow(ctx context.Context, key string) (Result, error)
+}
+
+// New returns a Redis-backed limiter when rdb is set (shared across instances),
+// otherwise an in-memory limiter (per process).
+//
+// It returns nil when limit or window is not positive (rate limiting disabled).
+func New(rdb *redis.Client, prefix string, limit int, window time.Duration) Limiter {
+	if limit <= 0 || window <= 0 {
+		return nil
+	}
+	if rdb != nil {
+		return NewRedis(rdb, prefix, limit, window)
+	}
+	return NewMemory(limit, window)
+}
+
+// RedisLimiter counts requests with INCR on a per-window key.
+type RedisLimiter struct {
+	rdb    *redis.Client
+	prefix string
+	limit  int
+	window time.Duration
+	now    func() time.Time
+}
+
+func NewRedis(rdb *redis.Client, prefix string, limit int, window time.Duration) *RedisLimiter {
+	return &RedisLimiter{rdb: rdb, prefix: prefix, limit: limit, window: window, now: time.Now}
+}
+
+func (l *RedisLimiter) Allow(ctx context.Context, key string) (Result, error) {
+	now := l.now()
+	slot := now.UnixNano() / int64(l.window)
+	resetAt := time.Unix(0, (slot+1)*int64(l.window))
+	redisKey := fmt.Sprintf("%s:%s:%s", l.prefix, key, strconv.FormatInt(slot, 10))
+
+	pipe := l.rdb.TxPipeline()
+	incr := pipe.Incr(ctx, redisKey)
+	// Keep the key slightly longer than the window to tolerate clock skew between instances.
+	pipe.Expire(ctx, redisKey, l.window+time.Second)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return Result{Allowed: true}, fmt.Errorf("rate limit incr: %w", err)
+	}
+
+	count := int(incr.Val())
+	return buildResult(count, l.limit, resetAt.Sub(now)), nil
+}
+
+// MemoryLimiter is a process-local fallback used when Redis is disabled.
+type MemoryLimiter struct {
+	mu      sync.Mutex
+	limit   int
+	window  time.Duration
+	entries map[string]*memoryEntry
+	sweepAt time.Time
+	now     func() time.Time
+}
+
+type memoryEntry struct {
+	slot  int64
+	count int
+}
+
+func NewMemory(limit int, window time.Duration) *MemoryLimiter {
+	return &MemoryLimiter{limit: limit, window: window, entries: map[string]*memoryEntry{}, now: time.Now}
+}
+
+func (l *MemoryLimiter) Allow(_ context.Context, key string) (Result, error) {
+	now := l.now()
+	slot := now.UnixNano() / int64(l.window)
+	resetAt := time.Unix(0, (slot+1)*int64(l.window))
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Lazy sweep of stale windows (no background goroutine to manage).
+	if now.After(l.sweepAt) {
+		for k, e := range l.entries {
+			if e.slot < slot {
+				delete(l.entries, k)
+			}
+		}
+		l.sweepAt = resetAt
+	}
+
+	e := l.entries[key]
+	if e == nil || e.slot != slot {
+		e = &memoryEntry{slot: slot}
+		l.entries[key] = e
+	}
+	e.count++
+
+	return buildResult(e.count, l.limit, resetAt.Sub(now)), nil
+}
+
+func buildResult(count, limit int, untilReset time.Duration) Result {
+	remaining := limit - count
+	if remaining < 0 {
+		remaining = 0
+	}
+	if count > limit {
+		return Result{Allowed: false, Remaining: 0, RetryAfter: untilReset}
+	}
+	return Result{Allowed: true, Remaining: remaining}
+}
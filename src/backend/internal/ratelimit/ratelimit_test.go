@@ -0,0 +1,49 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryLimiter_FixedWindow(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	l := NewMemory(2, time.Minute)
+	l.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		res, err := l.Allow(ctx, "1.2.3.4")
+		if err != nil || !res.Allowed {
+			t.Fatalf("attempt %d: expected allowed, got %+v err=%v", i+1, res, err)
+		}
+	}
+
+	res, _ := l.Allow(ctx, "1.2.3.4")
+	if res.Allowed {
+		t.Fatalf("expected third attempt to be limited")
+	}
+	if res.RetryAfter <= 0 || res.RetryAfter > time.Minute {
+		t.Fatalf("unexpected retry after: %s", res.RetryAfter)
+	}
+
+	// Other keys are independent.
+	if res, _ := l.Allow(ctx, "5.6.7.8"); !res.Allowed {
+		t.Fatalf("expected other key to be allowed")
+	}
+
+	// Next window resets the counter.
+	now = now.Add(time.Minute)
+	if res, _ := l.Allow(ctx, "1.2.3.4"); !res.Allowed {
+		t.Fatalf("expected allowed in next window")
+	}
+}
+
+func TestNew_DisabledWhenLimitNotPositive(t *testing.T) {
+	if l := New(nil, "x", 0, time.Minute); l != nil {
+		t.Fatalf("expected nil limiter")
+	}
+	if _, ok := New(nil, "x", 5, time.Minute).(*MemoryLimiter); !ok {
+		t.Fatalf("expected memory limiter without redis")
+	}
+}
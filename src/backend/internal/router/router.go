@@ -1,6 +1,7 @@
 package router
 
 import (
+	"log/slog"
 	"os"
 	"strings"
 	"time"
@@ -15,6 +16,7 @@ import (
 	"evening-gown/internal/handler/health"
 	publicHandlers "evening-gown/internal/handler/public"
 	"evening-gown/internal/middleware"
+	"evening-gown/internal/model"
 )
 
 // Dependencies groups handlers required by the router.
@@ -32,6 +34,7 @@ type Dependencies struct {
 		Updates  *publicHandlers.UpdatesHandler
 		Contacts *publicHandlers.ContactsHandler
 		Events   *publicHandlers.EventsHandler
+		Taxonomy *publicHandlers.TaxonomyHandler
 	}
 
 	// Admin backoffice APIs (JWT-protected)
@@ -39,13 +42,25 @@ type Dependencies struct {
 		Auth     *adminHandlers.AuthHandler
 		Assets   *adminHandlers.AssetsHandler
 		Uploads  *adminHandlers.UploadsHandler
+		Media    *adminHandlers.MediaHandler
 		Products *adminHandlers.ProductsHandler
 		Updates  *adminHandlers.UpdatesHandler
 		Contacts *adminHandlers.ContactsHandler
 		Events   *adminHandlers.EventsHandler
 		Settings *adminHandlers.SettingsHandler
+		Users    *adminHandlers.UsersHandler
+		Audit    *adminHandlers.AuditHandler
+		Previews *adminHandlers.PreviewsHandler
+		Trash    *adminHandlers.TrashHandler
+		Taxonomy *adminHandlers.TaxonomyHandler
+		// Forgot/reset password (unauthenticated).
+		PasswordReset *adminHandlers.PasswordResetHandler
 		// Middleware applied to protected admin routes.
 		AuthMiddleware gin.HandlerFunc
+		// Optional audit trail middleware (runs after AuthMiddleware).
+		AuditMiddleware gin.HandlerFunc
+		// Optional throttle applied to the login endpoint (per client IP).
+		LoginRateLimit gin.HandlerFunc
 	}
 }
 
@@ -53,6 +68,16 @@ type Dependencies struct {
 func New(deps Dependencies) *gin.Engine {
 	r := gin.New()
 
+	// Trusted reverse proxies: ClientIP (login rate limit, sessions, audit) only honours
+	// X-Forwarded-For / X-Real-IP when the direct peer is listed here. Nothing is trusted
+	// by default, so clients cannot spoof their address.
+	//
+	// Set TRUSTED_PROXIES to comma-separated IPs/CIDRs of the load balancer in front of the app.
+	if err := r.SetTrustedProxies(splitCommaEnv("TRUSTED_PROXIES")); err != nil {
+		slog.Default().Warn("invalid TRUSTED_PROXIES; trusting no proxies", "err", err)
+		_ = r.SetTrustedProxies(nil)
+	}
+
 	// Request ID (X-Request-Id). Useful for tracing and logs.
 	r.Use(requestid.New())
 	// Attach request-scoped logger (includes request_id).
@@ -108,10 +133,13 @@ func New(deps Dependencies) *gin.Engine {
 			authGroup.POST("/token", deps.Auth.IssueToken)
 		}
 		authGroup.GET("/verify", deps.Auth.VerifyToken)
+
+		// Public keys for verifying admin tokens (RS256/EdDSA only; empty for HS256).
+		r.GET("/.well-known/jwks.json", deps.Auth.JWKS)
 	}
 
 	// Public website APIs (no auth)
-	if deps.Public.Assets != nil || deps.Public.Products != nil || deps.Public.Updates != nil || deps.Public.Contacts != nil || deps.Public.Events != nil {
+	if deps.Public.Assets != nil || deps.Public.Products != nil || deps.Public.Updates != nil || deps.Public.Contacts != nil || deps.Public.Events != nil || deps.Public.Taxonomy != nil {
 		api := r.Group("/api/v1")
 		if deps.Public.Assets != nil {
 			api.GET("/assets/*key", deps.Public.Assets.Get)
@@ -130,65 +158,157 @@ func New(deps Dependencies) *gin.Engine {
 		if deps.Public.Events != nil {
 			api.POST("/events", deps.Public.Events.Create)
 		}
+		if deps.Public.Taxonomy != nil {
+			api.GET("/taxonomy", deps.Public.Taxonomy.List)
+		}
 	}
 
 	// Admin backoffice APIs (JWT-protected)
-	if deps.Admin.Auth != nil || deps.Admin.Products != nil || deps.Admin.Updates != nil || deps.Admin.Contacts != nil || deps.Admin.Events != nil || deps.Admin.Settings != nil {
+	if deps.Admin.Auth != nil || deps.Admin.Products != nil || deps.Admin.Updates != nil || deps.Admin.Contacts != nil || deps.Admin.Events != nil || deps.Admin.Settings != nil || deps.Admin.Users != nil || deps.Admin.Audit != nil || deps.Admin.Previews != nil || deps.Admin.Trash != nil || deps.Admin.Taxonomy != nil || deps.Admin.Media != nil || deps.Admin.PasswordReset != nil {
 		admin := r.Group("/api/v1/admin")
 		if deps.Admin.Auth != nil {
-			// Login is unprotected.
-			admin.POST("/auth/login", deps.Admin.Auth.Login)
+			// Login is unprotected (but rate limited when configured).
+			// With 2FA enabled it takes two steps: password, then TOTP code.
+			if deps.Admin.LoginRateLimit != nil {
+				admin.POST("/auth/login", deps.Admin.LoginRateLimit, deps.Admin.Auth.Login)
+				admin.POST("/auth/login/2fa", deps.Admin.LoginRateLimit, deps.Admin.Auth.LoginTwoFactor)
+			} else {
+				admin.POST("/auth/login", deps.Admin.Auth.Login)
+				admin.POST("/auth/login/2fa", deps.Admin.Auth.LoginTwoFactor)
+			}
 			// Refresh is unprotected (it authenticates via refresh token).
 			admin.POST("/auth/refresh", deps.Admin.Auth.Refresh)
 		}
+		if deps.Admin.PasswordReset != nil {
+			// Unprotected; throttled like login since both are brute-force targets.
+			if deps.Admin.LoginRateLimit != nil {
+				admin.POST("/auth/forgot", deps.Admin.LoginRateLimit, deps.Admin.PasswordReset.Forgot)
+				admin.POST("/auth/reset", deps.Admin.LoginRateLimit, deps.Admin.PasswordReset.Reset)
+			} else {
+				admin.POST("/auth/forgot", deps.Admin.PasswordReset.Forgot)
+				admin.POST("/auth/reset", deps.Admin.PasswordReset.Reset)
+			}
+		}
 		// Protected admin routes.
 		if deps.Admin.AuthMiddleware != nil {
 			admin.Use(deps.Admin.AuthMiddleware)
 		}
+		// Records who changed what on every protected route.
+		if deps.Admin.AuditMiddleware != nil {
+			admin.Use(deps.Admin.AuditMiddleware)
+		}
+		// Per-route permission scopes. Without an auth middleware there is no user to check,
+		// so scopes are skipped as well (same as the rest of the group).
+		scope := func(perm string) gin.HandlerFunc {
+			if deps.Admin.AuthMiddleware == nil {
+				return func(c *gin.Context) { c.Next() }
+			}
+			return middleware.RequirePermission(perm)
+		}
 		if deps.Admin.Assets != nil {
-			admin.GET("/assets/*key", deps.Admin.Assets.Get)
+			admin.GET("/assets/*key", scope(model.PermAssetsRead), deps.Admin.Assets.Get)
 		}
 		if deps.Admin.Uploads != nil {
-			admin.POST("/uploads/images", deps.Admin.Uploads.UploadImage)
+			admin.POST("/uploads/images", scope(model.PermUploadsWrite), deps.Admin.Uploads.UploadImage)
+			admin.POST("/uploads/direct", scope(model.PermUploadsWrite), deps.Admin.Uploads.CreateDirectUpload)
+			admin.POST("/uploads/direct/finalize", scope(model.PermUploadsWrite), deps.Admin.Uploads.FinalizeDirectUpload)
+		}
+		if deps.Admin.Media != nil {
+			admin.GET("/media", scope(model.PermAssetsRead), deps.Admin.Media.List)
 		}
 		if deps.Admin.Settings != nil {
-			admin.GET("/settings/product-detail-template", deps.Admin.Settings.GetProductDetailTemplate)
-			admin.PUT("/settings/product-detail-template", deps.Admin.Settings.PutProductDetailTemplate)
+			admin.GET("/settings/product-detail-template", scope(model.PermSettingsRead), deps.Admin.Settings.GetProductDetailTemplate)
+			admin.PUT("/settings/product-detail-template", scope(model.PermSettingsWrite), deps.Admin.Settings.PutProductDetailTemplate)
 		}
 		if deps.Admin.Auth != nil {
+			// Any authenticated backoffice user may manage their own account.
 			admin.GET("/me", deps.Admin.Auth.Me)
 			admin.PATCH("/me/password", deps.Admin.Auth.ChangePassword)
+			admin.GET("/me/sessions", deps.Admin.Auth.ListSessions)
+			admin.DELETE("/me/sessions", deps.Admin.Auth.RevokeOtherSessions)
+			admin.DELETE("/me/sessions/:id", deps.Admin.Auth.RevokeSession)
+			admin.GET("/me/2fa", deps.Admin.Auth.TwoFactorStatus)
+			admin.POST("/me/2fa", deps.Admin.Auth.BeginTwoFactor)
+			admin.POST("/me/2fa/verify", deps.Admin.Auth.ConfirmTwoFactor)
+			admin.DELETE("/me/2fa", deps.Admin.Auth.DisableTwoFactor)
+		}
+		if deps.Admin.Users != nil {
+			admin.GET("/users", scope(model.PermUsersManage), deps.Admin.Users.List)
+			admin.POST("/users", scope(model.PermUsersManage), deps.Admin.Users.Invite)
+			admin.GET("/users/:id", scope(model.PermUsersManage), deps.Admin.Users.Get)
+			admin.PATCH("/users/:id", scope(model.PermUsersManage), deps.Admin.Users.Update)
+			admin.POST("/users/:id/disable", scope(model.PermUsersManage), deps.Admin.Users.Disable)
+			admin.POST("/users/:id/enable", scope(model.PermUsersManage), deps.Admin.Users.Enable)
+			admin.POST("/users/:id/unlock", scope(model.PermUsersManage), deps.Admin.Users.Unlock)
+			admin.DELETE("/users/:id", scope(model.PermUsersManage), deps.Admin.Users.Delete)
+		}
+		if deps.Admin.Audit != nil {
+			admin.GET("/audit", scope(model.PermAuditRead), deps.Admin.Audit.List)
+			admin.GET("/audit/export", scope(model.PermAuditRead), deps.Admin.Audit.Export)
+		}
+		if deps.Admin.Previews != nil {
+			admin.POST("/products/:id/preview-link", scope(model.PermPreviewsWrite), deps.Admin.Previews.Product)
+			admin.POST("/updates/:id/preview-link", scope(model.PermPreviewsWrite), deps.Admin.Previews.Update)
 		}
 		if deps.Admin.Products != nil {
-			admin.GET("/products", deps.Admin.Products.List)
-			admin.POST("/products", deps.Admin.Products.Create)
-			admin.GET("/products/:id", deps.Admin.Products.Get)
-			admin.PATCH("/products/:id", deps.Admin.Products.Update)
-			admin.POST("/products/:id/publish", deps.Admin.Products.Publish)
-			admin.POST("/products/:id/unpublish", deps.Admin.Products.Unpublish)
-			admin.DELETE("/products/:id", deps.Admin.Products.Delete)
+			admin.GET("/products", scope(model.PermProductsRead), deps.Admin.Products.List)
+			admin.POST("/products", scope(model.PermProductsWrite), deps.Admin.Products.Create)
+			admin.GET("/products/export", scope(model.PermProductsRead), deps.Admin.Products.Export)
+			admin.POST("/products/import", scope(model.PermProductsWrite), deps.Admin.Products.Import)
+			admin.POST("/products/bulk", scope(model.PermProductsWrite), deps.Admin.Products.Bulk)
+			admin.GET("/products/:id", scope(model.PermProductsRead), deps.Admin.Products.Get)
+			admin.PATCH("/products/:id", scope(model.PermProductsWrite), deps.Admin.Products.Update)
+			admin.POST("/products/:id/publish", scope(model.PermProductsWrite), deps.Admin.Products.Publish)
+			admin.POST("/products/:id/unpublish", scope(model.PermProductsWrite), deps.Admin.Products.Unpublish)
+			admin.PUT("/products/:id/schedule", scope(model.PermProductsWrite), deps.Admin.Products.Schedule)
+			admin.GET("/products/:id/variants", scope(model.PermProductsRead), deps.Admin.Products.ListVariants)
+			admin.POST("/products/:id/variants", scope(model.PermProductsWrite), deps.Admin.Products.CreateVariant)
+			admin.POST("/products/:id/variants/sync", scope(model.PermProductsWrite), deps.Admin.Products.SyncVariants)
+			admin.PATCH("/products/:id/variants/:variantId", scope(model.PermProductsWrite), deps.Admin.Products.UpdateVariant)
+			admin.DELETE("/products/:id/variants/:variantId", scope(model.PermProductsWrite), deps.Admin.Products.DeleteVariant)
+			admin.GET("/products/:id/draft", scope(model.PermProductsRead), deps.Admin.Products.GetDraft)
+			admin.DELETE("/products/:id/draft", scope(model.PermProductsWrite), deps.Admin.Products.DiscardDraft)
+			admin.POST("/products/:id/publish-changes", scope(model.PermProductsWrite), deps.Admin.Products.PublishChanges)
+			admin.GET("/products/:id/revisions", scope(model.PermProductsRead), deps.Admin.Products.ListRevisions)
+			admin.GET("/products/:id/revisions/:rev", scope(model.PermProductsRead), deps.Admin.Products.GetRevision)
+			admin.GET("/products/:id/revisions/:rev/diff", scope(model.PermProductsRead), deps.Admin.Products.DiffRevision)
+			admin.POST("/products/:id/revisions/:rev/restore", scope(model.PermProductsWrite), deps.Admin.Products.RestoreRevision)
+			admin.DELETE("/products/:id", scope(model.PermProductsWrite), deps.Admin.Products.Delete)
+		}
+		if deps.Admin.Taxonomy != nil {
+			admin.GET("/taxonomy", scope(model.PermProductsRead), deps.Admin.Taxonomy.List)
+			admin.POST("/taxonomy", scope(model.PermProductsWrite), deps.Admin.Taxonomy.Create)
+			admin.PATCH("/taxonomy/:id", scope(model.PermProductsWrite), deps.Admin.Taxonomy.Update)
+			admin.DELETE("/taxonomy/:id", scope(model.PermProductsWrite), deps.Admin.Taxonomy.Delete)
 		}
 		if deps.Admin.Updates != nil {
-			admin.GET("/updates", deps.Admin.Updates.List)
-			admin.POST("/updates", deps.Admin.Updates.Create)
-			admin.GET("/updates/:id", deps.Admin.Updates.Get)
-			admin.PATCH("/updates/:id", deps.Admin.Updates.Update)
-			admin.POST("/updates/:id/publish", deps.Admin.Updates.Publish)
-			admin.POST("/updates/:id/unpublish", deps.Admin.Updates.Unpublish)
-			admin.DELETE("/updates/:id", deps.Admin.Updates.Delete)
+			admin.GET("/updates", scope(model.PermUpdatesRead), deps.Admin.Updates.List)
+			admin.POST("/updates", scope(model.PermUpdatesWrite), deps.Admin.Updates.Create)
+			admin.GET("/updates/:id", scope(model.PermUpdatesRead), deps.Admin.Updates.Get)
+			admin.PATCH("/updates/:id", scope(model.PermUpdatesWrite), deps.Admin.Updates.Update)
+			admin.POST("/updates/:id/publish", scope(model.PermUpdatesWrite), deps.Admin.Updates.Publish)
+			admin.POST("/updates/:id/unpublish", scope(model.PermUpdatesWrite), deps.Admin.Updates.Unpublish)
+			admin.PUT("/updates/:id/schedule", scope(model.PermUpdatesWrite), deps.Admin.Updates.Schedule)
+			admin.DELETE("/updates/:id", scope(model.PermUpdatesWrite), deps.Admin.Updates.Delete)
 		}
 		if deps.Admin.Contacts != nil {
-			admin.GET("/contacts", deps.Admin.Contacts.List)
-			admin.GET("/contacts/unread-count", deps.Admin.Contacts.UnreadCount)
-			admin.GET("/contacts/:id", deps.Admin.Contacts.Get)
-			admin.PATCH("/contacts/:id", deps.Admin.Contacts.Update)
-			admin.DELETE("/contacts/:id", deps.Admin.Contacts.Delete)
+			admin.GET("/contacts", scope(model.PermContactsRead), deps.Admin.Contacts.List)
+			admin.GET("/contacts/unread-count", scope(model.PermContactsRead), deps.Admin.Contacts.UnreadCount)
+			admin.GET("/contacts/:id", scope(model.PermContactsRead), deps.Admin.Contacts.Get)
+			admin.PATCH("/contacts/:id", scope(model.PermContactsWrite), deps.Admin.Contacts.Update)
+			admin.DELETE("/contacts/:id", scope(model.PermContactsWrite), deps.Admin.Contacts.Delete)
 		}
 		if deps.Admin.Events != nil {
-			admin.GET("/events", deps.Admin.Events.List)
-			admin.GET("/events/metrics", deps.Admin.Events.Metrics)
-			admin.GET("/events/:id", deps.Admin.Events.Get)
-			admin.DELETE("/events/:id", deps.Admin.Events.Delete)
+			admin.GET("/events", scope(model.PermEventsRead), deps.Admin.Events.List)
+			admin.GET("/events/metrics", scope(model.PermEventsRead), deps.Admin.Events.Metrics)
+			admin.GET("/events/:id", scope(model.PermEventsRead), deps.Admin.Events.Get)
+			admin.DELETE("/events/:id", scope(model.PermEventsWrite), deps.Admin.Events.Delete)
+		}
+		if deps.Admin.Trash != nil {
+			// Per-type permissions are checked by the handler.
+			admin.GET("/trash", deps.Admin.Trash.List)
+			admin.POST("/trash/:type/:id/restore", deps.Admin.Trash.Restore)
+			admin.DELETE("/trash/:type/:id", deps.Admin.Trash.Purge)
 		}
 	}
 
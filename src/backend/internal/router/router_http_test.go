@@ -2,13 +2,19 @@ package router
 
 import (
 	"bytes"
+	"context"
+	"encoding/csv"
 	"encoding/json"
+	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -20,9 +26,15 @@ import (
 	authHandlerPkg "evening-gown/internal/handler/auth"
 	"evening-gown/internal/handler/health"
 	publicHandlers "evening-gown/internal/handler/public"
+	"evening-gown/internal/mail"
 	"evening-gown/internal/middleware"
+	"evening-gown/internal/model"
+	"evening-gown/internal/ratelimit"
+	"evening-gown/internal/security"
+	"evening-gown/internal/trash"
 
 	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
@@ -135,6 +147,18 @@ func TestRouter_AuthDevTokenIssuer_IsGated(t *testing.T) {
 			t.Fatalf("expected claims.sub=123, got %#v", claims)
 		}
 	}
+
+	// JWKS is always routed with the auth handler; HS256 secrets are never published.
+	{
+		r := New(Dependencies{Auth: authHandler})
+		resp := doRequest(t, r, http.MethodGet, "/.well-known/jwks.json", nil, nil)
+		if resp.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+		}
+		if strings.TrimSpace(resp.Body.String()) != `{"keys":[]}` {
+			t.Fatalf("expected empty key set, got %s", resp.Body.String())
+		}
+	}
 }
 
 func TestRouter_CORS_DefaultAndRestricted(t *testing.T) {
@@ -539,7 +563,7 @@ func TestRouter_PublicAndAdmin_APIs_EndToEnd(t *testing.T) {
 	}
 
 	// Admin: change password should force logout (old token becomes invalid).
-	newAdminPassword := "passw0rd456" // >= 10 chars
+	newAdminPassword := "velvet-hem-456" // satisfies the default password policy
 	{
 		resp := doRequest(t, r, http.MethodPatch, "/api/v1/admin/me/password", []byte(`{"oldPassword":"`+adminPassword+`","newPassword":"`+newAdminPassword+`"}`), withAuth(jsonHeaders(), adminToken))
 		if resp.Code != http.StatusOK {
@@ -572,15 +596,2542 @@ func TestRouter_PublicAndAdmin_APIs_EndToEnd(t *testing.T) {
 	}
 }
 
-func openTestDB(t *testing.T) *gorm.DB {
-	t.Helper()
+func TestRouter_AdminUsers_RoleScopes(t *testing.T) {
+	db := openTestDB(t)
 
-	db, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
-	if err != nil {
-		t.Fatalf("open sqlite: %v", err)
+	publicCache := cache.NewPublicCache(nil)
+
+	r := newAdminRouter(t, db, func(deps *Dependencies) {
+		deps.Admin.Products = adminHandlers.NewProductsHandler(db, publicCache)
+		deps.Admin.Contacts = adminHandlers.NewContactsHandler(db)
+		deps.Admin.Events = adminHandlers.NewEventsHandler(db)
+		deps.Admin.Users = adminHandlers.NewUsersHandler(db)
+	})
+
+	adminToken := loginAdmin(t, r, testAdminEmail, testAdminPassword)
+
+	// Invite a merchandiser without a password: a temporary one is returned once.
+	var merchID uint
+	var merchPassword string
+	{
+		resp := doRequest(t, r, http.MethodPost, "/api/v1/admin/users", []byte(`{"email":"Merch@Example.com","role":"merchandiser"}`), withAuth(jsonHeaders(), adminToken))
+		if resp.Code != http.StatusCreated {
+			t.Fatalf("expected %d, got %d: %s", http.StatusCreated, resp.Code, resp.Body.String())
+		}
+		var got map[string]any
+		mustJSON(t, resp.Body.Bytes(), &got)
+		user, _ := got["user"].(map[string]any)
+		merchID = mustUintFromJSONNumber(t, user["id"])
+		merchPassword, _ = got["temporaryPassword"].(string)
+		if merchID == 0 || strings.TrimSpace(merchPassword) == "" {
+			t.Fatalf("expected user id and temporary password, got %s", resp.Body.String())
+		}
+		if user["email"] != "merch@example.com" {
+			t.Fatalf("expected normalized email, got %v", user["email"])
+		}
 	}
-	if err := bootstrap.AutoMigrate(db); err != nil {
-		t.Fatalf("auto migrate: %v", err)
+
+	// Duplicate email and unknown roles are rejected.
+	{
+		resp := doRequest(t, r, http.MethodPost, "/api/v1/admin/users", []byte(`{"email":"merch@example.com","role":"sales"}`), withAuth(jsonHeaders(), adminToken))
+		if resp.Code != http.StatusConflict {
+			t.Fatalf("expected %d, got %d: %s", http.StatusConflict, resp.Code, resp.Body.String())
+		}
+		resp = doRequest(t, r, http.MethodPost, "/api/v1/admin/users", []byte(`{"email":"x@example.com","role":"root"}`), withAuth(jsonHeaders(), adminToken))
+		if resp.Code != http.StatusBadRequest {
+			t.Fatalf("expected %d, got %d: %s", http.StatusBadRequest, resp.Code, resp.Body.String())
+		}
+	}
+
+	merchToken := loginAdmin(t, r, "merch@example.com", merchPassword)
+
+	// Merchandiser: products allowed, contacts/events/users forbidden.
+	{
+		resp := doRequest(t, r, http.MethodGet, "/api/v1/admin/products", nil, withAuth(nil, merchToken))
+		if resp.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+		}
+		for _, path := range []string{"/api/v1/admin/contacts", "/api/v1/admin/events", "/api/v1/admin/users"} {
+			resp := doRequest(t, r, http.MethodGet, path, nil, withAuth(nil, merchToken))
+			if resp.Code != http.StatusForbidden {
+				t.Fatalf("%s: expected %d, got %d: %s", path, http.StatusForbidden, resp.Code, resp.Body.String())
+			}
+		}
+	}
+
+	// Me exposes role permissions.
+	{
+		resp := doRequest(t, r, http.MethodGet, "/api/v1/admin/me", nil, withAuth(nil, merchToken))
+		if resp.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+		}
+		var got map[string]any
+		mustJSON(t, resp.Body.Bytes(), &got)
+		if got["role"] != "merchandiser" {
+			t.Fatalf("expected role merchandiser, got %v", got["role"])
+		}
+		perms, _ := got["permissions"].([]any)
+		if len(perms) == 0 {
+			t.Fatalf("expected permissions")
+		}
+	}
+
+	// Admin cannot disable themselves (or the last active admin).
+	{
+		resp := doRequest(t, r, http.MethodPost, "/api/v1/admin/users/1/disable", nil, withAuth(nil, adminToken))
+		if resp.Code != http.StatusBadRequest {
+			t.Fatalf("expected %d, got %d: %s", http.StatusBadRequest, resp.Code, resp.Body.String())
+		}
+	}
+
+	// Disabling the merchandiser revokes access immediately.
+	merchPath := "/api/v1/admin/users/" + strconv.FormatUint(uint64(merchID), 10)
+	{
+		resp := doRequest(t, r, http.MethodPost, merchPath+"/disable", nil, withAuth(nil, adminToken))
+		if resp.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+		}
+		resp = doRequest(t, r, http.MethodGet, "/api/v1/admin/products", nil, withAuth(nil, merchToken))
+		if resp.Code != http.StatusForbidden {
+			t.Fatalf("expected %d, got %d: %s", http.StatusForbidden, resp.Code, resp.Body.String())
+		}
+	}
+
+	// Delete removes the account.
+	{
+		resp := doRequest(t, r, http.MethodDelete, merchPath, nil, withAuth(nil, adminToken))
+		if resp.Code != http.StatusNoContent {
+			t.Fatalf("expected %d, got %d: %s", http.StatusNoContent, resp.Code, resp.Body.String())
+		}
+		resp = doRequest(t, r, http.MethodGet, merchPath, nil, withAuth(nil, adminToken))
+		if resp.Code != http.StatusNotFound {
+			t.Fatalf("expected %d, got %d: %s", http.StatusNotFound, resp.Code, resp.Body.String())
+		}
+
+		// The address is free again for a new invite.
+		resp = doRequest(t, r, http.MethodPost, "/api/v1/admin/users", []byte(`{"email":"merch@example.com","role":"sales"}`), withAuth(jsonHeaders(), adminToken))
+		if resp.Code != http.StatusCreated {
+			t.Fatalf("expected %d, got %d: %s", http.StatusCreated, resp.Code, resp.Body.String())
+		}
+	}
+}
+
+func TestRouter_AdminLogin_LockoutAndRateLimit(t *testing.T) {
+	db := openTestDB(t)
+
+	r := newAdminRouter(t, db, func(deps *Dependencies) {
+		deps.Admin.Auth = adminHandlers.NewAuthHandlerWithOptions(db, testJWTService(t), adminHandlers.AuthHandlerOptions{
+			Lockout: security.LockoutPolicy{Threshold: 3, Duration: time.Minute, MaxDuration: time.Hour},
+		})
+		deps.Admin.Users = adminHandlers.NewUsersHandler(db)
+	})
+
+	adminToken := loginAdmin(t, r, testAdminEmail, testAdminPassword)
+
+	resp := doRequest(t, r, http.MethodPost, "/api/v1/admin/users", []byte(`{"email":"sales@example.com","role":"sales"}`), withAuth(jsonHeaders(), adminToken))
+	if resp.Code != http.StatusCreated {
+		t.Fatalf("expected %d, got %d: %s", http.StatusCreated, resp.Code, resp.Body.String())
+	}
+	var invited map[string]any
+	mustJSON(t, resp.Body.Bytes(), &invited)
+	salesPassword, _ := invited["temporaryPassword"].(string)
+	salesID := mustUintFromJSONNumber(t, invited["user"].(map[string]any)["id"])
+
+	badLogin := []byte(`{"email":"sales@example.com","password":"wrong-password"}`)
+
+	// Failures below the threshold stay 401; reaching it locks the account.
+	for i := 1; i <= 3; i++ {
+		resp := doRequest(t, r, http.MethodPost, "/api/v1/admin/auth/login", badLogin, jsonHeaders())
+		want := http.StatusUnauthorized
+		if i == 3 {
+			want = http.StatusLocked
+		}
+		if resp.Code != want {
+			t.Fatalf("attempt %d: expected %d, got %d: %s", i, want, resp.Code, resp.Body.String())
+		}
+	}
+
+	// Unknown emails follow the same 401 … 423 sequence, so lockouts do not reveal accounts.
+	for i := 1; i <= 3; i++ {
+		resp := doRequest(t, r, http.MethodPost, "/api/v1/admin/auth/login", []byte(`{"email":"ghost@example.com","password":"wrong-password"}`), jsonHeaders())
+		want := http.StatusUnauthorized
+		if i == 3 {
+			want = http.StatusLocked
+		}
+		if resp.Code != want {
+			t.Fatalf("unknown attempt %d: expected %d, got %d: %s", i, want, resp.Code, resp.Body.String())
+		}
+		if i == 3 && resp.Header().Get("Retry-After") == "" {
+			t.Fatalf("expected Retry-After header")
+		}
+	}
+
+	// Even the correct password is rejected while locked.
+	{
+		body, _ := json.Marshal(map[string]string{"email": "sales@example.com", "password": salesPassword})
+		resp := doRequest(t, r, http.MethodPost, "/api/v1/admin/auth/login", body, jsonHeaders())
+		if resp.Code != http.StatusLocked {
+			t.Fatalf("expected %d, got %d: %s", http.StatusLocked, resp.Code, resp.Body.String())
+		}
+		if resp.Header().Get("Retry-After") == "" {
+			t.Fatalf("expected Retry-After header")
+		}
+		var got map[string]any
+		mustJSON(t, resp.Body.Bytes(), &got)
+		if got["code"] != "account_locked" {
+			t.Fatalf("expected account_locked, got %v", got["code"])
+		}
+	}
+
+	// Admin unlock restores access.
+	{
+		resp := doRequest(t, r, http.MethodPost, "/api/v1/admin/users/"+strconv.FormatUint(uint64(salesID), 10)+"/unlock", nil, withAuth(nil, adminToken))
+		if resp.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+		}
+		var got map[string]any
+		mustJSON(t, resp.Body.Bytes(), &got)
+		if got["lockedUntil"] != nil || mustUintFromJSONNumber(t, got["failedLoginCount"]) != 0 {
+			t.Fatalf("expected lock cleared, got %s", resp.Body.String())
+		}
+		loginAdmin(t, r, "sales@example.com", salesPassword)
+	}
+
+	// Per-IP rate limit on the login route. X-Forwarded-For from an untrusted peer is
+	// ignored, so rotating it does not reset the budget.
+	{
+		limited := newAdminRouter(t, db, func(deps *Dependencies) {
+			deps.Admin.LoginRateLimit = middleware.RateLimit(ratelimit.NewMemory(2, time.Minute), "admin_login")
+		})
+		spoofed := func(i int) map[string]string {
+			h := jsonHeaders()
+			h["X-Forwarded-For"] = fmt.Sprintf("203.0.113.%d", i+1)
+			return h
+		}
+		for i := 0; i < 2; i++ {
+			resp := doRequest(t, limited, http.MethodPost, "/api/v1/admin/auth/login", []byte(`{"email":"nobody@example.com","password":"x"}`), spoofed(i))
+			if resp.Code != http.StatusUnauthorized {
+				t.Fatalf("expected %d, got %d: %s", http.StatusUnauthorized, resp.Code, resp.Body.String())
+			}
+		}
+		resp := doRequest(t, limited, http.MethodPost, "/api/v1/admin/auth/login", []byte(`{"email":"nobody@example.com","password":"x"}`), spoofed(2))
+		if resp.Code != http.StatusTooManyRequests {
+			t.Fatalf("expected %d, got %d: %s", http.StatusTooManyRequests, resp.Code, resp.Body.String())
+		}
+		if resp.Header().Get("Retry-After") == "" {
+			t.Fatalf("expected Retry-After header")
+		}
+	}
+}
+
+func TestRouter_AdminSessions_RotationReuseAndRevoke(t *testing.T) {
+	db := openTestDB(t)
+
+	r := newAdminRouter(t, db, nil)
+
+	login := func(device string) (string, string) {
+		t.Helper()
+		body, _ := json.Marshal(map[string]string{"email": testAdminEmail, "password": testAdminPassword})
+		h := jsonHeaders()
+		h["User-Agent"] = device
+		resp := doRequest(t, r, http.MethodPost, "/api/v1/admin/auth/login", body, h)
+		if resp.Code != http.StatusOK {
+			t.Fatalf("login: expected %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+		}
+		var got map[string]any
+		mustJSON(t, resp.Body.Bytes(), &got)
+		return got["token"].(string), got["refresh_token"].(string)
+	}
+	refresh := func(rt string) *httptest.ResponseRecorder {
+		t.Helper()
+		body, _ := json.Marshal(map[string]string{"refresh_token": rt})
+		return doRequest(t, r, http.MethodPost, "/api/v1/admin/auth/refresh", body, jsonHeaders())
+	}
+	expectCode := func(resp *httptest.ResponseRecorder, status int, code string) {
+		t.Helper()
+		if resp.Code != status {
+			t.Fatalf("expected %d, got %d: %s", status, resp.Code, resp.Body.String())
+		}
+		if code == "" {
+			return
+		}
+		var got map[string]any
+		mustJSON(t, resp.Body.Bytes(), &got)
+		if got["code"] != code {
+			t.Fatalf("expected code %q, got %v", code, got["code"])
+		}
+	}
+
+	_, refreshA := login("device-a")
+	accessB, refreshB := login("device-b")
+
+	// A second login no longer invalidates the first device.
+	respA := refresh(refreshA)
+	expectCode(respA, http.StatusOK, "")
+	var rotatedA map[string]any
+	mustJSON(t, respA.Body.Bytes(), &rotatedA)
+	accessA2 := rotatedA["token"].(string)
+	refreshA2 := rotatedA["refresh_token"].(string)
+	expectCode(refresh(refreshB), http.StatusOK, "")
+
+	// Device list marks the calling session.
+	{
+		resp := doRequest(t, r, http.MethodGet, "/api/v1/admin/me/sessions", nil, withAuth(nil, accessA2))
+		expectCode(resp, http.StatusOK, "")
+		var got struct {
+			Total int `json:"total"`
+			Items []struct {
+				UserAgent string `json:"userAgent"`
+				Current   bool   `json:"current"`
+			} `json:"items"`
+		}
+		mustJSON(t, resp.Body.Bytes(), &got)
+		if got.Total != 2 {
+			t.Fatalf("expected 2 sessions, got %s", resp.Body.String())
+		}
+		current := 0
+		for _, it := range got.Items {
+			if it.Current {
+				current++
+				if it.UserAgent != "device-a" {
+					t.Fatalf("expected current session device-a, got %q", it.UserAgent)
+				}
+			}
+		}
+		if current != 1 {
+			t.Fatalf("expected exactly one current session, got %s", resp.Body.String())
+		}
+	}
+
+	// Replaying A's first refresh token revokes the whole family.
+	expectCode(refresh(refreshA), http.StatusUnauthorized, "refresh_token_reused")
+	expectCode(refresh(refreshA2), http.StatusUnauthorized, "session_revoked")
+	expectCode(doRequest(t, r, http.MethodGet, "/api/v1/admin/me", nil, withAuth(nil, accessA2)), http.StatusUnauthorized, "")
+
+	// Device B survives; sign out every other device from B.
+	accessC, _ := login("device-c")
+	var sessionB string
+	{
+		// B's access token predates its rotation but stays valid within the session.
+		resp := doRequest(t, r, http.MethodGet, "/api/v1/admin/me/sessions", nil, withAuth(nil, accessB))
+		expectCode(resp, http.StatusOK, "")
+		var got struct {
+			Total int `json:"total"`
+			Items []struct {
+				ID      string `json:"id"`
+				Current bool   `json:"current"`
+			} `json:"items"`
+		}
+		mustJSON(t, resp.Body.Bytes(), &got)
+		if got.Total != 2 {
+			t.Fatalf("expected 2 sessions, got %s", resp.Body.String())
+		}
+		for _, it := range got.Items {
+			if it.Current {
+				sessionB = it.ID
+			}
+		}
+	}
+	{
+		resp := doRequest(t, r, http.MethodDelete, "/api/v1/admin/me/sessions", nil, withAuth(nil, accessB))
+		expectCode(resp, http.StatusOK, "")
+		var got map[string]any
+		mustJSON(t, resp.Body.Bytes(), &got)
+		if mustUintFromJSONNumber(t, got["revoked"]) != 1 {
+			t.Fatalf("expected 1 revoked session, got %s", resp.Body.String())
+		}
+		expectCode(doRequest(t, r, http.MethodGet, "/api/v1/admin/me", nil, withAuth(nil, accessC)), http.StatusUnauthorized, "")
+		expectCode(doRequest(t, r, http.MethodGet, "/api/v1/admin/me", nil, withAuth(nil, accessB)), http.StatusOK, "")
+	}
+
+	// Revoking one's own session is a logout.
+	expectCode(doRequest(t, r, http.MethodDelete, "/api/v1/admin/me/sessions/unknown", nil, withAuth(nil, accessB)), http.StatusNotFound, "")
+	expectCode(doRequest(t, r, http.MethodDelete, "/api/v1/admin/me/sessions/"+sessionB, nil, withAuth(nil, accessB)), http.StatusNoContent, "")
+	expectCode(doRequest(t, r, http.MethodGet, "/api/v1/admin/me", nil, withAuth(nil, accessB)), http.StatusUnauthorized, "")
+}
+
+func TestRouter_AdminTwoFactor_EnrollAndLogin(t *testing.T) {
+	db := openTestDB(t)
+
+	r := newAdminRouter(t, db, nil)
+
+	token := loginAdmin(t, r, testAdminEmail, testAdminPassword)
+
+	// Enroll: password re-check, pending secret, then confirm with a code.
+	var secret string
+	{
+		resp := doRequest(t, r, http.MethodPost, "/api/v1/admin/me/2fa", []byte(`{"password":"wrong-password"}`), withAuth(jsonHeaders(), token))
+		if resp.Code != http.StatusBadRequest {
+			t.Fatalf("expected %d, got %d: %s", http.StatusBadRequest, resp.Code, resp.Body.String())
+		}
+		resp = doRequest(t, r, http.MethodPost, "/api/v1/admin/me/2fa", []byte(`{"password":"`+testAdminPassword+`"}`), withAuth(jsonHeaders(), token))
+		if resp.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+		}
+		var got map[string]any
+		mustJSON(t, resp.Body.Bytes(), &got)
+		secret, _ = got["secret"].(string)
+		uri, _ := got["otpauth_uri"].(string)
+		if secret == "" || !strings.HasPrefix(uri, "otpauth://totp/") {
+			t.Fatalf("unexpected enrollment response: %s", resp.Body.String())
+		}
+	}
+	var recoveryCodes []string
+	{
+		code, _ := security.TOTPCode(secret, time.Now())
+		resp := doRequest(t, r, http.MethodPost, "/api/v1/admin/me/2fa/verify", []byte(`{"code":"`+code+`"}`), withAuth(jsonHeaders(), token))
+		if resp.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+		}
+		var got struct {
+			Enabled       bool     `json:"enabled"`
+			RecoveryCodes []string `json:"recovery_codes"`
+		}
+		mustJSON(t, resp.Body.Bytes(), &got)
+		if !got.Enabled || len(got.RecoveryCodes) != 10 {
+			t.Fatalf("unexpected verify response: %s", resp.Body.String())
+		}
+		recoveryCodes = got.RecoveryCodes
+	}
+
+	passwordStep := func() string {
+		t.Helper()
+		body, _ := json.Marshal(map[string]string{"email": testAdminEmail, "password": testAdminPassword})
+		resp := doRequest(t, r, http.MethodPost, "/api/v1/admin/auth/login", body, jsonHeaders())
+		if resp.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+		}
+		var got map[string]any
+		mustJSON(t, resp.Body.Bytes(), &got)
+		if got["mfa_required"] != true || got["token"] != nil {
+			t.Fatalf("expected mfa challenge, got %s", resp.Body.String())
+		}
+		return got["mfa_token"].(string)
+	}
+	secondStep := func(mfaToken, code string) *httptest.ResponseRecorder {
+		t.Helper()
+		body, _ := json.Marshal(map[string]string{"mfa_token": mfaToken, "code": code})
+		return doRequest(t, r, http.MethodPost, "/api/v1/admin/auth/login/2fa", body, jsonHeaders())
+	}
+
+	// The pending token is not an access token.
+	mfaToken := passwordStep()
+	if resp := doRequest(t, r, http.MethodGet, "/api/v1/admin/me", nil, withAuth(nil, mfaToken)); resp.Code != http.StatusUnauthorized {
+		t.Fatalf("expected %d, got %d: %s", http.StatusUnauthorized, resp.Code, resp.Body.String())
+	}
+	if resp := secondStep(mfaToken, "not-a-code"); resp.Code != http.StatusUnauthorized {
+		t.Fatalf("expected %d, got %d: %s", http.StatusUnauthorized, resp.Code, resp.Body.String())
+	}
+
+	// A fresh TOTP code completes the login exactly once (the enrollment step is already spent).
+	nextCode, _ := security.TOTPCode(secret, time.Now().Add(security.TOTPPeriod))
+	if resp := secondStep(mfaToken, nextCode); resp.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+	}
+	if resp := secondStep(passwordStep(), nextCode); resp.Code != http.StatusUnauthorized {
+		t.Fatalf("expected replay to be rejected, got %d: %s", resp.Code, resp.Body.String())
+	}
+
+	// Recovery codes are single-use (dash and case are optional).
+	typed := strings.ToUpper(strings.ReplaceAll(recoveryCodes[0], "-", ""))
+	if resp := secondStep(passwordStep(), typed); resp.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+	}
+	if resp := secondStep(passwordStep(), recoveryCodes[0]); resp.Code != http.StatusUnauthorized {
+		t.Fatalf("expected %d, got %d: %s", http.StatusUnauthorized, resp.Code, resp.Body.String())
+	}
+
+	{
+		resp := doRequest(t, r, http.MethodGet, "/api/v1/admin/me/2fa", nil, withAuth(nil, token))
+		var got map[string]any
+		mustJSON(t, resp.Body.Bytes(), &got)
+		if got["enabled"] != true || mustUintFromJSONNumber(t, got["recoveryCodesRemaining"]) != 9 {
+			t.Fatalf("unexpected status: %s", resp.Body.String())
+		}
+	}
+
+	// Disabling requires password + code; afterwards login is single-step again.
+	{
+		body, _ := json.Marshal(map[string]string{"password": testAdminPassword, "code": recoveryCodes[1]})
+		resp := doRequest(t, r, http.MethodDelete, "/api/v1/admin/me/2fa", body, withAuth(jsonHeaders(), token))
+		if resp.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+		}
+		loginAdmin(t, r, testAdminEmail, testAdminPassword)
+	}
+}
+
+func TestRouter_AdminTwoFactor_EncryptsSecrets(t *testing.T) {
+	db := openTestDB(t)
+
+	r := newAdminRouter(t, db, func(deps *Dependencies) {
+		deps.Admin.Auth = adminHandlers.NewAuthHandlerWithOptions(db, testJWTService(t), adminHandlers.AuthHandlerOptions{
+			TOTPKey: "totp-test-key",
+		})
+	})
+
+	token := loginAdmin(t, r, testAdminEmail, testAdminPassword)
+	stored := func() model.User {
+		t.Helper()
+		var u model.User
+		if err := db.Where("email = ?", testAdminEmail).First(&u).Error; err != nil {
+			t.Fatalf("load admin: %v", err)
+		}
+		return u
+	}
+
+	var secret string
+	{
+		resp := doRequest(t, r, http.MethodPost, "/api/v1/admin/me/2fa", []byte(`{"password":"`+testAdminPassword+`"}`), withAuth(jsonHeaders(), token))
+		if resp.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+		}
+		var got map[string]any
+		mustJSON(t, resp.Body.Bytes(), &got)
+		secret, _ = got["secret"].(string)
+	}
+	if u := stored(); !security.IsSealed(u.TOTPPendingSecret) || strings.Contains(u.TOTPPendingSecret, secret) {
+		t.Fatalf("expected the pending secret encrypted, got %q", u.TOTPPendingSecret)
+	}
+	code, _ := security.TOTPCode(secret, time.Now())
+	if resp := doRequest(t, r, http.MethodPost, "/api/v1/admin/me/2fa/verify", []byte(`{"code":"`+code+`"}`), withAuth(jsonHeaders(), token)); resp.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+	}
+	if u := stored(); !security.IsSealed(u.TOTPSecret) || strings.Contains(u.TOTPSecret, secret) {
+		t.Fatalf("expected the secret encrypted, got %q", u.TOTPSecret)
+	}
+
+	loginWithCode := func(at time.Time) int {
+		t.Helper()
+		body, _ := json.Marshal(map[string]string{"email": testAdminEmail, "password": testAdminPassword})
+		resp := doRequest(t, r, http.MethodPost, "/api/v1/admin/auth/login", body, jsonHeaders())
+		var challenge map[string]any
+		mustJSON(t, resp.Body.Bytes(), &challenge)
+		mfaToken, _ := challenge["mfa_token"].(string)
+		code, _ := security.TOTPCode(secret, at)
+		body, _ = json.Marshal(map[string]string{"mfa_token": mfaToken, "code": code})
+		return doRequest(t, r, http.MethodPost, "/api/v1/admin/auth/login/2fa", body, jsonHeaders()).Code
+	}
+	if got := loginWithCode(time.Now().Add(security.TOTPPeriod)); got != http.StatusOK {
+		t.Fatalf("expected %d with the encrypted secret, got %d", http.StatusOK, got)
+	}
+
+	// Secrets stored before the key was configured keep working and get encrypted on use.
+	if err := db.Model(&model.User{}).Where("email = ?", testAdminEmail).
+		Updates(map[string]any{"totp_secret": secret, "totp_last_used_step": 0}).Error; err != nil {
+		t.Fatalf("store plaintext secret: %v", err)
+	}
+	if got := loginWithCode(time.Now()); got != http.StatusOK {
+		t.Fatalf("expected %d with a plaintext secret, got %d", http.StatusOK, got)
+	}
+	if u := stored(); !security.IsSealed(u.TOTPSecret) {
+		t.Fatalf("expected the plaintext secret encrypted after use, got %q", u.TOTPSecret)
+	}
+}
+
+func TestRouter_AdminPasswordReset(t *testing.T) {
+	db := openTestDB(t)
+
+	mailer := &captureMailer{}
+
+	r := newAdminRouter(t, db, func(deps *Dependencies) {
+		deps.Admin.PasswordReset = adminHandlers.NewPasswordResetHandler(db, mailer, adminHandlers.PasswordResetOptions{
+			ResetURL: "https://admin.example.com/reset?lang=en",
+			TTL:      time.Hour,
+		})
+	})
+
+	oldToken := loginAdmin(t, r, testAdminEmail, testAdminPassword)
+
+	// Unknown accounts get the same answer and no email.
+	resp := doRequest(t, r, http.MethodPost, "/api/v1/admin/auth/forgot", []byte(`{"email":"nobody@example.com"}`), jsonHeaders())
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected silent 200, got %d: %s", resp.Code, resp.Body.String())
+	}
+
+	// Mail is delivered in the background; only the known account gets one.
+	resp = doRequest(t, r, http.MethodPost, "/api/v1/admin/auth/forgot", []byte(`{"email":"ADMIN@example.com"}`), jsonHeaders())
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+	}
+	sent := mailer.wait(t, 1)
+	if len(sent) != 1 {
+		t.Fatalf("expected one mail, got %d", len(sent))
+	}
+	if got := sent[0].To; len(got) != 1 || got[0] != testAdminEmail {
+		t.Fatalf("unexpected recipients: %v", got)
+	}
+	m := regexp.MustCompile(`https://admin\.example\.com/reset\?lang=en&token=([A-Za-z0-9_-]+)`).FindStringSubmatch(sent[0].Text)
+	if m == nil {
+		t.Fatalf("reset link not found in mail: %s", sent[0].Text)
+	}
+	resetToken := m[1]
+
+	newPassword := "n3w-passw0rd-456"
+	reset := func(token, password string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]string{"token": token, "newPassword": password})
+		return doRequest(t, r, http.MethodPost, "/api/v1/admin/auth/reset", body, jsonHeaders())
+	}
+
+	if resp := reset(resetToken, "short"); resp.Code != http.StatusBadRequest {
+		t.Fatalf("expected weak password rejected, got %d: %s", resp.Code, resp.Body.String())
+	}
+	if resp := reset("bogus", newPassword); resp.Code != http.StatusBadRequest {
+		t.Fatalf("expected bogus token rejected, got %d: %s", resp.Code, resp.Body.String())
+	}
+	if resp := reset(resetToken, newPassword); resp.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+	}
+
+	// Tokens are single-use, existing JWTs are invalidated, the new password works.
+	{
+		resp := reset(resetToken, "another-passw0rd-789")
+		if resp.Code != http.StatusBadRequest {
+			t.Fatalf("expected reused token rejected, got %d: %s", resp.Code, resp.Body.String())
+		}
+		var got map[string]any
+		mustJSON(t, resp.Body.Bytes(), &got)
+		if got["code"] != "invalid_reset_token" {
+			t.Fatalf("expected invalid_reset_token, got %v", got["code"])
+		}
+	}
+	if resp := doRequest(t, r, http.MethodGet, "/api/v1/admin/me", nil, withAuth(nil, oldToken)); resp.Code != http.StatusUnauthorized {
+		t.Fatalf("expected old token rejected, got %d: %s", resp.Code, resp.Body.String())
+	}
+	loginAdmin(t, r, testAdminEmail, newPassword)
+}
+
+func TestRouter_AdminPasswordPolicy_HistoryAndRehash(t *testing.T) {
+	db := openTestDB(t)
+
+	policy := security.DefaultPasswordPolicy()
+	policy.HistorySize = 3
+	policy.BcryptCost = bcrypt.MinCost
+
+	r := newAdminRouter(t, db, func(deps *Dependencies) {
+		deps.Admin.Auth = adminHandlers.NewAuthHandlerWithOptions(db, testJWTService(t), adminHandlers.AuthHandlerOptions{
+			Lockout:        security.DefaultLockoutPolicy(),
+			PasswordPolicy: policy,
+		})
+		deps.Admin.Users = adminHandlers.NewUsersHandlerWithPasswordPolicy(db, policy)
+	})
+
+	// The bootstrap hash uses the default cost; a successful login upgrades it.
+	token := loginAdmin(t, r, testAdminEmail, testAdminPassword)
+	{
+		var user model.User
+		if err := db.Where("email = ?", testAdminEmail).First(&user).Error; err != nil {
+			t.Fatalf("load admin: %v", err)
+		}
+		if cost, err := bcrypt.Cost([]byte(user.PasswordHash)); err != nil || cost != bcrypt.MinCost {
+			t.Fatalf("expected rehash to cost %d, got %d (err=%v)", bcrypt.MinCost, cost, err)
+		}
+	}
+	// The rehash keeps the issued token valid.
+	if resp := doRequest(t, r, http.MethodGet, "/api/v1/admin/me", nil, withAuth(nil, token)); resp.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+	}
+
+	current := testAdminPassword
+	change := func(newPassword string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]string{"oldPassword": current, "newPassword": newPassword})
+		resp := doRequest(t, r, http.MethodPatch, "/api/v1/admin/me/password", body, withAuth(jsonHeaders(), token))
+		if resp.Code == http.StatusOK {
+			current = newPassword
+			token = loginAdmin(t, r, testAdminEmail, current)
+		}
+		return resp
+	}
+	expectRejected := func(resp *httptest.ResponseRecorder, code, reason string) {
+		t.Helper()
+		if resp.Code != http.StatusBadRequest {
+			t.Fatalf("expected %d, got %d: %s", http.StatusBadRequest, resp.Code, resp.Body.String())
+		}
+		var got map[string]any
+		mustJSON(t, resp.Body.Bytes(), &got)
+		if got["code"] != code {
+			t.Fatalf("expected code %s, got %s", code, resp.Body.String())
+		}
+		if reason == "" {
+			return
+		}
+		reasons, _ := got["reasons"].([]any)
+		for _, got := range reasons {
+			if got == reason {
+				return
+			}
+		}
+		t.Fatalf("expected reason %s, got %s", reason, resp.Body.String())
+	}
+
+	expectRejected(change("Password2024!"), "weak_password", security.PasswordCommon)
+	expectRejected(change("admin-velvet-9"), "weak_password", security.PasswordContainsEmail)
+	expectRejected(change("velvethemline"), "weak_password", security.PasswordFewClasses)
+
+	for _, pw := range []string{"velvet-hem-101", "satin-train-202"} {
+		if resp := change(pw); resp.Code != http.StatusOK {
+			t.Fatalf("change to %s: expected %d, got %d: %s", pw, http.StatusOK, resp.Code, resp.Body.String())
+		}
+	}
+	// History size 3: the current and the two previous passwords are blocked.
+	expectRejected(change("velvet-hem-101"), "password_reused", "")
+	if resp := change("tulle-veil-303"); resp.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+	}
+	var history int64
+	if err := db.Model(&model.PasswordHistory{}).Count(&history).Error; err != nil {
+		t.Fatalf("count history: %v", err)
+	}
+	if history != 2 {
+		t.Fatalf("expected history pruned to 2, got %d", history)
+	}
+
+	// Explicit invite passwords go through the same policy.
+	resp := doRequest(t, r, http.MethodPost, "/api/v1/admin/users", []byte(`{"email":"sales@example.com","role":"sales","password":"qwerty12345"}`), withAuth(jsonHeaders(), token))
+	expectRejected(resp, "weak_password", security.PasswordCommon)
+}
+
+func TestRouter_AdminAudit_RecordsMutations(t *testing.T) {
+	db := openTestDB(t)
+
+	r := newAdminRouter(t, db, func(deps *Dependencies) {
+		deps.Admin.Products = adminHandlers.NewProductsHandler(db, cache.NewPublicCache(nil))
+		deps.Admin.Users = adminHandlers.NewUsersHandler(db)
+		deps.Admin.Audit = adminHandlers.NewAuditHandler(db)
+		deps.Admin.AuditMiddleware = middleware.Audit(db)
+	})
+
+	adminToken := loginAdmin(t, r, testAdminEmail, testAdminPassword)
+	traced := func(reqID string) map[string]string {
+		h := withAuth(jsonHeaders(), adminToken)
+		h["X-Request-Id"] = reqID
+		return h
+	}
+
+	var productID uint
+	{
+		resp := doRequest(t, r, http.MethodPost, "/api/v1/admin/products", []byte(`{"styleNo":"2001","season":"ss25","category":"gown","availability":"in_stock"}`), traced("req-create"))
+		if resp.Code != http.StatusCreated {
+			t.Fatalf("expected %d, got %d: %s", http.StatusCreated, resp.Code, resp.Body.String())
+		}
+		var got map[string]any
+		mustJSON(t, resp.Body.Bytes(), &got)
+		productID = mustUintFromJSONNumber(t, got["id"])
+	}
+	productPath := "/api/v1/admin/products/" + strconv.FormatUint(uint64(productID), 10)
+	if resp := doRequest(t, r, http.MethodPost, productPath+"/publish", nil, traced("req-publish")); resp.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+	}
+	if resp := doRequest(t, r, http.MethodDelete, productPath, nil, traced("req-delete")); resp.Code != http.StatusNoContent {
+		t.Fatalf("expected %d, got %d: %s", http.StatusNoContent, resp.Code, resp.Body.String())
+	}
+	// Failed mutations are not recorded.
+	if resp := doRequest(t, r, http.MethodDelete, productPath, nil, traced("req-missing")); resp.Code != http.StatusNotFound {
+		t.Fatalf("expected %d, got %d: %s", http.StatusNotFound, resp.Code, resp.Body.String())
+	}
+	// Routes without explicit auditing still leave a generic entry.
+	if resp := doRequest(t, r, http.MethodDelete, "/api/v1/admin/me/sessions", nil, traced("req-sessions")); resp.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+	}
+
+	type auditItem struct {
+		Action     string                     `json:"action"`
+		EntityType string                     `json:"entityType"`
+		EntityID   string                     `json:"entityId"`
+		ActorEmail string                     `json:"actorEmail"`
+		RequestID  string                     `json:"requestId"`
+		Changes    map[string]json.RawMessage `json:"changes"`
+	}
+	var list struct {
+		Total int64       `json:"total"`
+		Items []auditItem `json:"items"`
+	}
+	{
+		resp := doRequest(t, r, http.MethodGet, "/api/v1/admin/audit?entity_type=products&entity_id="+strconv.FormatUint(uint64(productID), 10), nil, withAuth(nil, adminToken))
+		if resp.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+		}
+		mustJSON(t, resp.Body.Bytes(), &list)
+	}
+	if list.Total != 3 || len(list.Items) != 3 {
+		t.Fatalf("expected 3 product entries, got %+v", list)
+	}
+	wantActions := []string{"product.delete", "product.publish", "product.create"}
+	wantRequests := []string{"req-delete", "req-publish", "req-create"}
+	for i, item := range list.Items {
+		if item.Action != wantActions[i] || item.RequestID != wantRequests[i] || item.ActorEmail != testAdminEmail {
+			t.Fatalf("entry %d: unexpected %+v", i, item)
+		}
+	}
+	if _, ok := list.Items[1].Changes["publishedAt"]; !ok || len(list.Items[1].Changes) != 1 {
+		t.Fatalf("expected publish diff on publishedAt only, got %+v", list.Items[1].Changes)
+	}
+	// Deletes keep a full copy of the deleted row.
+	if _, ok := list.Items[0].Changes["styleNo"]; !ok {
+		t.Fatalf("expected delete diff to include styleNo, got %+v", list.Items[0].Changes)
+	}
+
+	{
+		resp := doRequest(t, r, http.MethodGet, "/api/v1/admin/audit?entity_type=me", nil, withAuth(nil, adminToken))
+		mustJSON(t, resp.Body.Bytes(), &list)
+		if list.Total != 1 || list.Items[0].Action != "DELETE /api/v1/admin/me/sessions" {
+			t.Fatalf("expected generic entry, got %s", resp.Body.String())
+		}
+	}
+
+	// CSV export with the same filters.
+	{
+		resp := doRequest(t, r, http.MethodGet, "/api/v1/admin/audit/export?action=product.delete", nil, withAuth(nil, adminToken))
+		if resp.Code != http.StatusOK || !strings.HasPrefix(resp.Header().Get("Content-Type"), "text/csv") {
+			t.Fatalf("expected csv, got %d %q: %s", resp.Code, resp.Header().Get("Content-Type"), resp.Body.String())
+		}
+		records, err := csv.NewReader(resp.Body).ReadAll()
+		if err != nil {
+			t.Fatalf("parse csv: %v", err)
+		}
+		if len(records) != 2 || records[0][5] != "action" || records[1][5] != "product.delete" || records[1][3] != testAdminEmail {
+			t.Fatalf("unexpected csv: %v", records)
+		}
+	}
+
+	// Reading the trail requires audit:read.
+	{
+		resp := doRequest(t, r, http.MethodPost, "/api/v1/admin/users", []byte(`{"email":"merch@example.com","role":"merchandiser"}`), withAuth(jsonHeaders(), adminToken))
+		var invited map[string]any
+		mustJSON(t, resp.Body.Bytes(), &invited)
+		merchPassword, _ := invited["temporaryPassword"].(string)
+		merchToken := loginAdmin(t, r, "merch@example.com", merchPassword)
+		if resp := doRequest(t, r, http.MethodGet, "/api/v1/admin/audit", nil, withAuth(nil, merchToken)); resp.Code != http.StatusForbidden {
+			t.Fatalf("expected %d, got %d: %s", http.StatusForbidden, resp.Code, resp.Body.String())
+		}
+	}
+}
+
+func TestRouter_AdminProducts_Schedule(t *testing.T) {
+	db := openTestDB(t)
+
+	r := newAdminRouter(t, db, func(deps *Dependencies) {
+		deps.Admin.Products = adminHandlers.NewProductsHandler(db, cache.NewPublicCache(nil))
+	})
+
+	adminToken := loginAdmin(t, r, testAdminEmail, testAdminPassword)
+
+	var productID uint
+	{
+		resp := doRequest(t, r, http.MethodPost, "/api/v1/admin/products", []byte(`{"styleNo":"3001","season":"ss26","category":"gown","availability":"in_stock"}`), withAuth(jsonHeaders(), adminToken))
+		if resp.Code != http.StatusCreated {
+			t.Fatalf("expected %d, got %d: %s", http.StatusCreated, resp.Code, resp.Body.String())
+		}
+		var got map[string]any
+		mustJSON(t, resp.Body.Bytes(), &got)
+		productID = mustUintFromJSONNumber(t, got["id"])
+	}
+	productPath := "/api/v1/admin/products/" + strconv.FormatUint(uint64(productID), 10)
+
+	publishAt := time.Now().Add(48 * time.Hour).UTC().Truncate(time.Second)
+	unpublishAt := publishAt.Add(7 * 24 * time.Hour)
+
+	for _, body := range []string{
+		`{"publishAt":"2000-01-01T00:00:00Z"}`,
+		`{"publishAt":"` + unpublishAt.Format(time.RFC3339) + `","unpublishAt":"` + publishAt.Format(time.RFC3339) + `"}`,
+	} {
+		if resp := doRequest(t, r, http.MethodPut, productPath+"/schedule", []byte(body), withAuth(jsonHeaders(), adminToken)); resp.Code != http.StatusBadRequest {
+			t.Fatalf("expected %d for %s, got %d: %s", http.StatusBadRequest, body, resp.Code, resp.Body.String())
+		}
+	}
+
+	{
+		body := `{"publishAt":"` + publishAt.Format(time.RFC3339) + `","unpublishAt":"` + unpublishAt.Format(time.RFC3339) + `"}`
+		resp := doRequest(t, r, http.MethodPut, productPath+"/schedule", []byte(body), withAuth(jsonHeaders(), adminToken))
+		if resp.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+		}
+		var got struct {
+			PublishAt   *time.Time `json:"publishAt"`
+			UnpublishAt *time.Time `json:"unpublishAt"`
+			PublishedAt *time.Time `json:"publishedAt"`
+		}
+		mustJSON(t, resp.Body.Bytes(), &got)
+		if got.PublishAt == nil || !got.PublishAt.Equal(publishAt) || got.UnpublishAt == nil || got.PublishedAt != nil {
+			t.Fatalf("unexpected schedule: %s", resp.Body.String())
+		}
+	}
+
+	{
+		resp := doRequest(t, r, http.MethodGet, "/api/v1/admin/products?status=scheduled", nil, withAuth(nil, adminToken))
+		var list struct {
+			Total int64 `json:"total"`
+		}
+		mustJSON(t, resp.Body.Bytes(), &list)
+		if list.Total != 1 {
+			t.Fatalf("expected scheduled product listed, got %s", resp.Body.String())
+		}
+	}
+
+	// Publishing by hand supersedes the pending publishAt; unpublishAt stays.
+	{
+		if resp := doRequest(t, r, http.MethodPost, productPath+"/publish", nil, withAuth(nil, adminToken)); resp.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+		}
+		resp := doRequest(t, r, http.MethodGet, productPath, nil, withAuth(nil, adminToken))
+		var got map[string]any
+		mustJSON(t, resp.Body.Bytes(), &got)
+		if _, ok := got["publishAt"]; ok || got["unpublishAt"] == nil || got["publishedAt"] == nil {
+			t.Fatalf("unexpected product after publish: %s", resp.Body.String())
+		}
+	}
+}
+
+func TestRouter_AdminProducts_RevisionsDiffAndRestore(t *testing.T) {
+	db := openTestDB(t)
+
+	r := newAdminRouter(t, db, func(deps *Dependencies) {
+		deps.Admin.Products = adminHandlers.NewProductsHandler(db, cache.NewPublicCache(nil))
+		deps.Admin.AuditMiddleware = middleware.Audit(db)
+	})
+
+	adminToken := loginAdmin(t, r, testAdminEmail, testAdminPassword)
+
+	var productID uint
+	{
+		body := `{"styleNo":"4001","season":"ss26","category":"gown","availability":"in_stock","detail":{"sections":[{"id":"overview","type":"richText"}],"specs":[{"key":"pieces","value_i18n":{"zh":"1","en":"1"}}]}}`
+		resp := doRequest(t, r, http.MethodPost, "/api/v1/admin/products", []byte(body), withAuth(jsonHeaders(), adminToken))
+		if resp.Code != http.StatusCreated {
+			t.Fatalf("expected %d, got %d: %s", http.StatusCreated, resp.Code, resp.Body.String())
+		}
+		var got map[string]any
+		mustJSON(t, resp.Body.Bytes(), &got)
+		productID = mustUintFromJSONNumber(t, got["id"])
+	}
+	productPath := "/api/v1/admin/products/" + strconv.FormatUint(uint64(productID), 10)
+
+	// An accidental edit: new slug, spec value wiped, overview section dropped.
+	{
+		body := `{"slug":"oops","detail":{"sections":[],"specs":[{"key":"pieces","value_i18n":{"zh":"","en":""}}]}}`
+		if resp := doRequest(t, r, http.MethodPatch, productPath, []byte(body), withAuth(jsonHeaders(), adminToken)); resp.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+		}
+	}
+
+	type revisionList struct {
+		Total int64 `json:"total"`
+		Items []struct {
+			ID         uint            `json:"id"`
+			Reason     string          `json:"reason"`
+			ActorEmail string          `json:"actorEmail"`
+			Snapshot   json.RawMessage `json:"snapshot"`
+		} `json:"items"`
+	}
+	var revs revisionList
+	{
+		resp := doRequest(t, r, http.MethodGet, productPath+"/revisions", nil, withAuth(nil, adminToken))
+		if resp.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+		}
+		mustJSON(t, resp.Body.Bytes(), &revs)
+	}
+	if revs.Total != 1 || revs.Items[0].Reason != "update" || revs.Items[0].ActorEmail != testAdminEmail || len(revs.Items[0].Snapshot) != 0 {
+		t.Fatalf("unexpected revisions: %+v", revs)
+	}
+	revPath := productPath + "/revisions/" + strconv.FormatUint(uint64(revs.Items[0].ID), 10)
+
+	{
+		resp := doRequest(t, r, http.MethodGet, revPath+"/diff", nil, withAuth(nil, adminToken))
+		if resp.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+		}
+		var diff struct {
+			Against string                     `json:"against"`
+			Fields  map[string]json.RawMessage `json:"fields"`
+			Detail  struct {
+				Sections []struct{ Key, Op string } `json:"sections"`
+				Specs    []struct{ Key, Op string } `json:"specs"`
+			} `json:"detail"`
+		}
+		mustJSON(t, resp.Body.Bytes(), &diff)
+		if _, ok := diff.Fields["slug"]; diff.Against != "current" || !ok || len(diff.Fields) != 1 {
+			t.Fatalf("unexpected field diff: %s", resp.Body.String())
+		}
+		if len(diff.Detail.Sections) != 1 || diff.Detail.Sections[0].Key != "overview" || diff.Detail.Sections[0].Op != "removed" ||
+			len(diff.Detail.Specs) != 1 || diff.Detail.Specs[0].Op != "changed" {
+			t.Fatalf("unexpected detail diff: %s", resp.Body.String())
+		}
+	}
+
+	{
+		resp := doRequest(t, r, http.MethodPost, revPath+"/restore", nil, withAuth(nil, adminToken))
+		if resp.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+		}
+		var got struct {
+			Slug   string `json:"slug"`
+			Detail struct {
+				Sections []map[string]any `json:"sections"`
+			} `json:"detail"`
+		}
+		mustJSON(t, resp.Body.Bytes(), &got)
+		if got.Slug == "oops" || len(got.Detail.Sections) != 1 {
+			t.Fatalf("expected restored product, got %s", resp.Body.String())
+		}
+	}
+
+	// The restore snapshotted the edited row, so it can be undone too.
+	{
+		resp := doRequest(t, r, http.MethodGet, productPath+"/revisions", nil, withAuth(nil, adminToken))
+		mustJSON(t, resp.Body.Bytes(), &revs)
+		if revs.Total != 2 || revs.Items[0].Reason != "restore" {
+			t.Fatalf("unexpected revisions after restore: %+v", revs)
+		}
+	}
+
+	// Revisions are scoped to their product.
+	if resp := doRequest(t, r, http.MethodGet, "/api/v1/admin/products/999/revisions/"+strconv.FormatUint(uint64(revs.Items[0].ID), 10), nil, withAuth(nil, adminToken)); resp.Code != http.StatusNotFound {
+		t.Fatalf("expected %d, got %d: %s", http.StatusNotFound, resp.Code, resp.Body.String())
+	}
+}
+
+func TestRouter_AdminProducts_DraftsOfPublishedProducts(t *testing.T) {
+	db := openTestDB(t)
+
+	publicCache := cache.NewPublicCache(nil)
+
+	r := newAdminRouter(t, db, func(deps *Dependencies) {
+		deps.Public.Products = publicHandlers.NewProductsHandler(db, publicCache)
+		deps.Admin.Products = adminHandlers.NewProductsHandler(db, publicCache)
+	})
+
+	adminToken := loginAdmin(t, r, testAdminEmail, testAdminPassword)
+
+	createProduct := func(styleNo string) string {
+		t.Helper()
+		resp := doRequest(t, r, http.MethodPost, "/api/v1/admin/products", []byte(`{"styleNo":"`+styleNo+`","season":"ss26","category":"gown","availability":"in_stock"}`), withAuth(jsonHeaders(), adminToken))
+		if resp.Code != http.StatusCreated {
+			t.Fatalf("expected %d, got %d: %s", http.StatusCreated, resp.Code, resp.Body.String())
+		}
+		var got map[string]any
+		mustJSON(t, resp.Body.Bytes(), &got)
+		return strconv.FormatUint(uint64(mustUintFromJSONNumber(t, got["id"])), 10)
+	}
+	id := createProduct("5001")
+	otherID := createProduct("5002")
+	productPath := "/api/v1/admin/products/" + id
+	if resp := doRequest(t, r, http.MethodPost, productPath+"/publish", nil, withAuth(nil, adminToken)); resp.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+	}
+
+	season := func(path string, headers map[string]string) string {
+		t.Helper()
+		resp := doRequest(t, r, http.MethodGet, path, nil, headers)
+		if resp.Code != http.StatusOK {
+			t.Fatalf("GET %s: expected %d, got %d: %s", path, http.StatusOK, resp.Code, resp.Body.String())
+		}
+		var got struct {
+			Season string `json:"season"`
+		}
+		mustJSON(t, resp.Body.Bytes(), &got)
+		return got.Season
+	}
+
+	// Edits to a published product are staged.
+	for _, body := range []string{`{"season":"fw26"}`, `{"newRank":7}`} {
+		resp := doRequest(t, r, http.MethodPatch, productPath, []byte(body), withAuth(jsonHeaders(), adminToken))
+		if resp.Code != http.StatusAccepted {
+			t.Fatalf("expected %d, got %d: %s", http.StatusAccepted, resp.Code, resp.Body.String())
+		}
+	}
+	if got := season("/api/v1/products/"+id, nil); got != "ss26" {
+		t.Fatalf("expected public product unchanged, got season %q", got)
+	}
+	if got := season(productPath, withAuth(nil, adminToken)); got != "ss26" {
+		t.Fatalf("expected live row unchanged, got season %q", got)
+	}
+
+	{
+		resp := doRequest(t, r, http.MethodGet, productPath+"/draft", nil, withAuth(nil, adminToken))
+		if resp.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+		}
+		var got struct {
+			Product struct {
+				Season  string `json:"season"`
+				NewRank int    `json:"newRank"`
+			} `json:"product"`
+			Changes struct {
+				Fields map[string]json.RawMessage `json:"fields"`
+			} `json:"changes"`
+		}
+		mustJSON(t, resp.Body.Bytes(), &got)
+		if got.Product.Season != "fw26" || got.Product.NewRank != 7 || len(got.Changes.Fields) != 2 {
+			t.Fatalf("unexpected draft preview: %s", resp.Body.String())
+		}
+	}
+
+	// Unique columns are checked when staging.
+	if resp := doRequest(t, r, http.MethodPatch, productPath, []byte(`{"styleNo":"5002"}`), withAuth(jsonHeaders(), adminToken)); resp.Code != http.StatusConflict {
+		t.Fatalf("expected %d, got %d: %s", http.StatusConflict, resp.Code, resp.Body.String())
+	}
+
+	if resp := doRequest(t, r, http.MethodPost, productPath+"/publish-changes", nil, withAuth(nil, adminToken)); resp.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+	}
+	if got := season("/api/v1/products/"+id, nil); got != "fw26" {
+		t.Fatalf("expected public product updated, got season %q", got)
+	}
+	if resp := doRequest(t, r, http.MethodGet, productPath+"/draft", nil, withAuth(nil, adminToken)); resp.Code != http.StatusNotFound {
+		t.Fatalf("expected %d, got %d: %s", http.StatusNotFound, resp.Code, resp.Body.String())
+	}
+	if resp := doRequest(t, r, http.MethodPost, productPath+"/publish-changes", nil, withAuth(nil, adminToken)); resp.Code != http.StatusNotFound {
+		t.Fatalf("expected %d, got %d: %s", http.StatusNotFound, resp.Code, resp.Body.String())
+	}
+
+	// Discarding leaves the live row alone.
+	if resp := doRequest(t, r, http.MethodPatch, productPath, []byte(`{"season":"ss27"}`), withAuth(jsonHeaders(), adminToken)); resp.Code != http.StatusAccepted {
+		t.Fatalf("expected %d, got %d: %s", http.StatusAccepted, resp.Code, resp.Body.String())
+	}
+	if resp := doRequest(t, r, http.MethodDelete, productPath+"/draft", nil, withAuth(nil, adminToken)); resp.Code != http.StatusNoContent {
+		t.Fatalf("expected %d, got %d: %s", http.StatusNoContent, resp.Code, resp.Body.String())
+	}
+	if got := season(productPath, withAuth(nil, adminToken)); got != "fw26" {
+		t.Fatalf("expected live row kept after discard, got season %q", got)
+	}
+
+	// Restoring a revision of a published product is staged too, replacing the pending edit
+	// instead of rewriting the live row under it.
+	{
+		if resp := doRequest(t, r, http.MethodPatch, productPath, []byte(`{"season":"ss27"}`), withAuth(jsonHeaders(), adminToken)); resp.Code != http.StatusAccepted {
+			t.Fatalf("expected %d, got %d: %s", http.StatusAccepted, resp.Code, resp.Body.String())
+		}
+		resp := doRequest(t, r, http.MethodGet, productPath+"/revisions", nil, withAuth(nil, adminToken))
+		var revs struct {
+			Items []struct {
+				ID     uint   `json:"id"`
+				Reason string `json:"reason"`
+			} `json:"items"`
+		}
+		mustJSON(t, resp.Body.Bytes(), &revs)
+		if len(revs.Items) != 1 || revs.Items[0].Reason != "publish_changes" {
+			t.Fatalf("unexpected revisions: %s", resp.Body.String())
+		}
+		restorePath := productPath + "/revisions/" + strconv.FormatUint(uint64(revs.Items[0].ID), 10) + "/restore"
+		resp = doRequest(t, r, http.MethodPost, restorePath, nil, withAuth(nil, adminToken))
+		if resp.Code != http.StatusAccepted {
+			t.Fatalf("expected %d, got %d: %s", http.StatusAccepted, resp.Code, resp.Body.String())
+		}
+		if got := season(productPath, withAuth(nil, adminToken)); got != "fw26" {
+			t.Fatalf("expected live row unchanged by restore, got season %q", got)
+		}
+		resp = doRequest(t, r, http.MethodGet, productPath+"/draft", nil, withAuth(nil, adminToken))
+		var draft struct {
+			Product struct {
+				Season string `json:"season"`
+			} `json:"product"`
+		}
+		mustJSON(t, resp.Body.Bytes(), &draft)
+		if draft.Product.Season != "ss26" {
+			t.Fatalf("expected restored content in the draft, got %s", resp.Body.String())
+		}
+	}
+
+	// A pending draft survives an unpublish and keeps collecting edits; publishing again puts
+	// it live instead of the old row.
+	if resp := doRequest(t, r, http.MethodPost, productPath+"/unpublish", nil, withAuth(nil, adminToken)); resp.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+	}
+	if resp := doRequest(t, r, http.MethodPatch, productPath, []byte(`{"season":"ss27"}`), withAuth(jsonHeaders(), adminToken)); resp.Code != http.StatusAccepted {
+		t.Fatalf("expected %d, got %d: %s", http.StatusAccepted, resp.Code, resp.Body.String())
+	}
+	if resp := doRequest(t, r, http.MethodPost, productPath+"/publish", nil, withAuth(nil, adminToken)); resp.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+	}
+	if got := season("/api/v1/products/"+id, nil); got != "ss27" {
+		t.Fatalf("expected the draft published, got season %q", got)
+	}
+	if resp := doRequest(t, r, http.MethodGet, productPath+"/draft", nil, withAuth(nil, adminToken)); resp.Code != http.StatusNotFound {
+		t.Fatalf("expected %d, got %d: %s", http.StatusNotFound, resp.Code, resp.Body.String())
+	}
+
+	// Unpublished products are still edited in place.
+	if resp := doRequest(t, r, http.MethodPatch, "/api/v1/admin/products/"+otherID, []byte(`{"season":"fw26"}`), withAuth(jsonHeaders(), adminToken)); resp.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+	}
+}
+
+func TestRouter_PreviewLinks(t *testing.T) {
+	db := openTestDB(t)
+
+	previews := security.NewPreviewSigner("preview-test-secret")
+	publicCache := cache.NewPublicCache(nil)
+
+	r := newAdminRouter(t, db, func(deps *Dependencies) {
+		deps.Public.Products = publicHandlers.NewProductsHandlerWithPreview(db, publicCache, previews).WithImageWidths([]int{320, 768})
+		deps.Public.Updates = publicHandlers.NewUpdatesHandlerWithPreview(db, publicCache, previews)
+		deps.Admin.Products = adminHandlers.NewProductsHandler(db, publicCache)
+		deps.Admin.Updates = adminHandlers.NewUpdatesHandler(db, publicCache)
+		deps.Admin.Users = adminHandlers.NewUsersHandler(db)
+		deps.Admin.Previews = adminHandlers.NewPreviewsHandler(db, previews, adminHandlers.PreviewOptions{TTL: time.Hour, MaxTTL: 24 * time.Hour})
+	})
+
+	adminToken := loginAdmin(t, r, testAdminEmail, testAdminPassword)
+
+	var productID, updateID string
+	{
+		resp := doRequest(t, r, http.MethodPost, "/api/v1/admin/products", []byte(`{"styleNo":"6001","season":"ss26","category":"gown","availability":"in_stock","coverImageKey":"products/6001/cover.jpg","detail":{"gallery":[{"url":"/api/v1/assets/products/6001/gallery/1.jpg"}]}}`), withAuth(jsonHeaders(), adminToken))
+		if resp.Code != http.StatusCreated {
+			t.Fatalf("expected %d, got %d: %s", http.StatusCreated, resp.Code, resp.Body.String())
+		}
+		var got map[string]any
+		mustJSON(t, resp.Body.Bytes(), &got)
+		productID = strconv.FormatUint(uint64(mustUintFromJSONNumber(t, got["id"])), 10)
+	}
+	{
+		resp := doRequest(t, r, http.MethodPost, "/api/v1/admin/updates", []byte(`{"type":"company","title":"Coming soon"}`), withAuth(jsonHeaders(), adminToken))
+		if resp.Code != http.StatusCreated {
+			t.Fatalf("expected %d, got %d: %s", http.StatusCreated, resp.Code, resp.Body.String())
+		}
+		var got map[string]any
+		mustJSON(t, resp.Body.Bytes(), &got)
+		updateID = strconv.FormatUint(uint64(mustUintFromJSONNumber(t, got["id"])), 10)
+	}
+
+	// Sales can mint links without any catalog permissions.
+	var salesToken string
+	{
+		resp := doRequest(t, r, http.MethodPost, "/api/v1/admin/users", []byte(`{"email":"sales@example.com","role":"sales"}`), withAuth(jsonHeaders(), adminToken))
+		var invited map[string]any
+		mustJSON(t, resp.Body.Bytes(), &invited)
+		salesPassword, _ := invited["temporaryPassword"].(string)
+		salesToken = loginAdmin(t, r, "sales@example.com", salesPassword)
+	}
+
+	type link struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expiresAt"`
+		Path      string    `json:"path"`
+	}
+	mint := func(path, body string) link {
+		t.Helper()
+		resp := doRequest(t, r, http.MethodPost, path, []byte(body), withAuth(jsonHeaders(), salesToken))
+		if resp.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+		}
+		var got link
+		mustJSON(t, resp.Body.Bytes(), &got)
+		return got
+	}
+
+	if resp := doRequest(t, r, http.MethodPost, "/api/v1/admin/products/"+productID+"/preview-link", []byte(`{"ttl":"48h"}`), withAuth(jsonHeaders(), salesToken)); resp.Code != http.StatusBadRequest {
+		t.Fatalf("expected %d for ttl above max, got %d: %s", http.StatusBadRequest, resp.Code, resp.Body.String())
+	}
+	productLink := mint("/api/v1/admin/products/"+productID+"/preview-link", `{"ttl":"2h"}`)
+	if d := time.Until(productLink.ExpiresAt); d < time.Hour || d > 2*time.Hour {
+		t.Fatalf("unexpected expiry %v", productLink.ExpiresAt)
+	}
+
+	// Without a token the draft stays hidden.
+	if resp := doRequest(t, r, http.MethodGet, "/api/v1/products/"+productID, nil, nil); resp.Code != http.StatusNotFound {
+		t.Fatalf("expected %d, got %d: %s", http.StatusNotFound, resp.Code, resp.Body.String())
+	}
+	{
+		resp := doRequest(t, r, http.MethodGet, productLink.Path, nil, nil)
+		if resp.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+		}
+		if cc := resp.Header().Get("Cache-Control"); cc != "private, no-store" {
+			t.Fatalf("expected no-store, got %q", cc)
+		}
+		var got map[string]any
+		mustJSON(t, resp.Body.Bytes(), &got)
+		cover, _ := got["coverImage"].(string)
+		if got["preview"] != true || !strings.HasPrefix(cover, "/api/v1/assets/products/6001/cover.jpg?preview=") {
+			t.Fatalf("unexpected preview body: %s", resp.Body.String())
+		}
+		// So does every srcset candidate.
+		srcset, _ := got["coverImageSrcset"].(string)
+		candidates := strings.Split(srcset, ", ")
+		if len(candidates) != 2 {
+			t.Fatalf("unexpected srcset %q", srcset)
+		}
+		for _, cand := range candidates {
+			if !strings.Contains(cand, "&preview=") {
+				t.Fatalf("expected preview token in srcset candidate %q", cand)
+			}
+		}
+		// Detail images carry the token as well.
+		if !strings.Contains(resp.Body.String(), `"/api/v1/assets/products/6001/gallery/1.jpg?preview=`) {
+			t.Fatalf("expected tokenized gallery URL: %s", resp.Body.String())
+		}
+	}
+
+	// A token only unlocks its own item, and tampering invalidates it.
+	{
+		other := mint("/api/v1/admin/updates/"+updateID+"/preview-link", "")
+		if resp := doRequest(t, r, http.MethodGet, "/api/v1/products/"+productID+"?preview="+other.Token, nil, nil); resp.Code != http.StatusNotFound {
+			t.Fatalf("expected %d, got %d: %s", http.StatusNotFound, resp.Code, resp.Body.String())
+		}
+		if resp := doRequest(t, r, http.MethodGet, productLink.Path+"x", nil, nil); resp.Code != http.StatusNotFound {
+			t.Fatalf("expected %d, got %d: %s", http.StatusNotFound, resp.Code, resp.Body.String())
+		}
+		resp := doRequest(t, r, http.MethodGet, other.Path, nil, nil)
+		if resp.Code != http.StatusOK || !strings.Contains(resp.Body.String(), "Coming soon") {
+			t.Fatalf("expected update preview, got %d: %s", resp.Code, resp.Body.String())
+		}
+	}
+
+	// Once published, edits wait in the draft: the preview shows them, the public page does not.
+	if resp := doRequest(t, r, http.MethodPost, "/api/v1/admin/products/"+productID+"/publish", nil, withAuth(nil, adminToken)); resp.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+	}
+	if resp := doRequest(t, r, http.MethodPatch, "/api/v1/admin/products/"+productID, []byte(`{"coverImageKey":"products/6001/cover-v2.jpg"}`), withAuth(jsonHeaders(), adminToken)); resp.Code != http.StatusAccepted {
+		t.Fatalf("expected %d, got %d: %s", http.StatusAccepted, resp.Code, resp.Body.String())
+	}
+	for path, want := range map[string]string{
+		productLink.Path:                "/api/v1/assets/products/6001/cover-v2.jpg?preview=",
+		"/api/v1/products/" + productID: `"/api/v1/assets/products/6001/cover.jpg"`,
+	} {
+		resp := doRequest(t, r, http.MethodGet, path, nil, nil)
+		if resp.Code != http.StatusOK || !strings.Contains(resp.Body.String(), want) {
+			t.Fatalf("GET %s: expected %s, got %d: %s", path, want, resp.Code, resp.Body.String())
+		}
+	}
+
+	// Deleted items cannot be previewed.
+	if resp := doRequest(t, r, http.MethodDelete, "/api/v1/admin/products/"+productID, nil, withAuth(nil, adminToken)); resp.Code != http.StatusNoContent {
+		t.Fatalf("expected %d, got %d: %s", http.StatusNoContent, resp.Code, resp.Body.String())
+	}
+	if resp := doRequest(t, r, http.MethodGet, productLink.Path, nil, nil); resp.Code != http.StatusNotFound {
+		t.Fatalf("expected %d, got %d: %s", http.StatusNotFound, resp.Code, resp.Body.String())
+	}
+}
+
+func TestRouter_AdminProducts_Variants(t *testing.T) {
+	db := openTestDB(t)
+
+	publicCache := cache.NewPublicCache(nil)
+
+	r := newAdminRouter(t, db, func(deps *Dependencies) {
+		deps.Public.Products = publicHandlers.NewProductsHandler(db, publicCache)
+		deps.Admin.Products = adminHandlers.NewProductsHandler(db, publicCache)
+	})
+
+	adminToken := loginAdmin(t, r, testAdminEmail, testAdminPassword)
+
+	var productID string
+	{
+		body := `{"styleNo":"7001","season":"ss26","category":"gown","availability":"in_stock","detail":{"option_groups":[{"key":"color","options":[{"key":"ivory"},{"key":"black"}]},{"key":"size","options":["S"]}]}}`
+		resp := doRequest(t, r, http.MethodPost, "/api/v1/admin/products", []byte(body), withAuth(jsonHeaders(), adminToken))
+		if resp.Code != http.StatusCreated {
+			t.Fatalf("expected %d, got %d: %s", http.StatusCreated, resp.Code, resp.Body.String())
+		}
+		var got map[string]any
+		mustJSON(t, resp.Body.Bytes(), &got)
+		productID = strconv.FormatUint(uint64(mustUintFromJSONNumber(t, got["id"])), 10)
+	}
+	productPath := "/api/v1/admin/products/" + productID
+
+	type variant struct {
+		ID           uint   `json:"id"`
+		Color        string `json:"color"`
+		Size         string `json:"size"`
+		StyleNo      string `json:"styleNo"`
+		Availability string `json:"availability"`
+		MOQ          int    `json:"moq"`
+	}
+	listVariants := func() []variant {
+		t.Helper()
+		resp := doRequest(t, r, http.MethodGet, productPath+"/variants", nil, withAuth(nil, adminToken))
+		if resp.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+		}
+		var got struct {
+			Items []variant `json:"items"`
+		}
+		mustJSON(t, resp.Body.Bytes(), &got)
+		return got.Items
+	}
+	publicVariants := func() []variant {
+		t.Helper()
+		resp := doRequest(t, r, http.MethodGet, "/api/v1/products/"+productID, nil, nil)
+		if resp.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+		}
+		var got struct {
+			Variants []variant `json:"variants"`
+		}
+		mustJSON(t, resp.Body.Bytes(), &got)
+		return got.Variants
+	}
+
+	variants := listVariants()
+	if len(variants) != 2 || variants[0].Color != "ivory" || variants[1].Color != "black" || variants[0].Size != "S" {
+		t.Fatalf("expected variants generated from option groups, got %+v", variants)
+	}
+	ivory, black := variants[0], variants[1]
+	variantPath := func(v variant) string { return productPath + "/variants/" + strconv.FormatUint(uint64(v.ID), 10) }
+
+	for body, want := range map[string]int{
+		`{"availability":"sold_out"}`: http.StatusBadRequest,
+		`{"moq":-1}`:                  http.StatusBadRequest,
+		`{"styleSuffix":"iv","availability":"preorder","moq":5,"leadTime":"45 days"}`: http.StatusOK,
+	} {
+		if resp := doRequest(t, r, http.MethodPatch, variantPath(ivory), []byte(body), withAuth(jsonHeaders(), adminToken)); resp.Code != want {
+			t.Fatalf("PATCH %s: expected %d, got %d: %s", body, want, resp.Code, resp.Body.String())
+		}
+	}
+	if resp := doRequest(t, r, http.MethodPatch, variantPath(black), []byte(`{"availability":"archived"}`), withAuth(jsonHeaders(), adminToken)); resp.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+	}
+
+	if resp := doRequest(t, r, http.MethodPost, productPath+"/publish", nil, withAuth(nil, adminToken)); resp.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+	}
+	if got := publicVariants(); len(got) != 1 || got[0].StyleNo != "7001-IV" || got[0].Availability != "preorder" || got[0].MOQ != 5 {
+		t.Fatalf("unexpected public variants: %+v", got)
+	}
+
+	// Manual creation is limited to combinations from the option groups.
+	if resp := doRequest(t, r, http.MethodPost, productPath+"/variants", []byte(`{"color":"red","size":"S"}`), withAuth(jsonHeaders(), adminToken)); resp.Code != http.StatusBadRequest {
+		t.Fatalf("expected %d, got %d: %s", http.StatusBadRequest, resp.Code, resp.Body.String())
+	}
+	if resp := doRequest(t, r, http.MethodPost, productPath+"/variants", []byte(`{"color":"ivory","size":"S"}`), withAuth(jsonHeaders(), adminToken)); resp.Code != http.StatusConflict {
+		t.Fatalf("expected %d, got %d: %s", http.StatusConflict, resp.Code, resp.Body.String())
+	}
+	if resp := doRequest(t, r, http.MethodDelete, variantPath(black), nil, withAuth(nil, adminToken)); resp.Code != http.StatusNoContent {
+		t.Fatalf("expected %d, got %d: %s", http.StatusNoContent, resp.Code, resp.Body.String())
+	}
+	if resp := doRequest(t, r, http.MethodPost, productPath+"/variants/sync", nil, withAuth(nil, adminToken)); resp.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+	}
+	if got := listVariants(); len(got) != 2 || got[1].Color != "black" || got[1].Availability != "" {
+		t.Fatalf("expected sync to recreate the deleted variant, got %+v", got)
+	}
+
+	// Dropping an option only removes its variants once the change goes live; the rest keep their settings.
+	{
+		body := `{"detail":{"option_groups":[{"key":"color","options":[{"key":"ivory"}]},{"key":"size","options":["S","M"]}]}}`
+		if resp := doRequest(t, r, http.MethodPatch, productPath, []byte(body), withAuth(jsonHeaders(), adminToken)); resp.Code != http.StatusAccepted {
+			t.Fatalf("expected %d, got %d: %s", http.StatusAccepted, resp.Code, resp.Body.String())
+		}
+	}
+	if got := listVariants(); len(got) != 2 {
+		t.Fatalf("expected variants unchanged while drafting, got %+v", got)
+	}
+	if resp := doRequest(t, r, http.MethodPost, productPath+"/publish-changes", nil, withAuth(nil, adminToken)); resp.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+	}
+	got := listVariants()
+	if len(got) != 2 || got[0].ID != ivory.ID || got[0].MOQ != 5 || got[1].Color != "ivory" || got[1].Size != "M" {
+		t.Fatalf("unexpected variants after publishing changes: %+v", got)
+	}
+}
+
+func TestRouter_AdminProducts_ImportExport(t *testing.T) {
+	db := openTestDB(t)
+
+	publicCache := cache.NewPublicCache(nil)
+
+	r := newAdminRouter(t, db, func(deps *Dependencies) {
+		deps.Admin.Products = adminHandlers.NewProductsHandler(db, publicCache)
+	})
+
+	adminToken := loginAdmin(t, r, testAdminEmail, testAdminPassword)
+
+	// A published product: imported changes to it must go to a draft.
+	var publishedID string
+	{
+		body := `{"styleNo":"8001","season":"ss26","category":"gown","availability":"in_stock"}`
+		resp := doRequest(t, r, http.MethodPost, "/api/v1/admin/products", []byte(body), withAuth(jsonHeaders(), adminToken))
+		if resp.Code != http.StatusCreated {
+			t.Fatalf("expected %d, got %d: %s", http.StatusCreated, resp.Code, resp.Body.String())
+		}
+		var got map[string]any
+		mustJSON(t, resp.Body.Bytes(), &got)
+		publishedID = strconv.FormatUint(uint64(mustUintFromJSONNumber(t, got["id"])), 10)
+
+		resp = doRequest(t, r, http.MethodPost, "/api/v1/admin/products/"+publishedID+"/publish", nil, withAuth(nil, adminToken))
+		if resp.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+		}
+	}
+
+	// Single-product writes accept only the availabilities import accepts, so exports round-trip.
+	for _, tc := range []struct{ method, path, body string }{
+		{http.MethodPost, "/api/v1/admin/products", `{"styleNo":"8009","season":"ss26","category":"gown","availability":"sold_out"}`},
+		{http.MethodPatch, "/api/v1/admin/products/" + publishedID, `{"availability":"sold_out"}`},
+	} {
+		resp := doRequest(t, r, tc.method, tc.path, []byte(tc.body), withAuth(jsonHeaders(), adminToken))
+		if resp.Code != http.StatusBadRequest || !strings.Contains(resp.Body.String(), "invalid availability") {
+			t.Fatalf("%s %s: expected invalid availability, got %d: %s", tc.method, tc.path, resp.Code, resp.Body.String())
+		}
+	}
+
+	csvHeaders := withAuth(map[string]string{"Content-Type": "text/csv"}, adminToken)
+
+	type importRow struct {
+		Row     int    `json:"row"`
+		StyleNo string `json:"styleNo"`
+		Action  string `json:"action"`
+		Errors  []struct {
+			Column string `json:"column"`
+		} `json:"errors"`
+	}
+	type importReport struct {
+		DryRun  bool        `json:"dryRun"`
+		Applied bool        `json:"applied"`
+		Created int         `json:"created"`
+		Drafted int         `json:"drafted"`
+		Invalid int         `json:"invalid"`
+		Rows    []importRow `json:"rows"`
+	}
+	countProducts := func() int64 {
+		t.Helper()
+		var n int64
+		if err := db.Model(&model.Product{}).Count(&n).Error; err != nil {
+			t.Fatalf("count products: %v", err)
+		}
+		return n
+	}
+
+	bad := "styleNo,season,category,availability,slug,notes\n" +
+		"8001,,,preorder,,\n" +
+		"8002,FW26,bridal,in_stock,,\n" +
+		"8003,spring,dress,in_stock,style-8002,\n" +
+		"8002,fw26,bridal,in_stock,,\n"
+
+	// Dry run reports per-row problems without writing.
+	{
+		resp := doRequest(t, r, http.MethodPost, "/api/v1/admin/products/import", []byte(bad), csvHeaders)
+		if resp.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+		}
+		var got importReport
+		mustJSON(t, resp.Body.Bytes(), &got)
+		if !got.DryRun || got.Applied || got.Invalid != 2 || len(got.Rows) != 4 {
+			t.Fatalf("unexpected report: %s", resp.Body.String())
+		}
+		if got.Rows[0].Action != "draft" || got.Rows[1].Action != "create" {
+			t.Fatalf("unexpected actions: %s", resp.Body.String())
+		}
+		cols := map[string]bool{}
+		for _, e := range got.Rows[2].Errors {
+			cols[e.Column] = true
+		}
+		if got.Rows[2].Row != 4 || !cols["season"] || !cols["category"] || !cols["slug"] {
+			t.Fatalf("unexpected row 4 errors: %s", resp.Body.String())
+		}
+		if len(got.Rows[3].Errors) != 1 || got.Rows[3].Errors[0].Column != "styleNo" {
+			t.Fatalf("expected duplicate styleNo error: %s", resp.Body.String())
+		}
+	}
+
+	// Applying a file with invalid rows writes nothing.
+	{
+		before := countProducts()
+		resp := doRequest(t, r, http.MethodPost, "/api/v1/admin/products/import?mode=apply", []byte(bad), csvHeaders)
+		if resp.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("expected %d, got %d: %s", http.StatusUnprocessableEntity, resp.Code, resp.Body.String())
+		}
+		if after := countProducts(); after != before {
+			t.Fatalf("expected no writes, products %d -> %d", before, after)
+		}
+	}
+
+	// A clean file is applied in one go; semicolons (Excel in some locales) and a BOM are fine.
+	{
+		good := "\ufeffstyleNo;season;category;availability;isNew;detail\n" +
+			"8001;;;preorder;;\n" +
+			`8002;FW26;bridal;in_stock;yes;"{""option_groups"":[{""key"":""color"",""options"":[""ivory""]}]}"` + "\n"
+		resp := doRequest(t, r, http.MethodPost, "/api/v1/admin/products/import?mode=apply", []byte(good), csvHeaders)
+		if resp.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+		}
+		var got importReport
+		mustJSON(t, resp.Body.Bytes(), &got)
+		if !got.Applied || got.Created != 1 || got.Drafted != 1 {
+			t.Fatalf("unexpected report: %s", resp.Body.String())
+		}
+
+		var created model.Product
+		if err := db.Where("style_no = ?", "8002").First(&created).Error; err != nil {
+			t.Fatalf("load imported product: %v", err)
+		}
+		if created.Season != "fw26" || created.Slug != "style-8002" || !created.IsNew || created.PublishedAt != nil {
+			t.Fatalf("unexpected imported product: %+v", created)
+		}
+		var variants int64
+		if err := db.Model(&model.ProductVariant{}).Where("product_id = ?", created.ID).Count(&variants).Error; err != nil {
+			t.Fatalf("count variants: %v", err)
+		}
+		if variants != 1 {
+			t.Fatalf("expected 1 variant, got %d", variants)
+		}
+
+		var live model.Product
+		if err := db.First(&live, "style_no = ?", "8001").Error; err != nil {
+			t.Fatalf("load published product: %v", err)
+		}
+		if live.Availability != "in_stock" {
+			t.Fatalf("expected live row untouched, got %q", live.Availability)
+		}
+		resp = doRequest(t, r, http.MethodGet, "/api/v1/admin/products/"+publishedID+"/draft", nil, withAuth(nil, adminToken))
+		if resp.Code != http.StatusOK {
+			t.Fatalf("expected draft %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+		}
+	}
+
+	// Export round-trips: re-importing it changes nothing for the imported product.
+	{
+		resp := doRequest(t, r, http.MethodGet, "/api/v1/admin/products/export", nil, withAuth(nil, adminToken))
+		if resp.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+		}
+		exported := resp.Body.Bytes()
+		if !bytes.HasPrefix(exported, []byte("\ufeffstyleNo,slug,season,")) {
+			t.Fatalf("unexpected export header: %q", string(exported[:min(len(exported), 60)]))
+		}
+		if !strings.Contains(string(exported), "\n8002,style-8002,fw26,bridal,in_stock,true,") {
+			t.Fatalf("expected imported product in export: %s", exported)
+		}
+
+		resp = doRequest(t, r, http.MethodPost, "/api/v1/admin/products/import", exported, csvHeaders)
+		if resp.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+		}
+		var got importReport
+		mustJSON(t, resp.Body.Bytes(), &got)
+		if got.Invalid != 0 || len(got.Rows) != 2 || got.Rows[1].Action != "unchanged" {
+			t.Fatalf("unexpected re-import report: %s", resp.Body.String())
+		}
+	}
+}
+
+func TestRouter_AdminProducts_Bulk(t *testing.T) {
+	db := openTestDB(t)
+
+	publicCache := cache.NewPublicCache(nil)
+
+	r := newAdminRouter(t, db, func(deps *Dependencies) {
+		deps.Public.Products = publicHandlers.NewProductsHandler(db, publicCache)
+		deps.Admin.Products = adminHandlers.NewProductsHandler(db, publicCache)
+	})
+
+	adminToken := loginAdmin(t, r, testAdminEmail, testAdminPassword)
+
+	var ids []uint
+	for i, season := range []string{"ss25", "ss25", "ss25", "fw25"} {
+		body := fmt.Sprintf(`{"styleNo":"950%d","season":%q,"category":"gown","availability":"in_stock"}`, i, season)
+		resp := doRequest(t, r, http.MethodPost, "/api/v1/admin/products", []byte(body), withAuth(jsonHeaders(), adminToken))
+		if resp.Code != http.StatusCreated {
+			t.Fatalf("expected %d, got %d: %s", http.StatusCreated, resp.Code, resp.Body.String())
+		}
+		var got map[string]any
+		mustJSON(t, resp.Body.Bytes(), &got)
+		ids = append(ids, mustUintFromJSONNumber(t, got["id"]))
+	}
+
+	type bulkResponse struct {
+		Total   int `json:"total"`
+		Changed int `json:"changed"`
+		Results []struct {
+			ID     uint   `json:"id"`
+			Result string `json:"result"`
+		} `json:"results"`
+	}
+	bulk := func(body string, wantCode int) bulkResponse {
+		t.Helper()
+		resp := doRequest(t, r, http.MethodPost, "/api/v1/admin/products/bulk", []byte(body), withAuth(jsonHeaders(), adminToken))
+		if resp.Code != wantCode {
+			t.Fatalf("expected %d, got %d: %s", wantCode, resp.Code, resp.Body.String())
+		}
+		var got bulkResponse
+		if wantCode == http.StatusOK {
+			mustJSON(t, resp.Body.Bytes(), &got)
+		}
+		return got
+	}
+	publicTotal := func() int {
+		t.Helper()
+		resp := doRequest(t, r, http.MethodGet, "/api/v1/products", nil, nil)
+		if resp.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+		}
+		var got struct {
+			Total int `json:"total"`
+		}
+		mustJSON(t, resp.Body.Bytes(), &got)
+		return got.Total
+	}
+
+	// Validation: an empty filter, an unknown status or no target at all is rejected rather than hitting everything.
+	bulk(`{"action":"delete","filter":{}}`, http.StatusBadRequest)
+	bulk(`{"action":"delete","filter":{"status":"typo"}}`, http.StatusBadRequest)
+	bulk(`{"action":"unpublish","filter":{"status":"typo","category":"gown"}}`, http.StatusBadRequest)
+	bulk(`{"action":"delete"}`, http.StatusBadRequest)
+	bulk(`{"action":"archive","ids":[1]}`, http.StatusBadRequest)
+	bulk(`{"action":"set_season","ids":[1],"season":"spring"}`, http.StatusBadRequest)
+
+	// Publish by IDs; unknown IDs are reported, the rest applied.
+	{
+		got := bulk(fmt.Sprintf(`{"action":"publish","ids":[%d,%d,%d,999]}`, ids[0], ids[1], ids[3]), http.StatusOK)
+		if got.Total != 4 || got.Changed != 3 || got.Results[3].ID != 999 || got.Results[3].Result != "not_found" {
+			t.Fatalf("unexpected publish result: %+v", got)
+		}
+		if n := publicTotal(); n != 3 {
+			t.Fatalf("expected 3 public products, got %d", n)
+		}
+
+		// Already published: nothing to do.
+		got = bulk(fmt.Sprintf(`{"action":"publish","ids":[%d]}`, ids[0]), http.StatusOK)
+		if got.Changed != 0 || got.Results[0].Result != "unchanged" {
+			t.Fatalf("unexpected re-publish result: %+v", got)
+		}
+	}
+
+	// set_season by filter: published products are drafted, unpublished ones change directly.
+	{
+		got := bulk(`{"action":"set_season","filter":{"season":"ss25"},"season":"FW26"}`, http.StatusOK)
+		if got.Total != 3 || got.Changed != 3 {
+			t.Fatalf("unexpected set_season result: %+v", got)
+		}
+		byID := map[uint]string{}
+		for _, res := range got.Results {
+			byID[res.ID] = res.Result
+		}
+		if byID[ids[0]] != "drafted" || byID[ids[1]] != "drafted" || byID[ids[2]] != "ok" {
+			t.Fatalf("unexpected set_season results: %+v", got)
+		}
+		var unpublished, published model.Product
+		if err := db.First(&unpublished, ids[2]).Error; err != nil {
+			t.Fatalf("load product: %v", err)
+		}
+		if unpublished.Season != "fw26" {
+			t.Fatalf("expected season fw26, got %q", unpublished.Season)
+		}
+		if err := db.First(&published, ids[0]).Error; err != nil {
+			t.Fatalf("load product: %v", err)
+		}
+		if published.Season != "ss25" {
+			t.Fatalf("expected live season unchanged, got %q", published.Season)
+		}
+	}
+
+	// Unpublish everything published, then delete by filter.
+	{
+		got := bulk(`{"action":"unpublish","filter":{"status":"published"}}`, http.StatusOK)
+		if got.Changed != 3 {
+			t.Fatalf("unexpected unpublish result: %+v", got)
+		}
+		if n := publicTotal(); n != 0 {
+			t.Fatalf("expected no public products, got %d", n)
+		}
+
+		got = bulk(`{"action":"delete","filter":{"category":"gown"}}`, http.StatusOK)
+		if got.Changed != 4 {
+			t.Fatalf("unexpected delete result: %+v", got)
+		}
+		var live int64
+		if err := db.Model(&model.Product{}).Where("deleted_at IS NULL").Count(&live).Error; err != nil {
+			t.Fatalf("count products: %v", err)
+		}
+		if live != 0 {
+			t.Fatalf("expected all products deleted, %d left", live)
+		}
+	}
+
+	var audits int64
+	if err := db.Model(&model.AuditLog{}).Where("action = ?", "product.delete").Count(&audits).Error; err != nil {
+		t.Fatalf("count audit: %v", err)
+	}
+	if audits != 4 {
+		t.Fatalf("expected one audit entry per deleted product, got %d", audits)
+	}
+}
+
+func TestRouter_AdminTrash(t *testing.T) {
+	db := openTestDB(t)
+
+	var removed []string
+	purger := trash.NewPurger(db, trash.RemoverFunc(func(_ context.Context, key string) error {
+		removed = append(removed, key)
+		return nil
+	}), 30*24*time.Hour, nil)
+
+	publicCache := cache.NewPublicCache(nil)
+
+	r := newAdminRouter(t, db, func(deps *Dependencies) {
+		deps.Public.Contacts = publicHandlers.NewContactsHandler(db)
+		deps.Public.Events = publicHandlers.NewEventsHandler(db)
+		deps.Admin.Products = adminHandlers.NewProductsHandler(db, publicCache)
+		deps.Admin.Contacts = adminHandlers.NewContactsHandler(db)
+		deps.Admin.Events = adminHandlers.NewEventsHandler(db)
+		deps.Admin.Trash = adminHandlers.NewTrashHandler(db, publicCache, nil, purger)
+	})
+
+	adminToken := loginAdmin(t, r, testAdminEmail, testAdminPassword)
+	auth := withAuth(jsonHeaders(), adminToken)
+
+	expect := func(resp *httptest.ResponseRecorder, code int) {
+		t.Helper()
+		if resp.Code != code {
+			t.Fatalf("expected %d, got %d: %s", code, resp.Code, resp.Body.String())
+		}
+	}
+
+	var productIDs []uint
+	for _, body := range []string{
+		`{"styleNo":"9601","season":"ss25","category":"gown","availability":"in_stock","coverImageKey":"products/9601.jpg","hoverImageKey":"products/shared.jpg"}`,
+		`{"styleNo":"9602","season":"ss25","category":"gown","availability":"in_stock","coverImageKey":"products/shared.jpg"}`,
+	} {
+		resp := doRequest(t, r, http.MethodPost, "/api/v1/admin/products", []byte(body), auth)
+		expect(resp, http.StatusCreated)
+		var got map[string]any
+		mustJSON(t, resp.Body.Bytes(), &got)
+		productIDs = append(productIDs, mustUintFromJSONNumber(t, got["id"]))
+	}
+	expect(doRequest(t, r, http.MethodPost, "/api/v1/contacts", []byte(`{"name":"Alice","phone":"13800000000","message":"hi"}`), jsonHeaders()), http.StatusCreated)
+	expect(doRequest(t, r, http.MethodPost, "/api/v1/events", []byte(`{"event_type":"view","page_url":"/"}`), jsonHeaders()), http.StatusCreated)
+
+	var lead model.ContactLead
+	if err := db.First(&lead).Error; err != nil {
+		t.Fatalf("load lead: %v", err)
+	}
+	var event model.Event
+	if err := db.First(&event).Error; err != nil {
+		t.Fatalf("load event: %v", err)
+	}
+
+	expect(doRequest(t, r, http.MethodDelete, fmt.Sprintf("/api/v1/admin/products/%d", productIDs[0]), nil, auth), http.StatusNoContent)
+	expect(doRequest(t, r, http.MethodDelete, fmt.Sprintf("/api/v1/admin/contacts/%d", lead.ID), nil, auth), http.StatusNoContent)
+	expect(doRequest(t, r, http.MethodDelete, fmt.Sprintf("/api/v1/admin/events/%d", event.ID), nil, auth), http.StatusNoContent)
+
+	// Deleted leads and events disappear from their lists but stay in the trash.
+	resp := doRequest(t, r, http.MethodGet, "/api/v1/admin/contacts", nil, auth)
+	expect(resp, http.StatusOK)
+	var leads struct {
+		Total int64 `json:"total"`
+	}
+	mustJSON(t, resp.Body.Bytes(), &leads)
+	if leads.Total != 0 {
+		t.Fatalf("expected deleted lead hidden, got %d", leads.Total)
+	}
+	expect(doRequest(t, r, http.MethodGet, fmt.Sprintf("/api/v1/admin/events/%d", event.ID), nil, auth), http.StatusNotFound)
+
+	type trashList struct {
+		Total int64 `json:"total"`
+		Items []struct {
+			Type    string  `json:"type"`
+			ID      uint    `json:"id"`
+			Label   string  `json:"label"`
+			PurgeAt *string `json:"purgeAt"`
+		} `json:"items"`
+	}
+	listTrash := func(query string) trashList {
+		t.Helper()
+		resp := doRequest(t, r, http.MethodGet, "/api/v1/admin/trash"+query, nil, auth)
+		expect(resp, http.StatusOK)
+		var got trashList
+		mustJSON(t, resp.Body.Bytes(), &got)
+		return got
+	}
+
+	all := listTrash("")
+	if all.Total != 3 || len(all.Items) != 3 {
+		t.Fatalf("expected 3 items in trash, got %+v", all)
+	}
+	products := listTrash("?type=products")
+	if products.Total != 1 || products.Items[0].ID != productIDs[0] || products.Items[0].Label != "9601" || products.Items[0].PurgeAt == nil {
+		t.Fatalf("unexpected product trash: %+v", products)
+	}
+	expect(doRequest(t, r, http.MethodGet, "/api/v1/admin/trash?type=nope", nil, auth), http.StatusBadRequest)
+
+	// Restore the lead; restoring it again is a 404.
+	leadPath := fmt.Sprintf("/api/v1/admin/trash/contacts/%d", lead.ID)
+	expect(doRequest(t, r, http.MethodPost, leadPath+"/restore", nil, auth), http.StatusOK)
+	expect(doRequest(t, r, http.MethodPost, leadPath+"/restore", nil, auth), http.StatusNotFound)
+	expect(doRequest(t, r, http.MethodGet, fmt.Sprintf("/api/v1/admin/contacts/%d", lead.ID), nil, auth), http.StatusOK)
+
+	// Live records cannot be purged through the trash.
+	expect(doRequest(t, r, http.MethodDelete, fmt.Sprintf("/api/v1/admin/trash/products/%d", productIDs[1]), nil, auth), http.StatusNotFound)
+
+	// Purging the product keeps the image still used by the other one.
+	expect(doRequest(t, r, http.MethodDelete, fmt.Sprintf("/api/v1/admin/trash/products/%d", productIDs[0]), nil, auth), http.StatusNoContent)
+	if len(removed) != 1 || removed[0] != "products/9601.jpg" {
+		t.Fatalf("expected only products/9601.jpg removed, got %v", removed)
+	}
+	var n int64
+	db.Model(&model.Product{}).Where("id = ?", productIDs[0]).Count(&n)
+	if n != 0 {
+		t.Fatalf("expected product row purged")
+	}
+
+	left := listTrash("")
+	if left.Total != 1 || left.Items[0].Type != "events" {
+		t.Fatalf("expected only the event left in trash, got %+v", left)
+	}
+
+	var audits []model.AuditLog
+	if err := db.Where("action IN ?", []string{"contact.undelete", "product.purge"}).Find(&audits).Error; err != nil {
+		t.Fatalf("load audit: %v", err)
+	}
+	if len(audits) != 2 {
+		t.Fatalf("expected undelete and purge audit entries, got %d", len(audits))
+	}
+}
+
+func TestRouter_Taxonomy(t *testing.T) {
+	db := openTestDB(t)
+
+	publicCache := cache.NewPublicCache(nil)
+
+	r := newAdminRouter(t, db, func(deps *Dependencies) {
+		deps.Public.Taxonomy = publicHandlers.NewTaxonomyHandler(db, publicCache)
+		deps.Admin.Products = adminHandlers.NewProductsHandler(db, publicCache)
+		deps.Admin.Taxonomy = adminHandlers.NewTaxonomyHandler(db, publicCache)
+	})
+
+	adminToken := loginAdmin(t, r, testAdminEmail, testAdminPassword)
+	auth := withAuth(jsonHeaders(), adminToken)
+
+	expect := func(resp *httptest.ResponseRecorder, code int) {
+		t.Helper()
+		if resp.Code != code {
+			t.Fatalf("expected %d, got %d: %s", code, resp.Code, resp.Body.String())
+		}
+	}
+	publicCategories := func() []string {
+		t.Helper()
+		resp := doRequest(t, r, http.MethodGet, "/api/v1/taxonomy?kind=category", nil, nil)
+		expect(resp, http.StatusOK)
+		var got map[string][]struct {
+			Code      string            `json:"code"`
+			LabelI18n map[string]string `json:"labelI18n"`
+		}
+		mustJSON(t, resp.Body.Bytes(), &got)
+		if _, ok := got["season"]; ok {
+			t.Fatalf("expected only categories, got %v", got)
+		}
+		var codes []string
+		for _, it := range got["category"] {
+			codes = append(codes, it.Code)
+		}
+		return codes
+	}
+
+	// Default categories are seeded by the migration.
+	if got := strings.Join(publicCategories(), ","); got != "gown,couture,bridal" {
+		t.Fatalf("unexpected seeded categories: %s", got)
+	}
+	// So are the default seasons, after the unsorted ones added by openTestDB.
+	resp := doRequest(t, r, http.MethodGet, "/api/v1/taxonomy?kind=season", nil, nil)
+	expect(resp, http.StatusOK)
+	var seasons map[string][]struct {
+		Code string `json:"code"`
+	}
+	mustJSON(t, resp.Body.Bytes(), &seasons)
+	var seasonCodes []string
+	for _, it := range seasons["season"] {
+		seasonCodes = append(seasonCodes, it.Code)
+	}
+	if got := strings.Join(seasonCodes, ","); !strings.HasSuffix(got, ",fw25,ss25") {
+		t.Fatalf("expected default seasons fw25, ss25, got %s", got)
+	}
+
+	resp = doRequest(t, r, http.MethodPost, "/api/v1/admin/taxonomy", []byte(`{"kind":"category","code":"Evening_Wear","labelI18n":{"zh":"晚装","en":" Evening Wear "},"sortOrder":5}`), auth)
+	expect(resp, http.StatusCreated)
+	var term model.TaxonomyTerm
+	mustJSON(t, resp.Body.Bytes(), &term)
+	if term.Code != "evening_wear" || !term.Active {
+		t.Fatalf("unexpected term: %+v", term)
+	}
+	expect(doRequest(t, r, http.MethodPost, "/api/v1/admin/taxonomy", []byte(`{"kind":"category","code":"evening_wear"}`), auth), http.StatusConflict)
+	expect(doRequest(t, r, http.MethodPost, "/api/v1/admin/taxonomy", []byte(`{"kind":"season","code":"spring"}`), auth), http.StatusBadRequest)
+	expect(doRequest(t, r, http.MethodPost, "/api/v1/admin/taxonomy", []byte(`{"kind":"color","code":"red"}`), auth), http.StatusBadRequest)
+	expect(doRequest(t, r, http.MethodPost, "/api/v1/admin/taxonomy", []byte(`{"kind":"category","code":"x","labelI18n":["x"]}`), auth), http.StatusBadRequest)
+
+	if got := strings.Join(publicCategories(), ","); got != "evening_wear,gown,couture,bridal" {
+		t.Fatalf("expected new category first, got %s", got)
+	}
+
+	// Product writes are validated against active terms.
+	expect(doRequest(t, r, http.MethodPost, "/api/v1/admin/products", []byte(`{"styleNo":"9701","season":"ss25","category":"dress","availability":"in_stock"}`), auth), http.StatusBadRequest)
+	expect(doRequest(t, r, http.MethodPost, "/api/v1/admin/products", []byte(`{"styleNo":"9701","season":"ss99","category":"gown","availability":"in_stock"}`), auth), http.StatusBadRequest)
+	resp = doRequest(t, r, http.MethodPost, "/api/v1/admin/products", []byte(`{"styleNo":"9701","season":"SS25","category":"Evening_Wear","availability":"in_stock"}`), auth)
+	expect(resp, http.StatusCreated)
+	var product model.Product
+	mustJSON(t, resp.Body.Bytes(), &product)
+	if product.Season != "ss25" || product.Category != "evening_wear" {
+		t.Fatalf("expected normalized codes, got %s/%s", product.Season, product.Category)
+	}
+	productPath := fmt.Sprintf("/api/v1/admin/products/%d", product.ID)
+
+	// Deactivated terms disappear from menus and new assignments, but products keep them.
+	termPath := fmt.Sprintf("/api/v1/admin/taxonomy/%d", term.ID)
+	expect(doRequest(t, r, http.MethodPatch, termPath, []byte(`{"active":false}`), auth), http.StatusOK)
+	if got := strings.Join(publicCategories(), ","); got != "gown,couture,bridal" {
+		t.Fatalf("expected inactive category hidden, got %s", got)
+	}
+	expect(doRequest(t, r, http.MethodPost, "/api/v1/admin/products", []byte(`{"styleNo":"9702","season":"ss25","category":"evening_wear","availability":"in_stock"}`), auth), http.StatusBadRequest)
+	expect(doRequest(t, r, http.MethodPatch, productPath, []byte(`{"category":"evening_wear","newRank":3}`), auth), http.StatusOK)
+	expect(doRequest(t, r, http.MethodPatch, productPath, []byte(`{"category":"nope"}`), auth), http.StatusBadRequest)
+
+	// The admin list includes inactive terms.
+	resp = doRequest(t, r, http.MethodGet, "/api/v1/admin/taxonomy?kind=category", nil, auth)
+	expect(resp, http.StatusOK)
+	var list struct {
+		Total int `json:"total"`
+	}
+	mustJSON(t, resp.Body.Bytes(), &list)
+	if list.Total != 4 {
+		t.Fatalf("expected 4 categories, got %d", list.Total)
+	}
+
+	// Terms in use cannot be deleted.
+	expect(doRequest(t, r, http.MethodDelete, termPath, nil, auth), http.StatusConflict)
+	expect(doRequest(t, r, http.MethodPatch, productPath, []byte(`{"category":"bridal"}`), auth), http.StatusOK)
+	expect(doRequest(t, r, http.MethodDelete, termPath, nil, auth), http.StatusNoContent)
+	expect(doRequest(t, r, http.MethodDelete, termPath, nil, auth), http.StatusNotFound)
+}
+
+func TestRouter_ProductsSearch(t *testing.T) {
+	db := openTestDB(t)
+
+	publicCache := cache.NewPublicCache(nil)
+
+	r := newAdminRouter(t, db, func(deps *Dependencies) {
+		deps.Public.Products = publicHandlers.NewProductsHandler(db, publicCache)
+		deps.Admin.Products = adminHandlers.NewProductsHandler(db, publicCache)
+	})
+
+	adminToken := loginAdmin(t, r, testAdminEmail, testAdminPassword)
+	auth := withAuth(jsonHeaders(), adminToken)
+
+	for _, body := range []string{
+		`{"styleNo":"9801","season":"ss25","category":"gown","availability":"in_stock","detail":{"title_i18n":{"zh":"象牙白真丝鱼尾","en":"Ivory Silk Mermaid"}}}`,
+		`{"styleNo":"9802","season":"ss25","category":"gown","availability":"in_stock","detail":{"title_i18n":{"zh":"黑色薄纱","en":"Black Tulle"},"description_i18n":{"en":"Ivory lining"}}}`,
+		`{"styleNo":"9803","season":"ss25","category":"gown","availability":"in_stock","detail":{"title_i18n":{"en":"Ivory Silk Column"}}}`,
+	} {
+		resp := doRequest(t, r, http.MethodPost, "/api/v1/admin/products", []byte(body), auth)
+		if resp.Code != http.StatusCreated {
+			t.Fatalf("expected %d, got %d: %s", http.StatusCreated, resp.Code, resp.Body.String())
+		}
+		var got map[string]any
+		mustJSON(t, resp.Body.Bytes(), &got)
+		// 9803 stays unpublished.
+		if got["styleNo"] == "9803" {
+			continue
+		}
+		path := fmt.Sprintf("/api/v1/admin/products/%d/publish", mustUintFromJSONNumber(t, got["id"]))
+		if resp := doRequest(t, r, http.MethodPost, path, nil, auth); resp.Code != http.StatusOK {
+			t.Fatalf("publish: expected %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+		}
+	}
+
+	styles := func(path string, headers map[string]string) []string {
+		t.Helper()
+		resp := doRequest(t, r, http.MethodGet, path, nil, headers)
+		if resp.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+		}
+		var got struct {
+			Total int64 `json:"total"`
+			Items []struct {
+				StyleNo string `json:"styleNo"`
+			} `json:"items"`
+		}
+		mustJSON(t, resp.Body.Bytes(), &got)
+		var out []string
+		for _, it := range got.Items {
+			out = append(out, it.StyleNo)
+		}
+		if int(got.Total) != len(out) {
+			t.Fatalf("expected total %d to match items %v", got.Total, out)
+		}
+		return out
+	}
+
+	if got := strings.Join(styles("/api/v1/products?q=Ivory%20Silk", nil), ","); got != "9801" {
+		t.Fatalf("expected only the published match, got %s", got)
+	}
+	if got := strings.Join(styles("/api/v1/products?q=ivory", nil), ","); got != "9802,9801" {
+		t.Fatalf("expected description matches too, got %s", got)
+	}
+	if got := strings.Join(styles("/api/v1/products?q=%E9%B1%BC%E5%B0%BE", nil), ","); got != "9801" {
+		t.Fatalf("expected zh title match, got %s", got)
+	}
+	if got := styles("/api/v1/products?q=9802&category=bridal", nil); len(got) != 0 {
+		t.Fatalf("expected search combined with filters, got %v", got)
+	}
+	if got := strings.Join(styles("/api/v1/products?q=9802", nil), ","); got != "9802" {
+		t.Fatalf("expected style number match, got %s", got)
+	}
+
+	// Admin search includes unpublished products.
+	if got := strings.Join(styles("/api/v1/admin/products?q=ivory+silk", auth), ","); got != "9803,9801" {
+		t.Fatalf("unexpected admin search: %s", got)
+	}
+
+	// Bulk filters accept the same query.
+	resp := doRequest(t, r, http.MethodPost, "/api/v1/admin/products/bulk", []byte(`{"action":"unpublish","filter":{"q":"mermaid"}}`), auth)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+	}
+	if got := strings.Join(styles("/api/v1/products", nil), ","); got != "9802" {
+		t.Fatalf("expected 9801 unpublished, got %s", got)
+	}
+}
+
+func TestRouter_ListCursorPaging(t *testing.T) {
+	db := openTestDB(t)
+
+	publicCache := cache.NewPublicCache(nil)
+
+	r := newAdminRouter(t, db, func(deps *Dependencies) {
+		deps.Public.Products = publicHandlers.NewProductsHandler(db, publicCache)
+		deps.Public.Updates = publicHandlers.NewUpdatesHandler(db, publicCache)
+		deps.Admin.Products = adminHandlers.NewProductsHandler(db, publicCache)
+		deps.Admin.Updates = adminHandlers.NewUpdatesHandler(db, publicCache)
+	})
+
+	adminToken := loginAdmin(t, r, testAdminEmail, testAdminPassword)
+	auth := withAuth(jsonHeaders(), adminToken)
+
+	for _, styleNo := range []string{"9903", "9901", "9905", "9902", "9904"} {
+		body := fmt.Sprintf(`{"styleNo":%q,"season":"ss25","category":"gown","availability":"in_stock"}`, styleNo)
+		resp := doRequest(t, r, http.MethodPost, "/api/v1/admin/products", []byte(body), auth)
+		if resp.Code != http.StatusCreated {
+			t.Fatalf("expected %d, got %d: %s", http.StatusCreated, resp.Code, resp.Body.String())
+		}
+		var got map[string]any
+		mustJSON(t, resp.Body.Bytes(), &got)
+		path := fmt.Sprintf("/api/v1/admin/products/%d/publish", mustUintFromJSONNumber(t, got["id"]))
+		if resp := doRequest(t, r, http.MethodPost, path, nil, auth); resp.Code != http.StatusOK {
+			t.Fatalf("publish: expected %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+		}
+	}
+	for i := 1; i <= 3; i++ {
+		body := fmt.Sprintf(`{"type":"company","title":"Update %d","body":"Body"}`, i)
+		resp := doRequest(t, r, http.MethodPost, "/api/v1/admin/updates", []byte(body), auth)
+		if resp.Code != http.StatusCreated {
+			t.Fatalf("expected %d, got %d: %s", http.StatusCreated, resp.Code, resp.Body.String())
+		}
+		var got map[string]any
+		mustJSON(t, resp.Body.Bytes(), &got)
+		path := fmt.Sprintf("/api/v1/admin/updates/%d/publish", mustUintFromJSONNumber(t, got["id"]))
+		if resp := doRequest(t, r, http.MethodPost, path, nil, auth); resp.Code != http.StatusOK {
+			t.Fatalf("publish: expected %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+		}
+	}
+
+	// walk follows next_cursor from the first page and returns the field of every item.
+	walk := func(path, field string, headers map[string]string) []string {
+		t.Helper()
+		var out []string
+		cursor := ""
+		for pages := 0; pages < 10; pages++ {
+			resp := doRequest(t, r, http.MethodGet, path+"&cursor="+url.QueryEscape(cursor), nil, headers)
+			if resp.Code != http.StatusOK {
+				t.Fatalf("expected %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+			}
+			var got map[string]any
+			mustJSON(t, resp.Body.Bytes(), &got)
+			if _, ok := got["total"]; ok {
+				t.Fatalf("expected no total in cursor mode: %s", resp.Body.String())
+			}
+			for _, it := range got["items"].([]any) {
+				out = append(out, fmt.Sprint(it.(map[string]any)[field]))
+			}
+			next, _ := got["next_cursor"].(string)
+			if next == "" {
+				return out
+			}
+			cursor = next
+		}
+		t.Fatalf("paging did not terminate")
+		return nil
+	}
+
+	if got := walk("/api/v1/admin/products?sort=style_no&limit=2", "styleNo", auth); strings.Join(got, ",") != "9901,9902,9903,9904,9905" {
+		t.Fatalf("unexpected admin style_no pages: %v", got)
+	}
+	// Default order is id desc among products with equal is_new/new_rank: creation order reversed.
+	if got := walk("/api/v1/products?limit=2", "styleNo", nil); strings.Join(got, ",") != "9904,9902,9905,9901,9903" {
+		t.Fatalf("unexpected public default pages: %v", got)
+	}
+	if got := walk("/api/v1/products?sort=style_no&limit=3", "styleNo", nil); strings.Join(got, ",") != "9901,9902,9903,9904,9905" {
+		t.Fatalf("unexpected public style_no pages: %v", got)
+	}
+	if got := walk("/api/v1/updates?limit=2", "title", nil); strings.Join(got, ",") != "Update 3,Update 2,Update 1" {
+		t.Fatalf("unexpected public updates pages: %v", got)
+	}
+
+	// Offset paging keeps total and also hands out a cursor for the next page.
+	{
+		resp := doRequest(t, r, http.MethodGet, "/api/v1/admin/products?sort=style_no&limit=2&offset=2", nil, auth)
+		if resp.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+		}
+		var got map[string]any
+		mustJSON(t, resp.Body.Bytes(), &got)
+		if mustUintFromJSONNumber(t, got["total"]) != 5 {
+			t.Fatalf("expected total=5, got %#v", got["total"])
+		}
+		next, _ := got["next_cursor"].(string)
+		if next == "" {
+			t.Fatalf("expected next_cursor: %s", resp.Body.String())
+		}
+		resp = doRequest(t, r, http.MethodGet, "/api/v1/admin/products?sort=style_no&limit=2&cursor="+url.QueryEscape(next), nil, auth)
+		mustJSON(t, resp.Body.Bytes(), &got)
+		items := got["items"].([]any)
+		if len(items) != 1 || items[0].(map[string]any)["styleNo"] != "9905" {
+			t.Fatalf("unexpected page after offset cursor: %s", resp.Body.String())
+		}
+	}
+
+	for _, tc := range []struct {
+		path    string
+		headers map[string]string
+	}{
+		{"/api/v1/admin/products?sort=price", auth},
+		{"/api/v1/admin/updates?cursor=garbage", auth},
+		{"/api/v1/products?sort=style_no&cursor=garbage", nil},
+		{"/api/v1/updates?sort=oldest", nil},
+		// Relevance order has no cursor.
+		{"/api/v1/products?q=99&cursor=", nil},
+		{"/api/v1/admin/products?q=99&cursor=", auth},
+	} {
+		if resp := doRequest(t, r, http.MethodGet, tc.path, nil, tc.headers); resp.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected %d, got %d: %s", tc.path, http.StatusBadRequest, resp.Code, resp.Body.String())
+		}
+	}
+
+	// A cursor issued for one sort is not accepted by another.
+	{
+		resp := doRequest(t, r, http.MethodGet, "/api/v1/admin/products?sort=style_no&limit=1&cursor=", nil, auth)
+		var got map[string]any
+		mustJSON(t, resp.Body.Bytes(), &got)
+		next, _ := got["next_cursor"].(string)
+		resp = doRequest(t, r, http.MethodGet, "/api/v1/admin/products?sort=newest&cursor="+url.QueryEscape(next), nil, auth)
+		if resp.Code != http.StatusBadRequest {
+			t.Fatalf("expected %d, got %d: %s", http.StatusBadRequest, resp.Code, resp.Body.String())
+		}
+	}
+}
+
+func TestRouter_ProductsFacets(t *testing.T) {
+	db := openTestDB(t)
+
+	publicCache := cache.NewPublicCache(nil)
+
+	r := newAdminRouter(t, db, func(deps *Dependencies) {
+		deps.Public.Products = publicHandlers.NewProductsHandler(db, publicCache)
+		deps.Admin.Products = adminHandlers.NewProductsHandler(db, publicCache)
+	})
+
+	adminToken := loginAdmin(t, r, testAdminEmail, testAdminPassword)
+	auth := withAuth(jsonHeaders(), adminToken)
+
+	for _, tc := range []struct {
+		body    string
+		publish bool
+	}{
+		{`{"styleNo":"9701","season":"ss25","category":"gown","availability":"in_stock","isNew":true}`, true},
+		{`{"styleNo":"9702","season":"ss25","category":"bridal","availability":"preorder"}`, true},
+		{`{"styleNo":"9703","season":"fw25","category":"gown","availability":"in_stock"}`, true},
+		{`{"styleNo":"9704","season":"fw25","category":"gown","availability":"in_stock"}`, true},
+		// Drafts are not counted.
+		{`{"styleNo":"9705","season":"ss25","category":"couture","availability":"in_stock"}`, false},
+	} {
+		resp := doRequest(t, r, http.MethodPost, "/api/v1/admin/products", []byte(tc.body), auth)
+		if resp.Code != http.StatusCreated {
+			t.Fatalf("expected %d, got %d: %s", http.StatusCreated, resp.Code, resp.Body.String())
+		}
+		if !tc.publish {
+			continue
+		}
+		var got map[string]any
+		mustJSON(t, resp.Body.Bytes(), &got)
+		path := fmt.Sprintf("/api/v1/admin/products/%d/publish", mustUintFromJSONNumber(t, got["id"]))
+		if resp := doRequest(t, r, http.MethodPost, path, nil, auth); resp.Code != http.StatusOK {
+			t.Fatalf("publish: expected %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+		}
+	}
+
+	type facetCount struct {
+		Value string `json:"value"`
+		Count int64  `json:"count"`
+	}
+	type listResp struct {
+		Total  int64                   `json:"total"`
+		Items  []map[string]any        `json:"items"`
+		Facets map[string][]facetCount `json:"facets"`
+	}
+	get := func(path string) listResp {
+		t.Helper()
+		resp := doRequest(t, r, http.MethodGet, path, nil, nil)
+		if resp.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+		}
+		var got listResp
+		mustJSON(t, resp.Body.Bytes(), &got)
+		return got
+	}
+	counts := func(fs []facetCount) string {
+		parts := make([]string, 0, len(fs))
+		for _, f := range fs {
+			parts = append(parts, fmt.Sprintf("%s=%d", f.Value, f.Count))
+		}
+		return strings.Join(parts, ",")
+	}
+
+	if got := get("/api/v1/products"); got.Facets != nil {
+		t.Fatalf("expected no facets unless requested, got %#v", got.Facets)
+	}
+
+	// Each facet ignores its own filter: selecting ss25 still shows fw25 and its count.
+	got := get("/api/v1/products?facets=true&season=ss25&limit=1")
+	if got.Total != 2 || len(got.Items) != 1 {
+		t.Fatalf("unexpected listing: total=%d items=%d", got.Total, len(got.Items))
+	}
+	for facet, want := range map[string]string{
+		"season":       "fw25=2,ss25=2",
+		"category":     "bridal=1,gown=1",
+		"availability": "in_stock=1,preorder=1",
+		"is_new":       "true=1,false=1",
+	} {
+		if c := counts(got.Facets[facet]); c != want {
+			t.Fatalf("facet %s: expected %s, got %s", facet, want, c)
+		}
+	}
+
+	got = get("/api/v1/products?facets=true&category=gown&availability=in_stock")
+	for facet, want := range map[string]string{
+		"season":       "fw25=2,ss25=1",
+		"category":     "gown=3",
+		"availability": "in_stock=3",
+		"is_new":       "true=1,false=2",
+	} {
+		if c := counts(got.Facets[facet]); c != want {
+			t.Fatalf("facet %s: expected %s, got %s", facet, want, c)
+		}
+	}
+}
+
+func TestRouter_AdminMedia(t *testing.T) {
+	db := openTestDB(t)
+
+	r := newAdminRouter(t, db, func(deps *Dependencies) {
+		deps.Admin.Media = adminHandlers.NewMediaHandler(db)
+		deps.Admin.Products = adminHandlers.NewProductsHandler(db, cache.NewPublicCache(nil))
+	})
+
+	adminToken := loginAdmin(t, r, testAdminEmail, testAdminPassword)
+	auth := withAuth(jsonHeaders(), adminToken)
+
+	const usedBase = "products/M1/cover/2026/02/01/u1"
+	assets := []model.Asset{
+		{ObjectKey: usedBase + "/w1600.jpg", Base: usedBase, Kind: "cover", StyleNo: "M1", Size: 300},
+		{ObjectKey: "products/M1/hover/2026/02/01/u2/w768.jpg", Base: "products/M1/hover/2026/02/01/u2", Kind: "hover", StyleNo: "M1", Size: 100},
+		{ObjectKey: "products/M2/cover/2026/02/01/u3/w768.jpg", Base: "products/M2/cover/2026/02/01/u3", Kind: "cover", StyleNo: "M2", Size: 200},
+	}
+	for i := range assets {
+		if err := db.Create(&assets[i]).Error; err != nil {
+			t.Fatalf("create asset: %v", err)
+		}
+	}
+	body := fmt.Sprintf(`{"styleNo":"M1","season":"ss25","category":"gown","availability":"in_stock","coverImageKey":%q}`, usedBase+"/w320.jpg")
+	if resp := doRequest(t, r, http.MethodPost, "/api/v1/admin/products", []byte(body), auth); resp.Code != http.StatusCreated {
+		t.Fatalf("create product: expected %d, got %d: %s", http.StatusCreated, resp.Code, resp.Body.String())
+	}
+
+	resp := doRequest(t, r, http.MethodGet, "/api/v1/admin/media?styleNo=m1&sort=size", nil, auth)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+	}
+	var got struct {
+		Total int64 `json:"total"`
+		Items []struct {
+			ObjectKey  string `json:"objectKey"`
+			URL        string `json:"url"`
+			References int64  `json:"references"`
+		} `json:"items"`
+	}
+	mustJSON(t, resp.Body.Bytes(), &got)
+	if got.Total != 2 || len(got.Items) != 2 {
+		t.Fatalf("expected the 2 assets of M1, got %s", resp.Body.String())
+	}
+	if got.Items[0].ObjectKey != usedBase+"/w1600.jpg" || got.Items[0].References != 1 || got.Items[0].URL != "/api/v1/assets/"+usedBase+"/w1600.jpg" {
+		t.Fatalf("unexpected first item: %+v", got.Items[0])
+	}
+	if got.Items[1].References != 0 {
+		t.Fatalf("expected the hover image unreferenced: %+v", got.Items[1])
+	}
+
+	if resp := doRequest(t, r, http.MethodGet, "/api/v1/admin/media?kind=banner", nil, auth); resp.Code != http.StatusBadRequest {
+		t.Fatalf("expected %d, got %d", http.StatusBadRequest, resp.Code)
+	}
+	if resp := doRequest(t, r, http.MethodGet, "/api/v1/admin/media?sort=name", nil, auth); resp.Code != http.StatusBadRequest {
+		t.Fatalf("expected %d, got %d", http.StatusBadRequest, resp.Code)
+	}
+}
+
+func TestRouter_ProductAssetsIndex(t *testing.T) {
+	db := openTestDB(t)
+
+	r := newAdminRouter(t, db, func(deps *Dependencies) {
+		deps.Admin.Products = adminHandlers.NewProductsHandler(db, cache.NewPublicCache(nil))
+	})
+
+	adminToken := loginAdmin(t, r, testAdminEmail, testAdminPassword)
+	auth := withAuth(jsonHeaders(), adminToken)
+
+	indexed := func(id uint) []string {
+		t.Helper()
+		var keys []string
+		if err := db.Model(&model.ProductAsset{}).Where("product_id = ?", id).Order("object_key asc").Pluck("object_key", &keys).Error; err != nil {
+			t.Fatalf("list product assets: %v", err)
+		}
+		return keys
+	}
+
+	body := `{"styleNo":"P1","season":"ss25","category":"gown","availability":"in_stock",
+		"coverImageKey":"products/P1/cover/a/w1600.jpg",
+		"detail":{"sections":[{"images":["/api/v1/assets/products/P1/gallery/b/w768.jpg"]}]}}`
+	resp := doRequest(t, r, http.MethodPost, "/api/v1/admin/products", []byte(body), auth)
+	if resp.Code != http.StatusCreated {
+		t.Fatalf("expected %d, got %d: %s", http.StatusCreated, resp.Code, resp.Body.String())
+	}
+	var created map[string]any
+	mustJSON(t, resp.Body.Bytes(), &created)
+	id := mustUintFromJSONNumber(t, created["id"])
+	if got := indexed(id); len(got) != 2 || got[0] != "products/P1/cover/a/w1600.jpg" || got[1] != "products/P1/gallery/b/w768.jpg" {
+		t.Fatalf("unexpected index after create: %v", got)
+	}
+
+	// Replacing the cover of an unpublished product updates the index at once.
+	path := fmt.Sprintf("/api/v1/admin/products/%d", id)
+	if resp := doRequest(t, r, http.MethodPatch, path, []byte(`{"coverImageKey":"products/P1/cover/c/w1600.jpg"}`), auth); resp.Code != http.StatusOK {
+		t.Fatalf("update: expected %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+	}
+	if got := indexed(id); len(got) != 2 || got[0] != "products/P1/cover/c/w1600.jpg" {
+		t.Fatalf("unexpected index after update: %v", got)
+	}
+
+	// Published products are edited through a draft; the index follows the live row.
+	if resp := doRequest(t, r, http.MethodPost, path+"/publish", nil, auth); resp.Code != http.StatusOK {
+		t.Fatalf("publish: expected %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+	}
+	if resp := doRequest(t, r, http.MethodPatch, path, []byte(`{"hoverImageKey":"products/P1/hover/d/w1600.jpg"}`), auth); resp.Code != http.StatusAccepted {
+		t.Fatalf("draft update: expected %d, got %d: %s", http.StatusAccepted, resp.Code, resp.Body.String())
+	}
+	if got := indexed(id); len(got) != 2 {
+		t.Fatalf("expected the draft not to be indexed, got %v", got)
+	}
+	if resp := doRequest(t, r, http.MethodPost, path+"/publish-changes", nil, auth); resp.Code != http.StatusOK {
+		t.Fatalf("publish changes: expected %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+	}
+	if got := indexed(id); len(got) != 3 || got[2] != "products/P1/hover/d/w1600.jpg" {
+		t.Fatalf("unexpected index after publishing changes: %v", got)
+	}
+}
+
+type captureMailer struct {
+	mu   sync.Mutex
+	sent []mail.Message
+}
+
+func (m *captureMailer) Send(_ context.Context, msg mail.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+// wait returns the captured messages once at least n arrived (mail is sent asynchronously).
+func (m *captureMailer) wait(t *testing.T, n int) []mail.Message {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		m.mu.Lock()
+		sent := append([]mail.Message(nil), m.sent...)
+		m.mu.Unlock()
+		if len(sent) >= n || time.Now().After(deadline) {
+			return sent
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// The admin account created by newAdminRouter.
+const (
+	testAdminEmail    = "admin@example.com"
+	testAdminPassword = "passw0rd123"
+)
+
+// testJWTService returns the JWT service used by newAdminRouter. Services built from the same
+// config accept each other's tokens.
+func testJWTService(t *testing.T) *jwtauth.Service {
+	t.Helper()
+
+	jwtCfg := config.JWTConfig{Secret: "test-secret", Issuer: "evening-gown", ExpiresIn: time.Hour, RefreshExpiresIn: 24 * time.Hour}
+	jwtSvc, err := jwtauth.New(jwtCfg)
+	if err != nil {
+		t.Fatalf("create jwt service: %v", err)
+	}
+	return jwtSvc
+}
+
+// newAdminRouter creates the test admin account in db and returns a router with admin login
+// and auth wired to it; configure (optional) adds the handlers under test or replaces them.
+func newAdminRouter(t *testing.T, db *gorm.DB, configure func(*Dependencies)) *gin.Engine {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+	if err := bootstrap.EnsureSingleAdmin(db, testAdminEmail, testAdminPassword); err != nil {
+		t.Fatalf("ensure admin: %v", err)
+	}
+
+	jwtSvc := testJWTService(t)
+	var deps Dependencies
+	deps.Admin.Auth = adminHandlers.NewAuthHandler(db, jwtSvc)
+	deps.Admin.AuthMiddleware = middleware.AdminAuth(db, jwtSvc)
+	if configure != nil {
+		configure(&deps)
+	}
+	return New(deps)
+}
+
+func loginAdmin(t *testing.T, h http.Handler, email, password string) string {
+	t.Helper()
+
+	body, _ := json.Marshal(map[string]string{"email": email, "password": password})
+	resp := doRequest(t, h, http.MethodPost, "/api/v1/admin/auth/login", body, jsonHeaders())
+	if resp.Code != http.StatusOK {
+		t.Fatalf("login %s: expected %d, got %d: %s", email, http.StatusOK, resp.Code, resp.Body.String())
+	}
+	var got map[string]any
+	mustJSON(t, resp.Body.Bytes(), &got)
+	token, _ := got["token"].(string)
+	if strings.TrimSpace(token) == "" {
+		t.Fatalf("expected token")
+	}
+	return token
+}
+
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	if err := bootstrap.AutoMigrate(db); err != nil {
+		t.Fatalf("auto migrate: %v", err)
+	}
+	// Product writes only accept seasons that exist in the taxonomy; ss25 / fw25 are defaults.
+	for _, season := range []string{"ss26", "fw26", "ss27"} {
+		term := model.TaxonomyTerm{Kind: model.TaxonomySeason, Code: season, LabelI18n: []byte(`{}`), Active: true}
+		if err := db.Create(&term).Error; err != nil {
+			t.Fatalf("seed season: %v", err)
+		}
 	}
 
 	sqlDB, err := db.DB()
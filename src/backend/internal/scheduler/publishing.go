@@ -0,0 +1,199 @@
+package scheduler
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"evening-gown/internal/audit"
+	"evening-gown/internal/cache"
+	"evening-gown/internal/catalog"
+	"evening-gown/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// publishBatchSize bounds one transaction; remaining rows are picked up by the next loop iteration.
+const publishBatchSize = 100
+
+// Publisher applies due publish_at / unpublish_at schedules on products and updates.
+//
+// Due rows are claimed with SELECT ... FOR UPDATE SKIP LOCKED on Postgres, and every flip is a
+// conditional UPDATE, so several instances can run it concurrently without double-applying.
+type Publisher struct {
+	db    *gorm.DB
+	cache *cache.PublicCache
+	now   func() time.Time
+}
+
+func NewPublisher(db *gorm.DB, publicCache *cache.PublicCache) *Publisher {
+	return &Publisher{db: db, cache: publicCache, now: time.Now}
+}
+
+// PublishResult counts the rows flipped by one Run.
+type PublishResult struct {
+	ProductsPublished   int
+	ProductsUnpublished int
+	UpdatesPublished    int
+	UpdatesUnpublished  int
+}
+
+// Run applies every schedule that is due. It implements Job.Run.
+func (p *Publisher) Run(ctx context.Context) error {
+	_, err := p.RunOnce(ctx)
+	return err
+}
+
+// RunOnce applies every due schedule and reports what changed.
+func (p *Publisher) RunOnce(ctx context.Context) (PublishResult, error) {
+	var res PublishResult
+	if p == nil || p.db == nil {
+		return res, nil
+	}
+	now := p.now().UTC()
+	ctx = audit.WithActor(ctx, audit.Actor{Email: "scheduler", Role: "system"})
+
+	productID := func(v model.Product) uint { return v.ID }
+	updateID := func(v model.UpdatePost) uint { return v.ID }
+
+	// Publish before unpublish: when both are overdue (e.g. after downtime) the item ends unpublished.
+	var err error
+	if res.ProductsPublished, err = applyDue(ctx, p.db, now, flip{
+		action:     "product.scheduled_publish",
+		entityType: "products",
+		column:     "publish_at",
+		updates: func(due time.Time) map[string]any {
+			return map[string]any{"published_at": due, "publish_at": nil}
+		},
+		// Edits saved while the product was unpublished go live with it, as in Publish.
+		then: func(ctx context.Context, tx *gorm.DB, id uint) error {
+			_, _, err := catalog.PromotePendingDraft(ctx, tx, id)
+			return err
+		},
+	}, productID, func(v model.Product) *time.Time { return v.PublishAt }); err != nil {
+		return res, err
+	}
+	if res.ProductsUnpublished, err = applyDue(ctx, p.db, now, flip{
+		action:     "product.scheduled_unpublish",
+		entityType: "products",
+		column:     "unpublish_at",
+		updates: func(time.Time) map[string]any {
+			return map[string]any{"published_at": nil, "unpublish_at": nil}
+		},
+	}, productID, func(v model.Product) *time.Time { return v.UnpublishAt }); err != nil {
+		return res, err
+	}
+	if res.UpdatesPublished, err = applyDue(ctx, p.db, now, flip{
+		action:     "update.scheduled_publish",
+		entityType: "updates",
+		column:     "publish_at",
+		updates: func(due time.Time) map[string]any {
+			return map[string]any{"status": "published", "published_at": due, "publish_at": nil}
+		},
+	}, updateID, func(v model.UpdatePost) *time.Time { return v.PublishAt }); err != nil {
+		return res, err
+	}
+	if res.UpdatesUnpublished, err = applyDue(ctx, p.db, now, flip{
+		action:     "update.scheduled_unpublish",
+		entityType: "updates",
+		column:     "unpublish_at",
+		updates: func(time.Time) map[string]any {
+			return map[string]any{"status": "draft", "published_at": nil, "unpublish_at": nil}
+		},
+	}, updateID, func(v model.UpdatePost) *time.Time { return v.UnpublishAt }); err != nil {
+		return res, err
+	}
+
+	if p.cache != nil {
+		if res.ProductsPublished+res.ProductsUnpublished > 0 {
+			_, _ = p.cache.BumpProductsVersion(ctx)
+			// Flipped rows are not tracked individually; assume they reference images.
+			_, _ = p.cache.BumpAssetsVersion(ctx)
+		}
+		if res.UpdatesPublished+res.UpdatesUnpublished > 0 {
+			_, _ = p.cache.BumpUpdatesVersion(ctx)
+		}
+	}
+	return res, nil
+}
+
+type flip struct {
+	action     string
+	entityType string
+	// column holds the schedule timestamp (publish_at | unpublish_at).
+	column string
+	// updates returns the columns to set for a row scheduled at due.
+	updates func(due time.Time) map[string]any
+	// then, if set, runs in the same transaction after a row was flipped.
+	then func(ctx context.Context, tx *gorm.DB, id uint) error
+}
+
+// applyDue flips every row of T whose f.column is due, in batches, and returns how many changed.
+func applyDue[T any](ctx context.Context, db *gorm.DB, now time.Time, f flip, idOf func(T) uint, dueOf func(T) *time.Time) (int, error) {
+	total := 0
+	for {
+		n, claimed, err := applyDueBatch(ctx, db, now, f, idOf, dueOf)
+		total += n
+		if err != nil || claimed < publishBatchSize || n == 0 {
+			return total, err
+		}
+	}
+}
+
+func applyDueBatch[T any](ctx context.Context, db *gorm.DB, now time.Time, f flip, idOf func(T) uint, dueOf func(T) *time.Time) (int, int, error) {
+	changed, claimed := 0, 0
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		q := tx.Where(f.column+" IS NOT NULL AND "+f.column+" <= ? AND deleted_at IS NULL", now).
+			Order("id asc").
+			Limit(publishBatchSize)
+		if tx.Dialector.Name() == "postgres" {
+			// Rows claimed by another instance are skipped instead of waited on.
+			q = q.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"})
+		}
+		var due []T
+		if err := q.Find(&due).Error; err != nil {
+			return err
+		}
+		claimed = len(due)
+
+		for _, before := range due {
+			id := idOf(before)
+			at := now
+			if d := dueOf(before); d != nil {
+				at = d.UTC()
+			}
+			res := tx.Model(new(T)).
+				Where("id = ? AND "+f.column+" IS NOT NULL AND "+f.column+" <= ?", id, now).
+				Updates(f.updates(at))
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected == 0 {
+				continue
+			}
+			changed++
+			if f.then != nil {
+				if err := f.then(ctx, tx, id); err != nil {
+					return err
+				}
+			}
+
+			var after T
+			if err := tx.First(&after, id).Error; err != nil {
+				return err
+			}
+			if err := audit.Record(ctx, tx, audit.Entry{
+				Action:     f.action,
+				EntityType: f.entityType,
+				EntityID:   strconv.FormatUint(uint64(id), 10),
+				Before:     before,
+				After:      after,
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return changed, claimed, err
+}
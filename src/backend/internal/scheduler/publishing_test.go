@@ -0,0 +1,146 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"evening-gown/internal/model"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err == nil {
+		t.Cleanup(func() { _ = sqlDB.Close() })
+	}
+	if err := db.AutoMigrate(&model.Product{}, &model.ProductDraft{}, &model.ProductRevision{}, &model.ProductVariant{}, &model.ProductAsset{}, &model.UpdatePost{}, &model.AuditLog{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return db
+}
+
+func TestPublisher_AppliesDueSchedules(t *testing.T) {
+	db := openTestDB(t)
+	now := time.Date(2026, 3, 1, 9, 0, 30, 0, time.UTC)
+	launch := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	later := now.Add(24 * time.Hour)
+	past := now.Add(-time.Hour)
+
+	due := model.Product{Slug: "style-a", StyleNo: "A", Season: "ss26", Category: "gown", Availability: "in_stock", PublishAt: &launch, UnpublishAt: &later}
+	notYet := model.Product{Slug: "style-b", StyleNo: "B", Season: "ss26", Category: "gown", Availability: "in_stock", PublishAt: &later}
+	expiring := model.Product{Slug: "style-c", StyleNo: "C", Season: "ss25", Category: "gown", Availability: "in_stock", PublishedAt: &past, UnpublishAt: &launch}
+	deleted := model.Product{Slug: "style-d", StyleNo: "D", Season: "ss26", Category: "gown", Availability: "in_stock", PublishAt: &launch, DeletedAt: &past}
+	for _, p := range []*model.Product{&due, &notYet, &expiring, &deleted} {
+		if err := db.Create(p).Error; err != nil {
+			t.Fatalf("create product: %v", err)
+		}
+	}
+	post := model.UpdatePost{Type: "company", Status: "draft", Title: "Launch", PublishAt: &launch}
+	if err := db.Create(&post).Error; err != nil {
+		t.Fatalf("create update: %v", err)
+	}
+
+	p := NewPublisher(db, nil)
+	p.now = func() time.Time { return now }
+
+	res, err := p.RunOnce(context.Background())
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	want := PublishResult{ProductsPublished: 1, ProductsUnpublished: 1, UpdatesPublished: 1}
+	if res != want {
+		t.Fatalf("expected %+v, got %+v", want, res)
+	}
+
+	load := func(id uint) model.Product {
+		var got model.Product
+		if err := db.First(&got, id).Error; err != nil {
+			t.Fatalf("load product %d: %v", id, err)
+		}
+		return got
+	}
+	got := load(due.ID)
+	if got.PublishedAt == nil || !got.PublishedAt.Equal(launch) || got.PublishAt != nil || got.UnpublishAt == nil {
+		t.Fatalf("expected published at launch time with unpublish kept, got %+v", got)
+	}
+	got = load(notYet.ID)
+	if got.PublishedAt != nil || got.PublishAt == nil {
+		t.Fatalf("expected future schedule untouched, got %+v", got)
+	}
+	got = load(expiring.ID)
+	if got.PublishedAt != nil || got.UnpublishAt != nil {
+		t.Fatalf("expected unpublished, got %+v", got)
+	}
+	got = load(deleted.ID)
+	if got.PublishedAt != nil {
+		t.Fatalf("expected deleted product untouched, got %+v", got)
+	}
+	var gotPost model.UpdatePost
+	db.First(&gotPost, post.ID)
+	if gotPost.Status != "published" || gotPost.PublishedAt == nil || gotPost.PublishAt != nil {
+		t.Fatalf("expected update published, got %+v", gotPost)
+	}
+
+	var logs []model.AuditLog
+	db.Order("id").Find(&logs)
+	if len(logs) != 3 || logs[0].Action != "product.scheduled_publish" || logs[0].ActorRole != "system" {
+		t.Fatalf("unexpected audit entries: %+v", logs)
+	}
+
+	// Nothing is due anymore: a second run (or another instance) is a no-op.
+	if res, err := p.RunOnce(context.Background()); err != nil || res != (PublishResult{}) {
+		t.Fatalf("expected no-op, got %+v err=%v", res, err)
+	}
+}
+
+func TestPublisher_PromotesPendingDraft(t *testing.T) {
+	db := openTestDB(t)
+	now := time.Date(2026, 3, 1, 9, 0, 30, 0, time.UTC)
+	launch := now.Add(-time.Minute)
+
+	product := model.Product{Slug: "style-a", StyleNo: "A", Season: "ss26", Category: "gown", Availability: "in_stock", CoverImageKey: "products/a/old.jpg", DetailJSON: json.RawMessage(`{}`), PublishAt: &launch}
+	if err := db.Create(&product).Error; err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+	// Left over from edits made while the product was unpublished.
+	working := product
+	working.CoverImageKey = "products/a/new.jpg"
+	working.Availability = "preorder"
+	snapshot, err := json.Marshal(working)
+	if err != nil {
+		t.Fatalf("marshal draft: %v", err)
+	}
+	if err := db.Create(&model.ProductDraft{ProductID: product.ID, Snapshot: snapshot}).Error; err != nil {
+		t.Fatalf("create draft: %v", err)
+	}
+
+	p := NewPublisher(db, nil)
+	p.now = func() time.Time { return now }
+	if res, err := p.RunOnce(context.Background()); err != nil || res.ProductsPublished != 1 {
+		t.Fatalf("expected one product published, got %+v err=%v", res, err)
+	}
+
+	var got model.Product
+	if err := db.First(&got, product.ID).Error; err != nil {
+		t.Fatalf("load product: %v", err)
+	}
+	if got.PublishedAt == nil || got.CoverImageKey != "products/a/new.jpg" || got.Availability != "preorder" {
+		t.Fatalf("expected the draft published, got %+v", got)
+	}
+	var drafts, revisions, assets int64
+	db.Model(&model.ProductDraft{}).Count(&drafts)
+	db.Model(&model.ProductRevision{}).Where("product_id = ?", product.ID).Count(&revisions)
+	db.Model(&model.ProductAsset{}).Where("product_id = ? AND object_key = ?", product.ID, "products/a/new.jpg").Count(&assets)
+	if drafts != 0 || revisions != 1 || assets != 1 {
+		t.Fatalf("expected draft promoted (drafts=%d revisions=%d assets=%d)", drafts, revisions, assets)
+	}
+}
@@ -0,0 +1,92 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// Job is a periodic background task.
+//
+// Run must be safe to execute on several instances at once (use row locks or conditional updates).
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// Runner runs jobs on their own tickers until the context passed to Start is cancelled.
+//
+// Nothing runs before Start, so constructing a Runner is free (and goroutine-free) in tests.
+type Runner struct {
+	logger *slog.Logger
+	jobs   []Job
+	wg     sync.WaitGroup
+}
+
+func NewRunner(logger *slog.Logger) *Runner {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Runner{logger: logger}
+}
+
+// Add registers job. Jobs with a non-positive interval are disabled and ignored.
+func (r *Runner) Add(job Job) {
+	if job.Interval <= 0 || job.Run == nil {
+		r.logger.Info("scheduler job disabled", "job", job.Name)
+		return
+	}
+	r.jobs = append(r.jobs, job)
+}
+
+// Start launches one goroutine per job. Each job runs immediately, then every Interval.
+func (r *Runner) Start(ctx context.Context) {
+	for _, job := range r.jobs {
+		r.wg.Add(1)
+		go func(job Job) {
+			defer r.wg.Done()
+			r.loop(ctx, job)
+		}(job)
+	}
+}
+
+// Wait blocks until every job goroutine has returned (after ctx is cancelled).
+func (r *Runner) Wait() {
+	r.wg.Wait()
+}
+
+func (r *Runner) loop(ctx context.Context, job Job) {
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		r.runOnce(ctx, job)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runOnce isolates a single execution: errors and panics are logged, the loop keeps going.
+func (r *Runner) runOnce(ctx context.Context, job Job) {
+	if ctx.Err() != nil {
+		return
+	}
+	l := r.logger.With("job", job.Name)
+	defer func() {
+		if rec := recover(); rec != nil {
+			l.Error("scheduler job panic", "panic", fmt.Sprint(rec), "stack", string(debug.Stack()))
+		}
+	}()
+
+	start := time.Now()
+	if err := job.Run(ctx); err != nil && ctx.Err() == nil {
+		l.Error("scheduler job failed", "err", err, "latency_ms", time.Since(start).Milliseconds())
+	}
+}
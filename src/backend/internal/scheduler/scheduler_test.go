@@ -0,0 +1,37 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRunner_RunsUntilCancelled(t *testing.T) {
+	var runs atomic.Int32
+	r := NewRunner(nil)
+	r.Add(Job{Name: "tick", Interval: 5 * time.Millisecond, Run: func(context.Context) error {
+		if runs.Add(1) == 2 {
+			panic("boom")
+		}
+		return errors.New("keep going")
+	}})
+	r.Add(Job{Name: "disabled", Interval: 0, Run: func(context.Context) error {
+		t.Errorf("disabled job must not run")
+		return nil
+	}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r.Start(ctx)
+	deadline := time.Now().Add(2 * time.Second)
+	for runs.Load() < 3 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	r.Wait()
+
+	if runs.Load() < 3 {
+		t.Fatalf("expected the job to survive errors and panics, got %d runs", runs.Load())
+	}
+}
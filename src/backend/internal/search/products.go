@@ -0,0 +1,107 @@
+// Package search implements the catalog's free-text product search.
+//
+// On Postgres, products carry two generated columns maintained by the database (see
+// bootstrap.AutoMigrate): search_tsv, a weighted tsvector of the style number, slug
+// (weight A) and every string in detail_json (weight B), and search_text, the same content
+// as lower-cased text with a pg_trgm index for typo-tolerant matching. A product matches
+// when every query word prefixes a word of the document, or when the whole query is
+// similar enough to part of it; results are ranked by ts_rank plus trigram similarity.
+//
+// Other dialects (SQLite in tests) fall back to case-insensitive LIKE per word, ranking exact
+// and prefix style number matches first.
+package search
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	maxQueryRunes = 100
+	maxWords      = 8
+)
+
+// Normalize lower-cases raw, collapses whitespace and caps its length. It returns "" when
+// the query has no searchable word, so callers can treat it as "no search".
+func Normalize(raw string) string {
+	q := strings.Join(strings.Fields(strings.ToLower(raw)), " ")
+	if utf8.RuneCountInString(q) > maxQueryRunes {
+		q = strings.TrimSpace(string([]rune(q)[:maxQueryRunes]))
+	}
+	if len(words(q)) == 0 {
+		return ""
+	}
+	return q
+}
+
+// words splits a normalized query into letter/digit runs: "ab-001 silk" is [ab 001 silk].
+// Only these reach tsquery syntax and LIKE patterns, so they need no escaping.
+func words(q string) []string {
+	out := strings.FieldsFunc(q, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(out) > maxWords {
+		out = out[:maxWords]
+	}
+	return out
+}
+
+func isPostgres(db *gorm.DB) bool {
+	return db.Dialector != nil && db.Dialector.Name() == "postgres"
+}
+
+// prefixQuery builds a tsquery matching documents with a word starting with every term.
+func prefixQuery(ws []string) string {
+	parts := make([]string, len(ws))
+	for i, w := range ws {
+		parts[i] = w + ":*"
+	}
+	return strings.Join(parts, " & ")
+}
+
+// MatchProducts narrows a products query to rows matching the normalized query q.
+// An empty q leaves it unchanged.
+func MatchProducts(db *gorm.DB, q string) *gorm.DB {
+	ws := words(q)
+	if len(ws) == 0 {
+		return db
+	}
+	if isPostgres(db) {
+		return db.Where("(search_tsv @@ to_tsquery('simple', ?) OR ? <% search_text)", prefixQuery(ws), q)
+	}
+	for _, w := range ws {
+		like := "%" + w + "%"
+		db = db.Where("(LOWER(style_no) LIKE ? OR LOWER(slug) LIKE ? OR LOWER(CAST(detail_json AS TEXT)) LIKE ?)", like, like, like)
+	}
+	return db
+}
+
+// OrderProducts orders by relevance to q (most relevant first), then by fallback, a raw
+// ORDER BY list such as "id desc". Without a query only fallback applies.
+func OrderProducts(db *gorm.DB, q, fallback string) *gorm.DB {
+	ws := words(q)
+	if len(ws) == 0 {
+		return db.Order(fallback)
+	}
+	var rank clause.Expr
+	if isPostgres(db) {
+		rank = clause.Expr{
+			SQL:  "ts_rank(search_tsv, to_tsquery('simple', ?)) + word_similarity(?, search_text) DESC",
+			Vars: []any{prefixQuery(ws), q},
+		}
+	} else {
+		rank = clause.Expr{
+			SQL:  "CASE WHEN LOWER(style_no) = ? THEN 0 WHEN LOWER(style_no) LIKE ? THEN 1 ELSE 2 END",
+			Vars: []any{q, ws[0] + "%"},
+		}
+	}
+	if fallback != "" {
+		rank.SQL += ", " + fallback
+	}
+	rank.WithoutParentheses = true
+	return db.Order(clause.OrderBy{Expression: rank})
+}
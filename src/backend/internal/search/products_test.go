@@ -0,0 +1,78 @@
+package search
+
+import (
+	"testing"
+
+	"evening-gown/internal/model"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestNormalize(t *testing.T) {
+	cases := map[string]string{
+		"  Ivory   SILK\tMermaid ": "ivory silk mermaid",
+		"AB-001":                   "ab-001",
+		"白色 礼服":                    "白色 礼服",
+		" -- ":                     "",
+		"":                         "",
+	}
+	for in, want := range cases {
+		if got := Normalize(in); got != want {
+			t.Fatalf("Normalize(%q) = %q, want %q", in, got, want)
+		}
+	}
+	if got := words("ab-001 silk"); len(got) != 3 || got[0] != "ab" || got[1] != "001" || got[2] != "silk" {
+		t.Fatalf("unexpected words: %v", got)
+	}
+	if got := prefixQuery([]string{"ivory", "silk"}); got != "ivory:* & silk:*" {
+		t.Fatalf("unexpected tsquery: %q", got)
+	}
+}
+
+func TestMatchAndOrderProducts_SQLiteFallback(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := db.AutoMigrate(&model.Product{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	for _, p := range []model.Product{
+		{StyleNo: "7001", Slug: "style-7001", Season: "ss25", Category: "gown", Availability: "in_stock", DetailJSON: []byte(`{"title_i18n":{"en":"Ivory Silk Mermaid","zh":"象牙白真丝鱼尾"}}`)},
+		{StyleNo: "IVORY-1", Slug: "style-ivory-1", Season: "ss25", Category: "gown", Availability: "in_stock", DetailJSON: []byte(`{"title_i18n":{"en":"Ivory Tulle"}}`)},
+		{StyleNo: "7003", Slug: "style-7003", Season: "ss25", Category: "gown", Availability: "in_stock", DetailJSON: []byte(`{"specs":[{"key":"fabric","value_i18n":{"en":"Silk"}}]}`)},
+	} {
+		if err := db.Create(&p).Error; err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+
+	find := func(q string) []string {
+		t.Helper()
+		var out []model.Product
+		tx := MatchProducts(db.Model(&model.Product{}), Normalize(q))
+		if err := OrderProducts(tx, Normalize(q), "id asc").Find(&out).Error; err != nil {
+			t.Fatalf("search %q: %v", q, err)
+		}
+		var styles []string
+		for _, p := range out {
+			styles = append(styles, p.StyleNo)
+		}
+		return styles
+	}
+
+	if got := find("ivory silk"); len(got) != 1 || got[0] != "7001" {
+		t.Fatalf("expected every word to match, got %v", got)
+	}
+	// The style number match ranks first.
+	if got := find("IVORY"); len(got) != 2 || got[0] != "IVORY-1" || got[1] != "7001" {
+		t.Fatalf("unexpected ranking: %v", got)
+	}
+	if got := find("鱼尾"); len(got) != 1 || got[0] != "7001" {
+		t.Fatalf("expected i18n match, got %v", got)
+	}
+	if got := find(""); len(got) != 3 || got[0] != "7001" {
+		t.Fatalf("expected no filter without a query, got %v", got)
+	}
+}
@@ -0,0 +1,45 @@
+package security
+
+import "time"
+
+// LockoutPolicy describes progressive account lockout after failed logins.
+//
+// Once FailedLoginCount reaches Threshold the account is locked for Duration.
+// Every further failure (after the lock expired) doubles the window, capped at MaxDuration.
+type LockoutPolicy struct {
+	Threshold   int
+	Duration    time.Duration
+	MaxDuration time.Duration
+}
+
+// DefaultLockoutPolicy returns the policy used when nothing is configured.
+func DefaultLockoutPolicy() LockoutPolicy {
+	return LockoutPolicy{Threshold: 5, Duration: 15 * time.Minute, MaxDuration: 24 * time.Hour}
+}
+
+// Enabled reports whether lockout is active.
+func (p LockoutPolicy) Enabled() bool {
+	return p.Threshold > 0 && p.Duration > 0
+}
+
+// LockDuration returns how long the account must be locked after failedCount
+// consecutive failures. Zero means "do not lock".
+func (p LockoutPolicy) LockDuration(failedCount int) time.Duration {
+	if !p.Enabled() || failedCount < p.Threshold {
+		return 0
+	}
+
+	maxDur := p.MaxDuration
+	if maxDur <= 0 || maxDur < p.Duration {
+		maxDur = p.Duration
+	}
+
+	d := p.Duration
+	for i := p.Threshold; i < failedCount; i++ {
+		d *= 2
+		if d >= maxDur {
+			return maxDur
+		}
+	}
+	return d
+}
@@ -0,0 +1,39 @@
+package security
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLockoutPolicy_LockDuration(t *testing.T) {
+	p := LockoutPolicy{Threshold: 3, Duration: time.Minute, MaxDuration: 10 * time.Minute}
+
+	cases := []struct {
+		failed int
+		want   time.Duration
+	}{
+		{failed: 0, want: 0},
+		{failed: 2, want: 0},
+		{failed: 3, want: time.Minute},
+		{failed: 4, want: 2 * time.Minute},
+		{failed: 5, want: 4 * time.Minute},
+		{failed: 6, want: 8 * time.Minute},
+		{failed: 7, want: 10 * time.Minute},
+		{failed: 100, want: 10 * time.Minute},
+	}
+	for _, tc := range cases {
+		if got := p.LockDuration(tc.failed); got != tc.want {
+			t.Fatalf("failed=%d: expected %s got %s", tc.failed, tc.want, got)
+		}
+	}
+}
+
+func TestLockoutPolicy_Disabled(t *testing.T) {
+	p := LockoutPolicy{Threshold: 0, Duration: time.Minute}
+	if p.Enabled() {
+		t.Fatalf("expected disabled")
+	}
+	if got := p.LockDuration(50); got != 0 {
+		t.Fatalf("expected 0 got %s", got)
+	}
+}
@@ -1,7 +1,9 @@
 package security
 
 import (
+	"crypto/rand"
 	"errors"
+	"math/big"
 	"strings"
 
 	"golang.org/x/crypto/bcrypt"
@@ -34,3 +36,53 @@ func CheckPassword(hash, password string) bool {
 	}
 	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
 }
+
+const (
+	tempPasswordLength = 16
+	tempPasswordLower  = "abcdefghijkmnpqrstuvwxyz"
+	tempPasswordUpper  = "ABCDEFGHJKLMNPQRSTUVWXYZ"
+	tempPasswordDigits = "23456789"
+	tempPasswordSymbol = "!@#$%^&*-_=+"
+)
+
+// GenerateTemporaryPassword returns a random password for invited users.
+//
+// It always contains lower/upper/digit/symbol characters and avoids look-alikes (0/O, 1/l/I).
+func GenerateTemporaryPassword() (string, error) {
+	sets := []string{tempPasswordLower, tempPasswordUpper, tempPasswordDigits, tempPasswordSymbol}
+	all := strings.Join(sets, "")
+
+	out := make([]byte, 0, tempPasswordLength)
+	for _, set := range sets {
+		ch, err := randomChar(set)
+		if err != nil {
+			return "", err
+		}
+		out = append(out, ch)
+	}
+	for len(out) < tempPasswordLength {
+		ch, err := randomChar(all)
+		if err != nil {
+			return "", err
+		}
+		out = append(out, ch)
+	}
+
+	// Shuffle so the guaranteed classes are not always at the front.
+	for i := len(out) - 1; i > 0; i-- {
+		j, err := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+		if err != nil {
+			return "", err
+		}
+		out[i], out[j.Int64()] = out[j.Int64()], out[i]
+	}
+	return string(out), nil
+}
+
+func randomChar(set string) (byte, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(int64(len(set))))
+	if err != nil {
+		return 0, err
+	}
+	return set[n.Int64()], nil
+}
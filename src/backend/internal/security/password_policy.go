@@ -0,0 +1,188 @@
+package security
+
+import (
+	_ "embed"
+	"errors"
+	"strings"
+	"sync"
+	"unicode"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Reasons reported by PasswordPolicy.Validate.
+const (
+	PasswordTooShort       = "too_short"
+	PasswordTooLong        = "too_long"
+	PasswordFewClasses     = "too_few_character_classes"
+	PasswordCommon         = "common_password"
+	PasswordContainsEmail  = "contains_email"
+	passwordMaxBcryptBytes = 72
+)
+
+// ErrPasswordReused is returned when a new password matches one of the recent hashes.
+var ErrPasswordReused = errors.New("password was used recently")
+
+//go:embed common_passwords.txt
+var commonPasswordsFile string
+
+var (
+	commonPasswordsOnce sync.Once
+	commonPasswords     map[string]struct{}
+)
+
+// PasswordPolicy describes what a new password must satisfy and how it is stored.
+//
+// It applies to passwords chosen by users (change, reset, invite with an explicit password).
+// Existing hashes keep working; they are upgraded on the next login (see NeedsRehash).
+type PasswordPolicy struct {
+	MinLength int
+	// MinClasses is the number of character classes (lower, upper, digit, symbol) required.
+	MinClasses int
+	// DenyCommon rejects passwords from the bundled common-password list.
+	DenyCommon bool
+	// HistorySize rejects reuse of the current and the last N-1 previous passwords (0 disables).
+	HistorySize int
+	// BcryptCost is the work factor for new hashes (0 means bcrypt.DefaultCost).
+	BcryptCost int
+}
+
+// DefaultPasswordPolicy returns the policy used when nothing is configured.
+func DefaultPasswordPolicy() PasswordPolicy {
+	return PasswordPolicy{MinLength: 10, MinClasses: 2, DenyCommon: true, HistorySize: 5, BcryptCost: bcrypt.DefaultCost}
+}
+
+// PasswordPolicyError lists every rule a password failed, so clients can show them all at once.
+type PasswordPolicyError struct {
+	Reasons []string
+}
+
+func (e *PasswordPolicyError) Error() string {
+	return ErrWeakPassword.Error() + ": " + strings.Join(e.Reasons, ", ")
+}
+
+func (e *PasswordPolicyError) Unwrap() error { return ErrWeakPassword }
+
+// Validate checks password against the policy. email (optional) is used to reject
+// passwords built from the account name.
+//
+// The returned error is a *PasswordPolicyError (and matches ErrWeakPassword).
+func (p PasswordPolicy) Validate(password, email string) error {
+	password = strings.TrimSpace(password)
+
+	var reasons []string
+	n := len([]rune(password))
+	if n == 0 || n < p.MinLength {
+		reasons = append(reasons, PasswordTooShort)
+	}
+	// bcrypt silently ignores everything after 72 bytes.
+	if len(password) > passwordMaxBcryptBytes {
+		reasons = append(reasons, PasswordTooLong)
+	}
+	if p.MinClasses > 0 && passwordClasses(password) < p.MinClasses {
+		reasons = append(reasons, PasswordFewClasses)
+	}
+	if p.DenyCommon && IsCommonPassword(password) {
+		reasons = append(reasons, PasswordCommon)
+	}
+	if local := emailLocalPart(email); len(local) >= 3 && strings.Contains(strings.ToLower(password), local) {
+		reasons = append(reasons, PasswordContainsEmail)
+	}
+
+	if len(reasons) > 0 {
+		return &PasswordPolicyError{Reasons: reasons}
+	}
+	return nil
+}
+
+// Hash hashes password with the policy's bcrypt cost. It does not validate; call Validate first.
+func (p PasswordPolicy) Hash(password string) (string, error) {
+	password = strings.TrimSpace(password)
+	if password == "" {
+		return "", ErrWeakPassword
+	}
+	h, err := bcrypt.GenerateFromPassword([]byte(password), p.cost())
+	if err != nil {
+		return "", err
+	}
+	return string(h), nil
+}
+
+// NeedsRehash reports whether hash was produced with a different cost than the policy's.
+func (p PasswordPolicy) NeedsRehash(hash string) bool {
+	cost, err := bcrypt.Cost([]byte(hash))
+	if err != nil {
+		return false
+	}
+	return cost != p.cost()
+}
+
+func (p PasswordPolicy) cost() int {
+	if p.BcryptCost < bcrypt.MinCost || p.BcryptCost > bcrypt.MaxCost {
+		return bcrypt.DefaultCost
+	}
+	return p.BcryptCost
+}
+
+// IsCommonPassword reports whether password (case-insensitive) is on the bundled list,
+// also after stripping the usual trailing digits/symbols ("Password123!" -> "password").
+func IsCommonPassword(password string) bool {
+	commonPasswordsOnce.Do(loadCommonPasswords)
+
+	p := strings.ToLower(strings.TrimSpace(password))
+	if p == "" {
+		return false
+	}
+	if _, ok := commonPasswords[p]; ok {
+		return true
+	}
+	base := strings.TrimRightFunc(p, func(r rune) bool { return !unicode.IsLetter(r) })
+	if len(base) >= 4 && base != p {
+		if _, ok := commonPasswords[base]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func loadCommonPasswords() {
+	commonPasswords = map[string]struct{}{}
+	for _, line := range strings.Split(commonPasswordsFile, "\n") {
+		line = strings.ToLower(strings.TrimSpace(line))
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		commonPasswords[line] = struct{}{}
+	}
+}
+
+func passwordClasses(password string) int {
+	var lower, upper, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			symbol = true
+		}
+	}
+	n := 0
+	for _, ok := range []bool{lower, upper, digit, symbol} {
+		if ok {
+			n++
+		}
+	}
+	return n
+}
+
+func emailLocalPart(email string) string {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if i := strings.IndexByte(email, '@'); i >= 0 {
+		email = email[:i]
+	}
+	return email
+}
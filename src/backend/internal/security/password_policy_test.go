@@ -0,0 +1,73 @@
+package security
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestPasswordPolicy_Validate(t *testing.T) {
+	p := PasswordPolicy{MinLength: 10, MinClasses: 2, DenyCommon: true}
+
+	cases := []struct {
+		password string
+		email    string
+		want     []string
+	}{
+		{password: "velvet-hem-456", want: nil},
+		{password: "short-1", want: []string{PasswordTooShort}},
+		{password: "onlylettershere", want: []string{PasswordFewClasses}},
+		{password: "Password123!", want: []string{PasswordCommon}},
+		{password: "QWERTYUIOP", want: []string{PasswordFewClasses, PasswordCommon}},
+		{password: "jane.doe-2024", email: "Jane.Doe@example.com", want: []string{PasswordContainsEmail}},
+	}
+	for _, tc := range cases {
+		err := p.Validate(tc.password, tc.email)
+		if tc.want == nil {
+			if err != nil {
+				t.Fatalf("%q: expected valid, got %v", tc.password, err)
+			}
+			continue
+		}
+		var perr *PasswordPolicyError
+		if !errors.As(err, &perr) || !errors.Is(err, ErrWeakPassword) {
+			t.Fatalf("%q: expected policy error, got %v", tc.password, err)
+		}
+		if !reflect.DeepEqual(perr.Reasons, tc.want) {
+			t.Fatalf("%q: expected %v, got %v", tc.password, tc.want, perr.Reasons)
+		}
+	}
+}
+
+func TestIsCommonPassword(t *testing.T) {
+	for _, pw := range []string{"123456789", "Passw0rd", "iloveyou!!", "Dragon2024"} {
+		if !IsCommonPassword(pw) {
+			t.Fatalf("expected %q to be common", pw)
+		}
+	}
+	for _, pw := range []string{"velvet-hem-456", "", "ab12"} {
+		if IsCommonPassword(pw) {
+			t.Fatalf("expected %q not to be common", pw)
+		}
+	}
+}
+
+func TestPasswordPolicy_NeedsRehash(t *testing.T) {
+	p := PasswordPolicy{BcryptCost: bcrypt.MinCost}
+	h, err := p.Hash("velvet-hem-456")
+	if err != nil {
+		t.Fatalf("hash: %v", err)
+	}
+	if p.NeedsRehash(h) {
+		t.Fatalf("expected no rehash at same cost")
+	}
+	p.BcryptCost = bcrypt.MinCost + 1
+	if !p.NeedsRehash(h) {
+		t.Fatalf("expected rehash after cost change")
+	}
+	if p.NeedsRehash("not-a-bcrypt-hash") {
+		t.Fatalf("expected invalid hash to be left alone")
+	}
+}
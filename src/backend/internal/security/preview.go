@@ -0,0 +1,111 @@
+package security
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Preview token kinds: what a token unlocks.
+const (
+	PreviewProduct = "product"
+	PreviewUpdate  = "update"
+)
+
+var (
+	ErrPreviewInvalid  = errors.New("invalid preview token")
+	ErrPreviewExpired  = errors.New("preview token expired")
+	ErrPreviewDisabled = errors.New("preview links disabled")
+)
+
+// PreviewClaims is what a preview token grants: read access to one item until ExpiresAt.
+type PreviewClaims struct {
+	Kind      string
+	ID        uint
+	ExpiresAt time.Time
+}
+
+// PreviewSigner mints and verifies stateless, expiring preview tokens.
+//
+// Format: base64url("kind:id:exp") + "." + base64url(HMAC-SHA256(secret, payload)).
+// Tokens cannot be revoked individually; rotate the secret to invalidate all of them.
+type PreviewSigner struct {
+	key []byte
+}
+
+// NewPreviewSigner returns nil (previews disabled) when secret is empty.
+func NewPreviewSigner(secret string) *PreviewSigner {
+	secret = strings.TrimSpace(secret)
+	if secret == "" {
+		return nil
+	}
+	return &PreviewSigner{key: []byte(secret)}
+}
+
+// Sign returns a token for kind/id valid until expiresAt.
+func (s *PreviewSigner) Sign(kind string, id uint, expiresAt time.Time) (string, error) {
+	if s == nil {
+		return "", ErrPreviewDisabled
+	}
+	if kind == "" || strings.Contains(kind, ":") || id == 0 {
+		return "", ErrPreviewInvalid
+	}
+	payload := kind + ":" + strconv.FormatUint(uint64(id), 10) + ":" + strconv.FormatInt(expiresAt.Unix(), 10)
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString([]byte(payload)) + "." + enc.EncodeToString(s.mac(payload)), nil
+}
+
+// Verify checks the signature and expiry of token and returns its claims.
+func (s *PreviewSigner) Verify(token string, now time.Time) (PreviewClaims, error) {
+	var claims PreviewClaims
+	if s == nil {
+		return claims, ErrPreviewDisabled
+	}
+	enc := base64.RawURLEncoding
+	rawPayload, rawMAC, ok := strings.Cut(strings.TrimSpace(token), ".")
+	if !ok {
+		return claims, ErrPreviewInvalid
+	}
+	payload, err := enc.DecodeString(rawPayload)
+	if err != nil {
+		return claims, ErrPreviewInvalid
+	}
+	mac, err := enc.DecodeString(rawMAC)
+	if err != nil || !hmac.Equal(mac, s.mac(string(payload))) {
+		return claims, ErrPreviewInvalid
+	}
+
+	parts := strings.Split(string(payload), ":")
+	if len(parts) != 3 {
+		return claims, ErrPreviewInvalid
+	}
+	id, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil || id == 0 {
+		return claims, ErrPreviewInvalid
+	}
+	exp, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return claims, ErrPreviewInvalid
+	}
+	claims = PreviewClaims{Kind: parts[0], ID: uint(id), ExpiresAt: time.Unix(exp, 0).UTC()}
+	if !now.Before(claims.ExpiresAt) {
+		return claims, ErrPreviewExpired
+	}
+	return claims, nil
+}
+
+// Allows reports whether token is a valid, unexpired grant for kind/id.
+func (s *PreviewSigner) Allows(token, kind string, id uint, now time.Time) bool {
+	claims, err := s.Verify(token, now)
+	return err == nil && claims.Kind == kind && claims.ID == id
+}
+
+func (s *PreviewSigner) mac(payload string) []byte {
+	m := hmac.New(sha256.New, s.key)
+	m.Write([]byte("preview:" + payload))
+	return m.Sum(nil)
+}
@@ -0,0 +1,58 @@
+package security
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPreviewSigner_SignAndVerify(t *testing.T) {
+	s := NewPreviewSigner("preview-test-secret")
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	token, err := s.Sign(PreviewProduct, 42, now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	claims, err := s.Verify(token, now)
+	if err != nil || claims.Kind != PreviewProduct || claims.ID != 42 || !claims.ExpiresAt.Equal(now.Add(time.Hour)) {
+		t.Fatalf("unexpected claims %+v err=%v", claims, err)
+	}
+	if !s.Allows(token, PreviewProduct, 42, now) || s.Allows(token, PreviewProduct, 43, now) || s.Allows(token, PreviewUpdate, 42, now) {
+		t.Fatalf("token must only grant its own item")
+	}
+
+	if _, err := s.Verify(token, now.Add(time.Hour)); !errors.Is(err, ErrPreviewExpired) {
+		t.Fatalf("expected expired, got %v", err)
+	}
+	if _, err := NewPreviewSigner("other-secret").Verify(token, now); !errors.Is(err, ErrPreviewInvalid) {
+		t.Fatalf("expected invalid signature, got %v", err)
+	}
+
+	// Tampering with the payload breaks the signature.
+	payload, mac, _ := strings.Cut(token, ".")
+	forged, _ := s.Sign(PreviewProduct, 7, now.Add(time.Hour))
+	forgedPayload, _, _ := strings.Cut(forged, ".")
+	if _, err := s.Verify(forgedPayload+"."+mac, now); !errors.Is(err, ErrPreviewInvalid) {
+		t.Fatalf("expected invalid for swapped payload, got %v", err)
+	}
+	for _, bad := range []string{"", "abc", payload, payload + ".!!"} {
+		if _, err := s.Verify(bad, now); !errors.Is(err, ErrPreviewInvalid) {
+			t.Fatalf("expected invalid for %q, got %v", bad, err)
+		}
+	}
+}
+
+func TestPreviewSigner_Disabled(t *testing.T) {
+	s := NewPreviewSigner("  ")
+	if s != nil {
+		t.Fatalf("expected nil signer for empty secret")
+	}
+	if _, err := s.Sign(PreviewProduct, 1, time.Now().Add(time.Hour)); !errors.Is(err, ErrPreviewDisabled) {
+		t.Fatalf("expected disabled, got %v", err)
+	}
+	if s.Allows("x.y", PreviewProduct, 1, time.Now()) {
+		t.Fatalf("nil signer must not allow anything")
+	}
+}
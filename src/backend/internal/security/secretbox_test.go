@@ -0,0 +1,49 @@
+package security
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSecretBox_SealAndOpen(t *testing.T) {
+	b := NewSecretBox("totp-test-key")
+	const secret = "JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"
+
+	sealed, err := b.Seal(secret)
+	if err != nil {
+		t.Fatalf("seal: %v", err)
+	}
+	if !IsSealed(sealed) || strings.Contains(sealed, secret) {
+		t.Fatalf("expected an encrypted value, got %q", sealed)
+	}
+	if again, _ := b.Seal(secret); again == sealed {
+		t.Fatalf("expected a fresh nonce per seal")
+	}
+	if got, err := b.Open(sealed); err != nil || got != secret {
+		t.Fatalf("open: got %q err=%v", got, err)
+	}
+
+	// Plaintext written before a key was configured still opens.
+	if got, err := b.Open(secret); err != nil || got != secret {
+		t.Fatalf("open plaintext: got %q err=%v", got, err)
+	}
+
+	if _, err := NewSecretBox("other-key").Open(sealed); !errors.Is(err, ErrSecretInvalid) {
+		t.Fatalf("expected invalid with the wrong key, got %v", err)
+	}
+	if _, err := b.Open(sealed[:len(sealed)-2]); !errors.Is(err, ErrSecretInvalid) {
+		t.Fatalf("expected invalid for a truncated value, got %v", err)
+	}
+
+	var none *SecretBox
+	if NewSecretBox("  ") != nil {
+		t.Fatalf("expected no box without a key")
+	}
+	if got, _ := none.Seal(secret); got != secret {
+		t.Fatalf("expected plaintext without a key, got %q", got)
+	}
+	if _, err := none.Open(sealed); !errors.Is(err, ErrSecretKeyMissing) {
+		t.Fatalf("expected key missing, got %v", err)
+	}
+}
@@ -0,0 +1,152 @@
+package security
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// TOTP parameters (RFC 6238 defaults understood by every authenticator app).
+const (
+	TOTPPeriod = 30 * time.Second
+	TOTPDigits = 6
+	// TOTPSkew is the number of adjacent time steps accepted to tolerate clock drift.
+	TOTPSkew = 1
+
+	totpSecretBytes = 20
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateTOTPSecret returns a random base32 secret (160 bits, no padding).
+func GenerateTOTPSecret() (string, error) {
+	b := make([]byte, totpSecretBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate totp secret: %w", err)
+	}
+	return totpEncoding.EncodeToString(b), nil
+}
+
+// TOTPStep returns the RFC 6238 time step counter for t.
+func TOTPStep(t time.Time) int64 {
+	return t.Unix() / int64(TOTPPeriod/time.Second)
+}
+
+// TOTPCode returns the code for secret at time t.
+func TOTPCode(secret string, t time.Time) (string, error) {
+	key, err := decodeTOTPSecret(secret)
+	if err != nil {
+		return "", err
+	}
+	return hotp(key, TOTPStep(t)), nil
+}
+
+// ValidateTOTP checks code against secret around time t (±TOTPSkew steps).
+// It returns the matched step so callers can reject replays of the same code.
+func ValidateTOTP(secret, code string, t time.Time) (int64, bool) {
+	code = strings.ReplaceAll(strings.TrimSpace(code), " ", "")
+	if len(code) != TOTPDigits {
+		return 0, false
+	}
+	key, err := decodeTOTPSecret(secret)
+	if err != nil {
+		return 0, false
+	}
+
+	step := TOTPStep(t)
+	for i := -TOTPSkew; i <= TOTPSkew; i++ {
+		want := hotp(key, step+int64(i))
+		if hmac.Equal([]byte(want), []byte(code)) {
+			return step + int64(i), true
+		}
+	}
+	return 0, false
+}
+
+// TOTPProvisioningURI builds the otpauth:// URI rendered as a QR code by the frontend.
+func TOTPProvisioningURI(issuer, account, secret string) string {
+	label := account
+	if issuer != "" {
+		label = issuer + ":" + account
+	}
+	q := url.Values{}
+	q.Set("secret", secret)
+	if issuer != "" {
+		q.Set("issuer", issuer)
+	}
+	q.Set("algorithm", "SHA1")
+	q.Set("digits", fmt.Sprintf("%d", TOTPDigits))
+	q.Set("period", fmt.Sprintf("%d", int(TOTPPeriod/time.Second)))
+
+	u := url.URL{Scheme: "otpauth", Host: "totp", Path: "/" + label, RawQuery: q.Encode()}
+	return u.String()
+}
+
+func decodeTOTPSecret(secret string) ([]byte, error) {
+	s := strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(secret), " ", ""))
+	s = strings.TrimRight(s, "=")
+	key, err := totpEncoding.DecodeString(s)
+	if err != nil || len(key) == 0 {
+		return nil, fmt.Errorf("invalid totp secret")
+	}
+	return key, nil
+}
+
+// hotp implements RFC 4226 with HMAC-SHA1 and dynamic truncation.
+func hotp(key []byte, counter int64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(counter))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	bin := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for i := 0; i < TOTPDigits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", TOTPDigits, bin%mod)
+}
+
+const (
+	recoveryCodeAlphabet = "abcdefghijkmnpqrstuvwxyz23456789"
+	recoveryCodeHalf     = 5
+)
+
+// GenerateRecoveryCodes returns n one-time codes formatted as "xxxxx-xxxxx".
+func GenerateRecoveryCodes(n int) ([]string, error) {
+	out := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		var b strings.Builder
+		for j := 0; j < recoveryCodeHalf*2; j++ {
+			if j == recoveryCodeHalf {
+				b.WriteByte('-')
+			}
+			ch, err := randomChar(recoveryCodeAlphabet)
+			if err != nil {
+				return nil, err
+			}
+			b.WriteByte(ch)
+		}
+		out = append(out, b.String())
+	}
+	return out, nil
+}
+
+// NormalizeRecoveryCode lower-cases user input and restores the dash if it was omitted.
+func NormalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), " ", ""))
+	if len(code) == recoveryCodeHalf*2 && !strings.Contains(code, "-") {
+		code = code[:recoveryCodeHalf] + "-" + code[recoveryCodeHalf:]
+	}
+	return code
+}
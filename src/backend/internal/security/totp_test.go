@@ -0,0 +1,84 @@
+package security
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+// RFC 6238 appendix B vectors (SHA1 seed), truncated to 6 digits.
+func TestTOTPCode_RFC6238Vectors(t *testing.T) {
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+	cases := []struct {
+		unix int64
+		want string
+	}{
+		{unix: 59, want: "287082"},
+		{unix: 1111111109, want: "081804"},
+		{unix: 1111111111, want: "050471"},
+		{unix: 1234567890, want: "005924"},
+		{unix: 2000000000, want: "279037"},
+	}
+	for _, tc := range cases {
+		got, err := TOTPCode(secret, time.Unix(tc.unix, 0))
+		if err != nil {
+			t.Fatalf("totp code: %v", err)
+		}
+		if got != tc.want {
+			t.Fatalf("t=%d: expected %s got %s", tc.unix, tc.want, got)
+		}
+	}
+}
+
+func TestValidateTOTP_SkewAndStep(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("generate secret: %v", err)
+	}
+	now := time.Unix(1_700_000_000, 0)
+
+	prev, _ := TOTPCode(secret, now.Add(-TOTPPeriod))
+	step, ok := ValidateTOTP(secret, prev, now)
+	if !ok || step != TOTPStep(now)-1 {
+		t.Fatalf("expected previous step accepted, got step=%d ok=%v", step, ok)
+	}
+
+	old, _ := TOTPCode(secret, now.Add(-3*TOTPPeriod))
+	if _, ok := ValidateTOTP(secret, old, now); ok {
+		t.Fatalf("expected code outside skew to be rejected")
+	}
+	if _, ok := ValidateTOTP(secret, "12345", now); ok {
+		t.Fatalf("expected short code to be rejected")
+	}
+}
+
+func TestTOTPProvisioningURI(t *testing.T) {
+	uri := TOTPProvisioningURI("Evening Gown", "admin@example.com", "JBSWY3DPEHPK3PXP")
+	for _, part := range []string{"otpauth://totp/", "secret=JBSWY3DPEHPK3PXP", "issuer=Evening+Gown", "digits=6", "period=30"} {
+		if !strings.Contains(uri, part) {
+			t.Fatalf("expected %q in %s", part, uri)
+		}
+	}
+}
+
+func TestGenerateRecoveryCodes(t *testing.T) {
+	codes, err := GenerateRecoveryCodes(10)
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	seen := map[string]bool{}
+	for _, c := range codes {
+		if len(c) != 11 || c[5] != '-' {
+			t.Fatalf("unexpected format %q", c)
+		}
+		if seen[c] {
+			t.Fatalf("duplicate code %q", c)
+		}
+		seen[c] = true
+		if NormalizeRecoveryCode(strings.ToUpper(strings.ReplaceAll(c, "-", ""))) != c {
+			t.Fatalf("normalize mismatch for %q", c)
+		}
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"evening-gown/internal/config"
+	"evening-gown/internal/imaging"
 
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
@@ -132,3 +133,82 @@ func PublicObjectURL(cfg config.MinioConfig, objectKey string) (string, error) {
 	u.Path = pathPrefix + "/" + cfg.Bucket + "/" + objectKey
 	return u.String(), nil
 }
+
+// PresignedPut returns a URL the holder can PUT objectKey to until it expires.
+// The URL targets cfg.Endpoint, so that endpoint must be reachable by the uploader.
+func PresignedPut(ctx context.Context, client *minio.Client, cfg config.MinioConfig, objectKey string, expires time.Duration) (string, error) {
+	objectKey = strings.TrimSpace(strings.TrimPrefix(objectKey, "/"))
+	if objectKey == "" {
+		return "", fmt.Errorf("objectKey is empty")
+	}
+	if err := EnsureBucket(ctx, client, cfg); err != nil {
+		return "", err
+	}
+	u, err := client.PresignedPutObject(ctx, cfg.Bucket, objectKey, expires)
+	if err != nil {
+		return "", fmt.Errorf("presign put: %w", err)
+	}
+	return u.String(), nil
+}
+
+// PresignedPost returns a browser form upload (URL and form fields) for objectKey. Unlike a
+// presigned PUT, MinIO itself enforces the content type and the 1..maxBytes size range.
+func PresignedPost(ctx context.Context, client *minio.Client, cfg config.MinioConfig, objectKey, contentType string, maxBytes int64, expires time.Duration) (string, map[string]string, error) {
+	objectKey = strings.TrimSpace(strings.TrimPrefix(objectKey, "/"))
+	if objectKey == "" {
+		return "", nil, fmt.Errorf("objectKey is empty")
+	}
+	if err := EnsureBucket(ctx, client, cfg); err != nil {
+		return "", nil, err
+	}
+	policy := minio.NewPostPolicy()
+	for _, err := range []error{
+		policy.SetBucket(cfg.Bucket),
+		policy.SetKey(objectKey),
+		policy.SetExpires(time.Now().UTC().Add(expires)),
+		policy.SetContentType(contentType),
+		policy.SetContentLengthRange(1, maxBytes),
+	} {
+		if err != nil {
+			return "", nil, fmt.Errorf("post policy: %w", err)
+		}
+	}
+	u, fields, err := client.PresignedPostPolicy(ctx, policy)
+	if err != nil {
+		return "", nil, fmt.Errorf("presign post: %w", err)
+	}
+	return u.String(), fields, nil
+}
+
+// RemoveObject deletes objectKey from the bucket. Removing a missing object is not an error.
+func RemoveObject(ctx context.Context, client *minio.Client, cfg config.MinioConfig, objectKey string) error {
+	objectKey = strings.TrimSpace(strings.TrimPrefix(objectKey, "/"))
+	if objectKey == "" {
+		return fmt.Errorf("objectKey is empty")
+	}
+	if err := client.RemoveObject(ctx, cfg.Bucket, objectKey, minio.RemoveObjectOptions{}); err != nil {
+		return fmt.Errorf("remove object: %w", err)
+	}
+	return nil
+}
+
+// RemoveImage deletes an uploaded image together with its width variants (see
+// imaging.VariantBase): for a rendition key every rendition of the upload is removed, not
+// only the referenced width.
+func RemoveImage(ctx context.Context, client *minio.Client, cfg config.MinioConfig, objectKey string) error {
+	objectKey = strings.TrimSpace(strings.TrimPrefix(objectKey, "/"))
+	if _, _, ok := imaging.ParseRenditionKey(objectKey); !ok {
+		if err := RemoveObject(ctx, client, cfg, objectKey); err != nil {
+			return err
+		}
+	}
+	for obj := range client.ListObjects(ctx, cfg.Bucket, minio.ListObjectsOptions{Prefix: imaging.VariantBase(objectKey) + "/"}) {
+		if obj.Err != nil {
+			return fmt.Errorf("list variants: %w", obj.Err)
+		}
+		if err := RemoveObject(ctx, client, cfg, obj.Key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
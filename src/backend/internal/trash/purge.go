@@ -0,0 +1,194 @@
+package trash
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"evening-gown/internal/audit"
+	"evening-gown/internal/media"
+	"evening-gown/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// purgeBatchSize bounds the rows removed per type and Run; the rest wait for the next run.
+const purgeBatchSize = 100
+
+// ObjectRemover deletes stored objects (MinIO in production).
+type ObjectRemover interface {
+	RemoveObject(ctx context.Context, key string) error
+}
+
+// RemoverFunc adapts a function to ObjectRemover.
+type RemoverFunc func(ctx context.Context, key string) error
+
+func (f RemoverFunc) RemoveObject(ctx context.Context, key string) error { return f(ctx, key) }
+
+// Purger permanently removes soft-deleted records.
+//
+// Purging a product also removes its revisions, draft and variants, and deletes the stored
+// images that no other product (live, deleted, drafted or in a revision) still references.
+// Objects are removed after the rows are committed; a failed removal only leaves an orphan.
+type Purger struct {
+	db        *gorm.DB
+	objects   ObjectRemover
+	retention time.Duration
+	logger    *slog.Logger
+	now       func() time.Time
+}
+
+// NewPurger returns a purger. objects may be nil (images are then kept); a non-positive
+// retention disables the retention run.
+func NewPurger(db *gorm.DB, objects ObjectRemover, retention time.Duration, logger *slog.Logger) *Purger {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Purger{db: db, objects: objects, retention: retention, logger: logger, now: time.Now}
+}
+
+// Retention is how long deleted records are kept (0: forever).
+func (p *Purger) Retention() time.Duration {
+	if p == nil || p.retention < 0 {
+		return 0
+	}
+	return p.retention
+}
+
+// Purge removes one deleted record now and returns the row as it was.
+func (p *Purger) Purge(ctx context.Context, t string, id uint) (any, error) {
+	if _, ok := kinds[t]; !ok {
+		return nil, ErrUnknownType
+	}
+	var (
+		row  any
+		keys []string
+	)
+	err := p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		if row, err = Load(ctx, tx, t, id); err != nil {
+			return err
+		}
+		keys, err = purgeRow(tx, t, id, row)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	p.removeObjects(ctx, keys)
+	return row, nil
+}
+
+// PurgeResult counts the rows removed by one RunOnce, per type.
+type PurgeResult map[string]int
+
+// Run purges records deleted longer than the retention ago. It implements scheduler.Job.Run.
+func (p *Purger) Run(ctx context.Context) error {
+	_, err := p.RunOnce(ctx)
+	return err
+}
+
+// RunOnce purges up to purgeBatchSize expired records per type, recording an audit entry each.
+func (p *Purger) RunOnce(ctx context.Context) (PurgeResult, error) {
+	res := PurgeResult{}
+	if p == nil || p.db == nil || p.retention <= 0 {
+		return res, nil
+	}
+	cutoff := p.now().UTC().Add(-p.retention)
+	ctx = audit.WithActor(ctx, audit.Actor{Email: "scheduler", Role: "system"})
+
+	for _, t := range Types() {
+		var ids []uint
+		if err := p.db.WithContext(ctx).Model(kinds[t].model()).
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).
+			Order("id asc").
+			Limit(purgeBatchSize).
+			Pluck("id", &ids).Error; err != nil {
+			return res, err
+		}
+		for _, id := range ids {
+			row, err := p.Purge(ctx, t, id)
+			if errors.Is(err, ErrNotFound) {
+				// Restored or purged by someone else meanwhile.
+				continue
+			}
+			if err != nil {
+				return res, err
+			}
+			res[t]++
+			if err := audit.Record(ctx, p.db, audit.Entry{
+				Action:     AuditAction(t, "purge"),
+				EntityType: t,
+				EntityID:   strconv.FormatUint(uint64(id), 10),
+				Before:     row,
+			}); err != nil {
+				p.logger.Warn("trash purge audit failed", "type", t, "id", id, "err", err)
+			}
+		}
+	}
+	return res, nil
+}
+
+// AuditAction names audit actions per type: AuditAction("products", "purge") is "product.purge".
+func AuditAction(t, verb string) string {
+	return strings.TrimSuffix(t, "s") + "." + verb
+}
+
+// purgeRow deletes row (already loaded in tx) and its dependents. For products it returns
+// the object keys that nothing references any more.
+func purgeRow(tx *gorm.DB, t string, id uint, row any) ([]string, error) {
+	switch v := row.(type) {
+	case *model.Product:
+		for _, dep := range []any{&model.ProductVariant{}, &model.ProductDraft{}, &model.ProductRevision{}, &model.ProductAsset{}} {
+			if err := tx.Where("product_id = ?", v.ID).Delete(dep).Error; err != nil {
+				return nil, err
+			}
+		}
+		if err := tx.Delete(&model.Product{}, v.ID).Error; err != nil {
+			return nil, err
+		}
+		return unreferencedKeys(tx, model.ProductImageKeys(*v))
+	case *model.User:
+		for _, dep := range []any{&model.Session{}, &model.RecoveryCode{}, &model.PasswordResetToken{}, &model.PasswordHistory{}} {
+			if err := tx.Where("user_id = ?", v.ID).Delete(dep).Error; err != nil {
+				return nil, err
+			}
+		}
+		return nil, tx.Delete(&model.User{}, v.ID).Error
+	}
+	return nil, tx.Where("deleted_at IS NOT NULL").Delete(kinds[t].model(), id).Error
+}
+
+// unreferencedKeys filters out keys still used by another product, draft or revision
+// (see media.ReferenceCounts).
+func unreferencedKeys(tx *gorm.DB, keys []string) ([]string, error) {
+	counts, err := media.ReferenceCounts(tx, keys)
+	if err != nil {
+		return nil, err
+	}
+	var out []string
+	for _, key := range keys {
+		if counts[key] == 0 {
+			out = append(out, key)
+		}
+	}
+	return out, nil
+}
+
+func (p *Purger) removeObjects(ctx context.Context, keys []string) {
+	if p.objects == nil {
+		return
+	}
+	for _, key := range keys {
+		if err := p.objects.RemoveObject(ctx, key); err != nil {
+			p.logger.Warn("trash purge remove object failed", "key", key, "err", err)
+			continue
+		}
+		if err := p.db.WithContext(ctx).Where("object_key = ?", key).Delete(&model.Asset{}).Error; err != nil {
+			p.logger.Warn("trash purge remove asset failed", "key", key, "err", err)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package trash
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"evening-gown/internal/bootstrap"
+	"evening-gown/internal/media"
+	"evening-gown/internal/model"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err == nil {
+		t.Cleanup(func() { _ = sqlDB.Close() })
+	}
+	if err := bootstrap.AutoMigrate(db); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return db
+}
+
+func TestPurger_RunOncePurgesExpiredRecords(t *testing.T) {
+	db := openTestDB(t)
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	expired := now.Add(-40 * 24 * time.Hour)
+	recent := now.Add(-24 * time.Hour)
+
+	old := model.Product{
+		Slug: "style-a", StyleNo: "A", Season: "ss26", Category: "gown", Availability: "in_stock",
+		CoverImageKey: "products/a.jpg",
+		HoverImageKey: "products/shared.jpg",
+		DetailJSON:    []byte(`{"sections":[{"images":["/api/v1/assets/products/a-detail.jpg"]}]}`),
+		DeletedAt:     &expired,
+	}
+	live := model.Product{Slug: "style-b", StyleNo: "B", Season: "ss26", Category: "gown", Availability: "in_stock", CoverImageKey: "products/shared.jpg"}
+	fresh := model.Product{Slug: "style-c", StyleNo: "C", Season: "ss26", Category: "gown", Availability: "in_stock", CoverImageKey: "products/c.jpg", DeletedAt: &recent}
+	for _, p := range []*model.Product{&old, &live, &fresh} {
+		if err := db.Create(p).Error; err != nil {
+			t.Fatalf("create product: %v", err)
+		}
+		if _, err := media.SyncProductAssets(context.Background(), db, p.ID); err != nil {
+			t.Fatalf("index product assets: %v", err)
+		}
+	}
+	if err := db.Create(&model.ProductRevision{ProductID: old.ID, Snapshot: []byte(`{}`), Reason: "update"}).Error; err != nil {
+		t.Fatalf("create revision: %v", err)
+	}
+	lead := model.ContactLead{Name: "Alice", Status: "new", DeletedAt: &expired}
+	if err := db.Create(&lead).Error; err != nil {
+		t.Fatalf("create lead: %v", err)
+	}
+
+	var removed []string
+	p := NewPurger(db, RemoverFunc(func(_ context.Context, key string) error {
+		removed = append(removed, key)
+		return nil
+	}), 30*24*time.Hour, nil)
+	p.now = func() time.Time { return now }
+
+	res, err := p.RunOnce(context.Background())
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if res[Products] != 1 || res[Contacts] != 1 || len(res) != 2 {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+
+	sort.Strings(removed)
+	if len(removed) != 2 || removed[0] != "products/a-detail.jpg" || removed[1] != "products/a.jpg" {
+		t.Fatalf("expected only the purged product's own images removed, got %v", removed)
+	}
+
+	var n int64
+	db.Model(&model.Product{}).Count(&n)
+	if n != 2 {
+		t.Fatalf("expected 2 products left, got %d", n)
+	}
+	db.Model(&model.ProductRevision{}).Where("product_id = ?", old.ID).Count(&n)
+	if n != 0 {
+		t.Fatalf("expected revisions purged, got %d", n)
+	}
+	db.Model(&model.ContactLead{}).Count(&n)
+	if n != 0 {
+		t.Fatalf("expected lead purged, got %d", n)
+	}
+	db.Model(&model.AuditLog{}).Where("action IN ?", []string{"product.purge", "contact.purge"}).Count(&n)
+	if n != 2 {
+		t.Fatalf("expected 2 purge audit entries, got %d", n)
+	}
+
+	// Nothing is due any more; a disabled retention never purges.
+	if res, err := p.RunOnce(context.Background()); err != nil || len(res) != 0 {
+		t.Fatalf("expected nothing to purge, got %+v (%v)", res, err)
+	}
+	p.retention = 0
+	p.now = func() time.Time { return now.Add(365 * 24 * time.Hour) }
+	if res, err := p.RunOnce(context.Background()); err != nil || len(res) != 0 {
+		t.Fatalf("expected retention 0 to keep records, got %+v (%v)", res, err)
+	}
+}
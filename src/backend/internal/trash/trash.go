@@ -0,0 +1,218 @@
+// Package trash lists, restores and purges soft-deleted records.
+//
+// Products, updates, users, contact leads and events are deleted by setting deleted_at.
+// They stay restorable until they are purged, either explicitly or by the retention job
+// (see Purger), which removes the rows for good.
+package trash
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
+
+	"evening-gown/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// Entity types, named like the audit log's entity_type.
+const (
+	Products = "products"
+	Updates  = "updates"
+	Users    = "users"
+	Contacts = "contacts"
+	Events   = "events"
+)
+
+var (
+	ErrUnknownType = errors.New("unknown trash type")
+	ErrNotFound    = errors.New("not in trash")
+	// ErrConflict means a unique value of the deleted row is now used by a live record.
+	ErrConflict = errors.New("conflicts with an existing record")
+)
+
+type kind struct {
+	model func() any
+	// label is the column shown in listings.
+	label string
+}
+
+var kinds = map[string]kind{
+	Products: {model: func() any { return &model.Product{} }, label: "style_no"},
+	Updates:  {model: func() any { return &model.UpdatePost{} }, label: "title"},
+	Users:    {model: func() any { return &model.User{} }, label: "email"},
+	Contacts: {model: func() any { return &model.ContactLead{} }, label: "name"},
+	Events:   {model: func() any { return &model.Event{} }, label: "event_type"},
+}
+
+// Types returns every entity type that has a trash, in display order.
+func Types() []string {
+	return []string{Products, Updates, Users, Contacts, Events}
+}
+
+// IsValidType reports whether t is one of Types.
+func IsValidType(t string) bool {
+	_, ok := kinds[t]
+	return ok
+}
+
+// Item is one soft-deleted record.
+type Item struct {
+	Type      string    `json:"type"`
+	ID        uint      `json:"id"`
+	Label     string    `json:"label"`
+	DeletedAt time.Time `json:"deletedAt"`
+	// PurgeAt is when the retention job removes the record; nil when retention is disabled.
+	PurgeAt *time.Time `json:"purgeAt,omitempty"`
+}
+
+// List returns the deleted records of the given types, most recently deleted first.
+// retention (0: disabled) only fills Item.PurgeAt.
+func List(ctx context.Context, db *gorm.DB, types []string, retention time.Duration, limit, offset int) ([]Item, int64, error) {
+	var (
+		total int64
+		items []Item
+	)
+	for _, t := range types {
+		k, ok := kinds[t]
+		if !ok {
+			return nil, 0, ErrUnknownType
+		}
+		q := db.WithContext(ctx).Model(k.model()).Where("deleted_at IS NOT NULL")
+
+		var n int64
+		if err := q.Count(&n).Error; err != nil {
+			return nil, 0, err
+		}
+		total += n
+
+		// The page of the merged list is within the first offset+limit rows of every type.
+		var rows []struct {
+			ID        uint
+			Label     string
+			DeletedAt time.Time
+		}
+		if err := q.Select("id, " + k.label + " AS label, deleted_at").
+			Order("deleted_at desc, id desc").
+			Limit(offset + limit).
+			Scan(&rows).Error; err != nil {
+			return nil, 0, err
+		}
+		for _, r := range rows {
+			it := Item{Type: t, ID: r.ID, Label: r.Label, DeletedAt: r.DeletedAt.UTC()}
+			if retention > 0 {
+				at := it.DeletedAt.Add(retention)
+				it.PurgeAt = &at
+			}
+			items = append(items, it)
+		}
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		if !items[i].DeletedAt.Equal(items[j].DeletedAt) {
+			return items[i].DeletedAt.After(items[j].DeletedAt)
+		}
+		return items[i].ID > items[j].ID
+	})
+	if offset >= len(items) {
+		return []Item{}, total, nil
+	}
+	items = items[offset:]
+	if len(items) > limit {
+		items = items[:limit]
+	}
+	return items, total, nil
+}
+
+// Load returns the deleted row (a pointer to its model) for audit and side effects.
+func Load(ctx context.Context, db *gorm.DB, t string, id uint) (any, error) {
+	k, ok := kinds[t]
+	if !ok {
+		return nil, ErrUnknownType
+	}
+	row := k.model()
+	if err := db.WithContext(ctx).Where("deleted_at IS NOT NULL").First(row, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return row, nil
+}
+
+// Restore clears deleted_at after checking that the record's unique values are still free.
+func Restore(ctx context.Context, db *gorm.DB, t string, id uint) error {
+	k, ok := kinds[t]
+	if !ok {
+		return ErrUnknownType
+	}
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		row, err := Load(ctx, tx, t, id)
+		if err != nil {
+			return err
+		}
+		if err := checkConflicts(tx, row); err != nil {
+			return err
+		}
+		updates := map[string]any{"deleted_at": nil}
+		if _, ok := row.(*model.Event); !ok {
+			updates["updated_at"] = time.Now().UTC()
+		}
+		res := tx.Model(k.model()).
+			Where("id = ? AND deleted_at IS NOT NULL", id).
+			Updates(updates)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		return nil
+	})
+}
+
+// checkConflicts rejects a restore that would duplicate a live unique value.
+//
+// The unique indexes also cover deleted rows, so the table itself cannot hold a duplicate;
+// what can is a pending product draft that claimed the slug or style number meanwhile.
+func checkConflicts(tx *gorm.DB, row any) error {
+	switch v := row.(type) {
+	case *model.Product:
+		var n int64
+		if err := tx.Model(&model.Product{}).
+			Where("id <> ? AND deleted_at IS NULL", v.ID).
+			Where("slug = ? OR style_no = ?", v.Slug, v.StyleNo).
+			Count(&n).Error; err != nil {
+			return err
+		}
+		if n > 0 {
+			return ErrConflict
+		}
+		var drafts []model.ProductDraft
+		if err := tx.Where("product_id <> ?", v.ID).Find(&drafts).Error; err != nil {
+			return err
+		}
+		for _, d := range drafts {
+			var p model.Product
+			if err := json.Unmarshal(d.Snapshot, &p); err != nil {
+				continue
+			}
+			if p.Slug == v.Slug || p.StyleNo == v.StyleNo {
+				return ErrConflict
+			}
+		}
+	case *model.User:
+		var n int64
+		if err := tx.Model(&model.User{}).
+			Where("id <> ? AND deleted_at IS NULL AND email = ?", v.ID, v.Email).
+			Count(&n).Error; err != nil {
+			return err
+		}
+		if n > 0 {
+			return ErrConflict
+		}
+	}
+	return nil
+}
@@ -52,7 +52,7 @@ const stripExt = (name: string) => {
 }
 
 export const compressImageToWebpUnderLimit = async (file: File, options?: CompressOptions): Promise<File> => {
-    const maxBytes = options?.maxBytes ?? appEnv.maxImageUploadBytes ?? 1048576
+    const maxBytes = options?.maxBytes ?? appEnv.maxImageUploadBytes ?? 20971520
     const minQuality = options?.minQuality ?? 0.45
     const maxQuality = options?.maxQuality ?? 0.92
 
@@ -56,7 +56,7 @@ const readLocaleFlag = (): unknown => {
 export const appEnv: AppEnv = {
     previewMode: parseBoolean(readPreviewFlag()),
     locale: parseLocale(readLocaleFlag()),
-    maxImageUploadBytes: parseIntNumber(readMaxImageUploadBytes(), 1048576),
+    maxImageUploadBytes: parseIntNumber(readMaxImageUploadBytes(), 20971520),
     mode: import.meta.env.MODE,
     dev: import.meta.env.DEV,
     prod: import.meta.env.PROD,